@@ -57,6 +57,7 @@ type ACME struct {
 	TLSChallenge          *acmeprovider.TLSChallenge  `description:"Activate TLS-ALPN-01 Challenge"`
 	ACMELogging           bool                        `description:"Enable debug logging of ACME actions."`
 	OverrideCertificates  bool                        `description:"Enable to override certificates in key-value store when using storeconfig"`
+	Cluster               *acmeprovider.Cluster       `description:"Share the ACME data between the instances in a KV store, where the elected leader performs the ACME operations"`
 	client                *lego.Client
 	store                 cluster.Store
 	challengeHTTPProvider *challengeHTTPProvider
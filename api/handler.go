@@ -1,16 +1,23 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/containous/mux"
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/healthcheck"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/safe"
 	"github.com/containous/traefik/types"
 	"github.com/containous/traefik/version"
 	"github.com/elazarl/go-bindata-assetfs"
+	"github.com/sirupsen/logrus"
 	thoasstats "github.com/thoas/stats"
 	"github.com/unrolled/render"
 )
@@ -31,13 +38,15 @@ type ProviderRepresentation struct {
 // RouterRepresentation extended version of a router configuration with an ID
 type RouterRepresentation struct {
 	*config.Router
-	ID string `json:"id"`
+	ID    string `json:"id"`
+	Error string `json:"error,omitempty"`
 }
 
 // MiddlewareRepresentation extended version of a middleware configuration with an ID
 type MiddlewareRepresentation struct {
 	*config.Middleware
-	ID string `json:"id"`
+	ID    string `json:"id"`
+	Error string `json:"error,omitempty"`
 }
 
 // ServiceRepresentation extended version of a service configuration with an ID
@@ -46,12 +55,53 @@ type ServiceRepresentation struct {
 	ID string `json:"id"`
 }
 
+// EntryPointRepresentation an entry point with the middlewares applied to all its routers
+type EntryPointRepresentation struct {
+	ID          string   `json:"id"`
+	Address     string   `json:"address"`
+	Middlewares []string `json:"middlewares,omitempty"`
+}
+
+// ServerStatusesRepresentation the passive health of the servers of a service
+type ServerStatusesRepresentation struct {
+	Servers []healthcheck.ServerStatus `json:"servers"`
+}
+
+// LogLevelRepresentation the level of the application logs
+type LogLevelRepresentation struct {
+	Level string `json:"level"`
+}
+
+// HealthRepresentation the aggregated health of all the services
+type HealthRepresentation struct {
+	HealthyServices   int      `json:"healthyServices"`
+	UnhealthyServices []string `json:"unhealthyServices,omitempty"`
+	CriticalServices  []string `json:"criticalServices,omitempty"`
+}
+
+type backendStatusGetter interface {
+	GetBackendStatus(backendName string) (healthcheck.BackendStatus, bool)
+}
+
+type serverStatusesGetter interface {
+	GetServerStatuses(serviceName string) ([]healthcheck.ServerStatus, bool)
+}
+
+type configurationErrorsGetter interface {
+	GetRouterError(providerName, routerName string) error
+	GetMiddlewareError(providerName, middlewareName string) error
+}
+
 // Handler expose api routes
 type Handler struct {
 	EntryPoint            string
 	Dashboard             bool
 	Debug                 bool
 	CurrentConfigurations *safe.Safe
+	HealthCheck           backendStatusGetter
+	ServerStatuses        serverStatusesGetter
+	ConfigurationErrors   configurationErrorsGetter
+	EntryPoints           static.EntryPoints
 	Statistics            *types.Statistics
 	Stats                 *thoasstats.Stats
 	// StatsRecorder         *middlewares.StatsRecorder // FIXME stats
@@ -78,6 +128,11 @@ func (p Handler) Append(router *mux.Router) {
 	router.Methods(http.MethodGet).Path("/api/providers/{provider}/middlewares/{middleware}").HandlerFunc(p.getMiddlewareHandler)
 	router.Methods(http.MethodGet).Path("/api/providers/{provider}/services").HandlerFunc(p.getServicesHandler)
 	router.Methods(http.MethodGet).Path("/api/providers/{provider}/services/{service}").HandlerFunc(p.getServiceHandler)
+	router.Methods(http.MethodGet).Path("/api/providers/{provider}/services/{service}/servers").HandlerFunc(p.getServerStatusesHandler)
+	router.Methods(http.MethodGet).Path("/api/health").HandlerFunc(p.getServicesHealthHandler)
+	router.Methods(http.MethodGet).Path("/api/entrypoints").HandlerFunc(p.getEntryPointsHandler)
+	router.Methods(http.MethodGet).Path("/api/log/level").HandlerFunc(p.getLogLevelHandler)
+	router.Methods(http.MethodPut).Path("/api/log/level").HandlerFunc(p.putLogLevelHandler)
 
 	// FIXME stats
 	// health route
@@ -172,7 +227,13 @@ func (p Handler) getRoutersHandler(rw http.ResponseWriter, request *http.Request
 
 	var routers []RouterRepresentation
 	for name, router := range provider.Routers {
-		routers = append(routers, RouterRepresentation{Router: router, ID: name})
+		representation := RouterRepresentation{Router: router, ID: name}
+		if p.ConfigurationErrors != nil {
+			if err := p.ConfigurationErrors.GetRouterError(providerID, name); err != nil {
+				representation.Error = err.Error()
+			}
+		}
+		routers = append(routers, representation)
 	}
 
 	err := templateRenderer.JSON(rw, http.StatusOK, routers)
@@ -220,7 +281,13 @@ func (p Handler) getMiddlewaresHandler(rw http.ResponseWriter, request *http.Req
 
 	var middlewares []MiddlewareRepresentation
 	for name, middleware := range provider.Middlewares {
-		middlewares = append(middlewares, MiddlewareRepresentation{Middleware: middleware, ID: name})
+		representation := MiddlewareRepresentation{Middleware: middleware, ID: name}
+		if p.ConfigurationErrors != nil {
+			if err := p.ConfigurationErrors.GetMiddlewareError(providerID, name); err != nil {
+				representation.Error = err.Error()
+			}
+		}
+		middlewares = append(middlewares, representation)
 	}
 
 	err := templateRenderer.JSON(rw, http.StatusOK, middlewares)
@@ -302,3 +369,136 @@ func (p Handler) getServiceHandler(rw http.ResponseWriter, request *http.Request
 		http.Error(rw, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+func (p Handler) getServerStatusesHandler(rw http.ResponseWriter, request *http.Request) {
+	providerID := mux.Vars(request)["provider"]
+	serviceID := mux.Vars(request)["service"]
+
+	currentConfigurations := p.CurrentConfigurations.Get().(config.Configurations)
+
+	provider, ok := currentConfigurations[providerID]
+	if !ok {
+		http.NotFound(rw, request)
+		return
+	}
+
+	if _, ok = provider.Services[serviceID]; !ok || p.ServerStatuses == nil {
+		http.NotFound(rw, request)
+		return
+	}
+
+	statuses, ok := p.ServerStatuses.GetServerStatuses(providerID + "." + serviceID)
+	if !ok {
+		http.NotFound(rw, request)
+		return
+	}
+
+	err := templateRenderer.JSON(rw, http.StatusOK, ServerStatusesRepresentation{Servers: statuses})
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (p Handler) getEntryPointsHandler(rw http.ResponseWriter, request *http.Request) {
+	var entryPoints []EntryPointRepresentation
+	for name, entryPoint := range p.EntryPoints {
+		entryPoints = append(entryPoints, EntryPointRepresentation{
+			ID:          name,
+			Address:     entryPoint.Address,
+			Middlewares: entryPoint.Middlewares,
+		})
+	}
+
+	sort.Slice(entryPoints, func(i, j int) bool {
+		return entryPoints[i].ID < entryPoints[j].ID
+	})
+
+	err := templateRenderer.JSON(rw, http.StatusOK, entryPoints)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (p Handler) getLogLevelHandler(rw http.ResponseWriter, request *http.Request) {
+	err := templateRenderer.JSON(rw, http.StatusOK, LogLevelRepresentation{Level: log.GetLevel().String()})
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// putLogLevelHandler changes the level of the application logs, until the next restart.
+func (p Handler) putLogLevelHandler(rw http.ResponseWriter, request *http.Request) {
+	var representation LogLevelRepresentation
+	if err := json.NewDecoder(io.LimitReader(request.Body, 1024)).Decode(&representation); err != nil {
+		http.Error(rw, fmt.Sprintf("invalid body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	level, err := logrus.ParseLevel(strings.ToLower(representation.Level))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.SetLevel(level)
+	log.FromContext(request.Context()).Infof("Log level set to %s", level)
+
+	err = templateRenderer.JSON(rw, http.StatusOK, LogLevelRepresentation{Level: level.String()})
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (p Handler) getServicesHealthHandler(rw http.ResponseWriter, request *http.Request) {
+	currentConfigurations := p.CurrentConfigurations.Get().(config.Configurations)
+
+	health := HealthRepresentation{}
+	for providerName, provider := range currentConfigurations {
+		for serviceName, service := range provider.Services {
+			if service.LoadBalancer == nil {
+				continue
+			}
+
+			qualifiedName := providerName + "." + serviceName
+			if p.isServiceHealthy(qualifiedName, service.LoadBalancer) {
+				health.HealthyServices++
+				continue
+			}
+
+			health.UnhealthyServices = append(health.UnhealthyServices, qualifiedName)
+			if service.LoadBalancer.HealthCheck != nil && service.LoadBalancer.HealthCheck.Critical {
+				health.CriticalServices = append(health.CriticalServices, qualifiedName)
+			}
+		}
+	}
+
+	sort.Strings(health.UnhealthyServices)
+	sort.Strings(health.CriticalServices)
+
+	statusCode := http.StatusOK
+	if len(health.CriticalServices) > 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	err := templateRenderer.JSON(rw, statusCode, health)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// isServiceHealthy tells if the service has at least one healthy server.
+// Without health check, all the configured servers are considered healthy.
+func (p Handler) isServiceHealthy(serviceName string, lb *config.LoadBalancerService) bool {
+	if p.HealthCheck != nil {
+		if status, ok := p.HealthCheck.GetBackendStatus(serviceName); ok {
+			return status.Up > 0
+		}
+	}
+
+	return len(lb.Servers) > 0
+}
@@ -1,14 +1,23 @@
 package api
 
 import (
+	"bytes"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/containous/mux"
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/healthcheck"
+	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/safe"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -208,3 +217,414 @@ func TestHandler_Configuration(t *testing.T) {
 		})
 	}
 }
+
+type fakeHealthCheck map[string]healthcheck.BackendStatus
+
+func (f fakeHealthCheck) GetBackendStatus(backendName string) (healthcheck.BackendStatus, bool) {
+	status, ok := f[backendName]
+	return status, ok
+}
+
+func TestHandler_Health(t *testing.T) {
+	type expected struct {
+		statusCode int
+		body       string
+	}
+
+	testCases := []struct {
+		desc          string
+		configuration config.Configurations
+		healthCheck   fakeHealthCheck
+		expected      expected
+	}{
+		{
+			desc:          "No services",
+			configuration: config.Configurations{},
+			expected:      expected{statusCode: http.StatusOK, body: `{"healthyServices":0}`},
+		},
+		{
+			desc: "Services without health check",
+			configuration: config.Configurations{
+				"foo": {
+					Services: map[string]*config.Service{
+						"bar": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers: []config.Server{{URL: "http://127.0.0.1"}},
+							},
+						},
+						"baz": {
+							LoadBalancer: &config.LoadBalancerService{},
+						},
+					},
+				},
+			},
+			expected: expected{statusCode: http.StatusOK, body: `{"healthyServices":1,"unhealthyServices":["foo.baz"]}`},
+		},
+		{
+			desc: "Unhealthy non critical service",
+			configuration: config.Configurations{
+				"foo": {
+					Services: map[string]*config.Service{
+						"bar": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers:     []config.Server{{URL: "http://127.0.0.1"}},
+								HealthCheck: &config.HealthCheck{Path: "/health"},
+							},
+						},
+					},
+				},
+			},
+			healthCheck: fakeHealthCheck{"foo.bar": {Up: 0, Down: 1}},
+			expected:    expected{statusCode: http.StatusOK, body: `{"healthyServices":0,"unhealthyServices":["foo.bar"]}`},
+		},
+		{
+			desc: "Unhealthy critical service",
+			configuration: config.Configurations{
+				"foo": {
+					Services: map[string]*config.Service{
+						"bar": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers:     []config.Server{{URL: "http://127.0.0.1"}},
+								HealthCheck: &config.HealthCheck{Path: "/health", Critical: true},
+							},
+						},
+						"baz": {
+							LoadBalancer: &config.LoadBalancerService{
+								Servers:     []config.Server{{URL: "http://127.0.0.1"}, {URL: "http://127.0.0.2"}},
+								HealthCheck: &config.HealthCheck{Path: "/health", Critical: true},
+							},
+						},
+					},
+				},
+			},
+			healthCheck: fakeHealthCheck{
+				"foo.bar": {Up: 0, Down: 1},
+				"foo.baz": {Up: 1, Down: 1},
+			},
+			expected: expected{statusCode: http.StatusServiceUnavailable, body: `{"healthyServices":1,"unhealthyServices":["foo.bar"],"criticalServices":["foo.bar"]}`},
+		},
+		{
+			desc: "Critical service without servers",
+			configuration: config.Configurations{
+				"foo": {
+					Services: map[string]*config.Service{
+						"bar": {
+							LoadBalancer: &config.LoadBalancerService{
+								HealthCheck: &config.HealthCheck{Path: "/health", Critical: true},
+							},
+						},
+					},
+				},
+			},
+			expected: expected{statusCode: http.StatusServiceUnavailable, body: `{"healthyServices":0,"unhealthyServices":["foo.bar"],"criticalServices":["foo.bar"]}`},
+		},
+		{
+			desc: "Critical service whose health check has no servers",
+			configuration: config.Configurations{
+				"foo": {
+					Services: map[string]*config.Service{
+						"bar": {
+							LoadBalancer: &config.LoadBalancerService{
+								HealthCheck: &config.HealthCheck{Path: "/health", Critical: true},
+							},
+						},
+					},
+				},
+			},
+			healthCheck: fakeHealthCheck{"foo.bar": {}},
+			expected:    expected{statusCode: http.StatusServiceUnavailable, body: `{"healthyServices":0,"unhealthyServices":["foo.bar"],"criticalServices":["foo.bar"]}`},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			currentConfiguration := &safe.Safe{}
+			currentConfiguration.Set(test.configuration)
+
+			handler := Handler{
+				CurrentConfigurations: currentConfiguration,
+				HealthCheck:           test.healthCheck,
+			}
+
+			router := mux.NewRouter()
+			handler.Append(router)
+
+			server := httptest.NewServer(router)
+
+			resp, err := http.DefaultClient.Get(server.URL + "/api/health")
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected.statusCode, resp.StatusCode)
+
+			content, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected.body, string(content))
+		})
+	}
+}
+
+type fakeConfigurationErrors map[string]error
+
+func (f fakeConfigurationErrors) GetRouterError(providerName, routerName string) error {
+	return f[providerName+".routers."+routerName]
+}
+
+func (f fakeConfigurationErrors) GetMiddlewareError(providerName, middlewareName string) error {
+	return f[providerName+".middlewares."+middlewareName]
+}
+
+func TestHandler_ConfigurationErrors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		path     string
+		expected string
+	}{
+		{
+			desc:     "Get the routers",
+			path:     "/api/providers/foo/routers",
+			expected: `[{"entryPoints":["foo"],"middlewares":["bar"],"id":"bar","error":"boom"}]`,
+		},
+		{
+			desc:     "Get the middlewares",
+			path:     "/api/providers/foo/middlewares",
+			expected: `[{"replacePathRegex":{"regex":"("},"id":"bar","error":"invalid regex"}]`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			currentConfiguration := &safe.Safe{}
+			currentConfiguration.Set(config.Configurations{
+				"foo": {
+					Routers: map[string]*config.Router{
+						"bar": {EntryPoints: []string{"foo"}, Middlewares: []string{"bar"}},
+					},
+					Middlewares: map[string]*config.Middleware{
+						"bar": {ReplacePathRegex: &config.ReplacePathRegex{Regex: "("}},
+					},
+				},
+			})
+
+			handler := Handler{
+				CurrentConfigurations: currentConfiguration,
+				ConfigurationErrors: fakeConfigurationErrors{
+					"foo.routers.bar":     errors.New("boom"),
+					"foo.middlewares.bar": errors.New("invalid regex"),
+				},
+			}
+
+			router := mux.NewRouter()
+			handler.Append(router)
+
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			content, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, string(content))
+		})
+	}
+}
+
+type fakeServerStatuses map[string][]healthcheck.ServerStatus
+
+func (f fakeServerStatuses) GetServerStatuses(serviceName string) ([]healthcheck.ServerStatus, bool) {
+	statuses, ok := f[serviceName]
+	return statuses, ok
+}
+
+func TestHandler_ServerStatuses(t *testing.T) {
+	recoveryTime := time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc               string
+		path               string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			desc:               "Service with a circuit breaker",
+			path:               "/api/providers/foo/services/bar/servers",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"servers":[{"url":"http://127.0.0.1","ejected":true,"recoveryTime":"2019-01-01T00:00:00Z","ejections":2,"requests":0,"errors":0},{"url":"http://127.0.0.2","ejected":false,"ejections":0,"requests":10,"errors":1}]}`,
+		},
+		{
+			desc:               "Service without circuit breaker",
+			path:               "/api/providers/foo/services/baz/servers",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:               "Service not found",
+			path:               "/api/providers/foo/services/qux/servers",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			currentConfiguration := &safe.Safe{}
+			currentConfiguration.Set(config.Configurations{
+				"foo": {
+					Services: map[string]*config.Service{
+						"bar": {LoadBalancer: &config.LoadBalancerService{}},
+						"baz": {LoadBalancer: &config.LoadBalancerService{}},
+					},
+				},
+			})
+
+			handler := Handler{
+				CurrentConfigurations: currentConfiguration,
+				ServerStatuses: fakeServerStatuses{
+					"foo.bar": {
+						{URL: "http://127.0.0.1", Ejected: true, RecoveryTime: &recoveryTime, Ejections: 2},
+						{URL: "http://127.0.0.2", Requests: 10, Errors: 1},
+					},
+				},
+			}
+
+			router := mux.NewRouter()
+			handler.Append(router)
+
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatusCode, resp.StatusCode)
+			if test.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			content, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			err = resp.Body.Close()
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedBody, string(content))
+		})
+	}
+}
+
+func TestHandler_EntryPoints(t *testing.T) {
+	handler := Handler{
+		EntryPoints: static.EntryPoints{
+			"web": {
+				Address:     ":80",
+				Middlewares: []string{"file.secure-headers"},
+			},
+			"api": {
+				Address: ":8080",
+			},
+		},
+	}
+
+	router := mux.NewRouter()
+	handler.Append(router)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.DefaultClient.Get(server.URL + "/api/entrypoints")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	content, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	err = resp.Body.Close()
+	require.NoError(t, err)
+
+	assert.Equal(t, `[{"id":"api","address":":8080"},{"id":"web","address":":80","middlewares":["file.secure-headers"]}]`, string(content))
+}
+
+func TestHandler_LogLevel(t *testing.T) {
+	// The log level is global.
+	defer log.SetLevel(log.GetLevel())
+	log.SetLevel(logrus.InfoLevel)
+
+	logs := &bytes.Buffer{}
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stdout)
+
+	router := mux.NewRouter()
+	Handler{}.Append(router)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	log.WithoutContext().Debug("hidden debug line")
+
+	testCases := []struct {
+		desc               string
+		body               string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			desc:               "invalid level",
+			body:               `{"level":"verbose"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "invalid body",
+			body:               `debug`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "debug level",
+			body:               `{"level":"DEBUG"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"level":"debug"}`,
+		},
+	}
+
+	for _, test := range testCases {
+		req, err := http.NewRequest(http.MethodPut, server.URL+"/api/log/level", strings.NewReader(test.body))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		content, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, test.expectedStatusCode, resp.StatusCode, test.desc)
+		if test.expectedBody != "" {
+			assert.Equal(t, test.expectedBody, string(content), test.desc)
+		}
+	}
+
+	log.WithoutContext().Debug("visible debug line")
+
+	resp, err := http.DefaultClient.Get(server.URL + "/api/log/level")
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, `{"level":"debug"}`, string(content))
+	assert.NotContains(t, logs.String(), "hidden debug line")
+	assert.Contains(t, logs.String(), "visible debug line")
+}
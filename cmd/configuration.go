@@ -23,6 +23,7 @@ import (
 	"github.com/containous/traefik/provider/file"
 	"github.com/containous/traefik/provider/marathon"
 	"github.com/containous/traefik/provider/rest"
+	"github.com/containous/traefik/tracing"
 	"github.com/containous/traefik/tracing/datadog"
 	"github.com/containous/traefik/tracing/jaeger"
 	"github.com/containous/traefik/tracing/zipkin"
@@ -49,6 +50,10 @@ func NewTraefikConfiguration() *TraefikConfiguration {
 			},
 			ServersTransport: &static.ServersTransport{
 				MaxIdleConnsPerHost: 200,
+				ForwardingTimeouts: &static.ForwardingTimeouts{
+					DialTimeout:           parse.Duration(configuration.DefaultDialTimeout),
+					ResponseHeaderTimeout: parse.Duration(static.DefaultResponseHeaderTimeout),
+				},
 			},
 		},
 		ConfigFile: "",
@@ -91,6 +96,7 @@ func NewTraefikDefaultPointersConfiguration() *TraefikConfiguration {
 		Backend:       "jaeger",
 		ServiceName:   "traefik",
 		SpanNameLimit: 0,
+		Propagation:   tracing.PropagationVendor,
 		Jaeger: &jaeger.Config{
 			SamplingServerURL:  "http://localhost:5778/sampling",
 			SamplingType:       "const",
@@ -1,9 +1,11 @@
 package healthcheck
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"time"
@@ -58,16 +60,24 @@ func Do(staticConfiguration static.Configuration) (*http.Response, error) {
 
 	client := &http.Client{Timeout: 5 * time.Second}
 	protocol := "http"
+	address := pingEntryPoint.Address
 
+	tr := &http.Transport{}
 	if pingEntryPoint.TLS != nil {
 		protocol = "https"
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client.Transport = tr
+	}
+
+	if socketPath, ok := pingEntryPoint.UnixSocketPath(); ok {
+		address = "localhost"
+		tr.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
 		}
 		client.Transport = tr
 	}
 
 	path := "/"
 
-	return client.Head(protocol + "://" + pingEntryPoint.Address + path + "ping")
+	return client.Head(protocol + "://" + address + path + "ping")
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/containous/traefik/cmd/bug"
 	"github.com/containous/traefik/cmd/healthcheck"
 	"github.com/containous/traefik/cmd/storeconfig"
+	"github.com/containous/traefik/cmd/validate"
 	cmdVersion "github.com/containous/traefik/cmd/version"
 	"github.com/containous/traefik/collector"
 	"github.com/containous/traefik/config"
@@ -30,6 +31,7 @@ import (
 	"github.com/containous/traefik/old/provider/kubernetes"
 	oldtypes "github.com/containous/traefik/old/types"
 	"github.com/containous/traefik/provider/aggregator"
+	"github.com/containous/traefik/provider/env"
 	"github.com/containous/traefik/safe"
 	"github.com/containous/traefik/server"
 	"github.com/containous/traefik/server/router"
@@ -129,6 +131,7 @@ Complete documentation is available at https://traefik.io`,
 	f.AddCommand(bug.NewCmd(traefikConfiguration, traefikPointersConfiguration))
 	f.AddCommand(storeConfigCmd)
 	f.AddCommand(healthcheck.NewCmd(traefikConfiguration, traefikPointersConfiguration))
+	f.AddCommand(validate.NewCmd(traefikCmd))
 
 	usedCmd, err := f.GetCommand()
 	if err != nil {
@@ -192,6 +195,13 @@ Complete documentation is available at https://traefik.io`,
 }
 
 func runCmd(staticConfiguration *static.Configuration, configFile string) error {
+	// The environment variables only override the static configuration when they are enabled by it.
+	if staticConfiguration.Providers != nil && staticConfiguration.Providers.Env != nil {
+		if err := env.Decode(os.Environ(), staticConfiguration); err != nil {
+			return fmt.Errorf("invalid environment variables: %v", err)
+		}
+	}
+
 	configureLogging(staticConfiguration)
 
 	if len(configFile) > 0 {
@@ -278,6 +288,7 @@ func runCmd(staticConfiguration *static.Configuration, configFile string) error
 
 	if staticConfiguration.Ping != nil {
 		staticConfiguration.Ping.WithContext(ctx)
+		svr.AddListener(staticConfiguration.Ping.ListenConfiguration)
 	}
 
 	svr.Start(ctx)
@@ -0,0 +1,130 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/containous/flaeg"
+	"github.com/containous/staert"
+	"github.com/containous/traefik/cmd"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/provider/file"
+	"github.com/containous/traefik/server"
+)
+
+// Output formats of the report.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Configuration holds the options of the validate command.
+type Configuration struct {
+	ConfigFile string `short:"c" description:"Configuration file to validate (TOML)."`
+	Format     string `description:"Output format of the report: text or json."`
+}
+
+// Report is the result of a validation.
+type Report struct {
+	ConfigFile string                      `json:"configFile,omitempty"`
+	Valid      bool                        `json:"valid"`
+	Errors     []server.ConfigurationError `json:"errors"`
+}
+
+// NewCmd builds a new Validate command.
+// The static configuration is read from the TOML file only, the command line flags of traefik do not apply.
+func NewCmd(traefikCmd *flaeg.Command) *flaeg.Command {
+	conf := &Configuration{Format: FormatText}
+
+	return &flaeg.Command{
+		Name:                  "validate",
+		Description:           `Validates the static and dynamic configurations without starting traefik`,
+		Config:                conf,
+		DefaultPointersConfig: &Configuration{},
+		Run: func() error {
+			if conf.Format != FormatText && conf.Format != FormatJSON {
+				return fmt.Errorf("unknown report format %q", conf.Format)
+			}
+
+			report := Validate(traefikCmd, conf.ConfigFile)
+			if err := report.Write(os.Stdout, conf.Format); err != nil {
+				return err
+			}
+
+			if !report.Valid {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+}
+
+// Validate loads the static configuration of the traefik command from the configuration file,
+// as well as the dynamic configuration of the file provider, and checks them without binding any entry point.
+// The dynamic configurations of the other providers depend on the state of their backends, and are not checked.
+func Validate(traefikCmd *flaeg.Command, configFile string) Report {
+	toml := staert.NewTomlSource("traefik", []string{configFile, "/etc/traefik/", "$HOME/.traefik/", "."})
+
+	s := staert.NewStaert(traefikCmd)
+	s.AddSource(toml)
+	if _, err := s.LoadConfig(); err != nil {
+		return newReport(toml.ConfigFileUsed(), []server.ConfigurationError{
+			{Kind: server.KindStatic, Name: toml.ConfigFileUsed(), Message: err.Error()},
+		})
+	}
+
+	staticConfiguration := &traefikCmd.Config.(*cmd.TraefikConfiguration).Configuration
+	staticConfiguration.SetEffectiveConfiguration(toml.ConfigFileUsed())
+
+	errs := server.ValidateEntryPoints(staticConfiguration.EntryPoints)
+
+	configurations := make(config.Configurations)
+	if fileProvider := staticConfiguration.Providers.File; fileProvider != nil {
+		conf, err := buildFileConfiguration(fileProvider)
+		if err != nil {
+			errs = append(errs, server.ConfigurationError{Kind: server.KindProvider, Name: "file", Message: err.Error()})
+		} else if conf != nil {
+			configurations["file"] = conf
+		}
+	}
+
+	errs = append(errs, server.ValidateConfigurations(staticConfiguration.EntryPoints, configurations)...)
+
+	return newReport(toml.ConfigFileUsed(), errs)
+}
+
+func buildFileConfiguration(fileProvider *file.Provider) (*config.Configuration, error) {
+	if err := fileProvider.Init(); err != nil {
+		return nil, err
+	}
+	return fileProvider.BuildConfiguration()
+}
+
+func newReport(configFile string, errs []server.ConfigurationError) Report {
+	if errs == nil {
+		errs = []server.ConfigurationError{}
+	}
+	return Report{ConfigFile: configFile, Valid: len(errs) == 0, Errors: errs}
+}
+
+// Write writes the report in the given format.
+func (r Report) Write(w io.Writer, format string) error {
+	if format == FormatJSON {
+		return json.NewEncoder(w).Encode(r)
+	}
+
+	for _, err := range r.Errors {
+		if _, errW := fmt.Fprintln(w, err.Error()); errW != nil {
+			return errW
+		}
+	}
+
+	if r.Valid {
+		_, err := fmt.Fprintln(w, "Configuration is valid")
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Configuration is invalid: %d error(s)\n", len(r.Errors))
+	return err
+}
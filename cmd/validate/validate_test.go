@@ -0,0 +1,122 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/flaeg"
+	"github.com/containous/traefik/cmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		content        string
+		expectedValid  bool
+		expectedErrors []string
+	}{
+		{
+			desc: "valid configuration",
+			content: `
+[entryPoints]
+  [entryPoints.web]
+  address = ":8000"
+
+[providers.file]
+
+[routers.foo]
+  entryPoints = ["web"]
+  rule = "Path(` + "`/foo`" + `)"
+  service = "bar"
+
+[services.bar.loadbalancer]
+  [[services.bar.loadbalancer.servers]]
+    url = "http://127.0.0.1:9000"
+`,
+			expectedValid: true,
+		},
+		{
+			desc: "invalid rule and entry point",
+			content: `
+[entryPoints]
+  [entryPoints.web]
+  address = ":foo"
+
+[providers.file]
+
+[routers.foo]
+  entryPoints = ["web"]
+  rule = "Invalid(` + "`/foo`" + `)"
+  service = "bar"
+
+[services.bar.loadbalancer]
+  [[services.bar.loadbalancer.servers]]
+    url = "http://127.0.0.1:9000"
+`,
+			expectedErrors: []string{"entryPoint", "router"},
+		},
+		{
+			desc:           "invalid static configuration",
+			content:        `[entryPoints`,
+			expectedErrors: []string{"static"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "traefik-validate")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			configFile := filepath.Join(dir, "traefik.toml")
+			err = ioutil.WriteFile(configFile, []byte(test.content), 0644)
+			require.NoError(t, err)
+
+			report := Validate(newTraefikCmd(), configFile)
+
+			assert.Equal(t, test.expectedValid, report.Valid)
+
+			var kinds []string
+			for _, err := range report.Errors {
+				kinds = append(kinds, err.Kind)
+			}
+			assert.Equal(t, test.expectedErrors, kinds)
+		})
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	report := newReport("traefik.toml", nil)
+
+	buf := &bytes.Buffer{}
+	err := report.Write(buf, FormatJSON)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"configFile":"traefik.toml","valid":true,"errors":[]}`, buf.String())
+
+	var decoded Report
+	err = json.Unmarshal(buf.Bytes(), &decoded)
+	require.NoError(t, err)
+	assert.True(t, decoded.Valid)
+
+	buf.Reset()
+	err = report.Write(buf, FormatText)
+	require.NoError(t, err)
+	assert.Equal(t, "Configuration is valid\n", buf.String())
+}
+
+func newTraefikCmd() *flaeg.Command {
+	return &flaeg.Command{
+		Name:                  "traefik",
+		Config:                cmd.NewTraefikConfiguration(),
+		DefaultPointersConfig: cmd.NewTraefikDefaultPointersConfiguration(),
+		Run:                   func() error { return nil },
+	}
+}
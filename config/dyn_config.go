@@ -3,10 +3,12 @@ package config
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"reflect"
+	"strings"
 
 	traefiktls "github.com/containous/traefik/tls"
 )
@@ -18,16 +20,80 @@ type Router struct {
 	Service     string   `json:"service,omitempty" toml:",omitempty"`
 	Rule        string   `json:"rule,omitempty" toml:",omitempty"`
 	Priority    int      `json:"priority,omitempty" toml:"priority,omitzero"`
+	// CatchAll makes the router, which has no rule, receive the requests matched by no other router of its entry points.
+	CatchAll bool `json:"catchAll,omitempty" toml:",omitempty"`
+
+	Observability *RouterObservability `json:"observability,omitempty" toml:",omitempty"`
+	TLS           *RouterTLSConfig     `json:"tls,omitempty" toml:",omitempty" label:"allowEmpty"`
+
+	// EntryPointsMiddlewares are the middlewares of the router on the given entry points, instead of Middlewares.
+	EntryPointsMiddlewares map[string][]string `json:"entryPointsMiddlewares,omitempty" toml:",omitempty"`
+}
+
+// RouterTLSConfig holds the TLS requirements of a router, on top of the ones of its entry points.
+// ClientCertificateRequired rejects with a 403 the requests without a client certificate verified by the client CAs of the entry point,
+// which lets the other routers of the entry point make the client certificates optional.
+// Options is the name of the TLS options of the connections to the domains of the router, instead of the ones of its entry points.
+type RouterTLSConfig struct {
+	ClientCertificateRequired bool   `json:"clientCertificateRequired,omitempty" toml:",omitempty"`
+	Options                   string `json:"options,omitempty" toml:",omitempty"`
+}
+
+// RouterObservability holds the observability features to disable on a router, e.g. a high-volume health check one.
+// DebugHeaders adds headers describing the router, the service and the backend server that handled each request to the responses.
+type RouterObservability struct {
+	DisableAccessLogs bool `json:"disableAccessLogs,omitempty" toml:",omitempty"`
+	DisableTracing    bool `json:"disableTracing,omitempty" toml:",omitempty"`
+	DebugHeaders      bool `json:"debugHeaders,omitempty" toml:",omitempty"`
+}
+
+// AccessLogsDisabled returns true if the access logs are disabled on the router.
+func (r *Router) AccessLogsDisabled() bool {
+	return r.Observability != nil && r.Observability.DisableAccessLogs
+}
+
+// TracingDisabled returns true if the tracing is disabled on the router.
+func (r *Router) TracingDisabled() bool {
+	return r.Observability != nil && r.Observability.DisableTracing
+}
+
+// DebugHeadersEnabled returns true if the debug headers are added to the responses of the router.
+func (r *Router) DebugHeadersEnabled() bool {
+	return r.Observability != nil && r.Observability.DebugHeaders
+}
+
+// MiddlewaresOn returns the middlewares of the router on the given entry point.
+func (r *Router) MiddlewaresOn(entryPointName string) []string {
+	if middlewares, ok := r.EntryPointsMiddlewares[entryPointName]; ok {
+		return middlewares
+	}
+	return r.Middlewares
+}
+
+// ClientCertificateRequired returns true if the requests of the router must present a client certificate.
+func (r *Router) ClientCertificateRequired() bool {
+	return r.TLS != nil && r.TLS.ClientCertificateRequired
 }
 
 // LoadBalancerService holds the LoadBalancerService configuration.
 type LoadBalancerService struct {
-	Stickiness         *Stickiness         `json:"stickiness,omitempty" toml:",omitempty" label:"allowEmpty"`
-	Servers            []Server            `json:"servers,omitempty" toml:",omitempty" label-slice-as-struct:"server"`
-	Method             string              `json:"method,omitempty" toml:",omitempty"`
-	HealthCheck        *HealthCheck        `json:"healthCheck,omitempty" toml:",omitempty"`
-	PassHostHeader     bool                `json:"passHostHeader" toml:",omitempty"`
-	ResponseForwarding *ResponseForwarding `json:"forwardingResponse,omitempty" toml:",omitempty"`
+	Stickiness         *Stickiness           `json:"stickiness,omitempty" toml:",omitempty" label:"allowEmpty"`
+	Servers            []Server              `json:"servers,omitempty" toml:",omitempty" label-slice-as-struct:"server"`
+	Method             string                `json:"method,omitempty" toml:",omitempty"`
+	HealthCheck        *HealthCheck          `json:"healthCheck,omitempty" toml:",omitempty"`
+	PassHostHeader     bool                  `json:"passHostHeader" toml:",omitempty"`
+	ResponseForwarding *ResponseForwarding   `json:"forwardingResponse,omitempty" toml:",omitempty"`
+	LatencyWeighting   *LatencyWeighting     `json:"latencyWeighting,omitempty" toml:",omitempty" label:"allowEmpty"`
+	SlowStart          *SlowStart            `json:"slowStart,omitempty" toml:",omitempty" label:"allowEmpty"`
+	CircuitBreaker     *ServerCircuitBreaker `json:"circuitBreaker,omitempty" toml:",omitempty" label:"allowEmpty"`
+	Unavailable        *Unavailable          `json:"unavailable,omitempty" toml:",omitempty"`
+	RateLimit          *ServiceRateLimit     `json:"rateLimit,omitempty" toml:",omitempty"`
+	// Scheme is the default scheme of the servers whose URL has no scheme.
+	Scheme string `json:"scheme,omitempty" toml:",omitempty"`
+	// DefaultUserAgent is the User-Agent of the forwarded requests whose client sent none.
+	DefaultUserAgent string `json:"defaultUserAgent,omitempty" toml:",omitempty"`
+	// ServerName is the server name sent in the TLS handshake (SNI) to the servers, instead of their host.
+	ServerName string `json:"serverName,omitempty" toml:",omitempty"`
 }
 
 // Mergeable tells if the given service is mergeable.
@@ -56,14 +122,74 @@ func (l *LoadBalancerService) SetDefaults() {
 // ResponseForwarding holds configuration for the forward of the response.
 type ResponseForwarding struct {
 	FlushInterval string `json:"flushInterval,omitempty" toml:",omitempty"`
+	// Timeout caps the time spent by a request in the service: past it, the request to the server is canceled,
+	// and a 504 is returned if the response was not sent yet.
+	// FIXME change string to parse.Duration
+	Timeout string `json:"timeout,omitempty" toml:",omitempty"`
+}
+
+// LatencyWeighting holds the configuration of the weights adjustment based on the servers latency.
+type LatencyWeighting struct {
+	// FIXME change string to parse.Duration
+	Interval string `json:"interval,omitempty" toml:",omitempty"`
+	// Sensitivity is the exponent applied to the ratio between the latency of the fastest server and the latency of a server.
+	// The higher it is, the less traffic the slow servers receive. Defaults to 1.
+	Sensitivity float64 `json:"sensitivity,omitempty" toml:",omitempty"`
+}
+
+// SlowStart holds the configuration of the progressive increase of the weight of the servers joining the load-balancer.
+type SlowStart struct {
+	// FIXME change string to parse.Duration
+	Duration string `json:"duration,omitempty" toml:",omitempty"`
+}
+
+// ServerCircuitBreaker holds the configuration of the circuit breaker which tracks the errors of each server,
+// and ejects from the load-balancer the servers whose ratio of errors is too high.
+type ServerCircuitBreaker struct {
+	ErrorRatio  float64 `json:"errorRatio,omitempty" toml:",omitempty"`
+	MinRequests int     `json:"minRequests,omitempty" toml:",omitempty"`
+	// FIXME change string to parse.Duration
+	Window string `json:"window,omitempty" toml:",omitempty"`
+	// FIXME change string to parse.Duration
+	RecoveryDuration string `json:"recoveryDuration,omitempty" toml:",omitempty"`
+}
+
+// ServiceRateLimit holds the configuration of the rate limiter of the requests sent to a service, whatever their client.
+// The requests beyond the rate are delayed up to MaxDelay, and rejected with a 503 after it.
+type ServiceRateLimit struct {
+	// Average is the number of requests per second.
+	Average int64 `json:"average,omitempty" toml:",omitempty"`
+	// Burst defaults to 1.
+	Burst int64 `json:"burst,omitempty" toml:",omitempty"`
+	// FIXME change string to parse.Duration
+	MaxDelay string `json:"maxDelay,omitempty" toml:",omitempty"`
+}
+
+// Unavailable holds the response of a load-balancer without any available server, instead of a 503:
+// a custom status code, a redirect, or the response of a fallback service.
+type Unavailable struct {
+	// StatusCode defaults to 503, or to 302 with a redirect.
+	StatusCode int    `json:"statusCode,omitempty" toml:",omitempty"`
+	Redirect   string `json:"redirect,omitempty" toml:",omitempty"`
+	Service    string `json:"service,omitempty" toml:",omitempty"`
 }
 
 // Stickiness holds the stickiness configuration.
 type Stickiness struct {
-	CookieName string `json:"cookieName,omitempty" toml:",omitempty"`
+	CookieName  string `json:"cookieName,omitempty" toml:",omitempty"`
+	Secure      bool   `json:"secure,omitempty" toml:",omitempty"`
+	HTTPOnly    bool   `json:"httpOnly,omitempty" toml:",omitempty"`
+	SameSite    string `json:"sameSite,omitempty" toml:",omitempty"`
+	Partitioned bool   `json:"partitioned,omitempty" toml:",omitempty"`
+	// ConsistentFailover sends the clients of a server which is down to a server chosen by consistent hashing of their cookie,
+	// until their server recovers.
+	ConsistentFailover bool `json:"consistentFailover,omitempty" toml:",omitempty"`
+	// HeaderName pins the clients through this header, holding an opaque hash of their server, instead of a cookie.
+	HeaderName string `json:"headerName,omitempty" toml:",omitempty"`
 }
 
 // Server holds the server configuration.
+// A server with a weight of zero is drained: it does not receive new traffic.
 type Server struct {
 	URL    string `json:"url" label:"-"`
 	Scheme string `toml:"-" json:"-"`
@@ -77,6 +203,45 @@ func (s *Server) SetDefaults() {
 	s.Scheme = "http"
 }
 
+// UnmarshalJSON decodes the server, with a weight of 1 when it is not specified.
+func (s *Server) UnmarshalJSON(data []byte) error {
+	type rawServer Server
+	server := rawServer{Weight: 1}
+	if err := json.Unmarshal(data, &server); err != nil {
+		return err
+	}
+
+	*s = Server(server)
+	return nil
+}
+
+// UnmarshalTOML decodes the server, with a weight of 1 when it is not specified.
+func (s *Server) UnmarshalTOML(data interface{}) error {
+	values, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid server: %v", data)
+	}
+
+	server := Server{Weight: 1}
+	for key, value := range values {
+		switch strings.ToLower(key) {
+		case "url":
+			if server.URL, ok = value.(string); !ok {
+				return fmt.Errorf("invalid server URL: %v", value)
+			}
+		case "weight":
+			weight, ok := value.(int64)
+			if !ok {
+				return fmt.Errorf("invalid server weight: %v", value)
+			}
+			server.Weight = int(weight)
+		}
+	}
+
+	*s = server
+	return nil
+}
+
 // HealthCheck holds the HealthCheck configuration.
 type HealthCheck struct {
 	Scheme string `json:"scheme,omitempty" toml:",omitempty"`
@@ -88,6 +253,10 @@ type HealthCheck struct {
 	Timeout  string            `json:"timeout,omitempty" toml:",omitempty"`
 	Hostname string            `json:"hostname,omitempty" toml:",omitempty"`
 	Headers  map[string]string `json:"headers,omitempty" toml:",omitempty"`
+	Critical bool              `json:"critical,omitempty" toml:",omitempty"`
+	// Mode is "http" by default, or "grpc" to call the standard gRPC health service, without path.
+	Mode        string `json:"mode,omitempty" toml:",omitempty"`
+	GRPCService string `json:"grpcService,omitempty" toml:",omitempty"`
 }
 
 // ClientTLS holds the TLS specific configurations as client
@@ -184,9 +353,62 @@ type Configuration struct {
 	Middlewares map[string]*Middleware      `json:"middlewares,omitempty" toml:",omitempty"`
 	Services    map[string]*Service         `json:"services,omitempty" toml:",omitempty"`
 	TLS         []*traefiktls.Configuration `json:"-" label:"-"`
+	TLSOptions  []*traefiktls.Options       `json:"-" label:"-"`
 }
 
 // Service holds a service configuration (can only be of one type at the same time).
 type Service struct {
 	LoadBalancer *LoadBalancerService `json:"loadbalancer,omitempty" toml:",omitempty,omitzero"`
+	Mirroring    *Mirroring           `json:"mirroring,omitempty" toml:",omitempty,omitzero" label:"-"`
+	Weighted     *Weighted            `json:"weighted,omitempty" toml:",omitempty,omitzero" label:"-"`
+	Static       *Static              `json:"static,omitempty" toml:",omitempty,omitzero" label:"-"`
+}
+
+// Static holds the configuration of a service which serves the files of a directory, without any backend.
+type Static struct {
+	Root string `json:"root,omitempty" toml:",omitempty"`
+	// IndexFiles are the files served for a directory, index.html by default.
+	IndexFiles       []string `json:"indexFiles,omitempty" toml:",omitempty"`
+	DirectoryListing bool     `json:"directoryListing,omitempty" toml:",omitempty"`
+	// SPAFallback serves the index file of the root instead of a 404, for the single-page applications routing on the client side.
+	SPAFallback bool `json:"spaFallback,omitempty" toml:",omitempty"`
+}
+
+// Weighted holds the configuration of a service which splits the requests between services, according to their weights.
+type Weighted struct {
+	Services []WeightedService `json:"services,omitempty" toml:",omitempty"`
+	// Sticky keeps sending a client to the service it was first sent to, with a cookie.
+	Sticky *Stickiness `json:"sticky,omitempty" toml:",omitempty"`
+}
+
+// WeightedService holds the configuration of a service of a weighted service.
+type WeightedService struct {
+	Name   string `json:"name,omitempty" toml:",omitempty"`
+	Weight int    `json:"weight,omitempty" toml:",omitempty"`
+}
+
+// Mirroring holds the configuration of a service which forwards the requests to a main service,
+// and sends a copy of them to mirror services whose responses are discarded.
+type Mirroring struct {
+	Service string          `json:"service,omitempty" toml:",omitempty"`
+	Mirrors []MirrorService `json:"mirrors,omitempty" toml:",omitempty"`
+	// Headers are added to the mirrored requests only, so that the mirrors can tell them apart.
+	Headers map[string]string `json:"headers,omitempty" toml:",omitempty"`
+	// SynchronousTimeout makes the requests wait for their mirrors, up to this duration, before being forwarded to the main service.
+	// It is meant for testing and debugging only: by default, the mirrors are asynchronous and do not delay the responses.
+	// FIXME change string to parse.Duration
+	SynchronousTimeout string `json:"synchronousTimeout,omitempty" toml:",omitempty"`
+	// Timeout is the maximum duration of a mirrored request, 30s by default.
+	// FIXME change string to parse.Duration
+	Timeout string `json:"timeout,omitempty" toml:",omitempty"`
+	// MaxBodySize is the maximum size in bytes of the body of a mirrored request, 1MiB by default:
+	// the requests with a larger body are not mirrored. -1 means no limit.
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:",omitempty"`
+}
+
+// MirrorService holds the configuration of a mirror.
+type MirrorService struct {
+	Name string `json:"name,omitempty" toml:",omitempty"`
+	// Percent is the percentage of the requests sent to the mirror, from 0 to 100.
+	Percent int `json:"percent,omitempty" toml:",omitempty"`
 }
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"net/http"
+
 	"github.com/containous/flaeg/parse"
 	"github.com/containous/traefik/ip"
 )
@@ -12,22 +14,30 @@ type Middleware struct {
 	StripPrefixRegex  *StripPrefixRegex  `json:"stripPrefixRegex,omitempty"`
 	ReplacePath       *ReplacePath       `json:"replacePath,omitempty"`
 	ReplacePathRegex  *ReplacePathRegex  `json:"replacePathRegex,omitempty"`
+	RequestTimeout    *RequestTimeout    `json:"requestTimeout,omitempty" label:"allowEmpty"`
 	Chain             *Chain             `json:"chain,omitempty"`
 	IPWhiteList       *IPWhiteList       `json:"ipWhiteList,omitempty"`
 	Headers           *Headers           `json:"headers,omitempty"`
+	HostRewrite       *HostRewrite       `json:"hostRewrite,omitempty"`
 	Errors            *ErrorPage         `json:"errors,omitempty"`
 	RateLimit         *RateLimit         `json:"rateLimit,omitempty"`
 	RedirectRegex     *RedirectRegex     `json:"redirectregex,omitempty"`
 	RedirectScheme    *RedirectScheme    `json:"redirectscheme,omitempty"`
 	BasicAuth         *BasicAuth         `json:"basicAuth,omitempty"`
 	DigestAuth        *DigestAuth        `json:"digestAuth,omitempty"`
+	DisableMethods    *DisableMethods    `json:"disableMethods,omitempty"`
 	ForwardAuth       *ForwardAuth       `json:"forwardAuth,omitempty"`
+	ExternalProcessor *ExternalProcessor `json:"externalProcessor,omitempty"`
 	MaxConn           *MaxConn           `json:"maxConn,omitempty"`
+	Maintenance       *Maintenance       `json:"maintenance,omitempty"`
 	Buffering         *Buffering         `json:"buffering,omitempty"`
+	Cache             *Cache             `json:"cache,omitempty" label:"allowEmpty"`
 	CircuitBreaker    *CircuitBreaker    `json:"circuitBreaker,omitempty"`
 	Compress          *Compress          `json:"compress,omitempty" label:"allowEmpty"`
 	PassTLSClientCert *PassTLSClientCert `json:"passTLSClientCert,omitempty"`
 	Retry             *Retry             `json:"retry,omitempty"`
+	StatusCodeRewrite *StatusCodeRewrite `json:"statusCodeRewrite,omitempty"`
+	Bandwidth         *Bandwidth         `json:"bandwidth,omitempty"`
 }
 
 // AddPrefix holds the AddPrefix configuration.
@@ -42,6 +52,19 @@ type Auth struct {
 	Forward *ForwardAuth `json:"forward,omitempty" export:"true"`
 }
 
+// Bandwidth holds the bandwidth limiting configuration.
+type Bandwidth struct {
+	// ReadRate and WriteRate are the maximum rates, in bytes per second, of the request and response bodies.
+	// Zero means unlimited.
+	ReadRate  int64 `json:"readRate,omitempty"`
+	WriteRate int64 `json:"writeRate,omitempty"`
+	// Burst is the maximum number of bytes transferred at once, one second of the rate by default.
+	Burst int64 `json:"burst,omitempty"`
+	// ExtractorFunc, when set, shares the rates between the requests in progress of the same source.
+	ExtractorFunc string      `json:"extractorFunc,omitempty"`
+	IPStrategy    *IPStrategy `json:"ipStrategy,omitempty" label:"allowEmpty"`
+}
+
 // BasicAuth holds the HTTP basic authentication configuration.
 type BasicAuth struct {
 	Users        `json:"users,omitempty" mapstructure:","`
@@ -49,6 +72,9 @@ type BasicAuth struct {
 	Realm        string `json:"realm,omitempty"`
 	RemoveHeader bool   `json:"removeHeader,omitempty"`
 	HeaderField  string `json:"headerField,omitempty" export:"true"`
+	// UnauthorizedBody and UnauthorizedContentType customize the response to the unauthenticated requests.
+	UnauthorizedBody        string `json:"unauthorizedBody,omitempty"`
+	UnauthorizedContentType string `json:"unauthorizedContentType,omitempty"`
 }
 
 // Buffering holds the request/response buffering configuration.
@@ -60,6 +86,14 @@ type Buffering struct {
 	RetryExpression      string `json:"retryExpression,omitempty"`
 }
 
+// Cache holds the response caching configuration.
+type Cache struct {
+	// MaxObjectSize is the maximum size in bytes of a cached response body, defaults to 1MB.
+	MaxObjectSize int64 `json:"maxObjectSize,omitempty"`
+	// MaxSize is the maximum total size in bytes of the cache, defaults to 64MB.
+	MaxSize int64 `json:"maxSize,omitempty"`
+}
+
 // Chain holds a chain of middlewares
 type Chain struct {
 	Middlewares []string `json:"middlewares"`
@@ -67,11 +101,24 @@ type Chain struct {
 
 // CircuitBreaker holds the circuit breaker configuration.
 type CircuitBreaker struct {
-	Expression string `json:"expression,omitempty"`
+	Expression string                  `json:"expression,omitempty"`
+	Fallback   *CircuitBreakerFallback `json:"fallback,omitempty"`
+}
+
+// CircuitBreakerFallback holds the response to the requests while the circuit breaker is open,
+// a 503 by default, or the service serving them.
+type CircuitBreakerFallback struct {
+	StatusCode  int    `json:"statusCode,omitempty"`
+	Body        string `json:"body,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Service     string `json:"service,omitempty"`
 }
 
 // Compress holds the compress configuration.
-type Compress struct{}
+type Compress struct {
+	// Level is the gzip compression level, from 1 (best speed) to 9 (best compression).
+	Level int `json:"level,omitempty"`
+}
 
 // DigestAuth holds the Digest HTTP authentication configuration.
 type DigestAuth struct {
@@ -82,6 +129,13 @@ type DigestAuth struct {
 	HeaderField  string `json:"headerField,omitempty" export:"true"`
 }
 
+// DisableMethods holds the HTTP methods to reject with a 405.
+// AllowPreflight still lets the CORS preflight requests through when OPTIONS is disabled.
+type DisableMethods struct {
+	Methods        []string `json:"methods,omitempty"`
+	AllowPreflight bool     `json:"allowPreflight,omitempty"`
+}
+
 // ErrorPage holds the custom error page configuration.
 type ErrorPage struct {
 	Status  []string `json:"status,omitempty"`
@@ -95,6 +149,18 @@ type ForwardAuth struct {
 	TLS                 *ClientTLS `description:"Enable TLS support" json:"tls,omitempty" export:"true"`
 	TrustForwardHeader  bool       `description:"Trust X-Forwarded-* headers" json:"trustForwardHeader,omitempty" export:"true"`
 	AuthResponseHeaders []string   `description:"Headers to be forwarded from auth response" json:"authResponseHeaders,omitempty"`
+	RemoveHeader        bool       `description:"Remove the Authorization header of the request forwarded to the backend once authenticated" json:"removeHeader,omitempty"`
+}
+
+// ExternalProcessor holds the external processor configuration.
+// The external processor receives each request, and decides whether it is denied,
+// or forwarded with rewritten headers and path.
+type ExternalProcessor struct {
+	Address string     `description:"External processor address" json:"address,omitempty"`
+	TLS     *ClientTLS `description:"Enable TLS support" json:"tls,omitempty" export:"true"`
+	// FIXME change string to parse.Duration
+	Timeout  string `description:"Timeout of the calls to the external processor (default 1s)" json:"timeout,omitempty" export:"true"`
+	FailOpen bool   `description:"Forward the unchanged request when the external processor fails, instead of responding with a 503" json:"failOpen,omitempty" export:"true"`
 }
 
 // Headers holds the custom header configuration.
@@ -154,21 +220,49 @@ func (h *Headers) HasSecureHeadersDefined() bool {
 		h.IsDevelopment)
 }
 
+// HostRewrite holds the Host header rewriting configuration.
+type HostRewrite struct {
+	Host          string `json:"host,omitempty"`
+	UseServerHost bool   `json:"useServerHost,omitempty"`
+}
+
 // IPStrategy holds the ip strategy configuration.
 type IPStrategy struct {
 	Depth       int      `json:"depth,omitempty" export:"true"`
 	ExcludedIPs []string `json:"excludedIPs,omitempty"`
+	TrustedIPs  []string `json:"trustedIPs,omitempty"`
 }
 
 // Get an IP selection strategy
 // if nil return the RemoteAddr strategy
 // else return a strategy base on the configuration using the X-Forwarded-For Header.
 // Depth override the ExcludedIPs
+// If TrustedIPs is set, the X-Forwarded-For Header is only used for requests coming from these IPs.
 func (s *IPStrategy) Get() (ip.Strategy, error) {
 	if s == nil {
 		return &ip.RemoteAddrStrategy{}, nil
 	}
 
+	strategy, err := s.getForwardedStrategy()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.TrustedIPs) > 0 {
+		checker, err := ip.NewChecker(s.TrustedIPs)
+		if err != nil {
+			return nil, err
+		}
+		return &ip.TrustedProxyStrategy{
+			Checker:  checker,
+			Strategy: strategy,
+		}, nil
+	}
+
+	return strategy, nil
+}
+
+func (s *IPStrategy) getForwardedStrategy() (ip.Strategy, error) {
 	if s.Depth > 0 {
 		return &ip.DepthStrategy{
 			Depth: s.Depth,
@@ -194,10 +288,30 @@ type IPWhiteList struct {
 	IPStrategy  *IPStrategy `json:"ipStrategy,omitempty" label:"allowEmpty"`
 }
 
+// Maintenance holds the maintenance mode configuration.
+type Maintenance struct {
+	Enabled     bool        `json:"enabled,omitempty"`
+	StatusCode  int         `json:"statusCode,omitempty"`
+	Body        string      `json:"body,omitempty"`
+	ContentType string      `json:"contentType,omitempty"`
+	RetryAfter  string      `json:"retryAfter,omitempty"`
+	SourceRange []string    `json:"sourceRange,omitempty"`
+	IPStrategy  *IPStrategy `json:"ipStrategy,omitempty" label:"allowEmpty"`
+}
+
+// SetDefaults Default values for a Maintenance.
+func (m *Maintenance) SetDefaults() {
+	m.StatusCode = http.StatusServiceUnavailable
+}
+
 // MaxConn holds maximum connection configuration.
 type MaxConn struct {
-	Amount        int64  `json:"amount,omitempty"`
-	ExtractorFunc string `json:"extractorFunc,omitempty"`
+	Amount        int64       `json:"amount,omitempty"`
+	ExtractorFunc string      `json:"extractorFunc,omitempty"`
+	IPStrategy    *IPStrategy `json:"ipStrategy,omitempty" label:"allowEmpty"`
+	// RejectionStatusCode and RejectionBody are the response to the rejected requests, 429 by default.
+	RejectionStatusCode int    `json:"rejectionStatusCode,omitempty"`
+	RejectionBody       string `json:"rejectionBody,omitempty"`
 }
 
 // SetDefaults Default values for a MaxConn.
@@ -222,7 +336,11 @@ type Rate struct {
 type RateLimit struct {
 	RateSet map[string]*Rate `json:"rateset,omitempty"`
 	// FIXME replace by ipStrategy see oxy and replace
-	ExtractorFunc string `json:"extractorFunc,omitempty"`
+	ExtractorFunc string      `json:"extractorFunc,omitempty"`
+	IPStrategy    *IPStrategy `json:"ipStrategy,omitempty" label:"allowEmpty"`
+	// RejectionStatusCode and RejectionBody are the response to the rejected requests, 429 by default.
+	RejectionStatusCode int    `json:"rejectionStatusCode,omitempty"`
+	RejectionBody       string `json:"rejectionBody,omitempty"`
 }
 
 // SetDefaults Default values for a MaxConn.
@@ -242,6 +360,8 @@ type RedirectScheme struct {
 	Scheme    string `json:"scheme,omitempty"`
 	Port      string `json:"port,omitempty"`
 	Permanent bool   `json:"permanent,omitempty"`
+	// Path replaces the path of the request in the redirection, which keeps the query of the request.
+	Path string `json:"path,omitempty"`
 }
 
 // ReplacePath holds the ReplacePath configuration.
@@ -255,14 +375,69 @@ type ReplacePathRegex struct {
 	Replacement string `json:"replacement,omitempty"`
 }
 
+// RequestTimeout holds the configuration of the deadline of the requests, given by the clients in a header.
+type RequestTimeout struct {
+	// Header holds the timeout of the request in milliseconds, defaults to X-Timeout-Ms.
+	Header string `json:"header,omitempty"`
+	// FIXME change string to parse.Duration
+	// Default is the timeout of the requests without a valid header, no timeout if empty.
+	Default string `json:"default,omitempty"`
+	// FIXME change string to parse.Duration
+	// Max caps the timeouts of the header, not capped if empty.
+	Max string `json:"max,omitempty"`
+}
+
 // Retry holds the retry configuration.
 type Retry struct {
 	Attempts int `description:"Number of attempts" export:"true"`
+	// FIXME change string to parse.Duration
+	PerTryTimeout string `description:"Timeout of each attempt. The attempts of the idempotent requests are retried when they time out, even after the server received the request" export:"true"`
+	// FIXME change string to parse.Duration
+	Timeout string `description:"Overall deadline across all the attempts" export:"true"`
+	// FIXME change string to parse.Duration
+	InitialInterval string `description:"Wait before the first retry, doubled at each new attempt up to one minute. If empty, retries are immediate" export:"true"`
+	Jitter          string `description:"Randomization of the waits between attempts: equal (default), full or none" export:"true"`
+	// Methods replaces the default retried methods, the idempotent ones.
+	Methods []string `description:"Methods of the retried requests. If empty, only the idempotent methods are retried: GET, HEAD, PUT, DELETE and OPTIONS" export:"true"`
+	// HealthyServersAttempts caps the attempts at the number of healthy servers of the load-balancer of the request,
+	// as a retry would otherwise be sent to a server that already failed.
+	HealthyServersAttempts bool `description:"Cap the attempts at the number of healthy servers of the service" export:"true"`
+	// NetworkErrors restricts the retries to the attempts which failed with a network error of these classes.
+	NetworkErrors []string `description:"Network errors retried: connectionRefused, connectionReset, timeout, dns or tls. If empty, all the failed attempts are retried" export:"true"`
+	// ResponseErrors retries the idempotent requests whose response could not be read from the server,
+	// as long as nothing was sent to the client yet.
+	ResponseErrors bool `description:"Retry the idempotent requests whose response could not be read from the server, until its body is sent to the client" export:"true"`
+	// Budget caps the retries of all the routers using the middleware, so that they do not amplify the load of failing servers.
+	Budget *RetryBudget `description:"Cap the retries at a ratio of the requests" export:"true"`
+}
+
+// RetryBudget caps the retries at a ratio of the requests over a sliding window.
+// Beyond the budget, the failed attempts are not retried.
+type RetryBudget struct {
+	Ratio float64 `description:"Maximum ratio of the retries to the requests, defaults to 0.1" export:"true"`
+	// FIXME change string to parse.Duration
+	Window     string `description:"Sliding window over which the retries and the requests are counted, defaults to 10s" export:"true"`
+	MinRetries int    `description:"Retries allowed in the window whatever the ratio, for the low traffic" export:"true"`
+}
+
+// StatusCodeRewrite holds the status code rewriting configuration.
+type StatusCodeRewrite struct {
+	Rewrites []StatusCodeMapping `json:"rewrites,omitempty"`
+}
+
+// StatusCodeMapping replaces the From status code of the responses by the To status code.
+type StatusCodeMapping struct {
+	From int `json:"from,omitempty"`
+	To   int `json:"to,omitempty"`
 }
 
 // StripPrefix holds the StripPrefix configuration.
 type StripPrefix struct {
 	Prefixes []string `json:"prefixes,omitempty"`
+	// ForwardedPrefixHeader is the header carrying the stripped prefix to the backend, X-Forwarded-Prefix by default.
+	ForwardedPrefixHeader string `json:"forwardedPrefixHeader,omitempty"`
+	// DisableForwardedPrefixHeader does not send the stripped prefix to the backend.
+	DisableForwardedPrefixHeader bool `json:"disableForwardedPrefixHeader,omitempty"`
 }
 
 // StripPrefixRegex holds the StripPrefixRegex configuration.
@@ -2,25 +2,72 @@ package static
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/containous/flaeg/parse"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/tls"
 )
 
+const unixSocketScheme = "unix://"
+
 // EntryPoint holds the entry point configuration.
+// The middlewares of the entry point are applied to all its routers, before their own middlewares.
 type EntryPoint struct {
-	Address          string
+	// Address is a TCP address, or the path of a Unix socket prefixed by unix://.
+	Address string
+	// SocketMode is the octal file mode of the Unix socket, e.g. 0660.
+	SocketMode string
+	// IPv6Only disables the dual-stack binding of the IPv6 addresses and of the addresses without host.
+	IPv6Only bool
+	// ReusePort binds the listener with SO_REUSEPORT, so that a new process can listen on the same port before the old one exits.
+	ReusePort bool
+	// MaxHeaderBytes is the maximum size of the request headers, defaults to http.DefaultMaxHeaderBytes.
+	MaxHeaderBytes   int
 	Transport        *EntryPointsTransport
 	TLS              *tls.TLS
 	ProxyProtocol    *ProxyProtocol
 	ForwardedHeaders *ForwardedHeaders
+	Middlewares      []string
+	// MaxConcurrentStreams is the maximum number of concurrent streams of an HTTP/2 connection, defaults to 250.
+	MaxConcurrentStreams uint32
+	// MaxResetStreams is the maximum number of streams an HTTP/2 client can reset per second on a connection,
+	// which is closed beyond it. Unlimited if zero.
+	MaxResetStreams int
+	// TLSHandshakeTimeout is the maximum duration of the TLS handshakes, after which the connections are closed.
+	// Defaults to 10 seconds, no timeout if negative.
+	TLSHandshakeTimeout parse.Duration
+	// PathNormalization removes the duplicate slashes and the dot segments of the request paths before the routers are matched.
+	PathNormalization *PathNormalization
+	// MaxConnections is the maximum number of open client connections, unlimited if zero.
+	MaxConnections int
+	// MaxConnectionsQueueTimeout is how long a connection beyond the limit waits for another one to close, before being closed.
+	// The connections beyond the limit are closed at once if zero.
+	MaxConnectionsQueueTimeout parse.Duration
+}
+
+// UnixSocketPath returns the path of the Unix socket the entry point listens on, if its address is a unix:// one.
+func (e *EntryPoint) UnixSocketPath() (string, bool) {
+	if !strings.HasPrefix(e.Address, unixSocketScheme) {
+		return "", false
+	}
+	return strings.TrimPrefix(e.Address, unixSocketScheme), true
 }
 
 // ForwardedHeaders Trust client forwarding headers.
 type ForwardedHeaders struct {
 	Insecure   bool
 	TrustedIPs []string
+	// XForwardedForMode is the mode of the X-Forwarded-For header sent to the servers: append (default), replace or remove.
+	// In the append mode, the client IP is appended to the header of the trusted clients only.
+	XForwardedForMode string
+}
+
+// PathNormalization configures the normalization of the request paths of an entry point.
+type PathNormalization struct {
+	// Forward sends the normalized paths to the servers, instead of the original ones.
+	Forward bool
 }
 
 // ProxyProtocol contains Proxy-Protocol configuration.
@@ -70,13 +117,71 @@ func (ep *EntryPoints) Set(value string) error {
 		return err
 	}
 
-	(*ep)[result["name"]] = &EntryPoint{
-		Address:          result["address"],
-		TLS:              configTLS,
-		ProxyProtocol:    makeEntryPointProxyProtocol(result),
-		ForwardedHeaders: makeEntryPointForwardedHeaders(result),
+	entryPoint := &EntryPoint{
+		Address:           result["address"],
+		SocketMode:        result["socketmode"],
+		IPv6Only:          toBool(result, "ipv6only"),
+		ReusePort:         toBool(result, "reuseport"),
+		TLS:               configTLS,
+		ProxyProtocol:     makeEntryPointProxyProtocol(result),
+		ForwardedHeaders:  makeEntryPointForwardedHeaders(result),
+		PathNormalization: makeEntryPointPathNormalization(result),
+	}
+
+	if len(result["middlewares"]) > 0 {
+		entryPoint.Middlewares = strings.Split(result["middlewares"], ",")
 	}
 
+	if len(result["maxheaderbytes"]) > 0 {
+		maxHeaderBytes, err := strconv.Atoi(result["maxheaderbytes"])
+		if err != nil {
+			return fmt.Errorf("invalid MaxHeaderBytes %q: %v", result["maxheaderbytes"], err)
+		}
+		entryPoint.MaxHeaderBytes = maxHeaderBytes
+	}
+
+	if len(result["maxconcurrentstreams"]) > 0 {
+		maxConcurrentStreams, err := strconv.ParseUint(result["maxconcurrentstreams"], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid MaxConcurrentStreams %q: %v", result["maxconcurrentstreams"], err)
+		}
+		entryPoint.MaxConcurrentStreams = uint32(maxConcurrentStreams)
+	}
+
+	if len(result["maxresetstreams"]) > 0 {
+		maxResetStreams, err := strconv.Atoi(result["maxresetstreams"])
+		if err != nil {
+			return fmt.Errorf("invalid MaxResetStreams %q: %v", result["maxresetstreams"], err)
+		}
+		entryPoint.MaxResetStreams = maxResetStreams
+	}
+
+	if len(result["maxconnections"]) > 0 {
+		maxConnections, err := strconv.Atoi(result["maxconnections"])
+		if err != nil {
+			return fmt.Errorf("invalid MaxConnections %q: %v", result["maxconnections"], err)
+		}
+		entryPoint.MaxConnections = maxConnections
+	}
+
+	if len(result["maxconnectionsqueuetimeout"]) > 0 {
+		var queueTimeout parse.Duration
+		if err := queueTimeout.Set(result["maxconnectionsqueuetimeout"]); err != nil {
+			return fmt.Errorf("invalid MaxConnectionsQueueTimeout %q: %v", result["maxconnectionsqueuetimeout"], err)
+		}
+		entryPoint.MaxConnectionsQueueTimeout = queueTimeout
+	}
+
+	if len(result["tlshandshaketimeout"]) > 0 {
+		var tlsHandshakeTimeout parse.Duration
+		if err := tlsHandshakeTimeout.Set(result["tlshandshaketimeout"]); err != nil {
+			return fmt.Errorf("invalid TLSHandshakeTimeout %q: %v", result["tlshandshaketimeout"], err)
+		}
+		entryPoint.TLSHandshakeTimeout = tlsHandshakeTimeout
+	}
+
+	(*ep)[result["name"]] = entryPoint
+
 	return nil
 }
 
@@ -100,6 +205,16 @@ func makeEntryPointProxyProtocol(result map[string]string) *ProxyProtocol {
 	return proxyProtocol
 }
 
+func makeEntryPointPathNormalization(result map[string]string) *PathNormalization {
+	if !toBool(result, "pathnormalization") && len(result["pathnormalization_forward"]) == 0 {
+		return nil
+	}
+
+	return &PathNormalization{
+		Forward: toBool(result, "pathnormalization_forward"),
+	}
+}
+
 func makeEntryPointTLS(result map[string]string) (*tls.TLS, error) {
 	var configTLS *tls.TLS
 
@@ -134,10 +249,18 @@ func makeEntryPointTLS(result map[string]string) (*tls.TLS, error) {
 			configTLS.CipherSuites = strings.Split(result["tls_ciphersuites"], ",")
 		}
 
+		if len(result["tls_alpnprotocols"]) > 0 {
+			configTLS.ALPNProtocols = strings.Split(result["tls_alpnprotocols"], ",")
+		}
+
 		if len(result["tls_snistrict"]) > 0 {
 			configTLS.SniStrict = toBool(result, "tls_snistrict")
 		}
 
+		if len(result["tls_defaultoptions"]) > 0 {
+			configTLS.DefaultOptions = result["tls_defaultoptions"]
+		}
+
 		if len(result["tls_defaultcertificate_cert"]) > 0 && len(result["tls_defaultcertificate_key"]) > 0 {
 			configTLS.DefaultCertificate = &tls.Certificate{
 				CertFile: tls.FileOrContent(result["tls_defaultcertificate_cert"]),
@@ -187,5 +310,7 @@ func makeEntryPointForwardedHeaders(result map[string]string) *ForwardedHeaders
 		forwardedHeaders.TrustedIPs = strings.Split(fhTrustedIPs, ",")
 	}
 
+	forwardedHeaders.XForwardedForMode = result["forwardedheaders_xforwardedformode"]
+
 	return forwardedHeaders
 }
@@ -2,7 +2,9 @@ package static
 
 import (
 	"testing"
+	"time"
 
+	"github.com/containous/flaeg/parse"
 	"github.com/containous/traefik/tls"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -188,6 +190,9 @@ func TestEntryPoints_Set(t *testing.T) {
 				"TLS " +
 				"TLS.MinVersion:VersionTLS11 " +
 				"TLS.CipherSuites:TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA384,TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA " +
+				"TLS.ALPNProtocols:http/1.1,h2 " +
+				"TLS.DefaultOptions:modern " +
+				"Middlewares:file.headers,file.compress " +
 				"CA:car " +
 				"CA.Optional:true " +
 				"ProxyProtocol.TrustedIPs:192.168.0.1 ",
@@ -195,18 +200,21 @@ func TestEntryPoints_Set(t *testing.T) {
 			expectedEntryPoint: &EntryPoint{
 				Address: ":8000",
 				TLS: &tls.TLS{
-					MinVersion:   "VersionTLS11",
-					CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA384", "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305", "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA", "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA"},
+					MinVersion:    "VersionTLS11",
+					CipherSuites:  []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA384", "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305", "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA", "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA"},
+					ALPNProtocols: []string{"http/1.1", "h2"},
 					ClientCA: tls.ClientCA{
 						Files:    tls.FilesOrContents{"car"},
 						Optional: true,
 					},
+					DefaultOptions: "modern",
 				},
 				ProxyProtocol: &ProxyProtocol{
 					Insecure:   false,
 					TrustedIPs: []string{"192.168.0.1"},
 				},
 				ForwardedHeaders: &ForwardedHeaders{},
+				Middlewares:      []string{"file.headers", "file.compress"},
 				// FIXME Test ServersTransport
 			},
 		},
@@ -247,6 +255,92 @@ func TestEntryPoints_Set(t *testing.T) {
 				ForwardedHeaders: &ForwardedHeaders{},
 			},
 		},
+		{
+			name:                   "max header bytes",
+			expression:             "Name:foo MaxHeaderBytes:16384",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				MaxHeaderBytes:   16384,
+				ForwardedHeaders: &ForwardedHeaders{},
+			},
+		},
+		{
+			name:                   "HTTP/2 limits",
+			expression:             "Name:foo MaxConcurrentStreams:100 MaxResetStreams:50",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				MaxConcurrentStreams: 100,
+				MaxResetStreams:      50,
+				ForwardedHeaders:     &ForwardedHeaders{},
+			},
+		},
+		{
+			name:                   "connection limit",
+			expression:             "Name:foo MaxConnections:100 MaxConnectionsQueueTimeout:1s",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				MaxConnections:             100,
+				MaxConnectionsQueueTimeout: parse.Duration(time.Second),
+				ForwardedHeaders:           &ForwardedHeaders{},
+			},
+		},
+		{
+			name:                   "TLS handshake timeout",
+			expression:             "Name:foo TLSHandshakeTimeout:5s",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				TLSHandshakeTimeout: parse.Duration(5 * time.Second),
+				ForwardedHeaders:    &ForwardedHeaders{},
+			},
+		},
+		{
+			name:                   "path normalization",
+			expression:             "Name:foo PathNormalization:true",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				PathNormalization: &PathNormalization{},
+				ForwardedHeaders:  &ForwardedHeaders{},
+			},
+		},
+		{
+			name:                   "path normalization forwarded",
+			expression:             "Name:foo PathNormalization.Forward:true",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				PathNormalization: &PathNormalization{Forward: true},
+				ForwardedHeaders:  &ForwardedHeaders{},
+			},
+		},
+		{
+			name:                   "unix socket",
+			expression:             "Name:foo Address:unix:///var/run/traefik.sock SocketMode:0660",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				Address:          "unix:///var/run/traefik.sock",
+				SocketMode:       "0660",
+				ForwardedHeaders: &ForwardedHeaders{},
+			},
+		},
+		{
+			name:                   "IPv6 only",
+			expression:             "Name:foo Address:[::]:443 IPv6Only:true",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				Address:          "[::]:443",
+				IPv6Only:         true,
+				ForwardedHeaders: &ForwardedHeaders{},
+			},
+		},
+		{
+			name:                   "reuse port",
+			expression:             "Name:foo Address::80 ReusePort:true",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				Address:          ":80",
+				ReusePort:        true,
+				ForwardedHeaders: &ForwardedHeaders{},
+			},
+		},
 		{
 			name:                   "ProxyProtocol insecure true",
 			expression:             "Name:foo ProxyProtocol.insecure:true",
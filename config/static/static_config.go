@@ -2,11 +2,13 @@ package static
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/containous/flaeg/parse"
 	"github.com/containous/traefik/acme"
+	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/old/provider/boltdb"
 	"github.com/containous/traefik/old/provider/consul"
@@ -22,6 +24,7 @@ import (
 	"github.com/containous/traefik/ping"
 	acmeprovider "github.com/containous/traefik/provider/acme"
 	"github.com/containous/traefik/provider/docker"
+	"github.com/containous/traefik/provider/env"
 	"github.com/containous/traefik/provider/file"
 	"github.com/containous/traefik/provider/marathon"
 	"github.com/containous/traefik/provider/rest"
@@ -45,6 +48,12 @@ const (
 	// DefaultIdleTimeout before closing an idle connection.
 	DefaultIdleTimeout = 180 * time.Second
 
+	// DefaultTLSHandshakeTimeout before closing a connection whose TLS handshake is not complete.
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+
+	// DefaultResponseHeaderTimeout before giving up waiting for the response headers of a backend server.
+	DefaultResponseHeaderTimeout = 60 * time.Second
+
 	// DefaultAcmeCAServer is the default ACME API endpoint
 	DefaultAcmeCAServer = "https://acme-v02.api.letsencrypt.org/directory"
 )
@@ -68,6 +77,8 @@ type Configuration struct {
 
 	HostResolver *types.HostResolverConfig `description:"Enable CNAME Flattening" export:"true"`
 
+	ClientIPStrategy *config.IPStrategy `description:"Client IP selection strategy used by the middlewares and the access logs" export:"true"`
+
 	ACME *acme.ACME `description:"Enable ACME (Let's Encrypt): automatic SSL" export:"true"`
 }
 
@@ -80,10 +91,25 @@ type Global struct {
 
 // ServersTransport options to configure communication between Traefik and the servers
 type ServersTransport struct {
-	InsecureSkipVerify  bool                `description:"Disable SSL certificate verification" export:"true"`
-	RootCAs             tls.FilesOrContents `description:"Add cert file for self-signed certificate"`
-	MaxIdleConnsPerHost int                 `description:"If non-zero, controls the maximum idle (keep-alive) to keep per-host.  If zero, DefaultMaxIdleConnsPerHost is used" export:"true"`
-	ForwardingTimeouts  *ForwardingTimeouts `description:"Timeouts for requests forwarded to the backend servers" export:"true"`
+	InsecureSkipVerify             bool                `description:"Disable SSL certificate verification" export:"true"`
+	RootCAs                        tls.FilesOrContents `description:"Add cert file for self-signed certificate"`
+	MaxIdleConns                   int                 `description:"If non-zero, controls the maximum idle (keep-alive) connections to keep across all hosts. If zero, no limit is set" export:"true"`
+	MaxIdleConnsPerHost            int                 `description:"If non-zero, controls the maximum idle (keep-alive) to keep per-host.  If zero, DefaultMaxIdleConnsPerHost is used" export:"true"`
+	MaxConnsPerHost                int                 `description:"If non-zero, limits the total number of connections per host, including connections in the dialing, active, and idle states. If zero, no limit is set" export:"true"`
+	MaxConnsWaitTimeout            parse.Duration      `description:"The amount of time to wait for a connection to a host when MaxConnsPerHost is reached, before answering with a 503. If zero, wait until the request is canceled" export:"true"`
+	IdleConnTimeout                parse.Duration      `description:"The maximum amount of time an idle (keep-alive) connection to a backend server remains open before closing itself. Defaults to 90 seconds. If negative, the idle connections are not closed" export:"true"`
+	ForwardingTimeouts             *ForwardingTimeouts `description:"Timeouts for requests forwarded to the backend servers" export:"true"`
+	Certificates                   tls.Certificates    `description:"Client certificates presented to the backend servers"`
+	HeaderTransports               *HeaderTransports   `description:"Transports selected by the value of a request header" export:"true"`
+	DNSCacheTTL                    parse.Duration      `description:"Duration for which the addresses of the backend servers are cached, whatever the TTL of their DNS records. If zero, no cache is used" export:"true"`
+	ExpiredCertificatesGracePeriod parse.Duration      `description:"Duration after their expiry during which the certificates of the backend servers are still accepted, with a warning. If zero, the expired certificates are rejected" export:"true"`
+}
+
+// HeaderTransports selects the transport to the servers by the value of a request header,
+// e.g. to present the client certificate of each tenant to the same servers.
+type HeaderTransports struct {
+	Header     string                       `description:"Request header whose value selects the transport" export:"true"`
+	Transports map[string]*ServersTransport `description:"Transports by header value, the requests with another value use the default transport" export:"true"`
 }
 
 // API holds the API configuration
@@ -105,13 +131,15 @@ type RespondingTimeouts struct {
 // ForwardingTimeouts contains timeout configurations for forwarding requests to the backend servers.
 type ForwardingTimeouts struct {
 	DialTimeout           parse.Duration `description:"The amount of time to wait until a connection to a backend server can be established. Defaults to 30 seconds. If zero, no timeout exists" export:"true"`
-	ResponseHeaderTimeout parse.Duration `description:"The amount of time to wait for a server's response headers after fully writing the request (including its body, if any). If zero, no timeout exists" export:"true"`
+	DialKeepAlive         parse.Duration `description:"The interval between the keep-alive probes of the connections to the backend servers. Defaults to 30 seconds. If negative, the keep-alive probes are disabled" export:"true"`
+	ResponseHeaderTimeout parse.Duration `description:"The amount of time to wait for a server's response headers after fully writing the request (including its body, if any), before answering with a 504. Defaults to 60 seconds. If zero or negative, no timeout exists" export:"true"`
 }
 
 // LifeCycle contains configurations relevant to the lifecycle (such as the shutdown phase) of Traefik.
 type LifeCycle struct {
 	RequestAcceptGraceTimeout parse.Duration `description:"Duration to keep accepting requests before Traefik initiates the graceful shutdown procedure"`
 	GraceTimeOut              parse.Duration `description:"Duration to give active requests a chance to finish before Traefik stops"`
+	DrainConnections          bool           `description:"Close the keep-alive connections after the responses served during the shutdown, with a Connection: close header"`
 }
 
 // Tracing holds the tracing configuration.
@@ -119,6 +147,7 @@ type Tracing struct {
 	Backend       string          `description:"Selects the tracking backend ('jaeger','zipkin', 'datadog')." export:"true"`
 	ServiceName   string          `description:"Set the name for this service" export:"true"`
 	SpanNameLimit int             `description:"Set the maximum character limit for Span names (default 0 = no limit)" export:"true"`
+	Propagation   string          `description:"Selects the propagated headers: 'vendor' for the ones of the backend, 'w3c' for the W3C Trace Context ones, or 'all' (default 'vendor')" export:"true"`
 	Jaeger        *jaeger.Config  `description:"Settings for jaeger"`
 	Zipkin        *zipkin.Config  `description:"Settings for zipkin"`
 	DataDog       *datadog.Config `description:"Settings for DataDog"`
@@ -142,6 +171,7 @@ type Providers struct {
 	Rancher                   *rancher.Provider       `description:"Enable Rancher backend with default settings" export:"true"`
 	DynamoDB                  *dynamodb.Provider      `description:"Enable DynamoDB backend with default settings" export:"true"`
 	Rest                      *rest.Provider          `description:"Enable Rest backend with default settings" export:"true"`
+	Env                       *env.Provider           `description:"Enable the environment variables backend" export:"true" label:"allowEmpty"`
 }
 
 // SetEffectiveConfiguration adds missing configuration parameters derived from existing ones.
@@ -312,13 +342,23 @@ func (c *Configuration) initACMEProvider() {
 // InitACMEProvider create an acme provider from the ACME part of globalConfiguration
 func (c *Configuration) InitACMEProvider() (*acmeprovider.Provider, error) {
 	if c.ACME != nil {
+		provider := &acmeprovider.Provider{}
+		provider.Configuration = convertACMEChallenge(c.ACME)
+
+		// In cluster mode, the ACME data is stored in the KV store of the cluster.
+		if provider.Cluster != nil {
+			c.ACME = nil
+			if err := provider.InitCluster(); err != nil {
+				return nil, fmt.Errorf("unable to initialize ACME provider in cluster mode: %v", err)
+			}
+			return provider, nil
+		}
+
 		if len(c.ACME.Storage) == 0 {
 			// Delete the ACME configuration to avoid starting ACME in cluster mode
 			c.ACME = nil
 			return nil, errors.New("unable to initialize ACME provider with no storage location for the certificates")
 		}
-		provider := &acmeprovider.Provider{}
-		provider.Configuration = convertACMEChallenge(c.ACME)
 
 		store := acmeprovider.NewLocalStore(provider.Storage)
 		provider.Store = store
@@ -371,6 +411,7 @@ func convertACMEChallenge(oldACMEChallenge *acme.ACME) *acmeprovider.Configurati
 		ACMELogging: oldACMEChallenge.ACMELogging,
 		CAServer:    oldACMEChallenge.CAServer,
 		EntryPoint:  oldACMEChallenge.EntryPoint,
+		Cluster:     oldACMEChallenge.Cluster,
 	}
 
 	for _, domain := range oldACMEChallenge.Domains {
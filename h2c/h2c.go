@@ -44,6 +44,10 @@ func init() {
 // to provide an http.Server.
 type Server struct {
 	*http.Server
+	// HTTP2 is the configuration of the h2c connections, the defaults of http2.Server if nil.
+	HTTP2 *http2.Server
+	// WrapConn wraps the h2c connections before they are served, if not nil.
+	WrapConn func(net.Conn) net.Conn
 }
 
 // Serve Put a middleware around the original handler to handle h2c
@@ -65,14 +69,12 @@ func (s Server) Serve(l net.Listener) error {
 				return
 			}
 			defer conn.Close()
-			h2cSrv := &http2.Server{}
-			h2cSrv.ServeConn(conn, &http2.ServeConnOpts{Handler: originalHandler})
+			s.serveConn(conn, originalHandler)
 			return
 		}
 		if conn, err := h2cUpgrade(w, r); err == nil {
 			defer conn.Close()
-			h2cSrv := &http2.Server{}
-			h2cSrv.ServeConn(conn, &http2.ServeConnOpts{Handler: originalHandler})
+			s.serveConn(conn, originalHandler)
 			return
 		}
 		originalHandler.ServeHTTP(w, r)
@@ -80,6 +82,20 @@ func (s Server) Serve(l net.Listener) error {
 	return s.Server.Serve(l)
 }
 
+func (s Server) serveConn(conn net.Conn, handler http.Handler) {
+	h2cSrv := s.HTTP2
+	if h2cSrv == nil {
+		h2cSrv = &http2.Server{}
+	}
+
+	if s.WrapConn != nil {
+		conn = s.WrapConn(conn)
+	}
+
+	// The server is the base configuration of the connections, whose requests then hold it under http.ServerContextKey.
+	h2cSrv.ServeConn(conn, &http2.ServeConnOpts{Handler: handler, BaseConfig: s.Server})
+}
+
 // initH2CWithPriorKnowledge implements creating a h2c connection with prior
 // knowledge (Section 3.4) and creates a net.Conn suitable for http2.ServeConn.
 // All we have to do is look for the client preface that is suppose to be part
@@ -0,0 +1,114 @@
+package healthcheck
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/golang/protobuf/proto"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	grpcHealthCheckPath = "/grpc.health.v1.Health/Check"
+
+	// grpcMaxMessageSize bounds the size of the health check responses.
+	grpcMaxMessageSize = 1 << 16
+)
+
+// checkGRPCHealth calls the standard gRPC health service of the server, through the transport of the backend,
+// so that its TLS and h2c settings apply.
+// The server is healthy when the service is SERVING.
+func checkGRPCHealth(serverURL *url.URL, backend *BackendConfig) error {
+	message, err := proto.Marshal(&healthpb.HealthCheckRequest{Service: backend.GRPCService})
+	if err != nil {
+		return fmt.Errorf("failed to encode gRPC request: %s", err)
+	}
+
+	// A gRPC message is prefixed by a compressed flag and its length.
+	body := make([]byte, 5+len(message))
+	binary.BigEndian.PutUint32(body[1:5], uint32(len(message)))
+	copy(body[5:], message)
+
+	u, err := backend.targetURL(serverURL, grpcHealthCheckPath)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC request: %s", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC request: %s", err)
+	}
+
+	req = backend.addHeadersAndHost(req)
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	client := http.Client{
+		Timeout:   backend.Options.Timeout,
+		Transport: backend.Options.Transport,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gRPC request failed: %s", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received error status code: %v", resp.StatusCode)
+	}
+
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, grpcMaxMessageSize))
+	if err != nil {
+		return fmt.Errorf("failed to read gRPC response: %s", err)
+	}
+
+	// The status is in the trailers, or in the headers of a response without message.
+	status, statusMessage := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, statusMessage = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		return fmt.Errorf("received gRPC error status %s: %s", status, statusMessage)
+	}
+
+	response, err := decodeGRPCHealthResponse(respBody)
+	if err != nil {
+		return fmt.Errorf("invalid gRPC response: %s", err)
+	}
+
+	if response.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("received gRPC serving status: %s", response.Status)
+	}
+
+	return nil
+}
+
+func decodeGRPCHealthResponse(body []byte) (*healthpb.HealthCheckResponse, error) {
+	if len(body) < 5 {
+		return nil, errors.New("missing message")
+	}
+
+	if body[0] != 0 {
+		return nil, errors.New("compressed message")
+	}
+
+	length := binary.BigEndian.Uint32(body[1:5])
+	if uint32(len(body)-5) != length {
+		return nil, fmt.Errorf("message of %d bytes instead of %d", len(body)-5, length)
+	}
+
+	response := &healthpb.HealthCheckResponse{}
+	if err := proto.Unmarshal(body[5:], response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,150 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// testHealthServer is a gRPC health service, whose serving status can be toggled.
+type testHealthServer struct {
+	lock   sync.Mutex
+	status healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (s *testHealthServer) Check(_ context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.Service != "" && req.Service != "foo.Bar" {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return &healthpb.HealthCheckResponse{Status: s.status}, nil
+}
+
+func (s *testHealthServer) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.status = status
+}
+
+func newTestGRPCServer(t *testing.T, healthServer *testHealthServer) (*httptest.Server, http.RoundTripper) {
+	t.Helper()
+
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	server := httptest.NewUnstartedServer(grpcServer)
+	require.NoError(t, http2.ConfigureServer(server.Config, nil))
+	server.TLS = server.Config.TLSConfig
+	server.StartTLS()
+
+	transport := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	require.NoError(t, http2.ConfigureTransport(transport))
+
+	return server, transport
+}
+
+func TestCheckGRPCHealth(t *testing.T) {
+	healthServer := &testHealthServer{}
+	server, transport := newTestGRPCServer(t, healthServer)
+	defer server.Close()
+
+	testCases := []struct {
+		desc          string
+		service       string
+		status        healthpb.HealthCheckResponse_ServingStatus
+		expectedError bool
+	}{
+		{
+			desc:   "serving",
+			status: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			desc:    "serving service",
+			service: "foo.Bar",
+			status:  healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			desc:          "not serving",
+			status:        healthpb.HealthCheckResponse_NOT_SERVING,
+			expectedError: true,
+		},
+		{
+			desc:          "unknown",
+			status:        healthpb.HealthCheckResponse_UNKNOWN,
+			expectedError: true,
+		},
+		{
+			desc:          "unknown service",
+			service:       "foo.Baz",
+			status:        healthpb.HealthCheckResponse_SERVING,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			healthServer.setStatus(test.status)
+
+			backend := NewBackendConfig(Options{
+				Mode:        ModeGRPC,
+				GRPCService: test.service,
+				Transport:   transport,
+				Timeout:     time.Second,
+			}, "backendName")
+
+			err := checkHealth(testhelpers.MustParseURL(server.URL), backend)
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckBackend_GRPC(t *testing.T) {
+	healthServer := &testHealthServer{status: healthpb.HealthCheckResponse_SERVING}
+	server, transport := newTestGRPCServer(t, healthServer)
+	defer server.Close()
+
+	serverURL := testhelpers.MustParseURL(server.URL)
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}, servers: []*url.URL{serverURL}}
+
+	backend := NewBackendConfig(Options{
+		Mode:      ModeGRPC,
+		Transport: transport,
+		Timeout:   time.Second,
+		LB:        lb,
+	}, "backendName")
+
+	check := newHealthCheck()
+
+	check.checkBackend(backend)
+	assert.Equal(t, []*url.URL{serverURL}, lb.Servers())
+
+	healthServer.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	check.checkBackend(backend)
+	assert.Empty(t, lb.Servers())
+	assert.Equal(t, 1, lb.numRemovedServers)
+
+	healthServer.setStatus(healthpb.HealthCheckResponse_SERVING)
+	check.checkBackend(backend)
+	assert.Equal(t, []*url.URL{serverURL}, lb.Servers())
+	assert.Equal(t, 1, lb.numUpsertedServers)
+}
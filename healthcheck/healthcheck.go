@@ -33,6 +33,14 @@ type metricsRegistry interface {
 	BackendServerUpGauge() metrics.Gauge
 }
 
+// Health check modes.
+const (
+	// ModeHTTP checks the status code of an HTTP request on the path.
+	ModeHTTP = "http"
+	// ModeGRPC calls the standard gRPC health service.
+	ModeGRPC = "grpc"
+)
+
 // Options are the public health check options.
 type Options struct {
 	Headers   map[string]string
@@ -44,9 +52,15 @@ type Options struct {
 	Interval  time.Duration
 	Timeout   time.Duration
 	LB        BalancerHandler
+	Mode      string
+	// GRPCService is the service checked by the gRPC health service, the whole server by default.
+	GRPCService string
 }
 
 func (opt Options) String() string {
+	if opt.Mode == ModeGRPC {
+		return fmt.Sprintf("[Mode: %s GRPCService: %s Hostname: %s Headers: %v Port: %d Interval: %s Timeout: %s]", opt.Mode, opt.GRPCService, opt.Hostname, opt.Headers, opt.Port, opt.Interval, opt.Timeout)
+	}
 	return fmt.Sprintf("[Hostname: %s Headers: %v Path: %s Port: %d Interval: %s Timeout: %s]", opt.Hostname, opt.Headers, opt.Path, opt.Port, opt.Interval, opt.Timeout)
 }
 
@@ -55,10 +69,39 @@ type BackendConfig struct {
 	Options
 	name         string
 	disabledURLs []*url.URL
+	lock         sync.RWMutex
+}
+
+// BackendStatus holds the number of healthy and unhealthy servers of a backend.
+type BackendStatus struct {
+	Up   int
+	Down int
+}
+
+// ServerStatus holds the passive health of a server of a load-balancer, measured by its circuit breaker.
+type ServerStatus struct {
+	URL     string `json:"url"`
+	Ejected bool   `json:"ejected"`
+	// RecoveryTime is when an ejected server is put back into the load-balancer.
+	RecoveryTime *time.Time `json:"recoveryTime,omitempty"`
+	Ejections    int        `json:"ejections"`
+	// Requests and Errors are counted in the current window of the circuit breaker.
+	Requests int `json:"requests"`
+	Errors   int `json:"errors"`
 }
 
 func (b *BackendConfig) newRequest(serverURL *url.URL) (*http.Request, error) {
-	u, err := serverURL.Parse(b.Path)
+	u, err := b.targetURL(serverURL, b.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.NewRequest(http.MethodGet, u.String(), http.NoBody)
+}
+
+// targetURL returns the URL of the health check of the server, on the path.
+func (b *BackendConfig) targetURL(serverURL *url.URL, path string) (*url.URL, error) {
+	u, err := serverURL.Parse(path)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +114,7 @@ func (b *BackendConfig) newRequest(serverURL *url.URL) (*http.Request, error) {
 		u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(b.Port))
 	}
 
-	return http.NewRequest(http.MethodGet, u.String(), http.NoBody)
+	return u, nil
 }
 
 // this function adds additional http headers and hostname to http.request
@@ -91,11 +134,14 @@ type HealthCheck struct {
 	Backends map[string]*BackendConfig
 	metrics  metricsRegistry
 	cancel   context.CancelFunc
+	lock     sync.RWMutex
 }
 
 // SetBackendsConfiguration set backends configuration
 func (hc *HealthCheck) SetBackendsConfiguration(parentCtx context.Context, backends map[string]*BackendConfig) {
+	hc.lock.Lock()
 	hc.Backends = backends
+	hc.lock.Unlock()
 	if hc.cancel != nil {
 		hc.cancel()
 	}
@@ -127,41 +173,78 @@ func (hc *HealthCheck) execute(ctx context.Context, backend *BackendConfig) {
 	}
 }
 
+// GetBackendStatus returns the health status of the servers of the given backend.
+// The boolean is false when no health check is configured for the backend.
+func (hc *HealthCheck) GetBackendStatus(backendName string) (BackendStatus, bool) {
+	hc.lock.RLock()
+	backend, ok := hc.Backends[backendName]
+	hc.lock.RUnlock()
+	if !ok {
+		return BackendStatus{}, false
+	}
+
+	backend.lock.RLock()
+	defer backend.lock.RUnlock()
+
+	return BackendStatus{
+		Up:   len(backend.LB.Servers()),
+		Down: len(backend.disabledURLs),
+	}, true
+}
+
+// checkBackend checks the health of the servers of the backend.
+// The lock of the backend is only held to read and update the servers, not during the health requests,
+// so that a slow server does not block the readers of its status.
 func (hc *HealthCheck) checkBackend(backend *BackendConfig) {
+	backend.lock.RLock()
 	enabledURLs := backend.LB.Servers()
-	var newDisabledURLs []*url.URL
+	disabledURLs := backend.disabledURLs
+	backend.lock.RUnlock()
+
+	var upURLs, stillDownURLs, downURLs []*url.URL
 	// FIXME re enable metrics
-	for _, disableURL := range backend.disabledURLs {
+	for _, disableURL := range disabledURLs {
 		// FIXME serverUpMetricValue := float64(0)
 		if err := checkHealth(disableURL, backend); err == nil {
 			log.Warnf("Health check up: Returning to server list. Backend: %q URL: %q", backend.name, disableURL.String())
-			if err = backend.LB.UpsertServer(disableURL, roundrobin.Weight(1)); err != nil {
-				log.Error(err)
-			}
+			upURLs = append(upURLs, disableURL)
 			// FIXME serverUpMetricValue = 1
 		} else {
 			log.Warnf("Health check still failing. Backend: %q URL: %q Reason: %s", backend.name, disableURL.String(), err)
-			newDisabledURLs = append(newDisabledURLs, disableURL)
+			stillDownURLs = append(stillDownURLs, disableURL)
 		}
 		// FIXME labelValues := []string{"backend", backend.name, "url", disableURL.String()}
 		// FIXME hc.metrics.BackendServerUpGauge().With(labelValues...).Set(serverUpMetricValue)
 	}
-	backend.disabledURLs = newDisabledURLs
 
 	// FIXME re enable metrics
 	for _, enableURL := range enabledURLs {
 		// FIXME serverUpMetricValue := float64(1)
 		if err := checkHealth(enableURL, backend); err != nil {
 			log.Warnf("Health check failed: Remove from server list. Backend: %q URL: %q Reason: %s", backend.name, enableURL.String(), err)
-			if err := backend.LB.RemoveServer(enableURL); err != nil {
-				log.Error(err)
-			}
-			backend.disabledURLs = append(backend.disabledURLs, enableURL)
+			downURLs = append(downURLs, enableURL)
 			// FIXME serverUpMetricValue = 0
 		}
 		// FIXME labelValues := []string{"backend", backend.name, "url", enableURL.String()}
 		// FIXME hc.metrics.BackendServerUpGauge().With(labelValues...).Set(serverUpMetricValue)
 	}
+
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+
+	for _, upURL := range upURLs {
+		if err := backend.LB.UpsertServer(upURL, roundrobin.Weight(1)); err != nil {
+			log.Error(err)
+		}
+	}
+
+	for _, downURL := range downURLs {
+		if err := backend.LB.RemoveServer(downURL); err != nil {
+			log.Error(err)
+		}
+	}
+
+	backend.disabledURLs = append(stillDownURLs, downURLs...)
 }
 
 // FIXME re add metrics
@@ -196,6 +279,10 @@ func NewBackendConfig(options Options, backendName string) *BackendConfig {
 // checkHealth returns a nil error in case it was successful and otherwise
 // a non-nil error with a meaningful description why the health check failed.
 func checkHealth(serverURL *url.URL, backend *BackendConfig) error {
+	if backend.Mode == ModeGRPC {
+		return checkGRPCHealth(serverURL, backend)
+	}
+
 	req, err := backend.newRequest(serverURL)
 	if err != nil {
 		return fmt.Errorf("failed to create HTTP request: %s", err)
@@ -152,6 +152,41 @@ func TestSetBackendsConfiguration(t *testing.T) {
 	}
 }
 
+func TestGetBackendStatus_SlowServer(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(received)
+		<-release
+	}))
+	defer ts.Close()
+
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}, servers: []*url.URL{testhelpers.MustParseURL(ts.URL)}}
+	backend := NewBackendConfig(Options{
+		Path:    "/path",
+		Timeout: 10 * time.Second,
+		LB:      lb,
+	}, "backendName")
+
+	check := HealthCheck{Backends: map[string]*BackendConfig{"backendName": backend}}
+
+	done := make(chan struct{})
+	go func() {
+		check.checkBackend(backend)
+		close(done)
+	}()
+
+	<-received
+
+	// The status is read while the health request of the server is pending.
+	status, ok := check.GetBackendStatus("backendName")
+	assert.True(t, ok)
+	assert.Equal(t, BackendStatus{Up: 1}, status)
+
+	close(release)
+	<-done
+}
+
 func TestNewRequest(t *testing.T) {
 	type expected struct {
 		err   bool
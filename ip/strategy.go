@@ -1,6 +1,7 @@
 package ip
 
 import (
+	"net"
 	"net/http"
 	"strings"
 )
@@ -61,3 +62,29 @@ func (s *CheckerStrategy) GetIP(req *http.Request) string {
 	}
 	return ""
 }
+
+// TrustedProxyStrategy a strategy that only applies its inner strategy to the requests coming from trusted proxies,
+// and uses the remote address for the other requests
+type TrustedProxyStrategy struct {
+	Checker  *Checker
+	Strategy Strategy
+}
+
+// GetIP return the selected IP
+func (s *TrustedProxyStrategy) GetIP(req *http.Request) string {
+	if s.Checker == nil || s.Checker.IsAuthorized(req.RemoteAddr) != nil {
+		return req.RemoteAddr
+	}
+	return s.Strategy.GetIP(req)
+}
+
+// ClientIP returns the IP selected by the strategy, without the port
+func ClientIP(strategy Strategy, req *http.Request) string {
+	addr := strategy.GetIP(req)
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
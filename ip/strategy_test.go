@@ -123,3 +123,80 @@ func TestExcludedIPsStrategy_GetIP(t *testing.T) {
 		})
 	}
 }
+
+func TestTrustedProxyStrategy_GetIP(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		trustedIPs    []string
+		remoteAddr    string
+		xForwardedFor string
+		expected      string
+	}{
+		{
+			desc:          "Use inner strategy for trusted proxy",
+			trustedIPs:    []string{"192.0.2.0/24"},
+			remoteAddr:    "192.0.2.1:1234",
+			xForwardedFor: "10.0.0.2,10.0.0.1",
+			expected:      "10.0.0.1",
+		},
+		{
+			desc:          "Use RemoteAddr for untrusted proxy",
+			trustedIPs:    []string{"10.0.0.0/8"},
+			remoteAddr:    "192.0.2.1:1234",
+			xForwardedFor: "10.0.0.2,10.0.0.1",
+			expected:      "192.0.2.1:1234",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			checker, err := NewChecker(test.trustedIPs)
+			require.NoError(t, err)
+
+			strategy := TrustedProxyStrategy{Checker: checker, Strategy: &DepthStrategy{Depth: 1}}
+			req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1", nil)
+			req.RemoteAddr = test.remoteAddr
+			req.Header.Set(xForwardedFor, test.xForwardedFor)
+			actual := strategy.GetIP(req)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		remoteAddr string
+		expected   string
+	}{
+		{
+			desc:       "Remove IPv4 port",
+			remoteAddr: "192.0.2.1:1234",
+			expected:   "192.0.2.1",
+		},
+		{
+			desc:       "Remove IPv6 port",
+			remoteAddr: "[2001:db8::1]:1234",
+			expected:   "2001:db8::1",
+		},
+		{
+			desc:       "Without port",
+			remoteAddr: "192.0.2.1",
+			expected:   "192.0.2.1",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1", nil)
+			req.RemoteAddr = test.remoteAddr
+			assert.Equal(t, test.expected, ClientIP(&RemoteAddrStrategy{}, req))
+		})
+	}
+}
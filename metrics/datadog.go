@@ -27,11 +27,21 @@ const (
 	ddConfigReloadsFailureTagName = "failure"
 	ddLastConfigReloadSuccessName = "config.reload.lastSuccessTimestamp"
 	ddLastConfigReloadFailureName = "config.reload.lastFailureTimestamp"
+	ddConfigReloadDurationName    = "config.reload.duration"
 	ddEntrypointReqsName          = "entrypoint.request.total"
 	ddEntrypointReqDurationName   = "entrypoint.request.duration"
 	ddEntrypointOpenConnsName     = "entrypoint.connections.open"
+	ddEntrypointPanicsName        = "entrypoint.panics.total"
+	ddEntrypointConnsName         = "entrypoint.connections.current"
 	ddOpenConnsName               = "backend.connections.open"
 	ddServerUpName                = "backend.server.up"
+	ddServerActiveConnsName       = "backend.server.connections.active"
+	ddServerIdleConnsName         = "backend.server.connections.idle"
+	ddServerSlowStartName         = "backend.server.slowstart.progress"
+	ddServerCircuitBreakerName    = "backend.server.circuitbreaker.open"
+	ddServerWeightName            = "backend.server.weight"
+	ddRouterReqSizeName           = "router.request.size"
+	ddRouterRespSizeName          = "router.response.size"
 )
 
 // RegisterDatadog registers the metrics pusher if this didn't happen yet and creates a datadog Registry instance.
@@ -41,19 +51,29 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 	}
 
 	registry := &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           datadogClient.NewCounter(ddConfigReloadsName, 1.0),
-		configReloadsFailureCounter:    datadogClient.NewCounter(ddConfigReloadsName, 1.0).With(ddConfigReloadsFailureTagName, "true"),
-		lastConfigReloadSuccessGauge:   datadogClient.NewGauge(ddLastConfigReloadSuccessName),
-		lastConfigReloadFailureGauge:   datadogClient.NewGauge(ddLastConfigReloadFailureName),
-		entrypointReqsCounter:          datadogClient.NewCounter(ddEntrypointReqsName, 1.0),
-		entrypointReqDurationHistogram: datadogClient.NewHistogram(ddEntrypointReqDurationName, 1.0),
-		entrypointOpenConnsGauge:       datadogClient.NewGauge(ddEntrypointOpenConnsName),
-		backendReqsCounter:             datadogClient.NewCounter(ddMetricsBackendReqsName, 1.0),
-		backendReqDurationHistogram:    datadogClient.NewHistogram(ddMetricsBackendLatencyName, 1.0),
-		backendRetriesCounter:          datadogClient.NewCounter(ddRetriesTotalName, 1.0),
-		backendOpenConnsGauge:          datadogClient.NewGauge(ddOpenConnsName),
-		backendServerUpGauge:           datadogClient.NewGauge(ddServerUpName),
+		enabled:                          true,
+		configReloadsCounter:             datadogClient.NewCounter(ddConfigReloadsName, 1.0),
+		configReloadsFailureCounter:      datadogClient.NewCounter(ddConfigReloadsName, 1.0).With(ddConfigReloadsFailureTagName, "true"),
+		lastConfigReloadSuccessGauge:     datadogClient.NewGauge(ddLastConfigReloadSuccessName),
+		lastConfigReloadFailureGauge:     datadogClient.NewGauge(ddLastConfigReloadFailureName),
+		configReloadDurationHistogram:    datadogClient.NewHistogram(ddConfigReloadDurationName, 1.0),
+		entrypointReqsCounter:            datadogClient.NewCounter(ddEntrypointReqsName, 1.0),
+		entrypointReqDurationHistogram:   datadogClient.NewHistogram(ddEntrypointReqDurationName, 1.0),
+		entrypointOpenConnsGauge:         datadogClient.NewGauge(ddEntrypointOpenConnsName),
+		entrypointPanicsCounter:          datadogClient.NewCounter(ddEntrypointPanicsName, 1.0),
+		entrypointConnectionsGauge:       datadogClient.NewGauge(ddEntrypointConnsName),
+		backendReqsCounter:               datadogClient.NewCounter(ddMetricsBackendReqsName, 1.0),
+		backendReqDurationHistogram:      datadogClient.NewHistogram(ddMetricsBackendLatencyName, 1.0),
+		backendRetriesCounter:            datadogClient.NewCounter(ddRetriesTotalName, 1.0),
+		backendOpenConnsGauge:            datadogClient.NewGauge(ddOpenConnsName),
+		backendServerUpGauge:             datadogClient.NewGauge(ddServerUpName),
+		backendServerActiveConnsGauge:    datadogClient.NewGauge(ddServerActiveConnsName),
+		backendServerIdleConnsGauge:      datadogClient.NewGauge(ddServerIdleConnsName),
+		backendServerSlowStartGauge:      datadogClient.NewGauge(ddServerSlowStartName),
+		backendServerCircuitBreakerGauge: datadogClient.NewGauge(ddServerCircuitBreakerName),
+		backendServerWeightGauge:         datadogClient.NewGauge(ddServerWeightName),
+		routerReqSizeHistogram:           datadogClient.NewHistogram(ddRouterReqSizeName, 1.0),
+		routerRespSizeHistogram:          datadogClient.NewHistogram(ddRouterRespSizeName, 1.0),
 	}
 
 	return registry
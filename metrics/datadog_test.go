@@ -31,6 +31,7 @@ func TestDatadog(t *testing.T) {
 		"traefik.backend.request.duration:10000.000000|h|#service:test,code:200\n",
 		"traefik.config.reload.total:1.000000|c\n",
 		"traefik.config.reload.total:1.000000|c|#failure:true\n",
+		"traefik.config.reload.duration:10.000000|h\n",
 		"traefik.entrypoint.request.total:1.000000|c|#entrypoint:test\n",
 		"traefik.entrypoint.request.duration:10000.000000|h|#entrypoint:test\n",
 		"traefik.entrypoint.connections.open:1.000000|g|#entrypoint:test\n",
@@ -45,6 +46,7 @@ func TestDatadog(t *testing.T) {
 		datadogRegistry.BackendRetriesCounter().With("service", "test").Add(1)
 		datadogRegistry.ConfigReloadsCounter().Add(1)
 		datadogRegistry.ConfigReloadsFailureCounter().Add(1)
+		datadogRegistry.ConfigReloadDurationHistogram().Observe(10)
 		datadogRegistry.EntrypointReqsCounter().With("entrypoint", "test").Add(1)
 		datadogRegistry.EntrypointReqDurationHistogram().With("entrypoint", "test").Observe(10000)
 		datadogRegistry.EntrypointOpenConnsGauge().With("entrypoint", "test").Set(1)
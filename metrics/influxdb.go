@@ -33,11 +33,21 @@ const (
 	influxDBConfigReloadsFailureName    = influxDBConfigReloadsName + ".failure"
 	influxDBLastConfigReloadSuccessName = "traefik.config.reload.lastSuccessTimestamp"
 	influxDBLastConfigReloadFailureName = "traefik.config.reload.lastFailureTimestamp"
+	influxDBConfigReloadDurationName    = "traefik.config.reload.duration"
 	influxDBEntrypointReqsName          = "traefik.entrypoint.requests.total"
 	influxDBEntrypointReqDurationName   = "traefik.entrypoint.request.duration"
 	influxDBEntrypointOpenConnsName     = "traefik.entrypoint.connections.open"
+	influxDBEntrypointPanicsName        = "traefik.entrypoint.panics.total"
+	influxDBEntrypointConnsName         = "traefik.entrypoint.connections.current"
 	influxDBOpenConnsName               = "traefik.backend.connections.open"
 	influxDBServerUpName                = "traefik.backend.server.up"
+	influxDBServerActiveConnsName       = "traefik.backend.server.connections.active"
+	influxDBServerIdleConnsName         = "traefik.backend.server.connections.idle"
+	influxDBServerSlowStartName         = "traefik.backend.server.slowstart.progress"
+	influxDBServerCircuitBreakerName    = "traefik.backend.server.circuitbreaker.open"
+	influxDBServerWeightName            = "traefik.backend.server.weight"
+	influxDBRouterReqSizeName           = "traefik.router.request.size"
+	influxDBRouterRespSizeName          = "traefik.router.response.size"
 )
 
 const (
@@ -55,19 +65,29 @@ func RegisterInfluxDB(ctx context.Context, config *types.InfluxDB) Registry {
 	}
 
 	return &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           influxDBClient.NewCounter(influxDBConfigReloadsName),
-		configReloadsFailureCounter:    influxDBClient.NewCounter(influxDBConfigReloadsFailureName),
-		lastConfigReloadSuccessGauge:   influxDBClient.NewGauge(influxDBLastConfigReloadSuccessName),
-		lastConfigReloadFailureGauge:   influxDBClient.NewGauge(influxDBLastConfigReloadFailureName),
-		entrypointReqsCounter:          influxDBClient.NewCounter(influxDBEntrypointReqsName),
-		entrypointReqDurationHistogram: influxDBClient.NewHistogram(influxDBEntrypointReqDurationName),
-		entrypointOpenConnsGauge:       influxDBClient.NewGauge(influxDBEntrypointOpenConnsName),
-		backendReqsCounter:             influxDBClient.NewCounter(influxDBMetricsBackendReqsName),
-		backendReqDurationHistogram:    influxDBClient.NewHistogram(influxDBMetricsBackendLatencyName),
-		backendRetriesCounter:          influxDBClient.NewCounter(influxDBRetriesTotalName),
-		backendOpenConnsGauge:          influxDBClient.NewGauge(influxDBOpenConnsName),
-		backendServerUpGauge:           influxDBClient.NewGauge(influxDBServerUpName),
+		enabled:                          true,
+		configReloadsCounter:             influxDBClient.NewCounter(influxDBConfigReloadsName),
+		configReloadsFailureCounter:      influxDBClient.NewCounter(influxDBConfigReloadsFailureName),
+		lastConfigReloadSuccessGauge:     influxDBClient.NewGauge(influxDBLastConfigReloadSuccessName),
+		lastConfigReloadFailureGauge:     influxDBClient.NewGauge(influxDBLastConfigReloadFailureName),
+		configReloadDurationHistogram:    influxDBClient.NewHistogram(influxDBConfigReloadDurationName),
+		entrypointReqsCounter:            influxDBClient.NewCounter(influxDBEntrypointReqsName),
+		entrypointReqDurationHistogram:   influxDBClient.NewHistogram(influxDBEntrypointReqDurationName),
+		entrypointOpenConnsGauge:         influxDBClient.NewGauge(influxDBEntrypointOpenConnsName),
+		entrypointPanicsCounter:          influxDBClient.NewCounter(influxDBEntrypointPanicsName),
+		entrypointConnectionsGauge:       influxDBClient.NewGauge(influxDBEntrypointConnsName),
+		backendReqsCounter:               influxDBClient.NewCounter(influxDBMetricsBackendReqsName),
+		backendReqDurationHistogram:      influxDBClient.NewHistogram(influxDBMetricsBackendLatencyName),
+		backendRetriesCounter:            influxDBClient.NewCounter(influxDBRetriesTotalName),
+		backendOpenConnsGauge:            influxDBClient.NewGauge(influxDBOpenConnsName),
+		backendServerUpGauge:             influxDBClient.NewGauge(influxDBServerUpName),
+		backendServerActiveConnsGauge:    influxDBClient.NewGauge(influxDBServerActiveConnsName),
+		backendServerIdleConnsGauge:      influxDBClient.NewGauge(influxDBServerIdleConnsName),
+		backendServerSlowStartGauge:      influxDBClient.NewGauge(influxDBServerSlowStartName),
+		backendServerCircuitBreakerGauge: influxDBClient.NewGauge(influxDBServerCircuitBreakerName),
+		backendServerWeightGauge:         influxDBClient.NewGauge(influxDBServerWeightName),
+		routerReqSizeHistogram:           influxDBClient.NewHistogram(influxDBRouterReqSizeName),
+		routerRespSizeHistogram:          influxDBClient.NewHistogram(influxDBRouterRespSizeName),
 	}
 }
 
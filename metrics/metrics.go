@@ -15,11 +15,14 @@ type Registry interface {
 	ConfigReloadsFailureCounter() metrics.Counter
 	LastConfigReloadSuccessGauge() metrics.Gauge
 	LastConfigReloadFailureGauge() metrics.Gauge
+	ConfigReloadDurationHistogram() metrics.Histogram
 
 	// entry point metrics
 	EntrypointReqsCounter() metrics.Counter
 	EntrypointReqDurationHistogram() metrics.Histogram
 	EntrypointOpenConnsGauge() metrics.Gauge
+	EntrypointPanicsCounter() metrics.Counter
+	EntrypointConnectionsGauge() metrics.Gauge
 
 	// backend metrics
 	BackendReqsCounter() metrics.Counter
@@ -27,6 +30,15 @@ type Registry interface {
 	BackendOpenConnsGauge() metrics.Gauge
 	BackendRetriesCounter() metrics.Counter
 	BackendServerUpGauge() metrics.Gauge
+	BackendServerActiveConnsGauge() metrics.Gauge
+	BackendServerIdleConnsGauge() metrics.Gauge
+	BackendServerSlowStartGauge() metrics.Gauge
+	BackendServerCircuitBreakerGauge() metrics.Gauge
+	BackendServerWeightGauge() metrics.Gauge
+
+	// router metrics
+	RouterReqSizeHistogram() metrics.Histogram
+	RouterRespSizeHistogram() metrics.Histogram
 }
 
 // NewVoidRegistry is a noop implementation of metrics.Registry.
@@ -43,14 +55,24 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var configReloadsFailureCounter []metrics.Counter
 	var lastConfigReloadSuccessGauge []metrics.Gauge
 	var lastConfigReloadFailureGauge []metrics.Gauge
+	var configReloadDurationHistogram []metrics.Histogram
 	var entrypointReqsCounter []metrics.Counter
 	var entrypointReqDurationHistogram []metrics.Histogram
 	var entrypointOpenConnsGauge []metrics.Gauge
+	var entrypointPanicsCounter []metrics.Counter
+	var entrypointConnectionsGauge []metrics.Gauge
 	var backendReqsCounter []metrics.Counter
 	var backendReqDurationHistogram []metrics.Histogram
 	var backendOpenConnsGauge []metrics.Gauge
 	var backendRetriesCounter []metrics.Counter
 	var backendServerUpGauge []metrics.Gauge
+	var backendServerActiveConnsGauge []metrics.Gauge
+	var backendServerIdleConnsGauge []metrics.Gauge
+	var backendServerSlowStartGauge []metrics.Gauge
+	var backendServerCircuitBreakerGauge []metrics.Gauge
+	var backendServerWeightGauge []metrics.Gauge
+	var routerReqSizeHistogram []metrics.Histogram
+	var routerRespSizeHistogram []metrics.Histogram
 
 	for _, r := range registries {
 		if r.ConfigReloadsCounter() != nil {
@@ -65,6 +87,9 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.LastConfigReloadFailureGauge() != nil {
 			lastConfigReloadFailureGauge = append(lastConfigReloadFailureGauge, r.LastConfigReloadFailureGauge())
 		}
+		if r.ConfigReloadDurationHistogram() != nil {
+			configReloadDurationHistogram = append(configReloadDurationHistogram, r.ConfigReloadDurationHistogram())
+		}
 		if r.EntrypointReqsCounter() != nil {
 			entrypointReqsCounter = append(entrypointReqsCounter, r.EntrypointReqsCounter())
 		}
@@ -74,6 +99,12 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.EntrypointOpenConnsGauge() != nil {
 			entrypointOpenConnsGauge = append(entrypointOpenConnsGauge, r.EntrypointOpenConnsGauge())
 		}
+		if r.EntrypointPanicsCounter() != nil {
+			entrypointPanicsCounter = append(entrypointPanicsCounter, r.EntrypointPanicsCounter())
+		}
+		if r.EntrypointConnectionsGauge() != nil {
+			entrypointConnectionsGauge = append(entrypointConnectionsGauge, r.EntrypointConnectionsGauge())
+		}
 		if r.BackendReqsCounter() != nil {
 			backendReqsCounter = append(backendReqsCounter, r.BackendReqsCounter())
 		}
@@ -89,39 +120,80 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.BackendServerUpGauge() != nil {
 			backendServerUpGauge = append(backendServerUpGauge, r.BackendServerUpGauge())
 		}
+		if r.BackendServerActiveConnsGauge() != nil {
+			backendServerActiveConnsGauge = append(backendServerActiveConnsGauge, r.BackendServerActiveConnsGauge())
+		}
+		if r.BackendServerIdleConnsGauge() != nil {
+			backendServerIdleConnsGauge = append(backendServerIdleConnsGauge, r.BackendServerIdleConnsGauge())
+		}
+		if r.BackendServerSlowStartGauge() != nil {
+			backendServerSlowStartGauge = append(backendServerSlowStartGauge, r.BackendServerSlowStartGauge())
+		}
+		if r.BackendServerCircuitBreakerGauge() != nil {
+			backendServerCircuitBreakerGauge = append(backendServerCircuitBreakerGauge, r.BackendServerCircuitBreakerGauge())
+		}
+		if r.BackendServerWeightGauge() != nil {
+			backendServerWeightGauge = append(backendServerWeightGauge, r.BackendServerWeightGauge())
+		}
+		if r.RouterReqSizeHistogram() != nil {
+			routerReqSizeHistogram = append(routerReqSizeHistogram, r.RouterReqSizeHistogram())
+		}
+		if r.RouterRespSizeHistogram() != nil {
+			routerRespSizeHistogram = append(routerRespSizeHistogram, r.RouterRespSizeHistogram())
+		}
 	}
 
 	return &standardRegistry{
-		enabled:                        len(registries) > 0,
-		configReloadsCounter:           multi.NewCounter(configReloadsCounter...),
-		configReloadsFailureCounter:    multi.NewCounter(configReloadsFailureCounter...),
-		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
-		lastConfigReloadFailureGauge:   multi.NewGauge(lastConfigReloadFailureGauge...),
-		entrypointReqsCounter:          multi.NewCounter(entrypointReqsCounter...),
-		entrypointReqDurationHistogram: multi.NewHistogram(entrypointReqDurationHistogram...),
-		entrypointOpenConnsGauge:       multi.NewGauge(entrypointOpenConnsGauge...),
-		backendReqsCounter:             multi.NewCounter(backendReqsCounter...),
-		backendReqDurationHistogram:    multi.NewHistogram(backendReqDurationHistogram...),
-		backendOpenConnsGauge:          multi.NewGauge(backendOpenConnsGauge...),
-		backendRetriesCounter:          multi.NewCounter(backendRetriesCounter...),
-		backendServerUpGauge:           multi.NewGauge(backendServerUpGauge...),
+		enabled:                          len(registries) > 0,
+		configReloadsCounter:             multi.NewCounter(configReloadsCounter...),
+		configReloadsFailureCounter:      multi.NewCounter(configReloadsFailureCounter...),
+		lastConfigReloadSuccessGauge:     multi.NewGauge(lastConfigReloadSuccessGauge...),
+		lastConfigReloadFailureGauge:     multi.NewGauge(lastConfigReloadFailureGauge...),
+		configReloadDurationHistogram:    multi.NewHistogram(configReloadDurationHistogram...),
+		entrypointReqsCounter:            multi.NewCounter(entrypointReqsCounter...),
+		entrypointReqDurationHistogram:   multi.NewHistogram(entrypointReqDurationHistogram...),
+		entrypointOpenConnsGauge:         multi.NewGauge(entrypointOpenConnsGauge...),
+		entrypointPanicsCounter:          multi.NewCounter(entrypointPanicsCounter...),
+		entrypointConnectionsGauge:       multi.NewGauge(entrypointConnectionsGauge...),
+		backendReqsCounter:               multi.NewCounter(backendReqsCounter...),
+		backendReqDurationHistogram:      multi.NewHistogram(backendReqDurationHistogram...),
+		backendOpenConnsGauge:            multi.NewGauge(backendOpenConnsGauge...),
+		backendRetriesCounter:            multi.NewCounter(backendRetriesCounter...),
+		backendServerUpGauge:             multi.NewGauge(backendServerUpGauge...),
+		backendServerActiveConnsGauge:    multi.NewGauge(backendServerActiveConnsGauge...),
+		backendServerIdleConnsGauge:      multi.NewGauge(backendServerIdleConnsGauge...),
+		backendServerSlowStartGauge:      multi.NewGauge(backendServerSlowStartGauge...),
+		backendServerCircuitBreakerGauge: multi.NewGauge(backendServerCircuitBreakerGauge...),
+		backendServerWeightGauge:         multi.NewGauge(backendServerWeightGauge...),
+		routerReqSizeHistogram:           multi.NewHistogram(routerReqSizeHistogram...),
+		routerRespSizeHistogram:          multi.NewHistogram(routerRespSizeHistogram...),
 	}
 }
 
 type standardRegistry struct {
-	enabled                        bool
-	configReloadsCounter           metrics.Counter
-	configReloadsFailureCounter    metrics.Counter
-	lastConfigReloadSuccessGauge   metrics.Gauge
-	lastConfigReloadFailureGauge   metrics.Gauge
-	entrypointReqsCounter          metrics.Counter
-	entrypointReqDurationHistogram metrics.Histogram
-	entrypointOpenConnsGauge       metrics.Gauge
-	backendReqsCounter             metrics.Counter
-	backendReqDurationHistogram    metrics.Histogram
-	backendOpenConnsGauge          metrics.Gauge
-	backendRetriesCounter          metrics.Counter
-	backendServerUpGauge           metrics.Gauge
+	enabled                          bool
+	configReloadsCounter             metrics.Counter
+	configReloadsFailureCounter      metrics.Counter
+	lastConfigReloadSuccessGauge     metrics.Gauge
+	lastConfigReloadFailureGauge     metrics.Gauge
+	configReloadDurationHistogram    metrics.Histogram
+	entrypointReqsCounter            metrics.Counter
+	entrypointReqDurationHistogram   metrics.Histogram
+	entrypointOpenConnsGauge         metrics.Gauge
+	entrypointPanicsCounter          metrics.Counter
+	entrypointConnectionsGauge       metrics.Gauge
+	backendReqsCounter               metrics.Counter
+	backendReqDurationHistogram      metrics.Histogram
+	backendOpenConnsGauge            metrics.Gauge
+	backendRetriesCounter            metrics.Counter
+	backendServerUpGauge             metrics.Gauge
+	backendServerActiveConnsGauge    metrics.Gauge
+	backendServerIdleConnsGauge      metrics.Gauge
+	backendServerSlowStartGauge      metrics.Gauge
+	backendServerCircuitBreakerGauge metrics.Gauge
+	backendServerWeightGauge         metrics.Gauge
+	routerReqSizeHistogram           metrics.Histogram
+	routerRespSizeHistogram          metrics.Histogram
 }
 
 func (r *standardRegistry) IsEnabled() bool {
@@ -144,6 +216,10 @@ func (r *standardRegistry) LastConfigReloadFailureGauge() metrics.Gauge {
 	return r.lastConfigReloadFailureGauge
 }
 
+func (r *standardRegistry) ConfigReloadDurationHistogram() metrics.Histogram {
+	return r.configReloadDurationHistogram
+}
+
 func (r *standardRegistry) EntrypointReqsCounter() metrics.Counter {
 	return r.entrypointReqsCounter
 }
@@ -156,6 +232,14 @@ func (r *standardRegistry) EntrypointOpenConnsGauge() metrics.Gauge {
 	return r.entrypointOpenConnsGauge
 }
 
+func (r *standardRegistry) EntrypointPanicsCounter() metrics.Counter {
+	return r.entrypointPanicsCounter
+}
+
+func (r *standardRegistry) EntrypointConnectionsGauge() metrics.Gauge {
+	return r.entrypointConnectionsGauge
+}
+
 func (r *standardRegistry) BackendReqsCounter() metrics.Counter {
 	return r.backendReqsCounter
 }
@@ -175,3 +259,31 @@ func (r *standardRegistry) BackendRetriesCounter() metrics.Counter {
 func (r *standardRegistry) BackendServerUpGauge() metrics.Gauge {
 	return r.backendServerUpGauge
 }
+
+func (r *standardRegistry) BackendServerActiveConnsGauge() metrics.Gauge {
+	return r.backendServerActiveConnsGauge
+}
+
+func (r *standardRegistry) BackendServerIdleConnsGauge() metrics.Gauge {
+	return r.backendServerIdleConnsGauge
+}
+
+func (r *standardRegistry) BackendServerSlowStartGauge() metrics.Gauge {
+	return r.backendServerSlowStartGauge
+}
+
+func (r *standardRegistry) BackendServerCircuitBreakerGauge() metrics.Gauge {
+	return r.backendServerCircuitBreakerGauge
+}
+
+func (r *standardRegistry) BackendServerWeightGauge() metrics.Gauge {
+	return r.backendServerWeightGauge
+}
+
+func (r *standardRegistry) RouterReqSizeHistogram() metrics.Histogram {
+	return r.routerReqSizeHistogram
+}
+
+func (r *standardRegistry) RouterRespSizeHistogram() metrics.Histogram {
+	return r.routerRespSizeHistogram
+}
@@ -27,22 +27,35 @@ const (
 	configReloadsFailuresTotalName = metricConfigPrefix + "reloads_failure_total"
 	configLastReloadSuccessName    = metricConfigPrefix + "last_reload_success"
 	configLastReloadFailureName    = metricConfigPrefix + "last_reload_failure"
+	configReloadDurationName       = metricConfigPrefix + "reload_duration_seconds"
 
 	// entrypoint
 	metricEntryPointPrefix    = MetricNamePrefix + "entrypoint_"
 	entrypointReqsTotalName   = metricEntryPointPrefix + "requests_total"
 	entrypointReqDurationName = metricEntryPointPrefix + "request_duration_seconds"
 	entrypointOpenConnsName   = metricEntryPointPrefix + "open_connections"
+	entrypointPanicsTotalName = metricEntryPointPrefix + "panics_total"
+	entrypointConnsName       = metricEntryPointPrefix + "connections"
 
 	// backend level.
 
 	// MetricBackendPrefix prefix of all backend metric names
-	MetricBackendPrefix     = MetricNamePrefix + "backend_"
-	backendReqsTotalName    = MetricBackendPrefix + "requests_total"
-	backendReqDurationName  = MetricBackendPrefix + "request_duration_seconds"
-	backendOpenConnsName    = MetricBackendPrefix + "open_connections"
-	backendRetriesTotalName = MetricBackendPrefix + "retries_total"
-	backendServerUpName     = MetricBackendPrefix + "server_up"
+	MetricBackendPrefix             = MetricNamePrefix + "backend_"
+	backendReqsTotalName            = MetricBackendPrefix + "requests_total"
+	backendReqDurationName          = MetricBackendPrefix + "request_duration_seconds"
+	backendOpenConnsName            = MetricBackendPrefix + "open_connections"
+	backendRetriesTotalName         = MetricBackendPrefix + "retries_total"
+	backendServerUpName             = MetricBackendPrefix + "server_up"
+	backendServerActiveConnsName    = MetricBackendPrefix + "server_active_connections"
+	backendServerIdleConnsName      = MetricBackendPrefix + "server_idle_connections"
+	backendServerSlowStartName      = MetricBackendPrefix + "server_slow_start_progress"
+	backendServerCircuitBreakerName = MetricBackendPrefix + "server_circuit_breaker_open"
+	backendServerWeightName         = MetricBackendPrefix + "server_weight"
+
+	// router level.
+	metricRouterPrefix = MetricNamePrefix + "router_"
+	routerReqSizeName  = metricRouterPrefix + "request_size_bytes"
+	routerRespSizeName = metricRouterPrefix + "response_size_bytes"
 )
 
 // promState holds all metric state internally and acts as the only Collector we register for Prometheus.
@@ -105,6 +118,11 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: configLastReloadFailureName,
 		Help: "Last config reload failure",
 	}, []string{})
+	configReloadDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+		Name:    configReloadDurationName,
+		Help:    "How long it took to reload the config.",
+		Buckets: buckets,
+	}, []string{})
 
 	entrypointReqs := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
 		Name: entrypointReqsTotalName,
@@ -119,6 +137,14 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: entrypointOpenConnsName,
 		Help: "How many open connections exist on an entrypoint, partitioned by method and protocol.",
 	}, []string{"method", "protocol", "entrypoint"})
+	entrypointPanics := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: entrypointPanicsTotalName,
+		Help: "How many requests panicked on an entrypoint, and were answered with a 500.",
+	}, []string{"entrypoint"})
+	entrypointConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: entrypointConnsName,
+		Help: "How many client connections are open on an entrypoint with a connection limit.",
+	}, []string{"entrypoint"})
 
 	backendReqs := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
 		Name: backendReqsTotalName,
@@ -141,36 +167,88 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: backendServerUpName,
 		Help: "Backend server is up, described by gauge value of 0 or 1.",
 	}, []string{"backend", "url"})
+	backendServerActiveConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: backendServerActiveConnsName,
+		Help: "How many connections to a backend server host are in use.",
+	}, []string{"transport", "host"})
+	backendServerIdleConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: backendServerIdleConnsName,
+		Help: "How many idle (keep-alive) connections to a backend server host are open.",
+	}, []string{"transport", "host"})
+	backendServerSlowStart := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: backendServerSlowStartName,
+		Help: "Progress of the slow start of a backend server, from 0 to 1.",
+	}, []string{"service", "url"})
+	backendServerCircuitBreaker := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: backendServerCircuitBreakerName,
+		Help: "Whether the circuit breaker of a backend server is open (1) or closed (0).",
+	}, []string{"service", "url"})
+	backendServerWeight := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: backendServerWeightName,
+		Help: "Effective weight of a backend server in the load-balancer of its service.",
+	}, []string{"service", "url"})
+
+	sizeBuckets := stdprometheus.ExponentialBuckets(100, 10, 6)
+	routerReqSizes := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+		Name:    routerReqSizeName,
+		Help:    "Size of the request bodies read on a router, partitioned by service.",
+		Buckets: sizeBuckets,
+	}, []string{"router", "service"})
+	routerRespSizes := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+		Name:    routerRespSizeName,
+		Help:    "Size of the response bodies written on a router, partitioned by service.",
+		Buckets: sizeBuckets,
+	}, []string{"router", "service"})
 
 	promState.describers = []func(chan<- *stdprometheus.Desc){
 		configReloads.cv.Describe,
 		configReloadsFailures.cv.Describe,
 		lastConfigReloadSuccess.gv.Describe,
 		lastConfigReloadFailure.gv.Describe,
+		configReloadDurations.hv.Describe,
 		entrypointReqs.cv.Describe,
 		entrypointReqDurations.hv.Describe,
 		entrypointOpenConns.gv.Describe,
+		entrypointPanics.cv.Describe,
+		entrypointConns.gv.Describe,
 		backendReqs.cv.Describe,
 		backendReqDurations.hv.Describe,
 		backendOpenConns.gv.Describe,
 		backendRetries.cv.Describe,
 		backendServerUp.gv.Describe,
+		backendServerActiveConns.gv.Describe,
+		backendServerIdleConns.gv.Describe,
+		backendServerSlowStart.gv.Describe,
+		backendServerCircuitBreaker.gv.Describe,
+		backendServerWeight.gv.Describe,
+		routerReqSizes.hv.Describe,
+		routerRespSizes.hv.Describe,
 	}
 
 	return &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           configReloads,
-		configReloadsFailureCounter:    configReloadsFailures,
-		lastConfigReloadSuccessGauge:   lastConfigReloadSuccess,
-		lastConfigReloadFailureGauge:   lastConfigReloadFailure,
-		entrypointReqsCounter:          entrypointReqs,
-		entrypointReqDurationHistogram: entrypointReqDurations,
-		entrypointOpenConnsGauge:       entrypointOpenConns,
-		backendReqsCounter:             backendReqs,
-		backendReqDurationHistogram:    backendReqDurations,
-		backendOpenConnsGauge:          backendOpenConns,
-		backendRetriesCounter:          backendRetries,
-		backendServerUpGauge:           backendServerUp,
+		enabled:                          true,
+		configReloadsCounter:             configReloads,
+		configReloadsFailureCounter:      configReloadsFailures,
+		lastConfigReloadSuccessGauge:     lastConfigReloadSuccess,
+		lastConfigReloadFailureGauge:     lastConfigReloadFailure,
+		configReloadDurationHistogram:    configReloadDurations,
+		entrypointReqsCounter:            entrypointReqs,
+		entrypointReqDurationHistogram:   entrypointReqDurations,
+		entrypointOpenConnsGauge:         entrypointOpenConns,
+		entrypointPanicsCounter:          entrypointPanics,
+		entrypointConnectionsGauge:       entrypointConns,
+		backendReqsCounter:               backendReqs,
+		backendReqDurationHistogram:      backendReqDurations,
+		backendOpenConnsGauge:            backendOpenConns,
+		backendRetriesCounter:            backendRetries,
+		backendServerUpGauge:             backendServerUp,
+		backendServerActiveConnsGauge:    backendServerActiveConns,
+		backendServerIdleConnsGauge:      backendServerIdleConns,
+		backendServerSlowStartGauge:      backendServerSlowStart,
+		backendServerCircuitBreakerGauge: backendServerCircuitBreaker,
+		backendServerWeightGauge:         backendServerWeight,
+		routerReqSizeHistogram:           routerReqSizes,
+		routerRespSizeHistogram:          routerRespSizes,
 	}
 }
 
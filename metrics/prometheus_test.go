@@ -113,6 +113,7 @@ func TestPrometheus(t *testing.T) {
 	prometheusRegistry.ConfigReloadsFailureCounter().Add(1)
 	prometheusRegistry.LastConfigReloadSuccessGauge().Set(float64(time.Now().Unix()))
 	prometheusRegistry.LastConfigReloadFailureGauge().Set(float64(time.Now().Unix()))
+	prometheusRegistry.ConfigReloadDurationHistogram().Observe(1)
 
 	prometheusRegistry.
 		EntrypointReqsCounter().
@@ -127,6 +128,16 @@ func TestPrometheus(t *testing.T) {
 		With("method", http.MethodGet, "protocol", "http", "entrypoint", "http").
 		Set(1)
 
+	prometheusRegistry.
+		EntrypointPanicsCounter().
+		With("entrypoint", "http").
+		Add(1)
+
+	prometheusRegistry.
+		EntrypointConnectionsGauge().
+		With("entrypoint", "http").
+		Set(1)
+
 	prometheusRegistry.
 		BackendReqsCounter().
 		With("backend", "backend1", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http").
@@ -148,6 +159,15 @@ func TestPrometheus(t *testing.T) {
 		With("backend", "backend1", "url", "http://127.0.0.10:80").
 		Set(1)
 
+	prometheusRegistry.
+		RouterReqSizeHistogram().
+		With("router", "router1", "service", "service1").
+		Observe(1024)
+	prometheusRegistry.
+		RouterRespSizeHistogram().
+		With("router", "router1", "service", "service1").
+		Observe(2048)
+
 	delayForTrackingCompletion()
 
 	metricsFamilies := mustScrape()
@@ -173,6 +193,10 @@ func TestPrometheus(t *testing.T) {
 			name:   configLastReloadFailureName,
 			assert: buildTimestampAssert(t, configLastReloadFailureName),
 		},
+		{
+			name:   configReloadDurationName,
+			assert: buildHistogramAssert(t, configReloadDurationName, 1),
+		},
 		{
 			name: entrypointReqsTotalName,
 			labels: map[string]string{
@@ -202,6 +226,20 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, entrypointOpenConnsName, 1),
 		},
+		{
+			name: entrypointPanicsTotalName,
+			labels: map[string]string{
+				"entrypoint": "http",
+			},
+			assert: buildCounterAssert(t, entrypointPanicsTotalName, 1),
+		},
+		{
+			name: entrypointConnsName,
+			labels: map[string]string{
+				"entrypoint": "http",
+			},
+			assert: buildGaugeAssert(t, entrypointConnsName, 1),
+		},
 		{
 			name: backendReqsTotalName,
 			labels: map[string]string{
@@ -246,6 +284,22 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, backendServerUpName, 1),
 		},
+		{
+			name: routerReqSizeName,
+			labels: map[string]string{
+				"router":  "router1",
+				"service": "service1",
+			},
+			assert: buildHistogramAssert(t, routerReqSizeName, 1),
+		},
+		{
+			name: routerRespSizeName,
+			labels: map[string]string{
+				"router":  "router1",
+				"service": "service1",
+			},
+			assert: buildHistogramAssert(t, routerRespSizeName, 1),
+		},
 	}
 
 	for _, test := range tests {
@@ -26,11 +26,21 @@ const (
 	statsdConfigReloadsFailureName    = statsdConfigReloadsName + ".failure"
 	statsdLastConfigReloadSuccessName = "config.reload.lastSuccessTimestamp"
 	statsdLastConfigReloadFailureName = "config.reload.lastFailureTimestamp"
+	statsdConfigReloadDurationName    = "config.reload.duration"
 	statsdEntrypointReqsName          = "entrypoint.request.total"
 	statsdEntrypointReqDurationName   = "entrypoint.request.duration"
 	statsdEntrypointOpenConnsName     = "entrypoint.connections.open"
+	statsdEntrypointPanicsName        = "entrypoint.panics.total"
+	statsdEntrypointConnsName         = "entrypoint.connections.current"
 	statsdOpenConnsName               = "backend.connections.open"
 	statsdServerUpName                = "backend.server.up"
+	statsdServerActiveConnsName       = "backend.server.connections.active"
+	statsdServerIdleConnsName         = "backend.server.connections.idle"
+	statsdServerSlowStartName         = "backend.server.slowstart.progress"
+	statsdServerCircuitBreakerName    = "backend.server.circuitbreaker.open"
+	statsdServerWeightName            = "backend.server.weight"
+	statsdRouterReqSizeName           = "router.request.size"
+	statsdRouterRespSizeName          = "router.response.size"
 )
 
 // RegisterStatsd registers the metrics pusher if this didn't happen yet and creates a statsd Registry instance.
@@ -40,19 +50,29 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 	}
 
 	return &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
-		configReloadsFailureCounter:    statsdClient.NewCounter(statsdConfigReloadsFailureName, 1.0),
-		lastConfigReloadSuccessGauge:   statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
-		lastConfigReloadFailureGauge:   statsdClient.NewGauge(statsdLastConfigReloadFailureName),
-		entrypointReqsCounter:          statsdClient.NewCounter(statsdEntrypointReqsName, 1.0),
-		entrypointReqDurationHistogram: statsdClient.NewTiming(statsdEntrypointReqDurationName, 1.0),
-		entrypointOpenConnsGauge:       statsdClient.NewGauge(statsdEntrypointOpenConnsName),
-		backendReqsCounter:             statsdClient.NewCounter(statsdMetricsBackendReqsName, 1.0),
-		backendReqDurationHistogram:    statsdClient.NewTiming(statsdMetricsBackendLatencyName, 1.0),
-		backendRetriesCounter:          statsdClient.NewCounter(statsdRetriesTotalName, 1.0),
-		backendOpenConnsGauge:          statsdClient.NewGauge(statsdOpenConnsName),
-		backendServerUpGauge:           statsdClient.NewGauge(statsdServerUpName),
+		enabled:                          true,
+		configReloadsCounter:             statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
+		configReloadsFailureCounter:      statsdClient.NewCounter(statsdConfigReloadsFailureName, 1.0),
+		lastConfigReloadSuccessGauge:     statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
+		lastConfigReloadFailureGauge:     statsdClient.NewGauge(statsdLastConfigReloadFailureName),
+		configReloadDurationHistogram:    statsdClient.NewTiming(statsdConfigReloadDurationName, 1.0),
+		entrypointReqsCounter:            statsdClient.NewCounter(statsdEntrypointReqsName, 1.0),
+		entrypointReqDurationHistogram:   statsdClient.NewTiming(statsdEntrypointReqDurationName, 1.0),
+		entrypointOpenConnsGauge:         statsdClient.NewGauge(statsdEntrypointOpenConnsName),
+		entrypointPanicsCounter:          statsdClient.NewCounter(statsdEntrypointPanicsName, 1.0),
+		entrypointConnectionsGauge:       statsdClient.NewGauge(statsdEntrypointConnsName),
+		backendReqsCounter:               statsdClient.NewCounter(statsdMetricsBackendReqsName, 1.0),
+		backendReqDurationHistogram:      statsdClient.NewTiming(statsdMetricsBackendLatencyName, 1.0),
+		backendRetriesCounter:            statsdClient.NewCounter(statsdRetriesTotalName, 1.0),
+		backendOpenConnsGauge:            statsdClient.NewGauge(statsdOpenConnsName),
+		backendServerUpGauge:             statsdClient.NewGauge(statsdServerUpName),
+		backendServerActiveConnsGauge:    statsdClient.NewGauge(statsdServerActiveConnsName),
+		backendServerIdleConnsGauge:      statsdClient.NewGauge(statsdServerIdleConnsName),
+		backendServerSlowStartGauge:      statsdClient.NewGauge(statsdServerSlowStartName),
+		backendServerCircuitBreakerGauge: statsdClient.NewGauge(statsdServerCircuitBreakerName),
+		backendServerWeightGauge:         statsdClient.NewGauge(statsdServerWeightName),
+		routerReqSizeHistogram:           statsdClient.NewTiming(statsdRouterReqSizeName, 1.0),
+		routerRespSizeHistogram:          statsdClient.NewTiming(statsdRouterRespSizeName, 1.0),
 	}
 }
 
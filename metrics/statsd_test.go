@@ -29,6 +29,7 @@ func TestStatsD(t *testing.T) {
 		"traefik.backend.request.duration:10000.000000|ms",
 		"traefik.config.reload.total:1.000000|c\n",
 		"traefik.config.reload.total:1.000000|c\n",
+		"traefik.config.reload.duration:10.000000|ms",
 		"traefik.entrypoint.request.total:1.000000|c\n",
 		"traefik.entrypoint.request.duration:10000.000000|ms",
 		"traefik.entrypoint.connections.open:1.000000|g\n",
@@ -43,6 +44,7 @@ func TestStatsD(t *testing.T) {
 		statsdRegistry.BackendReqDurationHistogram().With("service", "test", "code", string(http.StatusOK)).Observe(10000)
 		statsdRegistry.ConfigReloadsCounter().Add(1)
 		statsdRegistry.ConfigReloadsFailureCounter().Add(1)
+		statsdRegistry.ConfigReloadDurationHistogram().Observe(10)
 		statsdRegistry.EntrypointReqsCounter().With("entrypoint", "test").Add(1)
 		statsdRegistry.EntrypointReqDurationHistogram().With("entrypoint", "test").Observe(10000)
 		statsdRegistry.EntrypointOpenConnsGauge().With("entrypoint", "test").Set(1)
@@ -14,11 +14,12 @@ var (
 )
 
 // captureResponseWriter is a wrapper of type http.ResponseWriter
-// that tracks request status and size
+// that tracks request status and size, and the size of the headers once written
 type captureResponseWriter struct {
-	rw     http.ResponseWriter
-	status int
-	size   int64
+	rw          http.ResponseWriter
+	status      int
+	size        int64
+	headersSize int64
 }
 
 func (crw *captureResponseWriter) Header() http.Header {
@@ -28,6 +29,7 @@ func (crw *captureResponseWriter) Header() http.Header {
 func (crw *captureResponseWriter) Write(b []byte) (int, error) {
 	if crw.status == 0 {
 		crw.status = http.StatusOK
+		crw.headersSize = headersSize(crw.rw.Header())
 	}
 	size, err := crw.rw.Write(b)
 	crw.size += int64(size)
@@ -35,6 +37,9 @@ func (crw *captureResponseWriter) Write(b []byte) (int, error) {
 }
 
 func (crw *captureResponseWriter) WriteHeader(s int) {
+	if crw.status == 0 {
+		crw.headersSize = headersSize(crw.rw.Header())
+	}
 	crw.rw.WriteHeader(s)
 	crw.status = s
 }
@@ -66,3 +71,23 @@ func (crw *captureResponseWriter) Status() int {
 func (crw *captureResponseWriter) Size() int64 {
 	return crw.size
 }
+
+// HeadersSize returns the size of the headers when they were written,
+// or of the current headers if they are not written yet, as they are then written by the Go HTTP server.
+func (crw *captureResponseWriter) HeadersSize() int64 {
+	if crw.status == 0 {
+		return headersSize(crw.rw.Header())
+	}
+	return crw.headersSize
+}
+
+// headersSize returns the number of bytes of the headers in the HTTP/1.1 format, with a "Name: value\r\n" line per value.
+func headersSize(header http.Header) int64 {
+	var size int64
+	for name, values := range header {
+		for _, value := range values {
+			size += int64(len(name) + len(value) + len(": \r\n"))
+		}
+	}
+	return size
+}
@@ -39,6 +39,13 @@ func (f *FieldHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// DisableAccessLog prevents the access log of the request from being written.
+func DisableAccessLog(rw http.ResponseWriter, req *http.Request, next http.Handler, data *LogData) {
+	data.disabled = true
+
+	next.ServeHTTP(rw, req)
+}
+
 // AddServiceFields add service fields
 func AddServiceFields(rw http.ResponseWriter, req *http.Request, next http.Handler, data *LogData) {
 	data.Core[ServiceURL] = req.URL // note that this is *not* the original incoming URL
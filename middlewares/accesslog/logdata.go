@@ -44,6 +44,8 @@ const (
 	RequestProtocol = "RequestProtocol"
 	// RequestContentSize is the map key used for the number of bytes in the request entity (a.k.a. body) sent by the client.
 	RequestContentSize = "RequestContentSize"
+	// RequestHeadersSize is the map key used for the number of bytes in the headers of the request, including Host, in the HTTP/1.1 format.
+	RequestHeadersSize = "RequestHeadersSize"
 	// RequestRefererHeader is the Referer header in the request
 	RequestRefererHeader = "request_Referer"
 	// RequestUserAgentHeader is the User-Agent header in the request
@@ -60,6 +62,9 @@ const (
 	// DownstreamContentSize is the map key used for the number of bytes in the response entity returned to the client.
 	// This is in addition to the "Content-Length" header, which may be present in the origin response.
 	DownstreamContentSize = "DownstreamContentSize"
+	// DownstreamHeadersSize is the map key used for the number of bytes in the headers of the response returned to the client, in the HTTP/1.1 format.
+	// The headers added by the Go HTTP server once the headers are written, e.g. Date, are not counted.
+	DownstreamHeadersSize = "DownstreamHeadersSize"
 	// RequestCount is the map key used for the number of requests received since the Traefik instance started.
 	RequestCount = "RequestCount"
 	// GzipRatio is the map key used for the response body compression ratio achieved.
@@ -68,6 +73,10 @@ const (
 	Overhead = "Overhead"
 	// RetryAttempts is the map key used for the amount of attempts the request was retried.
 	RetryAttempts = "RetryAttempts"
+	// TraceID is the map key used for the ID of the trace of the request, when the tracing is enabled.
+	TraceID = "TraceID"
+	// SpanID is the map key used for the ID of the entry point span of the request, when the tracing is enabled.
+	SpanID = "SpanID"
 )
 
 // These are written out in the default case when no config is provided to specify keys of interest.
@@ -108,6 +117,10 @@ func init() {
 	allCoreKeys[StartLocal] = struct{}{}
 	allCoreKeys[Overhead] = struct{}{}
 	allCoreKeys[RetryAttempts] = struct{}{}
+	allCoreKeys[TraceID] = struct{}{}
+	allCoreKeys[SpanID] = struct{}{}
+	allCoreKeys[RequestHeadersSize] = struct{}{}
+	allCoreKeys[DownstreamHeadersSize] = struct{}{}
 }
 
 // CoreLogData holds the fields computed from the request/response.
@@ -119,4 +132,6 @@ type LogData struct {
 	Request            http.Header
 	OriginResponse     http.Header
 	DownstreamResponse http.Header
+
+	disabled bool
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/containous/alice"
 	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/ip"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/types"
 	"github.com/sirupsen/logrus"
@@ -30,23 +31,43 @@ const (
 
 	// JSONFormat is the JSON logging format.
 	JSONFormat string = "json"
+
+	// CommonTimeFormat is the time format of the common logging format.
+	CommonTimeFormat string = "common"
+
+	// RFC3339TimeFormat is the RFC3339 time format.
+	RFC3339TimeFormat string = "rfc3339"
+
+	// UTCTimeZone writes the timestamps in UTC.
+	UTCTimeZone string = "utc"
+
+	// LocalTimeZone writes the timestamps in the local time zone.
+	LocalTimeZone string = "local"
 )
 
-type handlerParams struct {
-	logDataTable *LogData
-	crr          *captureRequestReader
-	crw          *captureResponseWriter
-}
+// defaultOutputBufferingSize is the number of access logs kept while an additional output is busy.
+const defaultOutputBufferingSize = 100
 
 // Handler will write each request and its response to the access log.
 type Handler struct {
-	config         *types.AccessLog
+	config     *types.AccessLog
+	outputs    []*output
+	ipStrategy ip.Strategy
+}
+
+// output writes the access logs to a file, or to stdout, with its own format, filters and fields.
+type output struct {
+	config         types.AccessLogOutput
 	logger         *logrus.Logger
 	file           *os.File
 	mu             sync.Mutex
 	httpCodeRanges types.HTTPCodeRanges
-	logHandlerChan chan handlerParams
+	logHandlerChan chan *LogData
 	wg             sync.WaitGroup
+	// dropWhenFull drops the access logs when the buffer is full, instead of waiting for the output.
+	dropWhenFull bool
+	// location is the time zone of the timestamps, nil keeping the default one of the format.
+	location *time.Location
 }
 
 // WrapHandler Wraps access log handler into an Alice Constructor.
@@ -59,7 +80,45 @@ func WrapHandler(handler *Handler) alice.Constructor {
 }
 
 // NewHandler creates a new Handler.
-func NewHandler(config *types.AccessLog) (*Handler, error) {
+// If the IP strategy is nil, the client host is taken from the X-Forwarded-For header if any.
+func NewHandler(config *types.AccessLog, ipStrategy ip.Strategy) (*Handler, error) {
+	mainOutput, err := newOutput(types.AccessLogOutput{
+		FilePath:      config.FilePath,
+		Format:        config.Format,
+		Filters:       config.Filters,
+		Fields:        config.Fields,
+		BufferingSize: config.BufferingSize,
+		TimeFormat:    config.TimeFormat,
+		TimeZone:      config.TimeZone,
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	logHandler := &Handler{
+		config:     config,
+		outputs:    []*output{mainOutput},
+		ipStrategy: ipStrategy,
+	}
+
+	// The additional outputs are asynchronous, so that an output which cannot keep up does not block the others.
+	for _, outputConfig := range config.Outputs {
+		if outputConfig.BufferingSize <= 0 {
+			outputConfig.BufferingSize = defaultOutputBufferingSize
+		}
+
+		additionalOutput, err := newOutput(outputConfig, true)
+		if err != nil {
+			_ = logHandler.Close()
+			return nil, err
+		}
+		logHandler.outputs = append(logHandler.outputs, additionalOutput)
+	}
+
+	return logHandler, nil
+}
+
+func newOutput(config types.AccessLogOutput, dropWhenFull bool) (*output, error) {
 	file := os.Stdout
 	if len(config.FilePath) > 0 {
 		f, err := openAccessLogFile(config.FilePath)
@@ -68,15 +127,37 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 		}
 		file = f
 	}
-	logHandlerChan := make(chan handlerParams, config.BufferingSize)
+	logHandlerChan := make(chan *LogData, config.BufferingSize)
+
+	var timeLayout string
+	switch config.TimeFormat {
+	case "":
+	case CommonTimeFormat:
+		timeLayout = commonLogTimeFormat
+	case RFC3339TimeFormat:
+		timeLayout = time.RFC3339
+	default:
+		return nil, fmt.Errorf("unsupported access log time format: %s", config.TimeFormat)
+	}
+
+	var location *time.Location
+	switch config.TimeZone {
+	case "":
+	case UTCTimeZone:
+		location = time.UTC
+	case LocalTimeZone:
+		location = time.Local
+	default:
+		return nil, fmt.Errorf("unsupported access log time zone: %s", config.TimeZone)
+	}
 
 	var formatter logrus.Formatter
 
 	switch config.Format {
 	case CommonFormat:
-		formatter = new(CommonLogFormatter)
+		formatter = &CommonLogFormatter{TimeLayout: timeLayout, Location: location}
 	case JSONFormat:
-		formatter = new(logrus.JSONFormatter)
+		formatter = &logrus.JSONFormatter{TimestampFormat: timeLayout}
 	default:
 		return nil, fmt.Errorf("unsupported access log format: %s", config.Format)
 	}
@@ -88,32 +169,34 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 		Level:     logrus.InfoLevel,
 	}
 
-	logHandler := &Handler{
+	o := &output{
 		config:         config,
 		logger:         logger,
 		file:           file,
 		logHandlerChan: logHandlerChan,
+		dropWhenFull:   dropWhenFull,
+		location:       location,
 	}
 
 	if config.Filters != nil {
 		if httpCodeRanges, err := types.NewHTTPCodeRanges(config.Filters.StatusCodes); err != nil {
 			log.WithoutContext().Errorf("Failed to create new HTTP code ranges: %s", err)
 		} else {
-			logHandler.httpCodeRanges = httpCodeRanges
+			o.httpCodeRanges = httpCodeRanges
 		}
 	}
 
 	if config.BufferingSize > 0 {
-		logHandler.wg.Add(1)
+		o.wg.Add(1)
 		go func() {
-			defer logHandler.wg.Done()
-			for handlerParams := range logHandler.logHandlerChan {
-				logHandler.logTheRoundTrip(handlerParams.logDataTable, handlerParams.crr, handlerParams.crw)
+			defer o.wg.Done()
+			for logDataTable := range o.logHandlerChan {
+				o.logTheRoundTrip(logDataTable)
 			}
 		}()
 	}
 
-	return logHandler, nil
+	return o, nil
 }
 
 func openAccessLogFile(filePath string) (*os.File, error) {
@@ -176,10 +259,19 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 	core[RequestPath] = urlCopyString
 	core[RequestProtocol] = req.Proto
 
+	// The headers are measured before the middlewares change them.
+	requestHeadersSize := headersSize(req.Header)
+	if req.Host != "" {
+		requestHeadersSize += headersSize(http.Header{"Host": {req.Host}})
+	}
+	core[RequestHeadersSize] = requestHeadersSize
+
 	core[ClientAddr] = req.RemoteAddr
 	core[ClientHost], core[ClientPort] = silentSplitHostPort(req.RemoteAddr)
 
-	if forwardedFor := req.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+	if h.ipStrategy != nil {
+		core[ClientHost] = ip.ClientIP(h.ipStrategy, req)
+	} else if forwardedFor := req.Header.Get("X-Forwarded-For"); forwardedFor != "" {
 		core[ClientHost] = forwardedFor
 	}
 
@@ -187,47 +279,86 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 
 	next.ServeHTTP(crw, reqWithDataTable)
 
+	if logDataTable.disabled {
+		return
+	}
+
 	if _, ok := core[ClientUsername]; !ok {
 		core[ClientUsername] = usernameIfPresent(reqWithDataTable.URL)
 	}
 
 	logDataTable.DownstreamResponse = crw.Header()
 
-	if h.config.BufferingSize > 0 {
-		h.logHandlerChan <- handlerParams{
-			logDataTable: logDataTable,
-			crr:          crr,
-			crw:          crw,
-		}
-	} else {
-		h.logTheRoundTrip(logDataTable, crr, crw)
+	completeLogData(logDataTable, crr, crw)
+
+	for _, o := range h.outputs {
+		o.log(logDataTable)
 	}
 }
 
-// Close closes the Logger (i.e. the file, drain logHandlerChan, etc).
+// Close closes the Logger (i.e. the files, drain the logHandlerChan of the outputs, etc).
 func (h *Handler) Close() error {
-	close(h.logHandlerChan)
-	h.wg.Wait()
-	return h.file.Close()
+	var closeErr error
+	for _, o := range h.outputs {
+		if err := o.close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	return closeErr
 }
 
-// Rotate closes and reopens the log file to allow for rotation by an external source.
+// Rotate closes and reopens the log files to allow for rotation by an external source.
 func (h *Handler) Rotate() error {
-	var err error
+	for _, o := range h.outputs {
+		if err := o.rotate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	if h.file != nil {
-		defer func(f *os.File) {
-			f.Close()
-		}(h.file)
+func (o *output) log(logDataTable *LogData) {
+	switch {
+	case o.config.BufferingSize <= 0:
+		o.logTheRoundTrip(logDataTable)
+	case o.dropWhenFull:
+		select {
+		case o.logHandlerChan <- logDataTable:
+		default:
+			log.WithoutContext().Debugf("Access log output %q is full, dropping the access log", o.config.FilePath)
+		}
+	default:
+		o.logHandlerChan <- logDataTable
+	}
+}
+
+func (o *output) close() error {
+	close(o.logHandlerChan)
+	o.wg.Wait()
+	return o.file.Close()
+}
+
+func (o *output) rotate() error {
+	if len(o.config.FilePath) == 0 {
+		return nil
 	}
 
-	h.file, err = os.OpenFile(h.config.FilePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0664)
+	file, err := os.OpenFile(o.config.FilePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0664)
 	if err != nil {
 		return err
 	}
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	h.logger.Out = h.file
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file != nil {
+		defer func(f *os.File) {
+			f.Close()
+		}(o.file)
+	}
+
+	o.file = file
+	o.logger.Out = o.file
 	return nil
 }
 
@@ -248,8 +379,8 @@ func usernameIfPresent(theURL *url.URL) string {
 	return "-"
 }
 
-// Logging handler to log frontend name, backend name, and elapsed time.
-func (h *Handler) logTheRoundTrip(logDataTable *LogData, crr *captureRequestReader, crw *captureResponseWriter) {
+// completeLogData adds the fields known once the response is sent, shared by all the outputs.
+func completeLogData(logDataTable *LogData, crr *captureRequestReader, crw *captureResponseWriter) {
 	core := logDataTable.Core
 
 	retryAttempts, ok := core[RetryAttempts].(int)
@@ -268,41 +399,51 @@ func (h *Handler) logTheRoundTrip(logDataTable *LogData, crr *captureRequestRead
 	totalDuration := time.Now().UTC().Sub(core[StartUTC].(time.Time))
 	core[Duration] = totalDuration
 
-	if h.keepAccessLog(crw.Status(), retryAttempts, totalDuration) {
-		core[DownstreamContentSize] = crw.Size()
-		if original, ok := core[OriginContentSize]; ok {
-			o64 := original.(int64)
-			if crw.Size() != o64 && crw.Size() != 0 {
-				core[GzipRatio] = float64(o64) / float64(crw.Size())
-			}
+	core[DownstreamContentSize] = crw.Size()
+	core[DownstreamHeadersSize] = crw.HeadersSize()
+	if original, ok := core[OriginContentSize]; ok {
+		o64 := original.(int64)
+		if crw.Size() != o64 && crw.Size() != 0 {
+			core[GzipRatio] = float64(o64) / float64(crw.Size())
 		}
+	}
 
-		core[Overhead] = totalDuration
-		if origin, ok := core[OriginDuration]; ok {
-			core[Overhead] = totalDuration - origin.(time.Duration)
-		}
+	core[Overhead] = totalDuration
+	if origin, ok := core[OriginDuration]; ok {
+		core[Overhead] = totalDuration - origin.(time.Duration)
+	}
+}
 
+// Logging handler to log frontend name, backend name, and elapsed time.
+func (o *output) logTheRoundTrip(logDataTable *LogData) {
+	core := logDataTable.Core
+
+	if o.keepAccessLog(core[DownstreamStatus].(int), core[RetryAttempts].(int), core[Duration].(time.Duration)) {
 		fields := logrus.Fields{}
 
 		for k, v := range logDataTable.Core {
-			if h.config.Fields.Keep(k) {
+			if o.config.Fields.Keep(k) {
 				fields[k] = v
 			}
 		}
 
-		h.redactHeaders(logDataTable.Request, fields, "request_")
-		h.redactHeaders(logDataTable.OriginResponse, fields, "origin_")
-		h.redactHeaders(logDataTable.DownstreamResponse, fields, "downstream_")
+		o.redactHeaders(logDataTable.Request, fields, "request_")
+		o.redactHeaders(logDataTable.OriginResponse, fields, "origin_")
+		o.redactHeaders(logDataTable.DownstreamResponse, fields, "downstream_")
 
-		h.mu.Lock()
-		defer h.mu.Unlock()
-		h.logger.WithFields(fields).Println()
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		entry := o.logger.WithFields(fields)
+		if o.location != nil {
+			entry = entry.WithTime(time.Now().In(o.location))
+		}
+		entry.Println()
 	}
 }
 
-func (h *Handler) redactHeaders(headers http.Header, fields logrus.Fields, prefix string) {
+func (o *output) redactHeaders(headers http.Header, fields logrus.Fields, prefix string) {
 	for k := range headers {
-		v := h.config.Fields.KeepHeader(k)
+		v := o.config.Fields.KeepHeader(k)
 		if v == types.AccessLogKeep {
 			fields[prefix+k] = headers.Get(k)
 		} else if v == types.AccessLogRedact {
@@ -311,26 +452,26 @@ func (h *Handler) redactHeaders(headers http.Header, fields logrus.Fields, prefi
 	}
 }
 
-func (h *Handler) keepAccessLog(statusCode, retryAttempts int, duration time.Duration) bool {
-	if h.config.Filters == nil {
+func (o *output) keepAccessLog(statusCode, retryAttempts int, duration time.Duration) bool {
+	if o.config.Filters == nil {
 		// no filters were specified
 		return true
 	}
 
-	if len(h.httpCodeRanges) == 0 && !h.config.Filters.RetryAttempts && h.config.Filters.MinDuration == 0 {
+	if len(o.httpCodeRanges) == 0 && !o.config.Filters.RetryAttempts && o.config.Filters.MinDuration == 0 {
 		// empty filters were specified, e.g. by passing --accessLog.filters only (without other filter options)
 		return true
 	}
 
-	if h.httpCodeRanges.Contains(statusCode) {
+	if o.httpCodeRanges.Contains(statusCode) {
 		return true
 	}
 
-	if h.config.Filters.RetryAttempts && retryAttempts > 0 {
+	if o.config.Filters.RetryAttempts && retryAttempts > 0 {
 		return true
 	}
 
-	if h.config.Filters.MinDuration > 0 && (parse.Duration(duration) > h.config.Filters.MinDuration) {
+	if o.config.Filters.MinDuration > 0 && (parse.Duration(duration) > o.config.Filters.MinDuration) {
 		return true
 	}
 
@@ -15,7 +15,12 @@ const (
 )
 
 // CommonLogFormatter provides formatting in the Traefik common log format.
-type CommonLogFormatter struct{}
+type CommonLogFormatter struct {
+	// TimeLayout is the layout of the timestamp, the common log one when empty.
+	TimeLayout string
+	// Location is the time zone of the timestamp, UTC when nil.
+	Location *time.Location
+}
 
 // Format formats the log entry in the Traefik common log format.
 func (f *CommonLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
@@ -23,7 +28,16 @@ func (f *CommonLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 
 	var timestamp = defaultValue
 	if v, ok := entry.Data[StartUTC]; ok {
-		timestamp = v.(time.Time).Format(commonLogTimeFormat)
+		start := v.(time.Time)
+		if f.Location != nil {
+			start = start.In(f.Location)
+		}
+
+		layout := commonLogTimeFormat
+		if f.TimeLayout != "" {
+			layout = f.TimeLayout
+		}
+		timestamp = start.Format(layout)
 	}
 
 	var elapsedMillis int64
@@ -77,6 +77,50 @@ func TestCommonLogFormatter_Format(t *testing.T) {
 
 }
 
+func TestCommonLogFormatter_FormatTimestamp(t *testing.T) {
+	start := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc              string
+		formatter         CommonLogFormatter
+		expectedTimestamp string
+	}{
+		{
+			desc:              "default",
+			expectedTimestamp: "[10/Nov/2009:23:00:00 +0000]",
+		},
+		{
+			desc:              "RFC3339 layout",
+			formatter:         CommonLogFormatter{TimeLayout: time.RFC3339},
+			expectedTimestamp: "[2009-11-10T23:00:00Z]",
+		},
+		{
+			desc:              "other location",
+			formatter:         CommonLogFormatter{Location: time.FixedZone("", 2*60*60)},
+			expectedTimestamp: "[11/Nov/2009:01:00:00 +0200]",
+		},
+		{
+			desc:              "RFC3339 layout and other location",
+			formatter:         CommonLogFormatter{TimeLayout: time.RFC3339, Location: time.FixedZone("", -5*60*60)},
+			expectedTimestamp: "[2009-11-10T18:00:00-05:00]",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			entry := &logrus.Entry{Data: map[string]interface{}{StartUTC: start}}
+
+			raw, err := test.formatter.Format(entry)
+			assert.NoError(t, err)
+
+			assert.Contains(t, string(raw), " "+test.expectedTimestamp+" ")
+		})
+	}
+}
+
 func Test_toLog(t *testing.T) {
 
 	testCases := []struct {
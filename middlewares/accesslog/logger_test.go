@@ -1,6 +1,7 @@
 package accesslog
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -11,10 +12,12 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/ip"
 	"github.com/containous/traefik/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -49,7 +52,7 @@ func TestLogRotation(t *testing.T) {
 	rotatedFileName := fileName + ".rotated"
 
 	config := &types.AccessLog{FilePath: fileName, Format: CommonFormat}
-	logHandler, err := NewHandler(config)
+	logHandler, err := NewHandler(config, nil)
 	if err != nil {
 		t.Fatalf("Error creating new log handler: %s", err)
 	}
@@ -200,6 +203,8 @@ func TestLoggerJSON(t *testing.T) {
 				RequestPort:               assertString("-"),
 				DownstreamStatus:          assertFloat64(float64(testStatus)),
 				DownstreamContentSize:     assertFloat64(float64(len(testContent))),
+				DownstreamHeadersSize:     assertFloat64(0),
+				RequestHeadersSize:        assertFloat64(float64(len("User-Agent: testUserAgent\r\nReferer: testReferer\r\nHost: TestHost\r\n"))),
 				OriginContentSize:         assertFloat64(float64(len(testContent))),
 				OriginStatus:              assertFloat64(float64(testStatus)),
 				RequestRefererHeader:      assertString(testReferer),
@@ -336,6 +341,98 @@ func TestLoggerJSON(t *testing.T) {
 	}
 }
 
+func TestLoggerSizes(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		fields   *types.AccessLogFields
+		expected map[string]float64
+	}{
+		{
+			desc: "all sizes",
+			expected: map[string]float64{
+				RequestContentSize:    float64(len("request body")),
+				RequestHeadersSize:    float64(len("X-Foo: foo\r\nX-Foo: bar\r\nHost: foo.bar\r\n")),
+				DownstreamContentSize: float64(len("response body")),
+				DownstreamHeadersSize: float64(len("Content-Type: text/plain\r\nX-Bar: bar\r\n")),
+			},
+		},
+		{
+			desc: "dropped header sizes",
+			fields: &types.AccessLogFields{
+				Names: types.FieldNames{
+					RequestHeadersSize:    "drop",
+					DownstreamHeadersSize: "drop",
+				},
+			},
+			expected: map[string]float64{
+				RequestContentSize:    float64(len("request body")),
+				DownstreamContentSize: float64(len("response body")),
+			},
+		},
+		{
+			desc: "kept header sizes only",
+			fields: &types.AccessLogFields{
+				DefaultMode: "drop",
+				Names: types.FieldNames{
+					RequestHeadersSize:    "keep",
+					DownstreamHeadersSize: "keep",
+				},
+			},
+			expected: map[string]float64{
+				RequestHeadersSize:    float64(len("X-Foo: foo\r\nX-Foo: bar\r\nHost: foo.bar\r\n")),
+				DownstreamHeadersSize: float64(len("Content-Type: text/plain\r\nX-Bar: bar\r\n")),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tmpDir := createTempDir(t, JSONFormat)
+			defer os.RemoveAll(tmpDir)
+
+			logFilePath := filepath.Join(tmpDir, logFileNameSuffix)
+			logger, err := NewHandler(&types.AccessLog{FilePath: logFilePath, Format: JSONFormat, Fields: test.fields}, nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://foo.bar/", strings.NewReader("request body"))
+			req.Header.Add("X-Foo", "foo")
+			req.Header.Add("X-Foo", "bar")
+
+			logger.ServeHTTP(httptest.NewRecorder(), req, func(rw http.ResponseWriter, req *http.Request) {
+				_, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+
+				// Neither the request headers added after the logger, nor the response headers added once written are counted.
+				req.Header.Set("X-Added", "foo")
+				rw.Header().Set("Content-Type", "text/plain")
+				rw.Header().Set("X-Bar", "bar")
+				rw.WriteHeader(http.StatusOK)
+				rw.Header().Set("X-Added", "foo")
+				_, _ = rw.Write([]byte("response body"))
+			})
+			require.NoError(t, logger.Close())
+
+			logData, err := ioutil.ReadFile(logFilePath)
+			require.NoError(t, err)
+
+			jsonData := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal(logData, &jsonData))
+
+			for _, field := range []string{RequestContentSize, RequestHeadersSize, DownstreamContentSize, DownstreamHeadersSize} {
+				expected, ok := test.expected[field]
+				if !ok {
+					assert.NotContains(t, jsonData, field)
+					continue
+				}
+				assert.Equal(t, expected, jsonData[field], field)
+			}
+		})
+	}
+}
+
 func TestNewLogHandlerOutputStdout(t *testing.T) {
 	testCases := []struct {
 		desc        string
@@ -598,7 +695,7 @@ func createTempDir(t *testing.T, prefix string) string {
 }
 
 func doLogging(t *testing.T, config *types.AccessLog) {
-	logger, err := NewHandler(config)
+	logger, err := NewHandler(config, nil)
 	require.NoError(t, err)
 	defer logger.Close()
 
@@ -647,3 +744,277 @@ func logWriterTestHandlerFunc(rw http.ResponseWriter, r *http.Request) {
 
 	rw.WriteHeader(testStatus)
 }
+
+func TestLoggerClientHostWithIPStrategy(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		ipStrategy    ip.Strategy
+		remoteAddr    string
+		xForwardedFor string
+		expected      string
+	}{
+		{
+			desc:          "without IP strategy",
+			remoteAddr:    "10.0.0.1:1234",
+			xForwardedFor: "192.0.2.1, 192.0.2.2",
+			expected:      "192.0.2.1, 192.0.2.2",
+		},
+		{
+			desc:          "with remote address strategy",
+			ipStrategy:    &ip.RemoteAddrStrategy{},
+			remoteAddr:    "10.0.0.1:1234",
+			xForwardedFor: "192.0.2.1, 192.0.2.2",
+			expected:      "10.0.0.1",
+		},
+		{
+			desc:          "with depth strategy",
+			ipStrategy:    &ip.DepthStrategy{Depth: 2},
+			remoteAddr:    "10.0.0.1:1234",
+			xForwardedFor: "192.0.2.1, 192.0.2.2",
+			expected:      "192.0.2.1",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tmpDir := createTempDir(t, JSONFormat)
+			defer os.RemoveAll(tmpDir)
+
+			logger, err := NewHandler(&types.AccessLog{FilePath: filepath.Join(tmpDir, logFileNameSuffix), Format: JSONFormat}, test.ipStrategy)
+			require.NoError(t, err)
+			defer logger.Close()
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.RemoteAddr = test.remoteAddr
+			req.Header.Set("X-Forwarded-For", test.xForwardedFor)
+
+			var clientHost interface{}
+			logger.ServeHTTP(httptest.NewRecorder(), req, func(rw http.ResponseWriter, req *http.Request) {
+				clientHost = GetLogData(req).Core[ClientHost]
+			})
+
+			assert.Equal(t, test.expected, clientHost)
+		})
+	}
+}
+
+func TestLoggerTimeFormatAndZone(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		format         string
+		timeFormat     string
+		timeZone       string
+		expectedLayout string
+		expectedZone   *time.Location
+	}{
+		{
+			desc:           "common with common time in UTC",
+			format:         CommonFormat,
+			timeFormat:     CommonTimeFormat,
+			timeZone:       UTCTimeZone,
+			expectedLayout: commonLogTimeFormat,
+			expectedZone:   time.UTC,
+		},
+		{
+			desc:           "common with RFC3339 time in local time zone",
+			format:         CommonFormat,
+			timeFormat:     RFC3339TimeFormat,
+			timeZone:       LocalTimeZone,
+			expectedLayout: time.RFC3339,
+			expectedZone:   time.Local,
+		},
+		{
+			desc:           "JSON with common time in local time zone",
+			format:         JSONFormat,
+			timeFormat:     CommonTimeFormat,
+			timeZone:       LocalTimeZone,
+			expectedLayout: commonLogTimeFormat,
+			expectedZone:   time.Local,
+		},
+		{
+			desc:           "JSON with RFC3339 time in UTC",
+			format:         JSONFormat,
+			timeFormat:     RFC3339TimeFormat,
+			timeZone:       UTCTimeZone,
+			expectedLayout: time.RFC3339,
+			expectedZone:   time.UTC,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			tmpDir := createTempDir(t, test.format)
+			defer os.RemoveAll(tmpDir)
+
+			config := &types.AccessLog{
+				FilePath:   filepath.Join(tmpDir, logFileNameSuffix),
+				Format:     test.format,
+				TimeFormat: test.timeFormat,
+				TimeZone:   test.timeZone,
+			}
+			doLogging(t, config)
+
+			logData, err := ioutil.ReadFile(config.FilePath)
+			require.NoError(t, err)
+
+			if test.format == CommonFormat {
+				expectedTimestamp := testStart.In(test.expectedZone).Format(test.expectedLayout)
+				assert.Contains(t, string(logData), " ["+expectedTimestamp+"] ")
+				return
+			}
+
+			jsonData := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal(logData, &jsonData))
+
+			timestamp, err := time.Parse(test.expectedLayout, jsonData["time"].(string))
+			require.NoError(t, err)
+
+			_, offset := timestamp.Zone()
+			_, expectedOffset := time.Now().In(test.expectedZone).Zone()
+			assert.Equal(t, expectedOffset, offset)
+		})
+	}
+}
+
+func TestNewHandlerInvalidTimeFormatAndZone(t *testing.T) {
+	_, err := NewHandler(&types.AccessLog{Format: CommonFormat, TimeFormat: "foo"}, nil)
+	assert.Error(t, err)
+
+	_, err = NewHandler(&types.AccessLog{Format: CommonFormat, TimeZone: "foo"}, nil)
+	assert.Error(t, err)
+}
+
+func TestLoggerMultipleOutputs(t *testing.T) {
+	tmpDir := createTempDir(t, "multiple-outputs")
+	defer os.RemoveAll(tmpDir)
+
+	config := &types.AccessLog{
+		FilePath: filepath.Join(tmpDir, "main.log"),
+		Format:   CommonFormat,
+		Outputs: []types.AccessLogOutput{
+			{FilePath: filepath.Join(tmpDir, "common.log"), Format: CommonFormat},
+			{FilePath: filepath.Join(tmpDir, "json.log"), Format: JSONFormat},
+			{FilePath: filepath.Join(tmpDir, "filtered.log"), Format: CommonFormat, Filters: &types.AccessLogFilters{StatusCodes: []string{"500"}}},
+		},
+	}
+
+	logger, err := NewHandler(config, nil)
+	require.NoError(t, err)
+	require.Len(t, logger.outputs, 4)
+
+	buffers := make([]*syncBuffer, len(logger.outputs))
+	for i, o := range logger.outputs {
+		buffers[i] = &syncBuffer{}
+		o.mu.Lock()
+		o.logger.Out = buffers[i]
+		o.mu.Unlock()
+	}
+
+	logger.ServeHTTP(httptest.NewRecorder(), newTestRequest(), logWriterTestHandlerFunc)
+
+	require.NoError(t, logger.Close())
+
+	expectedLog := ` TestHost - TestUser [13/Apr/2016:07:14:19 -0700] "POST testpath HTTP/0.0" 123 12 "testReferer" "testUserAgent" 1 "testRouter" "http://127.0.0.1/testService" 1ms`
+	assertValidLogData(t, expectedLog, buffers[0].Bytes())
+	assertValidLogData(t, expectedLog, buffers[1].Bytes())
+
+	jsonData := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(buffers[2].Bytes(), &jsonData))
+	assert.Equal(t, testHostname, jsonData[RequestHost])
+	assert.Equal(t, float64(testStatus), jsonData[DownstreamStatus])
+
+	assert.Empty(t, buffers[3].String())
+}
+
+func TestLoggerMultipleOutputsBlocked(t *testing.T) {
+	tmpDir := createTempDir(t, "blocked-output")
+	defer os.RemoveAll(tmpDir)
+
+	config := &types.AccessLog{
+		FilePath: filepath.Join(tmpDir, "main.log"),
+		Format:   CommonFormat,
+		Outputs: []types.AccessLogOutput{
+			{FilePath: filepath.Join(tmpDir, "blocked.log"), Format: CommonFormat, BufferingSize: 1},
+			{FilePath: filepath.Join(tmpDir, "other.log"), Format: CommonFormat},
+		},
+	}
+
+	logger, err := NewHandler(config, nil)
+	require.NoError(t, err)
+
+	blocked := &blockingWriter{release: make(chan struct{})}
+	other := &syncBuffer{}
+	logger.outputs[1].logger.Out = blocked
+	logger.outputs[2].logger.Out = other
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			logger.ServeHTTP(httptest.NewRecorder(), newTestRequest(), logWriterTestHandlerFunc)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the blocked output blocks the requests")
+	}
+
+	close(blocked.release)
+	require.NoError(t, logger.Close())
+
+	assert.Equal(t, 10, strings.Count(other.String(), "\n"))
+}
+
+func newTestRequest() *http.Request {
+	return &http.Request{
+		Header: map[string][]string{
+			"User-Agent": {testUserAgent},
+			"Referer":    {testReferer},
+		},
+		Proto:      testProto,
+		Host:       testHostname,
+		Method:     testMethod,
+		RemoteAddr: fmt.Sprintf("%s:%d", testHostname, testPort),
+		URL: &url.URL{
+			User: url.UserPassword(testUsername, ""),
+			Path: testPath,
+		},
+	}
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Bytes()
+}
+
+func (b *syncBuffer) String() string {
+	return string(b.Bytes())
+}
+
+// blockingWriter blocks the writes until released.
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
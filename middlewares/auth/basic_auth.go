@@ -50,6 +50,17 @@ func NewBasic(ctx context.Context, next http.Handler, authConfig config.BasicAut
 	}
 	ba.auth = goauth.NewBasicAuthenticator(realm, ba.secretBasic)
 
+	if len(authConfig.UnauthorizedBody) > 0 || len(authConfig.UnauthorizedContentType) > 0 {
+		headers := *goauth.NormalHeaders
+		if len(authConfig.UnauthorizedBody) > 0 {
+			headers.UnauthResponse = authConfig.UnauthorizedBody
+		}
+		if len(authConfig.UnauthorizedContentType) > 0 {
+			headers.UnauthContentType = authConfig.UnauthorizedContentType
+		}
+		ba.auth.Headers = &headers
+	}
+
 	return ba, nil
 }
 
@@ -44,6 +44,71 @@ func TestBasicAuthFail(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "they should be equal")
 }
 
+func TestBasicAuthUnauthorizedResponse(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "traefik")
+	})
+
+	testCases := []struct {
+		desc                string
+		auth                config.BasicAuth
+		expectedRealm       string
+		expectedBody        string
+		expectedContentType string
+	}{
+		{
+			desc:                "default",
+			auth:                config.BasicAuth{Users: []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"}},
+			expectedRealm:       `Basic realm="traefik"`,
+			expectedBody:        "401 Unauthorized\n",
+			expectedContentType: "text/plain",
+		},
+		{
+			desc: "custom realm and body",
+			auth: config.BasicAuth{
+				Users:                   []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+				Realm:                   "My Company",
+				UnauthorizedBody:        `{"error":"please sign in"}`,
+				UnauthorizedContentType: "application/json",
+			},
+			expectedRealm:       `Basic realm="My Company"`,
+			expectedBody:        `{"error":"please sign in"}`,
+			expectedContentType: "application/json",
+		},
+		{
+			desc: "custom body only",
+			auth: config.BasicAuth{
+				Users:            []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+				UnauthorizedBody: "Please sign in",
+			},
+			expectedRealm:       `Basic realm="traefik"`,
+			expectedBody:        "Please sign in",
+			expectedContentType: "text/plain",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			authMiddleware, err := NewBasic(context.Background(), next, test.auth, "authName")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+			req.SetBasicAuth("test", "wrong")
+
+			authMiddleware.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			assert.Equal(t, test.expectedRealm, recorder.Header().Get("WWW-Authenticate"))
+			assert.Equal(t, test.expectedContentType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
 func TestBasicAuthSuccess(t *testing.T) {
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "traefik")
@@ -30,6 +30,7 @@ type forwardAuth struct {
 	name                string
 	tlsConfig           *tls.Config
 	trustForwardHeader  bool
+	removeHeader        bool
 }
 
 // NewForward creates a forward auth middleware.
@@ -42,6 +43,7 @@ func NewForward(ctx context.Context, next http.Handler, config config.ForwardAut
 		next:                next,
 		name:                name,
 		trustForwardHeader:  config.TrustForwardHeader,
+		removeHeader:        config.RemoveHeader,
 	}
 
 	if config.TLS != nil {
@@ -146,6 +148,12 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// The Authorization header is still sent to the authentication server, and can be set again from its response.
+	if fa.removeHeader {
+		logger.Debug("Removing the Authorization header")
+		req.Header.Del(authorizationHeader)
+	}
+
 	for _, headerName := range fa.authResponseHeaders {
 		req.Header.Set(headerName, forwardResponse.Header.Get(headerName))
 	}
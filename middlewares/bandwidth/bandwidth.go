@@ -0,0 +1,264 @@
+package bandwidth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/vulcand/oxy/utils"
+	"golang.org/x/time/rate"
+)
+
+const (
+	typeName = "Bandwidth"
+)
+
+// limiters are the token buckets of the bytes read from the request bodies and written to the response bodies.
+// A nil limiter is unlimited.
+type limiters struct {
+	read  *rate.Limiter
+	write *rate.Limiter
+	// requests is the number of requests in progress sharing the limiters.
+	requests int
+}
+
+// bandwidth is a middleware limiting the rates of the request and response bodies,
+// for each request, or for each source of the requests.
+type bandwidth struct {
+	next      http.Handler
+	readRate  int64
+	writeRate int64
+	burst     int64
+	extractor utils.SourceExtractor
+	name      string
+
+	lock    sync.Mutex
+	sources map[string]*limiters
+}
+
+// New creates a bandwidth limiting middleware.
+func New(ctx context.Context, next http.Handler, config config.Bandwidth, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	if config.ReadRate < 0 || config.WriteRate < 0 || config.Burst < 0 {
+		return nil, errors.New("the rates and the burst cannot be negative")
+	}
+	if config.ReadRate == 0 && config.WriteRate == 0 {
+		return nil, errors.New("no rate to limit")
+	}
+
+	b := &bandwidth{
+		next:      next,
+		readRate:  config.ReadRate,
+		writeRate: config.WriteRate,
+		burst:     config.Burst,
+		name:      name,
+	}
+
+	if len(config.ExtractorFunc) > 0 {
+		strategy, err := config.IPStrategy.Get()
+		if err != nil {
+			return nil, fmt.Errorf("error creating bandwidth limit: %v", err)
+		}
+
+		b.extractor, err = middlewares.NewSourceExtractor(config.ExtractorFunc, strategy)
+		if err != nil {
+			return nil, fmt.Errorf("error creating bandwidth limit: %v", err)
+		}
+		b.sources = make(map[string]*limiters)
+	}
+
+	return b, nil
+}
+
+func (b *bandwidth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return b.name, tracing.SpanKindNoneEnum
+}
+
+func (b *bandwidth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	lim, release, err := b.acquire(req)
+	if err != nil {
+		middlewares.GetLogger(req.Context(), b.name, typeName).Errorf("Error extracting the source of the request: %v", err)
+		tracing.SetErrorWithEvent(req, "Error extracting the source of the request")
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer release()
+
+	if lim.read != nil && req.Body != nil && req.Body != http.NoBody {
+		req.Body = &limitedReader{body: req.Body, limiter: lim.read, ctx: req.Context()}
+	}
+
+	if lim.write != nil {
+		rw = newLimitedResponseWriter(req.Context(), rw, lim.write)
+	}
+
+	b.next.ServeHTTP(rw, req)
+}
+
+// acquire returns the limiters of the request, and the function to call once the request is served.
+// The requests of the same source share the limiters while they are in progress.
+func (b *bandwidth) acquire(req *http.Request) (*limiters, func(), error) {
+	if b.extractor == nil {
+		return b.newLimiters(), func() {}, nil
+	}
+
+	source, _, err := b.extractor.Extract(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	lim, ok := b.sources[source]
+	if !ok {
+		lim = b.newLimiters()
+		b.sources[source] = lim
+	}
+	lim.requests++
+
+	release := func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+
+		lim.requests--
+		if lim.requests == 0 {
+			delete(b.sources, source)
+		}
+	}
+
+	return lim, release, nil
+}
+
+func (b *bandwidth) newLimiters() *limiters {
+	return &limiters{
+		read:  newLimiter(b.readRate, b.burst),
+		write: newLimiter(b.writeRate, b.burst),
+	}
+}
+
+// newLimiter creates a token bucket of bytesPerSecond, allowing to transfer up to burst bytes at once,
+// one second of the rate by default.
+func newLimiter(bytesPerSecond, burst int64) *rate.Limiter {
+	if bytesPerSecond == 0 {
+		return nil
+	}
+	if burst == 0 {
+		burst = bytesPerSecond
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), int(burst))
+}
+
+// limitedReader waits for the tokens of the bytes read from the body.
+type limitedReader struct {
+	body    io.ReadCloser
+	limiter *rate.Limiter
+	ctx     context.Context
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.limiter.Burst() {
+		p = p[:r.limiter.Burst()]
+	}
+
+	n, err := r.body.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (r *limitedReader) Close() error {
+	return r.body.Close()
+}
+
+type limitedResponseWriter interface {
+	http.ResponseWriter
+	http.Hijacker
+	http.Flusher
+}
+
+// limitedResponseWriterWithoutCloseNotify waits for the tokens of the bytes before writing them.
+// The hijacked connections are not limited.
+type limitedResponseWriterWithoutCloseNotify struct {
+	rw      http.ResponseWriter
+	limiter *rate.Limiter
+	ctx     context.Context
+}
+
+func (l *limitedResponseWriterWithoutCloseNotify) Header() http.Header {
+	return l.rw.Header()
+}
+
+func (l *limitedResponseWriterWithoutCloseNotify) WriteHeader(code int) {
+	l.rw.WriteHeader(code)
+}
+
+// Write writes the bytes by chunks of at most the burst of the limiter.
+func (l *limitedResponseWriterWithoutCloseNotify) Write(buf []byte) (int, error) {
+	written := 0
+	for len(buf) > 0 {
+		chunk := buf
+		if len(chunk) > l.limiter.Burst() {
+			chunk = chunk[:l.limiter.Burst()]
+		}
+
+		if err := l.limiter.WaitN(l.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+
+		n, err := l.rw.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		buf = buf[n:]
+	}
+	return written, nil
+}
+
+// Hijack hijacks the connection.
+func (l *limitedResponseWriterWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := l.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", l.rw)
+	}
+	return hijacker.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (l *limitedResponseWriterWithoutCloseNotify) Flush() {
+	if flusher, ok := l.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type limitedResponseWriterWithCloseNotify struct {
+	*limitedResponseWriterWithoutCloseNotify
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (l *limitedResponseWriterWithCloseNotify) CloseNotify() <-chan bool {
+	return l.rw.(http.CloseNotifier).CloseNotify()
+}
+
+func newLimitedResponseWriter(ctx context.Context, rw http.ResponseWriter, limiter *rate.Limiter) limitedResponseWriter {
+	writer := &limitedResponseWriterWithoutCloseNotify{rw: rw, limiter: limiter, ctx: ctx}
+	if _, ok := rw.(http.CloseNotifier); ok {
+		return &limitedResponseWriterWithCloseNotify{writer}
+	}
+	return writer
+}
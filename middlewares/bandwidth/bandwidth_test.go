@@ -0,0 +1,154 @@
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.Bandwidth
+		expectedError bool
+	}{
+		{
+			desc:   "write rate",
+			config: config.Bandwidth{WriteRate: 1024},
+		},
+		{
+			desc:   "read rate per source",
+			config: config.Bandwidth{ReadRate: 1024, ExtractorFunc: "client.ip"},
+		},
+		{
+			desc:          "no rate",
+			config:        config.Bandwidth{Burst: 1024},
+			expectedError: true,
+		},
+		{
+			desc:          "negative rate",
+			config:        config.Bandwidth{ReadRate: -1, WriteRate: 1024},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid extractor",
+			config:        config.Bandwidth{WriteRate: 1024, ExtractorFunc: "foo"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := New(context.Background(), next, test.config, "bandwidth")
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBandwidth(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		config           config.Bandwidth
+		uploadSize       int
+		downloadSize     int
+		requests         int
+		expectedDuration time.Duration
+	}{
+		{
+			desc:             "download",
+			config:           config.Bandwidth{WriteRate: 100 * 1024, Burst: 10 * 1024},
+			downloadSize:     60 * 1024,
+			requests:         1,
+			expectedDuration: 500 * time.Millisecond,
+		},
+		{
+			desc:             "upload",
+			config:           config.Bandwidth{ReadRate: 100 * 1024, Burst: 10 * 1024},
+			uploadSize:       60 * 1024,
+			requests:         1,
+			expectedDuration: 500 * time.Millisecond,
+		},
+		{
+			desc:             "unlimited download",
+			config:           config.Bandwidth{ReadRate: 100 * 1024, Burst: 10 * 1024},
+			downloadSize:     60 * 1024,
+			requests:         1,
+			expectedDuration: 0,
+		},
+		{
+			desc:             "concurrent downloads",
+			config:           config.Bandwidth{WriteRate: 100 * 1024, Burst: 10 * 1024},
+			downloadSize:     30 * 1024,
+			requests:         2,
+			expectedDuration: 200 * time.Millisecond,
+		},
+		{
+			desc:             "concurrent downloads of the same source",
+			config:           config.Bandwidth{WriteRate: 100 * 1024, Burst: 10 * 1024, ExtractorFunc: "request.host"},
+			downloadSize:     30 * 1024,
+			requests:         2,
+			expectedDuration: 500 * time.Millisecond,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Len(t, body, test.uploadSize)
+
+				_, err = rw.Write(make([]byte, test.downloadSize))
+				require.NoError(t, err)
+			})
+
+			handler, err := New(context.Background(), next, test.config, "bandwidth")
+			require.NoError(t, err)
+
+			start := time.Now()
+
+			var wg sync.WaitGroup
+			for i := 0; i < test.requests; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					req := testhelpers.MustNewRequest(http.MethodPost, "http://foo.bar", bytes.NewReader(make([]byte, test.uploadSize)))
+					recorder := httptest.NewRecorder()
+					handler.ServeHTTP(recorder, req)
+
+					assert.Equal(t, http.StatusOK, recorder.Code)
+					assert.Equal(t, test.downloadSize, recorder.Body.Len())
+				}()
+			}
+			wg.Wait()
+
+			elapsed := time.Since(start)
+			assert.True(t, elapsed >= test.expectedDuration*8/10, "elapsed %s, expected about %s", elapsed, test.expectedDuration)
+			assert.True(t, elapsed <= test.expectedDuration+300*time.Millisecond, "elapsed %s, expected about %s", elapsed, test.expectedDuration)
+
+			if sources := handler.(*bandwidth).sources; sources != nil {
+				assert.Empty(t, sources)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "Cache"
+
+	defaultMaxObjectSize = 1 << 20
+	defaultMaxSize       = 64 << 20
+)
+
+// cacheableStatusCodes are the status codes of the responses cacheable by default (RFC 7231, section 6.1).
+var cacheableStatusCodes = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// cache is a middleware serving the responses of the GET and HEAD requests from an in-memory cache,
+// while they are fresh according to their Cache-Control or Expires header.
+type cache struct {
+	next          http.Handler
+	store         *store
+	maxObjectSize int64
+	name          string
+}
+
+// New creates a caching middleware.
+func New(ctx context.Context, next http.Handler, config config.Cache, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	maxObjectSize, err := sizeOrDefault(config.MaxObjectSize, defaultMaxObjectSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max object size: %v", err)
+	}
+
+	maxSize, err := sizeOrDefault(config.MaxSize, defaultMaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max size: %v", err)
+	}
+
+	if maxObjectSize > maxSize {
+		return nil, fmt.Errorf("the max object size %d is greater than the max size %d", maxObjectSize, maxSize)
+	}
+
+	return &cache{
+		next:          next,
+		store:         newStore(maxSize),
+		maxObjectSize: maxObjectSize,
+		name:          name,
+	}, nil
+}
+
+func sizeOrDefault(size int64, defaultSize int64) (int64, error) {
+	if size < 0 {
+		return 0, fmt.Errorf("negative size %d", size)
+	}
+	if size == 0 {
+		return defaultSize, nil
+	}
+	return size, nil
+}
+
+func (c *cache) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *cache) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !isCacheableRequest(req) {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	key := req.Method + " " + req.Host + req.URL.RequestURI()
+	now := time.Now()
+
+	// With no-cache, the client asks for a response validated by the backend, which can still be stored.
+	if _, noCache := parseCacheControl(req.Header)["no-cache"]; !noCache {
+		if e := c.store.get(key, req, now); e != nil {
+			middlewares.GetLogger(req.Context(), c.name, typeName).Debugf("Serving %s from the cache", key)
+			c.serve(rw, req, e, now)
+			return
+		}
+	}
+
+	recorder := newResponseRecorder(rw, c.maxObjectSize)
+	c.next.ServeHTTP(recorder, req)
+
+	if e := newEntry(key, req, recorder, now); e != nil {
+		c.store.set(e)
+	}
+}
+
+func isCacheableRequest(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	if req.Header.Get("Upgrade") != "" {
+		return false
+	}
+
+	_, noStore := parseCacheControl(req.Header)["no-store"]
+	return !noStore
+}
+
+// newEntry returns the entry to store for the recorded response, or nil if the response is not cacheable.
+func newEntry(key string, req *http.Request, recorder responseRecorder, now time.Time) *entry {
+	statusCode, header, body, ok := recorder.response()
+	if !ok || !cacheableStatusCodes[statusCode] {
+		return nil
+	}
+
+	directives := parseCacheControl(header)
+	for _, directive := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := directives[directive]; ok {
+			return nil
+		}
+	}
+
+	if len(header["Set-Cookie"]) > 0 {
+		return nil
+	}
+
+	// The responses to the authenticated requests are only shared when they explicitly allow it (RFC 7234, section 3.2).
+	if req.Header.Get("Authorization") != "" {
+		_, public := directives["public"]
+		_, sharedMaxAge := directives["s-maxage"]
+		if !public && !sharedMaxAge {
+			return nil
+		}
+	}
+
+	vary := make(map[string]string)
+	for _, value := range header["Vary"] {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil
+			}
+			if name != "" {
+				name = textproto.CanonicalMIMEHeaderKey(name)
+				vary[name] = req.Header.Get(name)
+			}
+		}
+	}
+
+	ttl := freshness(header, directives, now)
+	if ttl <= 0 {
+		return nil
+	}
+
+	size := int64(len(key) + len(body))
+	for name, values := range header {
+		for _, value := range values {
+			size += int64(len(name) + len(value))
+		}
+	}
+
+	return &entry{
+		key:        key,
+		vary:       vary,
+		statusCode: statusCode,
+		header:     header,
+		body:       body,
+		storedAt:   now,
+		expiresAt:  now.Add(ttl),
+		size:       size,
+	}
+}
+
+// freshness returns the lifetime of the response, from its s-maxage, max-age, or Expires header.
+// The responses without an explicit lifetime are not cached.
+func freshness(header http.Header, directives map[string]string, now time.Time) time.Duration {
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if value, ok := directives[directive]; ok {
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0
+			}
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		date = now
+	}
+
+	return expires.Sub(date)
+}
+
+// parseCacheControl returns the directives of the Cache-Control header, with their value if any.
+func parseCacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, value := range header["Cache-Control"] {
+		for _, directive := range strings.Split(value, ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "" {
+				continue
+			}
+
+			name, arg := directive, ""
+			if i := strings.Index(directive, "="); i >= 0 {
+				name, arg = directive[:i], strings.Trim(directive[i+1:], `"`)
+			}
+			directives[strings.ToLower(strings.TrimSpace(name))] = arg
+		}
+	}
+	return directives
+}
+
+func (c *cache) serve(rw http.ResponseWriter, req *http.Request, e *entry, now time.Time) {
+	for name, values := range e.header {
+		rw.Header()[name] = append([]string(nil), values...)
+	}
+	rw.Header().Set("Age", strconv.Itoa(int(now.Sub(e.storedAt).Seconds())))
+
+	rw.WriteHeader(e.statusCode)
+	if req.Method != http.MethodHead {
+		if _, err := rw.Write(e.body); err != nil {
+			middlewares.GetLogger(req.Context(), c.name, typeName).Debugf("Error while serving %s from the cache: %v", e.key, err)
+		}
+	}
+}
+
+type responseRecorder interface {
+	http.ResponseWriter
+	http.Flusher
+	response() (int, http.Header, []byte, bool)
+}
+
+// responseRecorderWithoutCloseNotify forwards the response to the client,
+// while recording it up to maxSize bytes of body.
+type responseRecorderWithoutCloseNotify struct {
+	rw          http.ResponseWriter
+	maxSize     int64
+	statusCode  int
+	header      http.Header
+	body        bytes.Buffer
+	wroteHeader bool
+	overflow    bool
+}
+
+func (r *responseRecorderWithoutCloseNotify) Header() http.Header {
+	return r.rw.Header()
+}
+
+func (r *responseRecorderWithoutCloseNotify) WriteHeader(code int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+
+	r.statusCode = code
+	r.header = make(http.Header)
+	for name, values := range r.rw.Header() {
+		r.header[name] = append([]string(nil), values...)
+	}
+
+	r.rw.WriteHeader(code)
+}
+
+func (r *responseRecorderWithoutCloseNotify) Write(buf []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if !r.overflow {
+		if int64(r.body.Len()+len(buf)) > r.maxSize {
+			r.overflow = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(buf)
+		}
+	}
+
+	return r.rw.Write(buf)
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseRecorderWithoutCloseNotify) Flush() {
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// response returns the recorded response, and false if it was not entirely recorded.
+func (r *responseRecorderWithoutCloseNotify) response() (int, http.Header, []byte, bool) {
+	if !r.wroteHeader || r.overflow {
+		return 0, nil, nil, false
+	}
+	return r.statusCode, r.header, r.body.Bytes(), true
+}
+
+type responseRecorderWithCloseNotify struct {
+	*responseRecorderWithoutCloseNotify
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (r *responseRecorderWithCloseNotify) CloseNotify() <-chan bool {
+	return r.rw.(http.CloseNotifier).CloseNotify()
+}
+
+func newResponseRecorder(rw http.ResponseWriter, maxSize int64) responseRecorder {
+	recorder := &responseRecorderWithoutCloseNotify{rw: rw, maxSize: maxSize}
+	if _, ok := rw.(http.CloseNotifier); ok {
+		return &responseRecorderWithCloseNotify{recorder}
+	}
+	return recorder
+}
@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCache(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.Cache
+		expectedError bool
+	}{
+		{
+			desc:   "defaults",
+			config: config.Cache{},
+		},
+		{
+			desc:   "sizes",
+			config: config.Cache{MaxObjectSize: 1024, MaxSize: 4096},
+		},
+		{
+			desc:          "negative size",
+			config:        config.Cache{MaxSize: -1},
+			expectedError: true,
+		},
+		{
+			desc:          "object size greater than the cache size",
+			config:        config.Cache{MaxObjectSize: 4096, MaxSize: 1024},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, handler)
+			}
+		})
+	}
+}
+
+func TestCache_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		method         string
+		requestHeaders []http.Header
+		statusCode     int
+		headers        map[string]string
+		body           string
+		expectedCalls  int
+	}{
+		{
+			desc:          "max-age",
+			headers:       map[string]string{"Cache-Control": "public, max-age=60"},
+			expectedCalls: 1,
+		},
+		{
+			desc:          "s-maxage",
+			headers:       map[string]string{"Cache-Control": "s-maxage=60"},
+			expectedCalls: 1,
+		},
+		{
+			desc:          "Expires",
+			headers:       map[string]string{"Expires": time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)},
+			expectedCalls: 1,
+		},
+		{
+			desc:          "expired",
+			headers:       map[string]string{"Expires": time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)},
+			expectedCalls: 2,
+		},
+		{
+			desc:          "no explicit freshness",
+			expectedCalls: 2,
+		},
+		{
+			desc:          "no-store",
+			headers:       map[string]string{"Cache-Control": "no-store, max-age=60"},
+			expectedCalls: 2,
+		},
+		{
+			desc:          "private",
+			headers:       map[string]string{"Cache-Control": "private, max-age=60"},
+			expectedCalls: 2,
+		},
+		{
+			desc:          "Set-Cookie",
+			headers:       map[string]string{"Cache-Control": "max-age=60", "Set-Cookie": "session=foo"},
+			expectedCalls: 2,
+		},
+		{
+			desc:          "non cacheable status code",
+			statusCode:    http.StatusInternalServerError,
+			headers:       map[string]string{"Cache-Control": "max-age=60"},
+			expectedCalls: 2,
+		},
+		{
+			desc:          "cacheable status code",
+			statusCode:    http.StatusNotFound,
+			headers:       map[string]string{"Cache-Control": "max-age=60"},
+			expectedCalls: 1,
+		},
+		{
+			desc:          "non cacheable method",
+			method:        http.MethodPost,
+			headers:       map[string]string{"Cache-Control": "max-age=60"},
+			expectedCalls: 2,
+		},
+		{
+			desc:          "object too large",
+			headers:       map[string]string{"Cache-Control": "max-age=60"},
+			body:          strings.Repeat("a", 2048),
+			expectedCalls: 2,
+		},
+		{
+			desc:           "request no-cache",
+			requestHeaders: []http.Header{{}, {"Cache-Control": {"no-cache"}}},
+			headers:        map[string]string{"Cache-Control": "max-age=60"},
+			expectedCalls:  2,
+		},
+		{
+			desc:           "request no-store",
+			requestHeaders: []http.Header{{"Cache-Control": {"no-store"}}, {}},
+			headers:        map[string]string{"Cache-Control": "max-age=60"},
+			expectedCalls:  2,
+		},
+		{
+			desc:           "same Vary header",
+			requestHeaders: []http.Header{{"Accept-Encoding": {"gzip"}}, {"Accept-Encoding": {"gzip"}}},
+			headers:        map[string]string{"Cache-Control": "max-age=60", "Vary": "Accept-Encoding"},
+			expectedCalls:  1,
+		},
+		{
+			desc:           "different Vary header",
+			requestHeaders: []http.Header{{"Accept-Encoding": {"gzip"}}, {"Accept-Encoding": {"br"}}},
+			headers:        map[string]string{"Cache-Control": "max-age=60", "Vary": "Accept-Encoding"},
+			expectedCalls:  2,
+		},
+		{
+			desc:          "Vary all",
+			headers:       map[string]string{"Cache-Control": "max-age=60", "Vary": "*"},
+			expectedCalls: 2,
+		},
+		{
+			desc:           "authenticated request",
+			requestHeaders: []http.Header{{"Authorization": {"Basic Zm9vOmJhcg=="}}, {"Authorization": {"Basic Zm9vOmJhcg=="}}},
+			headers:        map[string]string{"Cache-Control": "max-age=60"},
+			expectedCalls:  2,
+		},
+		{
+			desc:           "authenticated request with a public response",
+			requestHeaders: []http.Header{{"Authorization": {"Basic Zm9vOmJhcg=="}}, {"Authorization": {"Basic Zm9vOmJhcg=="}}},
+			headers:        map[string]string{"Cache-Control": "public, max-age=60"},
+			expectedCalls:  1,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				calls++
+				for name, value := range test.headers {
+					rw.Header().Set(name, value)
+				}
+				if test.statusCode != 0 {
+					rw.WriteHeader(test.statusCode)
+				}
+
+				body := test.body
+				if body == "" {
+					body = fmt.Sprintf("response %d", calls)
+				}
+				fmt.Fprint(rw, body)
+			})
+
+			handler, err := New(context.Background(), next, config.Cache{MaxObjectSize: 1024}, "traefikTest")
+			require.NoError(t, err)
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			var recorders []*httptest.ResponseRecorder
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(method, "http://localhost/foo?bar=baz", nil)
+				if len(test.requestHeaders) > i {
+					req.Header = test.requestHeaders[i]
+				}
+
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+				recorders = append(recorders, recorder)
+			}
+
+			assert.Equal(t, test.expectedCalls, calls)
+
+			if test.expectedCalls == 1 {
+				assert.Equal(t, recorders[0].Code, recorders[1].Code)
+				assert.Equal(t, recorders[0].Body.String(), recorders[1].Body.String())
+				assert.Equal(t, "0", recorders[1].Header().Get("Age"))
+				assert.Equal(t, test.headers["Cache-Control"], recorders[1].Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestCache_HEAD(t *testing.T) {
+	var calls int
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		rw.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(rw, "foo")
+	})
+
+	handler, err := New(context.Background(), next, config.Cache{}, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	assert.Equal(t, "foo", recorder.Body.String())
+
+	// The method is part of the key, so that a HEAD request is not answered with a cached body.
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, "http://localhost/foo", nil))
+	assert.Equal(t, 2, calls)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "foo", recorder.Body.String())
+}
+
+func TestStore_LRUEviction(t *testing.T) {
+	s := newStore(30)
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil)
+	now := time.Now()
+
+	for _, key := range []string{"first", "second", "third"} {
+		s.set(&entry{key: key, size: 10, expiresAt: now.Add(time.Minute)})
+	}
+
+	// first becomes the most recently used entry, so that second is evicted.
+	require.NotNil(t, s.get("first", req, now))
+	s.set(&entry{key: "fourth", size: 10, expiresAt: now.Add(time.Minute)})
+
+	assert.NotNil(t, s.get("first", req, now))
+	assert.Nil(t, s.get("second", req, now))
+	assert.NotNil(t, s.get("third", req, now))
+	assert.NotNil(t, s.get("fourth", req, now))
+	assert.Equal(t, int64(30), s.size)
+
+	// An expired entry is removed when it is looked up.
+	assert.Nil(t, s.get("first", req, now.Add(time.Hour)))
+	assert.Equal(t, int64(20), s.size)
+}
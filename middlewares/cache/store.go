@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// entry is a cached response.
+type entry struct {
+	key string
+	// vary are the values, in the request, of the headers listed in the Vary header of the response.
+	vary       map[string]string
+	statusCode int
+	header     http.Header
+	body       []byte
+	storedAt   time.Time
+	expiresAt  time.Time
+	size       int64
+}
+
+// matches tells whether the entry is a response to the request, according to its Vary header.
+func (e *entry) matches(req *http.Request) bool {
+	for name, value := range e.vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// store is an in-memory LRU store of the responses, bounded by the total size of the entries.
+// The entries are indexed by their key, the variants of a same key being told apart by their Vary header.
+type store struct {
+	maxSize int64
+	size    int64
+	entries map[string][]*list.Element
+	lru     *list.List
+
+	lock sync.Mutex
+}
+
+func newStore(maxSize int64) *store {
+	return &store{
+		maxSize: maxSize,
+		entries: make(map[string][]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// get returns the fresh entry matching the request, if any.
+func (s *store) get(key string, req *http.Request, now time.Time) *entry {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, element := range s.entries[key] {
+		e := element.Value.(*entry)
+		if !e.matches(req) {
+			continue
+		}
+
+		if !now.Before(e.expiresAt) {
+			s.remove(element)
+			return nil
+		}
+
+		s.lru.MoveToFront(element)
+		return e
+	}
+
+	return nil
+}
+
+// set stores the entry, replacing its previous variant, and evicts the least recently used entries that do not fit anymore.
+func (s *store) set(e *entry) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, element := range s.entries[e.key] {
+		if sameVariant(element.Value.(*entry).vary, e.vary) {
+			s.remove(element)
+			break
+		}
+	}
+
+	s.entries[e.key] = append(s.entries[e.key], s.lru.PushFront(e))
+	s.size += e.size
+
+	for s.size > s.maxSize {
+		s.remove(s.lru.Back())
+	}
+}
+
+func (s *store) remove(element *list.Element) {
+	e := element.Value.(*entry)
+
+	variants := s.entries[e.key]
+	for i, variant := range variants {
+		if variant == element {
+			variants = append(variants[:i], variants[i+1:]...)
+			break
+		}
+	}
+
+	if len(variants) == 0 {
+		delete(s.entries, e.key)
+	} else {
+		s.entries[e.key] = variants
+	}
+
+	s.lru.Remove(element)
+	s.size -= e.size
+}
+
+func sameVariant(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if other, ok := b[name]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
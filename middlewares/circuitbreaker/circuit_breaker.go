@@ -2,6 +2,8 @@ package circuitbreaker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/containous/traefik/config"
@@ -21,15 +23,24 @@ type circuitBreaker struct {
 	name           string
 }
 
+type serviceBuilder interface {
+	Build(ctx context.Context, serviceName string, responseModifier func(*http.Response) error) (http.Handler, error)
+}
+
 // New creates a new circuit breaker middleware.
-func New(ctx context.Context, next http.Handler, confCircuitBreaker config.CircuitBreaker, name string) (http.Handler, error) {
+func New(ctx context.Context, next http.Handler, confCircuitBreaker config.CircuitBreaker, serviceBuilder serviceBuilder, name string) (http.Handler, error) {
 	expression := confCircuitBreaker.Expression
 
 	logger := middlewares.GetLogger(ctx, name, typeName)
 	logger.Debug("Creating middleware")
 	logger.Debug("Setting up with expression: %s", expression)
 
-	oxyCircuitBreaker, err := cbreaker.New(next, expression, createCircuitBreakerOptions(expression))
+	fallback, err := createFallback(ctx, expression, confCircuitBreaker.Fallback, serviceBuilder)
+	if err != nil {
+		return nil, err
+	}
+
+	oxyCircuitBreaker, err := cbreaker.New(next, expression, cbreaker.Fallback(fallback))
 	if err != nil {
 		return nil, err
 	}
@@ -39,16 +50,54 @@ func New(ctx context.Context, next http.Handler, confCircuitBreaker config.Circu
 	}, nil
 }
 
-// NewCircuitBreakerOptions returns a new CircuitBreakerOption
-func createCircuitBreakerOptions(expression string) cbreaker.CircuitBreakerOption {
-	return cbreaker.Fallback(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+// createFallback returns the handler of the requests while the circuit breaker is open,
+// which serves the fallback service or response, or a 503 by default.
+func createFallback(ctx context.Context, expression string, conf *config.CircuitBreakerFallback, serviceBuilder serviceBuilder) (http.Handler, error) {
+	if conf == nil {
+		conf = &config.CircuitBreakerFallback{}
+	}
+
+	if len(conf.Service) > 0 {
+		if conf.StatusCode != 0 || len(conf.Body) > 0 || len(conf.ContentType) > 0 {
+			return nil, errors.New("the fallback cannot have both a service and a response")
+		}
+
+		service, err := serviceBuilder.Build(ctx, conf.Service, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			tracing.SetErrorWithEvent(req, "blocked by circuit-breaker (%q)", expression)
+			service.ServeHTTP(rw, req)
+		}), nil
+	}
+
+	statusCode := http.StatusServiceUnavailable
+	if conf.StatusCode != 0 {
+		if conf.StatusCode < http.StatusOK || conf.StatusCode > 599 {
+			return nil, fmt.Errorf("invalid fallback status code %d: it must be between 200 and 599", conf.StatusCode)
+		}
+		statusCode = conf.StatusCode
+	}
+
+	body := conf.Body
+	if len(body) == 0 {
+		body = http.StatusText(statusCode)
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		tracing.SetErrorWithEvent(req, "blocked by circuit-breaker (%q)", expression)
-		rw.WriteHeader(http.StatusServiceUnavailable)
 
-		if _, err := rw.Write([]byte(http.StatusText(http.StatusServiceUnavailable))); err != nil {
+		if len(conf.ContentType) > 0 {
+			rw.Header().Set("Content-Type", conf.ContentType)
+		}
+		rw.WriteHeader(statusCode)
+
+		if _, err := rw.Write([]byte(body)); err != nil {
 			log.FromContext(req.Context()).Error(err)
 		}
-	}))
+	}), nil
 }
 
 func (c *circuitBreaker) GetTracingInformation() (string, ext.SpanKindEnum) {
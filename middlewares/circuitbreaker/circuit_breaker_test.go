@@ -0,0 +1,115 @@
+package circuitbreaker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker_Fallback(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		fallback            *config.CircuitBreakerFallback
+		expectedStatus      int
+		expectedBody        string
+		expectedContentType string
+		expectedError       bool
+	}{
+		{
+			desc:           "default fallback",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "Service Unavailable",
+		},
+		{
+			desc: "static response",
+			fallback: &config.CircuitBreakerFallback{
+				StatusCode:  http.StatusOK,
+				Body:        `{"items":[]}`,
+				ContentType: "application/json",
+			},
+			expectedStatus:      http.StatusOK,
+			expectedBody:        `{"items":[]}`,
+			expectedContentType: "application/json",
+		},
+		{
+			desc:           "status code only",
+			fallback:       &config.CircuitBreakerFallback{StatusCode: http.StatusTooManyRequests},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   "Too Many Requests",
+		},
+		{
+			desc:           "fallback service",
+			fallback:       &config.CircuitBreakerFallback{Service: "fallback"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "from the fallback service",
+		},
+		{
+			desc:          "service and response",
+			fallback:      &config.CircuitBreakerFallback{Service: "fallback", StatusCode: http.StatusOK},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid status code",
+			fallback:      &config.CircuitBreakerFallback{StatusCode: 600},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusInternalServerError)
+			})
+
+			serviceBuilder := &mockServiceBuilder{handler: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte("from the fallback service"))
+			})}
+
+			conf := config.CircuitBreaker{
+				Expression: "ResponseCodeRatio(500, 600, 0, 600) > 0.5",
+				Fallback:   test.fallback,
+			}
+
+			handler, err := New(context.Background(), next, conf, serviceBuilder, "circuitBreaker")
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// The breaker trips at one of the periodic checks of the expression.
+			var recorder *httptest.ResponseRecorder
+			for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				recorder = httptest.NewRecorder()
+				handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil))
+				if recorder.Code != http.StatusInternalServerError {
+					break
+				}
+			}
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+			if len(test.expectedContentType) > 0 {
+				assert.Equal(t, test.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+type mockServiceBuilder struct {
+	handler http.Handler
+}
+
+func (m *mockServiceBuilder) Build(_ context.Context, serviceName string, responseModifier func(*http.Response) error) (http.Handler, error) {
+	return m.handler, nil
+}
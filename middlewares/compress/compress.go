@@ -3,10 +3,12 @@ package compress
 import (
 	"compress/gzip"
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/NYTimes/gziphandler"
+	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/middlewares"
 	"github.com/containous/traefik/tracing"
 	"github.com/opentracing/opentracing-go/ext"
@@ -19,17 +21,23 @@ const (
 
 // Compress is a middleware that allows to compress the response.
 type compress struct {
-	next http.Handler
-	name string
+	next  http.Handler
+	name  string
+	level int
 }
 
 // New creates a new compress middleware.
-func New(ctx context.Context, next http.Handler, name string) (http.Handler, error) {
+func New(ctx context.Context, next http.Handler, conf config.Compress, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
 
+	if conf.Level != 0 && (conf.Level < gzip.BestSpeed || conf.Level > gzip.BestCompression) {
+		return nil, fmt.Errorf("invalid compression level %d: it must be between %d and %d", conf.Level, gzip.BestSpeed, gzip.BestCompression)
+	}
+
 	return &compress{
-		next: next,
-		name: name,
+		next:  next,
+		name:  name,
+		level: conf.Level,
 	}, nil
 }
 
@@ -38,7 +46,7 @@ func (c *compress) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	if strings.HasPrefix(contentType, "application/grpc") {
 		c.next.ServeHTTP(rw, req)
 	} else {
-		gzipHandler(c.next, middlewares.GetLogger(req.Context(), c.name, typeName)).ServeHTTP(rw, req)
+		gzipHandler(c.next, c.level, middlewares.GetLogger(req.Context(), c.name, typeName)).ServeHTTP(rw, req)
 	}
 }
 
@@ -46,9 +54,13 @@ func (c *compress) GetTracingInformation() (string, ext.SpanKindEnum) {
 	return c.name, tracing.SpanKindNoneEnum
 }
 
-func gzipHandler(h http.Handler, logger logrus.FieldLogger) http.Handler {
+func gzipHandler(h http.Handler, level int, logger logrus.FieldLogger) http.Handler {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
 	wrapper, err := gziphandler.GzipHandlerWithOpts(
-		gziphandler.CompressionLevel(gzip.DefaultCompression),
+		gziphandler.CompressionLevel(level),
 		gziphandler.MinSize(gziphandler.DefaultMinSize))
 	if err != nil {
 		logger.Error(err)
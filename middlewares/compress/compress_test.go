@@ -1,12 +1,16 @@
 package compress
 
 import (
+	"compress/gzip"
+	"context"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/NYTimes/gziphandler"
+	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/testhelpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -251,6 +255,75 @@ func TestIntegrationShouldCompress(t *testing.T) {
 	}
 }
 
+func TestNewCompressionLevel(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		level       int
+		expectedErr bool
+	}{
+		{desc: "default level", level: 0},
+		{desc: "best speed", level: gzip.BestSpeed},
+		{desc: "best compression", level: gzip.BestCompression},
+		{desc: "too low", level: -1, expectedErr: true},
+		{desc: "too high", level: 10, expectedErr: true},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				_, err := rw.Write(generateBytes(gziphandler.DefaultMinSize))
+				assert.NoError(t, err)
+			})
+
+			handler, err := New(context.Background(), next, config.Compress{Level: test.level}, "compress")
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Add(acceptEncodingHeader, gzipValue)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, gzipValue, rw.Header().Get(contentEncodingHeader))
+		})
+	}
+}
+
+func BenchmarkCompressionLevel(b *testing.B) {
+	body := generateBytes(100000)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, err := rw.Write(body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	for _, level := range []int{gzip.BestSpeed, 6, gzip.BestCompression} {
+		b.Run(fmt.Sprintf("level %d", level), func(b *testing.B) {
+			handler := &compress{next: next, level: level}
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Add(acceptEncodingHeader, gzipValue)
+
+			b.SetBytes(int64(len(body)))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				handler.ServeHTTP(httptest.NewRecorder(), req)
+			}
+		})
+	}
+}
+
 func generateBytes(len int) []byte {
 	var value []byte
 	for i := 0; i < len; i++ {
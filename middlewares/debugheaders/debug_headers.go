@@ -0,0 +1,37 @@
+package debugheaders
+
+import (
+	"context"
+	"net/http"
+)
+
+// Headers describing how a request was routed.
+const (
+	RouterHeader  = "X-Traefik-Router"
+	ServiceHeader = "X-Traefik-Service"
+	BackendHeader = "X-Traefik-Backend"
+)
+
+type contextKey int
+
+const enabledKey contextKey = iota
+
+// NewRouterHandler enables the debug headers for the requests of the router, and sets the router header on their responses.
+func NewRouterHandler(next http.Handler, routerName string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set(RouterHeader, routerName)
+		next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), enabledKey, true)))
+	})
+}
+
+// NewBackendHandler sets the service header, and the backend header from the URL of the server selected by the load-balancer,
+// on the responses of the requests with the debug headers enabled.
+func NewBackendHandler(next http.Handler, serviceName string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if enabled, _ := req.Context().Value(enabledKey).(bool); enabled {
+			rw.Header().Set(ServiceHeader, serviceName)
+			rw.Header().Set(BackendHeader, req.URL.Scheme+"://"+req.URL.Host)
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
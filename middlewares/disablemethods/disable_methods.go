@@ -0,0 +1,99 @@
+package disablemethods
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "DisableMethods"
+)
+
+// standardMethods are the methods listed in the Allow header of the responses, unless they are disabled.
+var standardMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// disableMethods is a middleware that rejects the requests using one of the disabled HTTP methods.
+type disableMethods struct {
+	next           http.Handler
+	methods        map[string]bool
+	allowPreflight bool
+	allow          string
+	name           string
+}
+
+// New creates a middleware rejecting the disabled HTTP methods with a 405.
+func New(ctx context.Context, next http.Handler, config config.DisableMethods, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug("Creating middleware")
+
+	if len(config.Methods) == 0 {
+		return nil, errors.New("no method to disable")
+	}
+
+	methods := make(map[string]bool)
+	for _, method := range config.Methods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" {
+			return nil, errors.New("empty method to disable")
+		}
+		methods[method] = true
+	}
+
+	logger.Debugf("Disabled methods: %s", config.Methods)
+
+	var allowed []string
+	for _, method := range standardMethods {
+		if !methods[method] {
+			allowed = append(allowed, method)
+		}
+	}
+
+	return &disableMethods{
+		next:           next,
+		methods:        methods,
+		allowPreflight: config.AllowPreflight,
+		allow:          strings.Join(allowed, ", "),
+		name:           name,
+	}, nil
+}
+
+func (d *disableMethods) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return d.name, tracing.SpanKindNoneEnum
+}
+
+func (d *disableMethods) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !d.methods[req.Method] || d.allowPreflight && isPreflight(req) {
+		d.next.ServeHTTP(rw, req)
+		return
+	}
+
+	middlewares.GetLogger(req.Context(), d.name, typeName).Debugf("Method %s disabled, rejecting request %s", req.Method, req.URL)
+
+	// A 405 response must list the allowed methods (RFC 7231 §6.5.5).
+	rw.Header().Set("Allow", d.allow)
+	http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// isPreflight tells whether the request is a CORS preflight request.
+func isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions &&
+		req.Header.Get("Origin") != "" &&
+		req.Header.Get("Access-Control-Request-Method") != ""
+}
@@ -0,0 +1,143 @@
+package disablemethods
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDisableMethods(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.DisableMethods
+		expectedError bool
+	}{
+		{
+			desc:   "methods",
+			config: config.DisableMethods{Methods: []string{"TRACE", "connect"}},
+		},
+		{
+			desc:          "no method",
+			config:        config.DisableMethods{},
+			expectedError: true,
+		},
+		{
+			desc:          "empty method",
+			config:        config.DisableMethods{Methods: []string{"TRACE", " "}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, handler)
+			}
+		})
+	}
+}
+
+func TestDisableMethods_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         config.DisableMethods
+		method         string
+		headers        map[string]string
+		expectedStatus int
+		expectedAllow  string
+	}{
+		{
+			desc:           "TRACE disabled",
+			config:         config.DisableMethods{Methods: []string{"TRACE", "CONNECT"}},
+			method:         http.MethodTrace,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedAllow:  "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS",
+		},
+		{
+			desc:           "lowercase configuration",
+			config:         config.DisableMethods{Methods: []string{"trace"}},
+			method:         http.MethodTrace,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedAllow:  "GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, OPTIONS",
+		},
+		{
+			desc:           "GET allowed",
+			config:         config.DisableMethods{Methods: []string{"TRACE", "CONNECT"}},
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "OPTIONS allowed when not disabled",
+			config:         config.DisableMethods{Methods: []string{"TRACE"}},
+			method:         http.MethodOptions,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "OPTIONS disabled",
+			config:         config.DisableMethods{Methods: []string{"OPTIONS"}, AllowPreflight: true},
+			method:         http.MethodOptions,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedAllow:  "GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, TRACE",
+		},
+		{
+			desc:   "preflight allowed",
+			config: config.DisableMethods{Methods: []string{"OPTIONS"}, AllowPreflight: true},
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "http://foo.bar",
+				"Access-Control-Request-Method": "PUT",
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:   "preflight disabled",
+			config: config.DisableMethods{Methods: []string{"OPTIONS"}},
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "http://foo.bar",
+				"Access-Control-Request-Method": "PUT",
+			},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedAllow:  "GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, TRACE",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(test.method, "http://localhost/foo", nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedAllow, recorder.Header().Get("Allow"))
+		})
+	}
+}
@@ -10,7 +10,8 @@ import (
 // has at least one active Server in respect to the healthchecks and if this
 // is not the case, it will stop the middleware chain and respond with 503.
 type emptyBackend struct {
-	next healthcheck.BalancerHandler
+	next        healthcheck.BalancerHandler
+	unavailable http.Handler
 }
 
 // New creates a new EmptyBackend middleware.
@@ -18,10 +19,21 @@ func New(lb healthcheck.BalancerHandler) http.Handler {
 	return &emptyBackend{next: lb}
 }
 
+// NewWithUnavailableHandler creates a new EmptyBackend middleware,
+// which delegates the response to the unavailable handler when there is no active Server.
+func NewWithUnavailableHandler(lb healthcheck.BalancerHandler, unavailable http.Handler) http.Handler {
+	return &emptyBackend{next: lb, unavailable: unavailable}
+}
+
 // ServeHTTP responds with 503 when there is no active Server and otherwise
 // invokes the next handler in the middleware chain.
 func (e *emptyBackend) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	if len(e.next.Servers()) == 0 {
+		if e.unavailable != nil {
+			e.unavailable.ServeHTTP(rw, req)
+			return
+		}
+
 		rw.WriteHeader(http.StatusServiceUnavailable)
 		_, err := rw.Write([]byte(http.StatusText(http.StatusServiceUnavailable)))
 		if err != nil {
@@ -0,0 +1,221 @@
+package externalprocessor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "ExternalProcessor"
+
+	defaultTimeout = time.Second
+
+	// maxResponseSize bounds the size of the responses of the external processor.
+	maxResponseSize = 1 << 20
+)
+
+// Decisions of the external processor.
+const (
+	// DecisionContinue forwards the request, after applying the rewrites of the response.
+	DecisionContinue = "continue"
+	// DecisionDeny responds to the client with the status code and body of the response.
+	DecisionDeny = "deny"
+)
+
+// ProcessingRequest is the JSON body POSTed to the external processor for each request.
+// The body of the request is not sent.
+type ProcessingRequest struct {
+	Method     string      `json:"method"`
+	Host       string      `json:"host"`
+	Path       string      `json:"path"`
+	RawQuery   string      `json:"rawQuery,omitempty"`
+	RemoteAddr string      `json:"remoteAddr"`
+	Headers    http.Header `json:"headers"`
+}
+
+// ProcessingResponse is the JSON body returned by the external processor, with a 200 status code.
+type ProcessingResponse struct {
+	Decision string `json:"decision"`
+
+	// StatusCode and Body are the response to the client of a denied request. StatusCode defaults to 403.
+	StatusCode int    `json:"statusCode,omitempty"`
+	Body       string `json:"body,omitempty"`
+
+	// SetHeaders, RemoveHeaders and Path are the rewrites of a forwarded request.
+	SetHeaders    map[string]string `json:"setHeaders,omitempty"`
+	RemoveHeaders []string          `json:"removeHeaders,omitempty"`
+	Path          string            `json:"path,omitempty"`
+}
+
+type externalProcessor struct {
+	address  string
+	client   *http.Client
+	failOpen bool
+	next     http.Handler
+	name     string
+}
+
+// New creates a middleware delegating the decision about each request to an external processor.
+func New(ctx context.Context, next http.Handler, config config.ExternalProcessor, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	if config.Address == "" {
+		return nil, errors.New("empty external processor address")
+	}
+
+	timeout := defaultTimeout
+	if config.Timeout != "" {
+		var err error
+		timeout, err = time.ParseDuration(config.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %v", err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %s: it must be positive", config.Timeout)
+		}
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(r *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.CreateTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+
+	return &externalProcessor{
+		address:  config.Address,
+		client:   client,
+		failOpen: config.FailOpen,
+		next:     next,
+		name:     name,
+	}, nil
+}
+
+func (e *externalProcessor) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return e.name, ext.SpanKindRPCClientEnum
+}
+
+func (e *externalProcessor) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), e.name, typeName)
+
+	response, err := e.process(req)
+	if err != nil {
+		logger.Debugf("Error calling %s. Cause: %s", e.address, err)
+		tracing.SetErrorWithEvent(req, "Error calling %s. Cause: %s", e.address, err)
+
+		if e.failOpen {
+			e.next.ServeHTTP(rw, req)
+			return
+		}
+
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	if response.Decision == DecisionDeny {
+		statusCode := response.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusForbidden
+		}
+
+		logger.Debugf("Request %s denied by %s with status code %d", req.URL, e.address, statusCode)
+		tracing.LogResponseCode(tracing.GetSpan(req), statusCode)
+
+		rw.WriteHeader(statusCode)
+		if _, err := io.WriteString(rw, response.Body); err != nil {
+			logger.Error(err)
+		}
+		return
+	}
+
+	for name, value := range response.SetHeaders {
+		req.Header.Set(name, value)
+	}
+
+	for _, name := range response.RemoveHeaders {
+		req.Header.Del(name)
+	}
+
+	if response.Path != "" {
+		req.URL.Path = response.Path
+		req.URL.RawPath = ""
+		req.RequestURI = req.URL.RequestURI()
+	}
+
+	e.next.ServeHTTP(rw, req)
+}
+
+// process sends the request to the external processor, and returns its valid response.
+func (e *externalProcessor) process(req *http.Request) (*ProcessingResponse, error) {
+	body, err := json.Marshal(ProcessingRequest{
+		Method:     req.Method,
+		Host:       req.Host,
+		Path:       req.URL.Path,
+		RawQuery:   req.URL.RawQuery,
+		RemoteAddr: req.RemoteAddr,
+		Headers:    req.Header,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	processorReq, err := http.NewRequest(http.MethodPost, e.address, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	processorReq = processorReq.WithContext(req.Context())
+	processorReq.Header.Set("Content-Type", "application/json")
+
+	tracing.InjectRequestHeaders(processorReq)
+
+	processorResp, err := e.client.Do(processorReq)
+	if err != nil {
+		return nil, err
+	}
+	defer processorResp.Body.Close()
+
+	if processorResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", processorResp.StatusCode)
+	}
+
+	respBody, err := ioutil.ReadAll(io.LimitReader(processorResp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ProcessingResponse{}
+	if err := json.Unmarshal(respBody, response); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+
+	if response.Decision != DecisionContinue && response.Decision != DecisionDeny {
+		return nil, fmt.Errorf("unknown decision %q", response.Decision)
+	}
+
+	if response.Path != "" && response.Path[0] != '/' {
+		return nil, fmt.Errorf("invalid path %q: it must start with /", response.Path)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,217 @@
+package externalprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExternalProcessor(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.ExternalProcessor
+		expectedError bool
+	}{
+		{
+			desc:   "address",
+			config: config.ExternalProcessor{Address: "http://localhost:9000", Timeout: "500ms"},
+		},
+		{
+			desc:          "no address",
+			config:        config.ExternalProcessor{},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid timeout",
+			config:        config.ExternalProcessor{Address: "http://localhost:9000", Timeout: "foo"},
+			expectedError: true,
+		},
+		{
+			desc:          "negative timeout",
+			config:        config.ExternalProcessor{Address: "http://localhost:9000", Timeout: "-1s"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, handler)
+			}
+		})
+	}
+}
+
+func TestExternalProcessor_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		response       string
+		statusCode     int
+		failOpen       bool
+		expectedStatus int
+		expectedBody   string
+		expectedPath   string
+		expectedHeader http.Header
+	}{
+		{
+			desc:           "continue",
+			response:       `{"decision":"continue"}`,
+			expectedStatus: http.StatusOK,
+			expectedPath:   "/foo",
+			expectedHeader: http.Header{"X-Remove": {"bar"}},
+		},
+		{
+			desc:           "continue with rewrites",
+			response:       `{"decision":"continue","setHeaders":{"X-Tenant":"acme"},"removeHeaders":["X-Remove"],"path":"/acme/foo"}`,
+			expectedStatus: http.StatusOK,
+			expectedPath:   "/acme/foo",
+			expectedHeader: http.Header{"X-Tenant": {"acme"}},
+		},
+		{
+			desc:           "deny",
+			response:       `{"decision":"deny","statusCode":401,"body":"unauthorized"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "unauthorized",
+		},
+		{
+			desc:           "deny with the default status code",
+			response:       `{"decision":"deny"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "unknown decision fails closed",
+			response:       `{"decision":"foo"}`,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "Service Unavailable\n",
+		},
+		{
+			desc:           "invalid path fails closed",
+			response:       `{"decision":"continue","path":"foo"}`,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "Service Unavailable\n",
+		},
+		{
+			desc:           "processor error fails closed",
+			statusCode:     http.StatusInternalServerError,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "Service Unavailable\n",
+		},
+		{
+			desc:           "processor error fails open",
+			statusCode:     http.StatusInternalServerError,
+			failOpen:       true,
+			expectedStatus: http.StatusOK,
+			expectedPath:   "/foo",
+			expectedHeader: http.Header{"X-Remove": {"bar"}},
+		},
+		{
+			desc:           "invalid response fails open",
+			response:       `decision`,
+			failOpen:       true,
+			expectedStatus: http.StatusOK,
+			expectedPath:   "/foo",
+			expectedHeader: http.Header{"X-Remove": {"bar"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			processor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				var processingReq ProcessingRequest
+				if err := json.NewDecoder(req.Body).Decode(&processingReq); err != nil {
+					http.Error(rw, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				if processingReq.Method != http.MethodGet || processingReq.Host != "foo.bar" ||
+					processingReq.Path != "/foo" || processingReq.RawQuery != "a=b" ||
+					processingReq.Headers.Get("X-Remove") != "bar" {
+					http.Error(rw, fmt.Sprintf("unexpected request %+v", processingReq), http.StatusBadRequest)
+					return
+				}
+
+				if test.statusCode != 0 {
+					rw.WriteHeader(test.statusCode)
+					return
+				}
+				fmt.Fprint(rw, test.response)
+			}))
+			defer processor.Close()
+
+			var forwardedReq *http.Request
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwardedReq = req
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			handler, err := New(context.Background(), next, config.ExternalProcessor{Address: processor.URL, FailOpen: test.failOpen}, "traefikTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo.bar/foo?a=b", nil)
+			req.Header.Set("X-Remove", "bar")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+
+			if test.expectedPath == "" {
+				assert.Nil(t, forwardedReq)
+				return
+			}
+
+			require.NotNil(t, forwardedReq)
+			assert.Equal(t, test.expectedPath, forwardedReq.URL.Path)
+			if test.expectedPath != "/foo" {
+				assert.Equal(t, test.expectedPath+"?a=b", forwardedReq.RequestURI)
+			}
+			assert.Equal(t, test.expectedHeader, forwardedReq.Header)
+		})
+	}
+}
+
+func TestExternalProcessor_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	processor := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer processor.Close()
+	defer close(release)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	handler, err := New(context.Background(), next, config.ExternalProcessor{Address: processor.URL, Timeout: "50ms"}, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://foo.bar/foo", nil)
+
+	start := time.Now()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.True(t, time.Since(start) < time.Second, "the call to the external processor was not bounded by the timeout")
+}
@@ -1,6 +1,8 @@
 package forwardedheaders
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/containous/traefik/ip"
@@ -8,16 +10,40 @@ import (
 	"github.com/vulcand/oxy/utils"
 )
 
+// Modes of the X-Forwarded-For header sent to the servers.
+const (
+	// XForwardedForAppend appends the client IP to the X-Forwarded-For header sent by a trusted client.
+	XForwardedForAppend = "append"
+	// XForwardedForReplace replaces the X-Forwarded-For header with the client IP.
+	XForwardedForReplace = "replace"
+	// XForwardedForRemove removes the X-Forwarded-For header.
+	XForwardedForRemove = "remove"
+)
+
+type removeXForwardedForKeyType int
+
+const removeXForwardedForKey removeXForwardedForKeyType = iota
+
 // XForwarded filter for XForwarded headers.
 type XForwarded struct {
-	insecure   bool
-	trustedIps []string
-	ipChecker  *ip.Checker
-	next       http.Handler
+	insecure          bool
+	trustedIps        []string
+	ipChecker         *ip.Checker
+	xForwardedForMode string
+	next              http.Handler
 }
 
 // NewXForwarded creates a new XForwarded.
-func NewXForwarded(insecure bool, trustedIps []string, next http.Handler) (*XForwarded, error) {
+// The X-Forwarded-For mode defaults to append.
+func NewXForwarded(insecure bool, trustedIps []string, xForwardedForMode string, next http.Handler) (*XForwarded, error) {
+	switch xForwardedForMode {
+	case "":
+		xForwardedForMode = XForwardedForAppend
+	case XForwardedForAppend, XForwardedForReplace, XForwardedForRemove:
+	default:
+		return nil, fmt.Errorf("unknown X-Forwarded-For mode %q", xForwardedForMode)
+	}
+
 	var ipChecker *ip.Checker
 	if len(trustedIps) > 0 {
 		var err error
@@ -28,10 +54,11 @@ func NewXForwarded(insecure bool, trustedIps []string, next http.Handler) (*XFor
 	}
 
 	return &XForwarded{
-		insecure:   insecure,
-		trustedIps: trustedIps,
-		ipChecker:  ipChecker,
-		next:       next,
+		insecure:          insecure,
+		trustedIps:        trustedIps,
+		ipChecker:         ipChecker,
+		xForwardedForMode: xForwardedForMode,
+		next:              next,
 	}, nil
 }
 
@@ -47,5 +74,21 @@ func (x *XForwarded) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		utils.RemoveHeaders(r.Header, forward.XHeaders...)
 	}
 
+	switch x.xForwardedForMode {
+	case XForwardedForReplace:
+		// The forwarder sets the header to the client IP.
+		r.Header.Del(forward.XForwardedFor)
+	case XForwardedForRemove:
+		// The forwarder sets the header anyway, it is removed from the forwarded request afterwards.
+		r.Header.Del(forward.XForwardedFor)
+		r = r.WithContext(context.WithValue(r.Context(), removeXForwardedForKey, true))
+	}
+
 	x.next.ServeHTTP(w, r)
 }
+
+// RemoveXForwardedFor tells whether the X-Forwarded-For header must be removed from the request forwarded to the servers.
+func RemoveXForwardedFor(ctx context.Context) bool {
+	remove, _ := ctx.Value(removeXForwardedForKey).(bool)
+	return remove
+}
@@ -2,10 +2,13 @@ package forwardedheaders
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/containous/traefik/testhelpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/forward"
 )
 
 func TestServeHTTP(t *testing.T) {
@@ -115,7 +118,7 @@ func TestServeHTTP(t *testing.T) {
 				req.Header.Set(k, v)
 			}
 
-			m, err := NewXForwarded(test.insecure, test.trustedIps, http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
+			m, err := NewXForwarded(test.insecure, test.trustedIps, "", http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
 			require.NoError(t, err)
 
 			m.ServeHTTP(nil, req)
@@ -126,3 +129,122 @@ func TestServeHTTP(t *testing.T) {
 		})
 	}
 }
+
+func TestXForwardedForModes(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		mode                  string
+		remoteAddr            string
+		expectedXForwardedFor []string
+	}{
+		{
+			desc:                  "append from a trusted client",
+			mode:                  XForwardedForAppend,
+			remoteAddr:            "10.0.1.100:80",
+			expectedXForwardedFor: []string{"10.0.1.0, 10.0.1.100"},
+		},
+		{
+			desc:                  "append from an untrusted client",
+			mode:                  XForwardedForAppend,
+			remoteAddr:            "10.0.1.101:80",
+			expectedXForwardedFor: []string{"10.0.1.101"},
+		},
+		{
+			desc:                  "default mode",
+			remoteAddr:            "10.0.1.100:80",
+			expectedXForwardedFor: []string{"10.0.1.0, 10.0.1.100"},
+		},
+		{
+			desc:                  "replace from a trusted client",
+			mode:                  XForwardedForReplace,
+			remoteAddr:            "10.0.1.100:80",
+			expectedXForwardedFor: []string{"10.0.1.100"},
+		},
+		{
+			desc:                  "replace from an untrusted client",
+			mode:                  XForwardedForReplace,
+			remoteAddr:            "10.0.1.101:80",
+			expectedXForwardedFor: []string{"10.0.1.101"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var xForwardedFor []string
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				xForwardedFor = req.Header[forward.XForwardedFor]
+			}))
+			defer server.Close()
+
+			forwarder, err := forward.New()
+			require.NoError(t, err)
+
+			serverURL := testhelpers.MustParseURL(server.URL)
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				req.URL = serverURL
+				forwarder.ServeHTTP(rw, req)
+			})
+
+			m, err := NewXForwarded(false, []string{"10.0.1.100"}, test.mode, next)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo/bar", nil)
+			req.RemoteAddr = test.remoteAddr
+			req.Header.Set(forward.XForwardedFor, "10.0.1.0")
+
+			recorder := httptest.NewRecorder()
+			m.ServeHTTP(recorder, req)
+
+			require.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, test.expectedXForwardedFor, xForwardedFor)
+		})
+	}
+}
+
+func TestXForwardedForRemove(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		remoteAddr string
+	}{
+		{
+			desc:       "trusted client",
+			remoteAddr: "10.0.1.100:80",
+		},
+		{
+			desc:       "untrusted client",
+			remoteAddr: "10.0.1.101:80",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var header http.Header
+			var remove bool
+			m, err := NewXForwarded(false, []string{"10.0.1.100"}, XForwardedForRemove, http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				header = req.Header
+				remove = RemoveXForwardedFor(req.Context())
+			}))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo/bar", nil)
+			req.RemoteAddr = test.remoteAddr
+			req.Header.Set(forward.XForwardedFor, "10.0.1.0")
+
+			m.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.NotContains(t, header, forward.XForwardedFor)
+			assert.True(t, remove)
+		})
+	}
+}
+
+func TestNewXForwarded_UnknownMode(t *testing.T) {
+	_, err := NewXForwarded(false, nil, "foo", http.NotFoundHandler())
+	assert.Error(t, err)
+}
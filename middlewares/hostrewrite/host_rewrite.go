@@ -0,0 +1,77 @@
+package hostrewrite
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "HostRewrite"
+)
+
+type key struct{}
+
+// hostRewrite is a middleware that overrides the Host header sent to the server.
+// The rewrite is applied by the forwarder, once the server has been selected.
+type hostRewrite struct {
+	next          http.Handler
+	host          string
+	useServerHost bool
+	name          string
+}
+
+// New creates a host rewrite middleware.
+func New(ctx context.Context, next http.Handler, config config.HostRewrite, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	if len(config.Host) > 0 && config.UseServerHost {
+		return nil, errors.New("host and useServerHost cannot be both set")
+	}
+
+	if len(config.Host) == 0 && !config.UseServerHost {
+		return nil, errors.New("host cannot be empty")
+	}
+
+	return &hostRewrite{
+		next:          next,
+		host:          config.Host,
+		useServerHost: config.UseServerHost,
+		name:          name,
+	}, nil
+}
+
+func (h *hostRewrite) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return h.name, tracing.SpanKindNoneEnum
+}
+
+func (h *hostRewrite) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	h.next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), key{}, h)))
+}
+
+// Rewrite overrides the Host header of the request sent to the server,
+// according to the host rewrite middleware of the request, if any.
+func Rewrite(outReq *http.Request) {
+	h, ok := outReq.Context().Value(key{}).(*hostRewrite)
+	if !ok {
+		return
+	}
+
+	if h.useServerHost {
+		outReq.Host = outReq.URL.Host
+	} else {
+		outReq.Host = h.host
+	}
+
+	// The websocket forwarder sends the Host header field.
+	if len(outReq.Header.Get("Host")) > 0 {
+		outReq.Header.Set("Host", outReq.Host)
+	}
+
+	middlewares.GetLogger(outReq.Context(), h.name, typeName).Debugf("Host header is now %s", outReq.Host)
+}
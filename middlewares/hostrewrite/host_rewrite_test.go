@@ -0,0 +1,107 @@
+package hostrewrite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.HostRewrite
+		expectedError bool
+	}{
+		{
+			desc:   "fixed host",
+			config: config.HostRewrite{Host: "foo.bar"},
+		},
+		{
+			desc:   "server host",
+			config: config.HostRewrite{UseServerHost: true},
+		},
+		{
+			desc:          "empty",
+			config:        config.HostRewrite{},
+			expectedError: true,
+		},
+		{
+			desc:          "fixed host and server host",
+			config:        config.HostRewrite{Host: "foo.bar", UseServerHost: true},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, handler)
+			}
+		})
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		config       *config.HostRewrite
+		expectedHost string
+	}{
+		{
+			desc:         "without middleware",
+			expectedHost: "client.host",
+		},
+		{
+			desc:         "fixed host",
+			config:       &config.HostRewrite{Host: "foo.bar"},
+			expectedHost: "foo.bar",
+		},
+		{
+			desc:         "server host",
+			config:       &config.HostRewrite{UseServerHost: true},
+			expectedHost: "10.0.0.1:8080",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var outReq *http.Request
+			var handler http.Handler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				// Simulate the load-balancer and the forwarder.
+				outReq = req.WithContext(req.Context())
+				outReq.URL = testhelpers.MustParseURL("http://10.0.0.1:8080")
+				Rewrite(outReq)
+			})
+
+			if test.config != nil {
+				var err error
+				handler, err = New(context.Background(), handler, *test.config, "traefikTest")
+				require.NoError(t, err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "http://client.host", nil)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.NotNil(t, outReq)
+			assert.Equal(t, test.expectedHost, outReq.Host)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/ip"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "Maintenance"
+)
+
+// maintenance is a middleware that answers with a static maintenance response,
+// except for the requests coming from the allowed source range.
+type maintenance struct {
+	next        http.Handler
+	enabled     bool
+	statusCode  int
+	body        []byte
+	contentType string
+	retryAfter  string
+	allowed     *ip.Checker
+	strategy    ip.Strategy
+	name        string
+}
+
+// New creates a maintenance middleware.
+func New(ctx context.Context, next http.Handler, config config.Maintenance, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug("Creating middleware")
+
+	statusCode := config.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	if statusCode < 100 || statusCode > 999 {
+		return nil, fmt.Errorf("invalid status code: %d", statusCode)
+	}
+
+	body := []byte(config.Body)
+	if len(body) == 0 {
+		body = []byte(http.StatusText(statusCode))
+	}
+
+	var checker *ip.Checker
+	if len(config.SourceRange) > 0 {
+		var err error
+		checker, err = ip.NewChecker(config.SourceRange)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse CIDR source range %s: %v", config.SourceRange, err)
+		}
+	}
+
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Enabled {
+		logger.Debugf("Maintenance mode enabled, allowed source range: %s", config.SourceRange)
+	}
+
+	return &maintenance{
+		next:        next,
+		enabled:     config.Enabled,
+		statusCode:  statusCode,
+		body:        body,
+		contentType: config.ContentType,
+		retryAfter:  config.RetryAfter,
+		allowed:     checker,
+		strategy:    strategy,
+		name:        name,
+	}, nil
+}
+
+func (m *maintenance) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return m.name, tracing.SpanKindNoneEnum
+}
+
+func (m *maintenance) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !m.enabled {
+		m.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if m.allowed != nil {
+		clientIP := m.strategy.GetIP(req)
+		if err := m.allowed.IsAuthorized(clientIP); err == nil {
+			m.next.ServeHTTP(rw, req)
+			return
+		}
+	}
+
+	logger := middlewares.GetLogger(req.Context(), m.name, typeName)
+	logger.Debugf("Maintenance mode, rejecting request %s", req.URL)
+
+	if len(m.contentType) > 0 {
+		rw.Header().Set("Content-Type", m.contentType)
+	} else {
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+
+	if len(m.retryAfter) > 0 {
+		rw.Header().Set("Retry-After", m.retryAfter)
+	}
+
+	rw.WriteHeader(m.statusCode)
+	if _, err := rw.Write(m.body); err != nil {
+		logger.Error(err)
+	}
+}
@@ -0,0 +1,142 @@
+package maintenance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaintenance(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.Maintenance
+		expectedError bool
+	}{
+		{
+			desc:   "default values",
+			config: config.Maintenance{},
+		},
+		{
+			desc: "invalid source range",
+			config: config.Maintenance{
+				SourceRange: []string{"foo"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "invalid status code",
+			config: config.Maintenance{
+				StatusCode: 42,
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, handler)
+			}
+		})
+	}
+}
+
+func TestMaintenance_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		config             config.Maintenance
+		remoteAddr         string
+		expectedStatusCode int
+		expectedBody       string
+		expectedRetryAfter string
+	}{
+		{
+			desc:               "disabled",
+			config:             config.Maintenance{},
+			remoteAddr:         "10.10.10.10:1234",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "backend",
+		},
+		{
+			desc: "enabled with defaults",
+			config: config.Maintenance{
+				Enabled: true,
+			},
+			remoteAddr:         "10.10.10.10:1234",
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       http.StatusText(http.StatusServiceUnavailable),
+		},
+		{
+			desc: "enabled with custom response",
+			config: config.Maintenance{
+				Enabled:    true,
+				StatusCode: http.StatusTeapot,
+				Body:       "We'll be back soon",
+				RetryAfter: "120",
+			},
+			remoteAddr:         "10.10.10.10:1234",
+			expectedStatusCode: http.StatusTeapot,
+			expectedBody:       "We'll be back soon",
+			expectedRetryAfter: "120",
+		},
+		{
+			desc: "enabled with allowed remote address",
+			config: config.Maintenance{
+				Enabled:     true,
+				SourceRange: []string{"10.10.10.0/24"},
+			},
+			remoteAddr:         "10.10.10.10:1234",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "backend",
+		},
+		{
+			desc: "enabled with non allowed remote address",
+			config: config.Maintenance{
+				Enabled:     true,
+				SourceRange: []string{"10.10.10.0/24"},
+				RetryAfter:  "60",
+			},
+			remoteAddr:         "20.20.20.20:1234",
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       http.StatusText(http.StatusServiceUnavailable),
+			expectedRetryAfter: "60",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte("backend"))
+			})
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.RemoteAddr = test.remoteAddr
+
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+			assert.Equal(t, test.expectedRetryAfter, recorder.Header().Get("Retry-After"))
+		})
+	}
+}
@@ -26,12 +26,26 @@ type maxConnection struct {
 func New(ctx context.Context, next http.Handler, maxConns config.MaxConn, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
 
-	extractFunc, err := utils.NewExtractor(maxConns.ExtractorFunc)
+	strategy, err := maxConns.IPStrategy.Get()
 	if err != nil {
 		return nil, fmt.Errorf("error creating connection limit: %v", err)
 	}
 
-	handler, err := connlimit.New(next, extractFunc, maxConns.Amount)
+	extractFunc, err := middlewares.NewSourceExtractor(maxConns.ExtractorFunc, strategy)
+	if err != nil {
+		return nil, fmt.Errorf("error creating connection limit: %v", err)
+	}
+
+	var options []connlimit.ConnLimitOption
+	if maxConns.RejectionStatusCode != 0 {
+		errHandler, err := middlewares.NewRejectionErrorHandler(utils.ErrorHandlerFunc(connErrHandler), maxConns.RejectionStatusCode, maxConns.RejectionBody)
+		if err != nil {
+			return nil, fmt.Errorf("error creating connection limit: %v", err)
+		}
+		options = append(options, connlimit.ErrorHandler(errHandler))
+	}
+
+	handler, err := connlimit.New(next, extractFunc, maxConns.Amount, options...)
 	if err != nil {
 		return nil, fmt.Errorf("error creating connection limit: %v", err)
 	}
@@ -39,6 +53,16 @@ func New(ctx context.Context, next http.Handler, maxConns config.MaxConn, name s
 	return &maxConnection{handler: handler, name: name}, nil
 }
 
+// connErrHandler responds like the default error handler of connlimit, which cannot be wrapped.
+func connErrHandler(rw http.ResponseWriter, req *http.Request, err error) {
+	if _, ok := err.(*connlimit.MaxConnError); ok {
+		rw.WriteHeader(http.StatusTooManyRequests)
+		_, _ = rw.Write([]byte(err.Error()))
+		return
+	}
+	utils.DefaultHandler.ServeHTTP(rw, req, err)
+}
+
 func (mc *maxConnection) GetTracingInformation() (string, ext.SpanKindEnum) {
 	return mc.name, tracing.SpanKindNoneEnum
 }
@@ -0,0 +1,89 @@
+package maxconnection
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxConnection_Rejection(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		statusCode     int
+		body           string
+		expectedStatus int
+		expectedBody   string
+		expectedError  bool
+	}{
+		{
+			desc:           "default status code",
+			expectedStatus: http.StatusTooManyRequests,
+			expectedBody:   "max connections reached: 1",
+		},
+		{
+			desc:           "custom status code",
+			statusCode:     http.StatusServiceUnavailable,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "max connections reached: 1",
+		},
+		{
+			desc:           "custom status code and body",
+			statusCode:     http.StatusServiceUnavailable,
+			body:           "too busy",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "too busy",
+		},
+		{
+			desc:          "invalid status code",
+			statusCode:    600,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			inFlight := make(chan struct{})
+			release := make(chan struct{})
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				close(inFlight)
+				<-release
+			})
+
+			handler, err := New(context.Background(), next, config.MaxConn{
+				Amount:              1,
+				ExtractorFunc:       "request.host",
+				RejectionStatusCode: test.statusCode,
+				RejectionBody:       test.body,
+			}, "traefikTest")
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+			}()
+			<-inFlight
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+			close(release)
+			<-done
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
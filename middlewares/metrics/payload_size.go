@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/containous/traefik/metrics"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+)
+
+// NewPayloadSizeHandler observes the sizes of the request and response bodies of the router,
+// counted while they are read and written through the handler.
+// The bytes of the hijacked connections are not counted.
+func NewPayloadSizeHandler(next http.Handler, registry metrics.Registry, routerName, serviceName string) http.Handler {
+	return &payloadSize{
+		next:      next,
+		reqSizes:  registry.RouterReqSizeHistogram().With("router", routerName, "service", serviceName),
+		respSizes: registry.RouterRespSizeHistogram().With("router", routerName, "service", serviceName),
+	}
+}
+
+type payloadSize struct {
+	next      http.Handler
+	reqSizes  gokitmetrics.Histogram
+	respSizes gokitmetrics.Histogram
+}
+
+func (p *payloadSize) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var body *countingReader
+	if req.Body != nil && req.Body != http.NoBody {
+		body = &countingReader{body: req.Body}
+		req.Body = body
+	}
+
+	writer := newCountingResponseWriter(rw)
+	p.next.ServeHTTP(writer, req)
+
+	var read int64
+	if body != nil {
+		read = atomic.LoadInt64(&body.count)
+	}
+	p.reqSizes.Observe(float64(read))
+	p.respSizes.Observe(float64(writer.size()))
+}
+
+// countingReader counts the bytes read from the body.
+type countingReader struct {
+	body  io.ReadCloser
+	count int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	atomic.AddInt64(&r.count, int64(n))
+	return n, err
+}
+
+func (r *countingReader) Close() error {
+	return r.body.Close()
+}
+
+type countingResponseWriter interface {
+	http.ResponseWriter
+	http.Hijacker
+	http.Flusher
+	size() int64
+}
+
+// countingResponseWriterWithoutCloseNotify counts the bytes written to the body.
+type countingResponseWriterWithoutCloseNotify struct {
+	rw    http.ResponseWriter
+	count int64
+}
+
+func (c *countingResponseWriterWithoutCloseNotify) Header() http.Header {
+	return c.rw.Header()
+}
+
+func (c *countingResponseWriterWithoutCloseNotify) WriteHeader(code int) {
+	c.rw.WriteHeader(code)
+}
+
+func (c *countingResponseWriterWithoutCloseNotify) Write(buf []byte) (int, error) {
+	n, err := c.rw.Write(buf)
+	atomic.AddInt64(&c.count, int64(n))
+	return n, err
+}
+
+// Hijack hijacks the connection.
+func (c *countingResponseWriterWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := c.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", c.rw)
+	}
+	return hijacker.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (c *countingResponseWriterWithoutCloseNotify) Flush() {
+	if flusher, ok := c.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (c *countingResponseWriterWithoutCloseNotify) size() int64 {
+	return atomic.LoadInt64(&c.count)
+}
+
+type countingResponseWriterWithCloseNotify struct {
+	*countingResponseWriterWithoutCloseNotify
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (c *countingResponseWriterWithCloseNotify) CloseNotify() <-chan bool {
+	return c.rw.(http.CloseNotifier).CloseNotify()
+}
+
+func newCountingResponseWriter(rw http.ResponseWriter) countingResponseWriter {
+	writer := &countingResponseWriterWithoutCloseNotify{rw: rw}
+	if _, ok := rw.(http.CloseNotifier); ok {
+		return &countingResponseWriterWithCloseNotify{writer}
+	}
+	return writer
+}
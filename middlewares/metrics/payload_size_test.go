@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/containous/traefik/metrics"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadSizeHandler(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		method           string
+		requestSize      int
+		responseSize     int
+		expectedRequest  float64
+		expectedResponse float64
+	}{
+		{
+			desc:             "request and response bodies",
+			method:           http.MethodPost,
+			requestSize:      1234,
+			responseSize:     5678,
+			expectedRequest:  1234,
+			expectedResponse: 5678,
+		},
+		{
+			desc:             "no request body",
+			method:           http.MethodGet,
+			responseSize:     42,
+			expectedResponse: 42,
+		},
+		{
+			desc:   "no body",
+			method: http.MethodGet,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Len(t, body, test.requestSize)
+
+				// Written by chunks, as a streamed response would be.
+				response := make([]byte, test.responseSize)
+				for len(response) > 0 {
+					n := len(response)
+					if n > 1000 {
+						n = 1000
+					}
+					_, err = rw.Write(response[:n])
+					require.NoError(t, err)
+					rw.(http.Flusher).Flush()
+					response = response[n:]
+				}
+			})
+
+			registry := &sizeRegistry{
+				Registry:  metrics.NewVoidRegistry(),
+				reqSizes:  &sizeHistogram{lock: &sync.Mutex{}, values: make(map[string][]float64)},
+				respSizes: &sizeHistogram{lock: &sync.Mutex{}, values: make(map[string][]float64)},
+			}
+
+			handler := NewPayloadSizeHandler(next, registry, "foo", "bar")
+
+			req := httptest.NewRequest(test.method, "http://foo.bar", bytes.NewReader(make([]byte, test.requestSize)))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.responseSize, recorder.Body.Len())
+			assert.Equal(t, []float64{test.expectedRequest}, registry.reqSizes.get("foo", "bar"))
+			assert.Equal(t, []float64{test.expectedResponse}, registry.respSizes.get("foo", "bar"))
+		})
+	}
+}
+
+type sizeRegistry struct {
+	metrics.Registry
+	reqSizes  *sizeHistogram
+	respSizes *sizeHistogram
+}
+
+func (r *sizeRegistry) RouterReqSizeHistogram() gokitmetrics.Histogram {
+	return r.reqSizes
+}
+
+func (r *sizeRegistry) RouterRespSizeHistogram() gokitmetrics.Histogram {
+	return r.respSizes
+}
+
+// sizeHistogram records the values observed for each set of label values.
+type sizeHistogram struct {
+	lock   *sync.Mutex
+	values map[string][]float64
+	labels []string
+}
+
+func (h *sizeHistogram) With(labelValues ...string) gokitmetrics.Histogram {
+	return &sizeHistogram{lock: h.lock, values: h.values, labels: append(h.labels, labelValues...)}
+}
+
+func (h *sizeHistogram) Observe(value float64) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	key := strings.Join(h.labels, ",")
+	h.values[key] = append(h.values[key], value)
+}
+
+func (h *sizeHistogram) get(routerName, serviceName string) []float64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.values[strings.Join([]string{"router", routerName, "service", serviceName}, ",")]
+}
@@ -0,0 +1,85 @@
+package pathnormalization
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/containous/alice"
+)
+
+const originalKey key = "original"
+
+type key string
+
+// original holds the path of a request as received, before its normalization.
+type original struct {
+	path       string
+	rawPath    string
+	requestURI string
+}
+
+// New creates a middleware normalizing the request paths before the rules of the routers are evaluated:
+// the duplicate slashes are collapsed, and the dot segments are resolved.
+// Unless forward is true, the original paths are put back by Restore once the routers are matched,
+// so that the middlewares and the servers receive the paths as sent by the clients.
+func New(next http.Handler, forward bool) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		normalized := Normalize(req.URL.Path)
+		if normalized == req.URL.Path {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		if !forward {
+			req = req.WithContext(context.WithValue(req.Context(), originalKey, original{
+				path:       req.URL.Path,
+				rawPath:    req.URL.RawPath,
+				requestURI: req.RequestURI,
+			}))
+		}
+
+		req.URL.Path = normalized
+		if req.URL.RawPath != "" {
+			req.URL.RawPath = Normalize(req.URL.RawPath)
+		}
+		req.RequestURI = req.URL.RequestURI()
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// WrapHandler wraps the path normalization into an alice.Constructor.
+func WrapHandler(forward bool) alice.Constructor {
+	return func(next http.Handler) (http.Handler, error) {
+		return New(next, forward), nil
+	}
+}
+
+// Restore puts back the original path of the requests normalized without forward.
+func Restore(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if orig, ok := req.Context().Value(originalKey).(original); ok {
+			req.URL.Path = orig.path
+			req.URL.RawPath = orig.rawPath
+			req.RequestURI = orig.requestURI
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// Normalize returns the path without duplicate slashes and dot segments, keeping its trailing slash.
+// The dot segments cannot go above the root.
+func Normalize(p string) string {
+	if p == "" {
+		return p
+	}
+
+	normalized := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && normalized != "/" {
+		normalized += "/"
+	}
+	return normalized
+}
@@ -0,0 +1,101 @@
+package pathnormalization
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/", expected: "/"},
+		{path: "/a/b", expected: "/a/b"},
+		{path: "/a//b", expected: "/a/b"},
+		{path: "//a///b/", expected: "/a/b/"},
+		{path: "/a/../admin", expected: "/admin"},
+		{path: "/a/./b/.", expected: "/a/b"},
+		{path: "/a/b/../", expected: "/a/"},
+		{path: "/../../admin", expected: "/admin"},
+		{path: "a/b", expected: "/a/b"},
+		{path: "", expected: ""},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.path, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, Normalize(test.path))
+		})
+	}
+}
+
+func TestPathNormalization(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		url                 string
+		forward             bool
+		expectedMatchedPath string
+		expectedPath        string
+		expectedRequestURI  string
+	}{
+		{
+			desc:                "dot segments",
+			url:                 "/a/../admin?foo=bar",
+			expectedMatchedPath: "/admin",
+			expectedPath:        "/a/../admin",
+			expectedRequestURI:  "/a/../admin?foo=bar",
+		},
+		{
+			desc:                "duplicate slashes",
+			url:                 "/a//b",
+			expectedMatchedPath: "/a/b",
+			expectedPath:        "/a//b",
+			expectedRequestURI:  "/a//b",
+		},
+		{
+			desc:                "dot segments forwarded",
+			url:                 "/a/../admin?foo=bar",
+			forward:             true,
+			expectedMatchedPath: "/admin",
+			expectedPath:        "/admin",
+			expectedRequestURI:  "/admin?foo=bar",
+		},
+		{
+			desc:                "normalized path",
+			url:                 "/admin/",
+			expectedMatchedPath: "/admin/",
+			expectedPath:        "/admin/",
+			expectedRequestURI:  "/admin/",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var matchedPath, path, requestURI string
+			next := Restore(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				path = req.URL.Path
+				requestURI = req.RequestURI
+			}))
+
+			handler := New(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				matchedPath = req.URL.Path
+				next.ServeHTTP(rw, req)
+			}), test.forward)
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, test.url, nil))
+
+			assert.Equal(t, test.expectedMatchedPath, matchedPath)
+			assert.Equal(t, test.expectedPath, path)
+			assert.Equal(t, test.expectedRequestURI, requestURI)
+		})
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/containous/traefik/tracing"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/vulcand/oxy/ratelimit"
-	"github.com/vulcand/oxy/utils"
 )
 
 const (
@@ -26,7 +25,12 @@ type rateLimiter struct {
 func New(ctx context.Context, next http.Handler, config config.RateLimit, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
 
-	extractFunc, err := utils.NewExtractor(config.ExtractorFunc)
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	extractFunc, err := middlewares.NewSourceExtractor(config.ExtractorFunc, strategy)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +42,16 @@ func New(ctx context.Context, next http.Handler, config config.RateLimit, name s
 		}
 	}
 
-	rl, err := ratelimit.New(next, extractFunc, rateSet)
+	var options []ratelimit.TokenLimiterOption
+	if config.RejectionStatusCode != 0 {
+		errHandler, err := middlewares.NewRejectionErrorHandler(&ratelimit.RateErrHandler{}, config.RejectionStatusCode, config.RejectionBody)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, ratelimit.ErrorHandler(errHandler))
+	}
+
+	rl, err := ratelimit.New(next, extractFunc, rateSet, options...)
 	if err != nil {
 		return nil, err
 	}
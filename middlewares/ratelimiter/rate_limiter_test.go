@@ -0,0 +1,82 @@
+package ratelimiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Rejection(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		statusCode     int
+		body           string
+		expectedStatus int
+		expectedBody   string
+		expectedError  bool
+	}{
+		{
+			desc:           "default status code",
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			desc:           "custom status code",
+			statusCode:     http.StatusServiceUnavailable,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			desc:           "custom status code and body",
+			statusCode:     http.StatusServiceUnavailable,
+			body:           "slow down",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "slow down",
+		},
+		{
+			desc:          "invalid status code",
+			statusCode:    http.StatusOK,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			handler, err := New(context.Background(), next, config.RateLimit{
+				RateSet: map[string]*config.Rate{
+					"rate": {Period: parse.Duration(time.Hour), Average: 1, Burst: 1},
+				},
+				ExtractorFunc:       "request.host",
+				RejectionStatusCode: test.statusCode,
+				RejectionBody:       test.body,
+			}, "traefikTest")
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+
+			recorder = httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -3,8 +3,10 @@ package recovery
 import (
 	"context"
 	"net/http"
+	"runtime/debug"
 
 	"github.com/containous/traefik/middlewares"
+	"github.com/go-kit/kit/metrics"
 	"github.com/sirupsen/logrus"
 )
 
@@ -13,28 +15,43 @@ const (
 )
 
 type recovery struct {
-	next http.Handler
-	name string
+	next   http.Handler
+	panics metrics.Counter
+	name   string
 }
 
 // New creates recovery middleware.
-func New(ctx context.Context, next http.Handler, name string) (http.Handler, error) {
+// It answers with a 500 to the requests whose handling panicked, and counts them in panics, if not nil.
+func New(ctx context.Context, next http.Handler, panics metrics.Counter, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
 
 	return &recovery{
-		next: next,
-		name: name,
+		next:   next,
+		panics: panics,
+		name:   name,
 	}, nil
 }
 
 func (re *recovery) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	defer recoverFunc(middlewares.GetLogger(req.Context(), re.name, typeName), rw)
+	defer re.recoverFunc(middlewares.GetLogger(req.Context(), re.name, typeName), rw)
 	re.next.ServeHTTP(rw, req)
 }
 
-func recoverFunc(logger logrus.FieldLogger, rw http.ResponseWriter) {
-	if err := recover(); err != nil {
-		logger.Errorf("Recovered from panic in http handler: %+v", err)
-		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+func (re *recovery) recoverFunc(logger logrus.FieldLogger, rw http.ResponseWriter) {
+	err := recover()
+	if err == nil {
+		return
 	}
+
+	// The handlers abort the response on purpose with http.ErrAbortHandler, which the server handles silently.
+	if err == http.ErrAbortHandler {
+		panic(err)
+	}
+
+	if re.panics != nil {
+		re.panics.Add(1)
+	}
+
+	logger.Errorf("Recovered from panic in http handler: %+v\n%s", err, debug.Stack())
+	http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 }
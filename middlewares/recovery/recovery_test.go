@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/go-kit/kit/metrics/generic"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -14,7 +15,7 @@ func TestRecoverHandler(t *testing.T) {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		panic("I love panicing!")
 	}
-	recovery, err := New(context.Background(), http.HandlerFunc(fn), "foo-recovery")
+	recovery, err := New(context.Background(), http.HandlerFunc(fn), nil, "foo-recovery")
 	require.NoError(t, err)
 
 	server := httptest.NewServer(recovery)
@@ -25,3 +26,38 @@ func TestRecoverHandler(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 }
+
+func TestRecoverHandler_ServerStaysUp(t *testing.T) {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/panic":
+			panic("I love panicing!")
+		case "/abort":
+			panic(http.ErrAbortHandler)
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	panics := generic.NewCounter("panics")
+	recovery, err := New(context.Background(), http.HandlerFunc(fn), panics, "foo-recovery")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(recovery)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/panic")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/ok")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The aborted responses are not recovered, they close the connection.
+	_, err = http.Get(server.URL + "/abort")
+	assert.Error(t, err)
+
+	assert.Equal(t, 1.0, panics.Value())
+}
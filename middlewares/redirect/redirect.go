@@ -3,12 +3,11 @@ package redirect
 import (
 	"bytes"
 	"context"
-	"html/template"
-	"io"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
+	"text/template"
 
 	"github.com/containous/traefik/tracing"
 	"github.com/opentracing/opentracing-go/ext"
@@ -18,7 +17,7 @@ import (
 type redirect struct {
 	next        http.Handler
 	regex       *regexp.Regexp
-	replacement string
+	replacement *template.Template
 	permanent   bool
 	errHandler  utils.ErrorHandler
 	name        string
@@ -31,9 +30,14 @@ func newRedirect(ctx context.Context, next http.Handler, regex string, replaceme
 		return nil, err
 	}
 
+	tmpl, err := template.New("replacement").Parse(replacement)
+	if err != nil {
+		return nil, err
+	}
+
 	return &redirect{
 		regex:       re,
-		replacement: replacement,
+		replacement: tmpl,
 		permanent:   permanent,
 		errHandler:  utils.DefaultHandler,
 		next:        next,
@@ -54,18 +58,20 @@ func (r *redirect) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// apply a rewrite regexp to the URL
-	newURL := r.regex.ReplaceAllString(oldURL, r.replacement)
-
-	// replace any variables that may be in there
-	rewrittenURL := &bytes.Buffer{}
-	if err := applyString(newURL, rewrittenURL, req); err != nil {
+	// replace any variables that may be in the replacement,
+	// before the rewrite so that the URL of the request is not interpreted as a template.
+	replacement := &bytes.Buffer{}
+	data := struct{ Request *http.Request }{Request: req}
+	if err := r.replacement.Execute(replacement, data); err != nil {
 		r.errHandler.ServeHTTP(rw, req, err)
 		return
 	}
 
+	// apply a rewrite regexp to the URL
+	newURL := r.regex.ReplaceAllString(oldURL, replacement.String())
+
 	// parse the rewritten URL and replace request URL with it
-	parsedURL, err := url.Parse(rewrittenURL.String())
+	parsedURL, err := url.Parse(newURL)
 	if err != nil {
 		r.errHandler.ServeHTTP(rw, req, err)
 		return
@@ -145,14 +151,3 @@ func isXForwardedHTTPS(request *http.Request) bool {
 
 	return len(xForwardedProto) > 0 && xForwardedProto == "https"
 }
-
-func applyString(in string, out io.Writer, req *http.Request) error {
-	t, err := template.New("t").Parse(in)
-	if err != nil {
-		return err
-	}
-
-	data := struct{ Request *http.Request }{Request: req}
-
-	return t.Execute(out, data)
-}
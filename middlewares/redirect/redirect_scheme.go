@@ -3,6 +3,7 @@ package redirect
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"github.com/containous/traefik/middlewares"
 	"github.com/pkg/errors"
@@ -12,7 +13,7 @@ import (
 
 const (
 	typeSchemeName      = "RedirectScheme"
-	schemeRedirectRegex = `^(https?:\/\/)?([\w\._-]+)(:\d+)?(.*)$`
+	schemeRedirectRegex = `^(https?:\/\/)?([\w\._-]+)(:\d+)?([^?]*)(.*)$`
 )
 
 // NewRedirectScheme creates a new RedirectScheme middleware.
@@ -30,5 +31,11 @@ func NewRedirectScheme(ctx context.Context, next http.Handler, conf config.Redir
 		port = ":" + conf.Port
 	}
 
-	return newRedirect(ctx, next, schemeRedirectRegex, conf.Scheme+"://${2}"+port+"${4}", conf.Permanent, name)
+	// The query of the request is kept, with its path unless it is replaced.
+	path := "${4}"
+	if len(conf.Path) > 0 {
+		path = strings.Replace(conf.Path, "$", "$$", -1)
+	}
+
+	return newRedirect(ctx, next, schemeRedirectRegex, conf.Scheme+"://${2}"+port+path+"${5}", conf.Permanent, name)
 }
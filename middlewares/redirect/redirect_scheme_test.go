@@ -173,6 +173,62 @@ func TestRedirectSchemeHandler(t *testing.T) {
 			expectedURL:    "https://foo",
 			expectedStatus: http.StatusFound,
 		},
+		{
+			desc: "HTTP to HTTPS with query",
+			config: config.RedirectScheme{
+				Scheme: "https",
+			},
+			url:            "http://foo/bar?a=1&b=c+d",
+			expectedURL:    "https://foo/bar?a=1&b=c+d",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc: "HTTP to HTTPS with special characters",
+			config: config.RedirectScheme{
+				Scheme: "https",
+			},
+			url:            "http://foo/caf%C3%A9/it's/a%2Fb?q=%3Cx%3E&r='{{x}}'",
+			expectedURL:    "https://foo/caf%C3%A9/it's/a%2Fb?q=%3Cx%3E&r='{{x}}'",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc: "HTTP to HTTPS with a template in the path",
+			config: config.RedirectScheme{
+				Scheme: "https",
+			},
+			url:            "http://foo/{{.Request.Host}}",
+			expectedURL:    "https://foo/%7B%7B.Request.Host%7D%7D",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc: "HTTP to HTTPS with empty query",
+			config: config.RedirectScheme{
+				Scheme: "https",
+			},
+			url:            "http://foo/bar?",
+			expectedURL:    "https://foo/bar?",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc: "HTTP to HTTPS with replaced path",
+			config: config.RedirectScheme{
+				Scheme: "https",
+				Path:   "/login",
+			},
+			url:            "http://foo:8080/bar/baz?a=1&b=2",
+			expectedURL:    "https://foo/login?a=1&b=2",
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc: "HTTP to HTTPS with replaced path and no query",
+			config: config.RedirectScheme{
+				Scheme: "https",
+				Path:   "/$1",
+			},
+			url:            "http://foo/bar",
+			expectedURL:    "https://foo/$1",
+			expectedStatus: http.StatusFound,
+		},
 	}
 
 	for _, test := range testCases {
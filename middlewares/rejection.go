@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/vulcand/oxy/utils"
+)
+
+// NewRejectionErrorHandler creates an error handler overriding the status code, and the body if not empty,
+// of the 429 responses of next to the requests rejected by a limit.
+// The headers set by next, like Retry-After, are kept.
+func NewRejectionErrorHandler(next utils.ErrorHandler, statusCode int, body string) (utils.ErrorHandler, error) {
+	if statusCode < http.StatusBadRequest || statusCode > 599 {
+		return nil, fmt.Errorf("invalid rejection status code %d: it must be a 4xx or a 5xx", statusCode)
+	}
+
+	return utils.ErrorHandlerFunc(func(rw http.ResponseWriter, req *http.Request, err error) {
+		next.ServeHTTP(&rejectionResponseWriter{ResponseWriter: rw, statusCode: statusCode, body: body}, req, err)
+	}), nil
+}
+
+type rejectionResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       string
+	rejected   bool
+}
+
+func (r *rejectionResponseWriter) WriteHeader(code int) {
+	if code != http.StatusTooManyRequests {
+		r.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	r.rejected = true
+	r.ResponseWriter.WriteHeader(r.statusCode)
+	if r.body != "" {
+		_, _ = r.ResponseWriter.Write([]byte(r.body))
+	}
+}
+
+func (r *rejectionResponseWriter) Write(b []byte) (int, error) {
+	if r.rejected && r.body != "" {
+		return len(b), nil
+	}
+	return r.ResponseWriter.Write(b)
+}
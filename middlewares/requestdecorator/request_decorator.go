@@ -11,15 +11,17 @@ import (
 )
 
 const (
-	canonicalKey key = "canonical"
-	flattenKey   key = "flatten"
+	canonicalKey     key = "canonical"
+	flattenKey       key = "flatten"
+	caseSensitiveKey key = "caseSensitive"
 )
 
 type key string
 
 // RequestDecorator is the struct for the middleware that adds the CanonicalDomain of the request Host into a context for later use.
 type RequestDecorator struct {
-	hostResolver *Resolver
+	hostResolver  *Resolver
+	caseSensitive bool
 }
 
 // New creates a new request host middleware.
@@ -31,13 +33,21 @@ func New(hostResolverConfig *types.HostResolverConfig) *RequestDecorator {
 			ResolvConfig:    hostResolverConfig.ResolvConfig,
 			ResolvDepth:     hostResolverConfig.ResolvDepth,
 		}
+		requestDecorator.caseSensitive = hostResolverConfig.CaseSensitive
 	}
 	return requestDecorator
 }
 
 func (r *RequestDecorator) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
-	host := types.CanonicalDomain(parseHost(req.Host))
-	reqt := req.WithContext(context.WithValue(req.Context(), canonicalKey, host))
+	var host string
+	ctx := req.Context()
+	if r.caseSensitive {
+		host = strings.TrimSpace(parseHost(req.Host))
+		ctx = context.WithValue(ctx, caseSensitiveKey, true)
+	} else {
+		host = types.CanonicalDomain(parseHost(req.Host))
+	}
+	reqt := req.WithContext(context.WithValue(ctx, canonicalKey, host))
 
 	if r.hostResolver != nil && r.hostResolver.CnameFlattening {
 		flatHost := r.hostResolver.CNAMEFlatten(reqt.Context(), host)
@@ -68,6 +78,12 @@ func GetCanonizedHost(ctx context.Context) string {
 	return ""
 }
 
+// IsCaseSensitiveHost returns whether the host stored in the given context kept the case of the request host.
+func IsCaseSensitiveHost(ctx context.Context) bool {
+	val, ok := ctx.Value(caseSensitiveKey).(bool)
+	return ok && val
+}
+
 // GetCNAMEFlatten return the flat name if it is present in the context.
 func GetCNAMEFlatten(ctx context.Context) string {
 	if val, ok := ctx.Value(flattenKey).(string); ok {
@@ -57,6 +57,47 @@ func TestRequestHost(t *testing.T) {
 	}
 }
 
+func TestRequestHostCaseSensitive(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		caseSensitive         bool
+		url                   string
+		expected              string
+		expectedCaseSensitive bool
+	}{
+		{
+			desc:     "lowercased host",
+			url:      "http://Example.COM:8080",
+			expected: "example.com",
+		},
+		{
+			desc:                  "case-sensitive host",
+			caseSensitive:         true,
+			url:                   "http://Example.COM:8080",
+			expected:              "Example.COM",
+			expectedCaseSensitive: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, test.expected, GetCanonizedHost(r.Context()))
+				assert.Equal(t, test.expectedCaseSensitive, IsCaseSensitiveHost(r.Context()))
+			})
+
+			rh := New(&types.HostResolverConfig{CaseSensitive: test.caseSensitive})
+
+			req := testhelpers.MustNewRequest(http.MethodGet, test.url, nil)
+
+			rh.ServeHTTP(nil, req, next)
+		})
+	}
+}
+
 func TestRequestFlattening(t *testing.T) {
 	testCases := []struct {
 		desc     string
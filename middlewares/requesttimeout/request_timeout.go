@@ -0,0 +1,121 @@
+package requesttimeout
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName      = "RequestTimeout"
+	defaultHeader = "X-Timeout-Ms"
+)
+
+// requestTimeout is a middleware setting the deadline of the requests from the timeout given by the clients in a header.
+type requestTimeout struct {
+	next           http.Handler
+	header         string
+	defaultTimeout time.Duration
+	maxTimeout     time.Duration
+	name           string
+}
+
+// New creates a middleware cancelling the requests once the timeout of their header,
+// in milliseconds and bounded by the maximum, is elapsed.
+// The requests without a valid header get the default timeout.
+func New(ctx context.Context, next http.Handler, config config.RequestTimeout, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	header := strings.TrimSpace(config.Header)
+	if header == "" {
+		header = defaultHeader
+	}
+
+	defaultTimeout, err := parseTimeout(config.Default)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default timeout: %v", err)
+	}
+
+	maxTimeout, err := parseTimeout(config.Max)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max timeout: %v", err)
+	}
+
+	if maxTimeout > 0 && defaultTimeout > maxTimeout {
+		return nil, fmt.Errorf("default timeout %s greater than the max timeout %s", defaultTimeout, maxTimeout)
+	}
+
+	return &requestTimeout{
+		next:           next,
+		header:         header,
+		defaultTimeout: defaultTimeout,
+		maxTimeout:     maxTimeout,
+		name:           name,
+	}, nil
+}
+
+func parseTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if timeout < 0 {
+		return 0, fmt.Errorf("negative value %s", value)
+	}
+
+	return timeout, nil
+}
+
+func (r *requestTimeout) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return r.name, tracing.SpanKindNoneEnum
+}
+
+func (r *requestTimeout) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	timeout := r.timeout(req)
+	if timeout <= 0 {
+		r.next.ServeHTTP(rw, req)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	defer cancel()
+
+	r.next.ServeHTTP(rw, req.WithContext(ctx))
+}
+
+// timeout returns the timeout of the request header bounded by the maximum, or the default timeout if the header is absent or invalid.
+func (r *requestTimeout) timeout(req *http.Request) time.Duration {
+	value := req.Header.Get(r.header)
+	if value == "" {
+		return r.defaultTimeout
+	}
+
+	millis, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || millis <= 0 {
+		middlewares.GetLogger(req.Context(), r.name, typeName).Debugf("Invalid timeout %q in the header %s, using the default one", value, r.header)
+		return r.defaultTimeout
+	}
+
+	// Compares the milliseconds, which do not overflow, unlike their duration.
+	if r.maxTimeout > 0 && millis > int64(r.maxTimeout/time.Millisecond) {
+		return r.maxTimeout
+	}
+	if millis > math.MaxInt64/int64(time.Millisecond) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(millis) * time.Millisecond
+}
@@ -0,0 +1,156 @@
+package requesttimeout
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRequestTimeout(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.RequestTimeout
+		expectedError bool
+	}{
+		{
+			desc:   "empty",
+			config: config.RequestTimeout{},
+		},
+		{
+			desc:   "default and max",
+			config: config.RequestTimeout{Header: "X-Deadline", Default: "10s", Max: "1m"},
+		},
+		{
+			desc:          "invalid default",
+			config:        config.RequestTimeout{Default: "foo"},
+			expectedError: true,
+		},
+		{
+			desc:          "negative max",
+			config:        config.RequestTimeout{Max: "-1s"},
+			expectedError: true,
+		},
+		{
+			desc:          "default greater than max",
+			config:        config.RequestTimeout{Default: "1m", Max: "10s"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, handler)
+			}
+		})
+	}
+}
+
+func TestRequestTimeout_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		config          config.RequestTimeout
+		headers         map[string]string
+		expectedTimeout time.Duration
+	}{
+		{
+			desc:            "header",
+			config:          config.RequestTimeout{Default: "10s", Max: "1m"},
+			headers:         map[string]string{"X-Timeout-Ms": "50"},
+			expectedTimeout: 50 * time.Millisecond,
+		},
+		{
+			desc:            "custom header",
+			config:          config.RequestTimeout{Header: "X-Deadline"},
+			headers:         map[string]string{"X-Deadline": "50", "X-Timeout-Ms": "10000"},
+			expectedTimeout: 50 * time.Millisecond,
+		},
+		{
+			desc:            "capped by max",
+			config:          config.RequestTimeout{Default: "10ms", Max: "50ms"},
+			headers:         map[string]string{"X-Timeout-Ms": "10000"},
+			expectedTimeout: 50 * time.Millisecond,
+		},
+		{
+			desc:            "huge header capped by max",
+			config:          config.RequestTimeout{Max: "50ms"},
+			headers:         map[string]string{"X-Timeout-Ms": "9223372036854775807"},
+			expectedTimeout: 50 * time.Millisecond,
+		},
+		{
+			desc:            "no header",
+			config:          config.RequestTimeout{Default: "50ms", Max: "1m"},
+			expectedTimeout: 50 * time.Millisecond,
+		},
+		{
+			desc:            "invalid header",
+			config:          config.RequestTimeout{Default: "50ms", Max: "1m"},
+			headers:         map[string]string{"X-Timeout-Ms": "foo"},
+			expectedTimeout: 50 * time.Millisecond,
+		},
+		{
+			desc:            "negative header",
+			config:          config.RequestTimeout{Default: "50ms", Max: "1m"},
+			headers:         map[string]string{"X-Timeout-Ms": "-10"},
+			expectedTimeout: 50 * time.Millisecond,
+		},
+		{
+			desc:   "no header and no default",
+			config: config.RequestTimeout{Max: "1m"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var cancelled bool
+			var elapsed time.Duration
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				start := time.Now()
+				select {
+				case <-req.Context().Done():
+					cancelled = true
+				case <-time.After(time.Second):
+				}
+				elapsed = time.Since(start)
+			})
+
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if test.expectedTimeout == 0 {
+				assert.False(t, cancelled)
+				return
+			}
+
+			assert.True(t, cancelled)
+			assert.True(t, elapsed >= test.expectedTimeout-5*time.Millisecond, "cancelled after %s", elapsed)
+			assert.True(t, elapsed < 500*time.Millisecond, "cancelled after %s", elapsed)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package retry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+)
+
+const (
+	defaultBudgetRatio  = 0.1
+	defaultBudgetWindow = 10 * time.Second
+	budgetBuckets       = 10
+)
+
+// Budget caps the retries at a ratio of the requests over a sliding window,
+// made of buckets which expire one after the other.
+// A budget can be shared by several retry middlewares, so that the retries are throttled globally.
+type Budget struct {
+	ratio          float64
+	minRetries     int
+	bucketDuration time.Duration
+	now            func() time.Time
+
+	lock        sync.Mutex
+	buckets     [budgetBuckets]budgetBucket
+	current     int
+	bucketStart time.Time
+}
+
+type budgetBucket struct {
+	requests int
+	retries  int
+}
+
+// NewBudget creates a retry budget.
+func NewBudget(conf config.RetryBudget) (*Budget, error) {
+	ratio := defaultBudgetRatio
+	if conf.Ratio != 0 {
+		if conf.Ratio < 0 {
+			return nil, fmt.Errorf("negative retry budget ratio %v", conf.Ratio)
+		}
+		ratio = conf.Ratio
+	}
+
+	window, err := parseTimeout(conf.Window)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry budget window: %v", err)
+	}
+	if window == 0 {
+		window = defaultBudgetWindow
+	}
+	if window < budgetBuckets {
+		return nil, fmt.Errorf("retry budget window %s too short", window)
+	}
+
+	if conf.MinRetries < 0 {
+		return nil, fmt.Errorf("negative retry budget minimum retries %d", conf.MinRetries)
+	}
+
+	return &Budget{
+		ratio:          ratio,
+		minRetries:     conf.MinRetries,
+		bucketDuration: window / budgetBuckets,
+		now:            time.Now,
+	}, nil
+}
+
+// request records a request.
+func (b *Budget) request() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.advance()
+	b.buckets[b.current].requests++
+}
+
+// withdraw records a retry and returns true if the budget allows it.
+func (b *Budget) withdraw() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.advance()
+
+	var requests, retries int
+	for _, bucket := range b.buckets {
+		requests += bucket.requests
+		retries += bucket.retries
+	}
+
+	if retries >= b.minRetries && float64(retries+1) > b.ratio*float64(requests) {
+		return false
+	}
+
+	b.buckets[b.current].retries++
+	return true
+}
+
+// advance resets the buckets which expired since the current one started.
+func (b *Budget) advance() {
+	now := b.now()
+	if b.bucketStart.IsZero() {
+		b.bucketStart = now
+		return
+	}
+
+	elapsed := int(now.Sub(b.bucketStart) / b.bucketDuration)
+	if elapsed <= 0 {
+		return
+	}
+
+	if elapsed >= budgetBuckets {
+		b.buckets = [budgetBuckets]budgetBucket{}
+		b.bucketStart = now
+		return
+	}
+
+	for i := 0; i < elapsed; i++ {
+		b.current = (b.current + 1) % budgetBuckets
+		b.buckets[b.current] = budgetBucket{}
+	}
+	b.bucketStart = b.bucketStart.Add(time.Duration(elapsed) * b.bucketDuration)
+}
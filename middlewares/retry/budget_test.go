@@ -0,0 +1,95 @@
+package retry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget(t *testing.T) {
+	budget, err := NewBudget(config.RetryBudget{Ratio: 0.5, Window: "10s", MinRetries: 1})
+	require.NoError(t, err)
+
+	now := time.Now()
+	budget.now = func() time.Time { return now }
+
+	// The minimum retries are allowed without requests.
+	assert.True(t, budget.withdraw())
+	assert.False(t, budget.withdraw())
+
+	for i := 0; i < 4; i++ {
+		budget.request()
+	}
+	assert.True(t, budget.withdraw())
+	assert.False(t, budget.withdraw())
+
+	// The retries and the requests of the first bucket are still counted.
+	now = now.Add(5 * time.Second)
+	budget.request()
+	budget.request()
+	assert.True(t, budget.withdraw())
+	assert.False(t, budget.withdraw())
+
+	// The first bucket expired, the requests of the second one allow one retry.
+	now = now.Add(5 * time.Second)
+	assert.False(t, budget.withdraw())
+
+	// All the buckets expired.
+	now = now.Add(time.Minute)
+	assert.True(t, budget.withdraw())
+	assert.False(t, budget.withdraw())
+}
+
+func TestRetryBudget(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		budget           *config.RetryBudget
+		expectedRetries  int
+		expectedAttempts int
+	}{
+		{
+			desc:             "without budget",
+			expectedRetries:  200,
+			expectedAttempts: 300,
+		},
+		{
+			desc:             "retries throttled",
+			budget:           &config.RetryBudget{Ratio: 0.1},
+			expectedRetries:  10,
+			expectedAttempts: 110,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts int
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				attempts++
+				rw.WriteHeader(http.StatusBadGateway)
+			})
+
+			retryListener := &countingRetryListener{}
+			handler, err := New(context.Background(), next, config.Retry{Attempts: 3, Budget: test.budget}, retryListener, "traefikTest")
+			require.NoError(t, err)
+
+			// All the requests fail.
+			for i := 0; i < 100; i++ {
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+				assert.Equal(t, http.StatusBadGateway, recorder.Code)
+			}
+
+			assert.Equal(t, test.expectedRetries, retryListener.timesCalled)
+			assert.Equal(t, test.expectedAttempts, attempts)
+		})
+	}
+}
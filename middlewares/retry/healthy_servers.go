@@ -0,0 +1,28 @@
+package retry
+
+import (
+	"net/http"
+	"net/url"
+)
+
+type serversKeyType int
+
+const serversKey serversKeyType = iota
+
+// Balancer is a load-balancer which exposes its healthy servers.
+type Balancer interface {
+	Servers() []*url.URL
+}
+
+// serversHolder holds the load-balancer which handled the attempts of a request.
+type serversHolder struct {
+	balancer Balancer
+}
+
+// SetBalancer reports the load-balancer handling the request to the retry middleware,
+// which caps its attempts at the number of healthy servers of the load-balancer when configured to.
+func SetBalancer(req *http.Request, balancer Balancer) {
+	if servers, ok := req.Context().Value(serversKey).(*serversHolder); ok {
+		servers.balancer = balancer
+	}
+}
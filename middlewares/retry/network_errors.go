@@ -0,0 +1,109 @@
+package retry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+)
+
+// Classes of the network errors which can be retried.
+const (
+	networkErrorConnectionRefused = "connectionRefused"
+	networkErrorConnectionReset   = "connectionReset"
+	networkErrorTimeout           = "timeout"
+	networkErrorDNS               = "dns"
+	networkErrorTLS               = "tls"
+)
+
+var networkErrorClassifiers = map[string]func(err error) bool{
+	networkErrorConnectionRefused: func(err error) bool {
+		return err == syscall.ECONNREFUSED
+	},
+	networkErrorConnectionReset: func(err error) bool {
+		return err == syscall.ECONNRESET || err == syscall.EPIPE
+	},
+	networkErrorTimeout: func(err error) bool {
+		netErr, ok := err.(net.Error)
+		return ok && netErr.Timeout()
+	},
+	networkErrorDNS: func(err error) bool {
+		_, ok := err.(*net.DNSError)
+		return ok
+	},
+	networkErrorTLS: func(err error) bool {
+		switch err.(type) {
+		case x509.UnknownAuthorityError, x509.HostnameError, x509.CertificateInvalidError, tls.RecordHeaderError:
+			return true
+		default:
+			return false
+		}
+	},
+}
+
+type errorKeyType int
+
+const errorKey errorKeyType = iota
+
+// errorHolder holds the error of the attempt in progress.
+type errorHolder struct {
+	err error
+}
+
+// SetError reports the error which ended an attempt to the retry middleware,
+// which only retries the configured network errors when configured to.
+func SetError(req *http.Request, err error) {
+	if holder, ok := req.Context().Value(errorKey).(*errorHolder); ok {
+		holder.err = err
+	}
+}
+
+func parseNetworkErrors(classes []string) (map[string]bool, error) {
+	if len(classes) == 0 {
+		return nil, nil
+	}
+
+	networkErrors := make(map[string]bool)
+	for _, class := range classes {
+		if _, ok := networkErrorClassifiers[class]; !ok {
+			return nil, fmt.Errorf("unknown network error %q", class)
+		}
+		networkErrors[class] = true
+	}
+
+	return networkErrors, nil
+}
+
+// isRetryable returns true if an error of the chain of err belongs to one of the network error classes.
+func isRetryable(err error, networkErrors map[string]bool) bool {
+	for ; err != nil; err = unwrapError(err) {
+		for class := range networkErrors {
+			if networkErrorClassifiers[class](err) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unwrapError returns the error wrapped by err, or nil.
+func unwrapError(err error) error {
+	switch e := err.(type) {
+	case *url.Error:
+		return e.Err
+	case *net.OpError:
+		return e.Err
+	case *os.SyscallError:
+		return e.Err
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	case interface{ Cause() error }:
+		return e.Cause()
+	default:
+		return nil
+	}
+}
@@ -2,14 +2,21 @@ package retry
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/middlewares"
 	"github.com/containous/traefik/tracing"
 	"github.com/opentracing/opentracing-go/ext"
@@ -22,6 +29,18 @@ const (
 	typeName = "Retry"
 )
 
+// Jitter strategies of the waits between attempts.
+const (
+	jitterEqual = "equal"
+	jitterFull  = "full"
+	jitterNone  = "none"
+)
+
+const maxInterval = time.Minute
+
+// idempotentMethods are the methods retried by default, as replaying them does not submit the request twice.
+var idempotentMethods = []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions}
+
 // Listener is used to inform about retry attempts.
 type Listener interface {
 	// Retried will be called when a retry happens, with the request attempt passed to it.
@@ -35,14 +54,37 @@ type Listeners []Listener
 
 // retry is a middleware that retries requests.
 type retry struct {
-	attempts int
-	next     http.Handler
-	listener Listener
-	name     string
+	attempts        int
+	perTryTimeout   time.Duration
+	timeout         time.Duration
+	initialInterval time.Duration
+	jitter          string
+	methods         map[string]bool
+	healthyServers  bool
+	networkErrors   map[string]bool
+	responseErrors  bool
+	budget          *Budget
+	next            http.Handler
+	listener        Listener
+	name            string
 }
 
-// New returns a new retry middleware.
+// New returns a new retry middleware, with a budget of its own if the configuration has one.
 func New(ctx context.Context, next http.Handler, config config.Retry, listener Listener, name string) (http.Handler, error) {
+	var budget *Budget
+	if config.Budget != nil {
+		var err error
+		budget, err = NewBudget(*config.Budget)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewWithBudget(ctx, next, config, budget, listener, name)
+}
+
+// NewWithBudget returns a new retry middleware sharing the given budget, if any, instead of the one of the configuration.
+func NewWithBudget(ctx context.Context, next http.Handler, config config.Retry, budget *Budget, listener Listener, name string) (http.Handler, error) {
 	logger := middlewares.GetLogger(ctx, name, typeName)
 	logger.Debug("Creating middleware")
 
@@ -50,19 +92,98 @@ func New(ctx context.Context, next http.Handler, config config.Retry, listener L
 		return nil, fmt.Errorf("incorrect (or empty) value for attempt (%d)", config.Attempts)
 	}
 
+	perTryTimeout, err := parseTimeout(config.PerTryTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid per try timeout: %v", err)
+	}
+
+	timeout, err := parseTimeout(config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout: %v", err)
+	}
+
+	initialInterval, err := parseTimeout(config.InitialInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid initial interval: %v", err)
+	}
+
+	jitter := config.Jitter
+	switch jitter {
+	case "":
+		jitter = jitterEqual
+	case jitterEqual, jitterFull, jitterNone:
+	default:
+		return nil, fmt.Errorf("unknown jitter %q", config.Jitter)
+	}
+
+	retriedMethods := config.Methods
+	if len(retriedMethods) == 0 {
+		retriedMethods = idempotentMethods
+	}
+
+	methods := make(map[string]bool)
+	for _, method := range retriedMethods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" {
+			return nil, errors.New("empty retried method")
+		}
+		methods[method] = true
+	}
+
+	networkErrors, err := parseNetworkErrors(config.NetworkErrors)
+	if err != nil {
+		return nil, err
+	}
+
 	return &retry{
-		attempts: config.Attempts,
-		next:     next,
-		listener: listener,
-		name:     name,
+		attempts:        config.Attempts,
+		perTryTimeout:   perTryTimeout,
+		timeout:         timeout,
+		initialInterval: initialInterval,
+		jitter:          jitter,
+		methods:         methods,
+		healthyServers:  config.HealthyServersAttempts,
+		networkErrors:   networkErrors,
+		responseErrors:  config.ResponseErrors,
+		budget:          budget,
+		next:            next,
+		listener:        listener,
+		name:            name,
 	}, nil
 }
 
+func parseTimeout(value string) (time.Duration, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if timeout < 0 {
+		return 0, fmt.Errorf("negative value %s", value)
+	}
+
+	return timeout, nil
+}
+
 func (r *retry) GetTracingInformation() (string, ext.SpanKindEnum) {
 	return r.name, tracing.SpanKindNoneEnum
 }
 
 func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if r.budget != nil {
+		r.budget.request()
+	}
+
+	// The requests using the other methods would be submitted again.
+	if !r.methods[req.Method] {
+		r.next.ServeHTTP(rw, req)
+		return
+	}
+
 	// if we might make multiple attempts, swap the body for an ioutil.NopCloser
 	// cf https://github.com/containous/traefik/issues/1008
 	if r.attempts > 1 {
@@ -71,35 +192,215 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		req.Body = ioutil.NopCloser(body)
 	}
 
+	// The overall deadline also applies to the attempt in progress when it is reached.
+	ctx := req.Context()
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	var servers *serversHolder
+	if r.healthyServers {
+		servers = &serversHolder{}
+		ctx = context.WithValue(ctx, serversKey, servers)
+	}
+
+	var attemptError *errorHolder
+	if r.networkErrors != nil {
+		attemptError = &errorHolder{}
+		ctx = context.WithValue(ctx, errorKey, attemptError)
+	}
+
+	// The attempts stalling once the server received the request are retried when they time out,
+	// so that their response is held back like the one of the attempts whose response errors are retried.
+	holdResponses := (r.responseErrors || r.perTryTimeout > 0) && isIdempotent(req.Method)
+
 	attempts := 1
 	for {
-		shouldRetry := attempts < r.attempts
+		shouldRetry := attempts < r.maxAttempts(servers)
 		retryResponseWriter := newResponseWriter(rw, shouldRetry)
 
-		// Disable retries when the backend already received request data
+		// Disable retries when the backend already received request data,
+		// or hold the response back until its body is sent when the response errors are retried.
+		requestSent := retryResponseWriter.DisableRetries
+		if holdResponses {
+			requestSent = retryResponseWriter.HoldResponse
+		}
+
 		trace := &httptrace.ClientTrace{
-			WroteHeaders: func() {
-				retryResponseWriter.DisableRetries()
-			},
+			WroteHeaders: requestSent,
 			WroteRequest: func(httptrace.WroteRequestInfo) {
-				retryResponseWriter.DisableRetries()
+				requestSent()
 			},
+			GotFirstResponseByte: retryResponseWriter.GotResponse,
 		}
-		newCtx := httptrace.WithClientTrace(req.Context(), trace)
 
-		r.next.ServeHTTP(retryResponseWriter, req.WithContext(newCtx))
+		if attemptError != nil {
+			attemptError.err = nil
+		}
+
+		attemptCtx, cancelAttempt := ctx, context.CancelFunc(func() {})
+		if r.perTryTimeout > 0 {
+			attemptCtx, cancelAttempt = context.WithTimeout(ctx, r.perTryTimeout)
+		}
+
+		aborted := r.serveAttempt(retryResponseWriter, req.WithContext(httptrace.WithClientTrace(attemptCtx, trace)))
+		timedOut := attemptCtx.Err() == context.DeadlineExceeded
+		cancelAttempt()
+
+		if retryResponseWriter.Holding() {
+			retryResponseWriter.EndHeldAttempt(aborted)
+
+			// Without the response errors, the attempts which failed after the server received the request are only retried when they timed out.
+			if !r.responseErrors && !timedOut && retryResponseWriter.ShouldRetry() {
+				retryResponseWriter.WriteLastAttempt()
+			}
+		}
 
 		if !retryResponseWriter.ShouldRetry() {
 			break
 		}
 
-		attempts++
 		logger := middlewares.GetLogger(req.Context(), r.name, typeName)
+
+		// The error of the attempt is not in the retried network errors, e.g. a certificate verification failure, which is permanent.
+		if attemptError != nil && attemptError.err != nil && !isRetryable(attemptError.err, r.networkErrors) {
+			logger.Debugf("Stop retrying request %v after %d attempt(s): %v is not retried", req.URL, attempts, attemptError.err)
+			retryResponseWriter.WriteLastAttempt()
+			break
+		}
+
+		// The load-balancer reported its healthy servers during the attempt: they could all have been tried already.
+		if attempts >= r.maxAttempts(servers) {
+			logger.Debugf("Stop retrying request %v after %d attempt(s): no other healthy server", req.URL, attempts)
+			retryResponseWriter.WriteLastAttempt()
+			break
+		}
+
+		// The request was canceled, or the overall deadline is exceeded: stop retrying and deliver the last response.
+		if ctx.Err() != nil {
+			logger.Debugf("Stop retrying request %v after %d attempt(s): %v", req.URL, attempts, ctx.Err())
+			retryResponseWriter.WriteLastAttempt()
+			break
+		}
+
+		// The retries beyond the budget would amplify the load of the failing servers.
+		if r.budget != nil && !r.budget.withdraw() {
+			logger.Debugf("Stop retrying request %v after %d attempt(s): retry budget exhausted", req.URL, attempts)
+			retryResponseWriter.WriteLastAttempt()
+			break
+		}
+
+		if !r.wait(ctx, attempts) {
+			logger.Debugf("Stop retrying request %v after %d attempt(s): %v", req.URL, attempts, ctx.Err())
+			retryResponseWriter.WriteLastAttempt()
+			break
+		}
+
+		attempts++
 		logger.Debugf("New attempt %d for request: %v", attempts, req.URL)
 		r.listener.Retried(req, attempts)
 	}
 }
 
+// serveAttempt forwards the request, and returns true if the copy of a held response was aborted,
+// i.e. its body could not be read from the server before anything was sent to the client.
+func (r *retry) serveAttempt(rw responseWriter, req *http.Request) (aborted bool) {
+	defer func() {
+		if !rw.Holding() {
+			return
+		}
+		if p := recover(); p != nil {
+			if p != http.ErrAbortHandler {
+				panic(p)
+			}
+			aborted = true
+		}
+	}()
+
+	r.next.ServeHTTP(rw, req)
+	return false
+}
+
+func isIdempotent(method string) bool {
+	for _, m := range idempotentMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// maxAttempts returns the number of attempts, capped at the number of healthy servers reported by the load-balancer.
+func (r *retry) maxAttempts(servers *serversHolder) int {
+	if servers == nil || servers.balancer == nil {
+		return r.attempts
+	}
+
+	count := len(servers.balancer.Servers())
+	if count < 1 {
+		count = 1
+	}
+	if count < r.attempts {
+		return count
+	}
+	return r.attempts
+}
+
+// wait waits before the next attempt, and returns false if the context ends in the meantime.
+func (r *retry) wait(ctx context.Context, attempts int) bool {
+	if r.initialInterval <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(r.backoff(attempts))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// The source of the jitters is seeded, unlike the global source of math/rand,
+// so that the Traefik instances do not wait the same durations, and it is not safe for concurrent use.
+var (
+	jitterRandLock sync.Mutex
+	jitterRand     = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func randInt63n(n int64) int64 {
+	jitterRandLock.Lock()
+	defer jitterRandLock.Unlock()
+
+	return jitterRand.Int63n(n)
+}
+
+// backoff returns the wait after the given number of attempts:
+// the initial interval doubled at each new attempt, randomized by the jitter strategy.
+func (r *retry) backoff(attempts int) time.Duration {
+	interval := r.initialInterval
+	for i := 1; i < attempts && interval < maxInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+
+	switch r.jitter {
+	case jitterFull:
+		return time.Duration(randInt63n(int64(interval) + 1))
+	case jitterEqual:
+		half := interval / 2
+		return half + time.Duration(randInt63n(int64(interval-half)+1))
+	default:
+		return interval
+	}
+}
+
 // Retried exists to implement the Listener interface. It calls Retried on each of its slice entries.
 func (l Listeners) Retried(req *http.Request, attempt int) {
 	for _, listener := range l {
@@ -112,6 +413,11 @@ type responseWriter interface {
 	http.Flusher
 	ShouldRetry() bool
 	DisableRetries()
+	WriteLastAttempt()
+	HoldResponse()
+	GotResponse()
+	Holding() bool
+	EndHeldAttempt(aborted bool)
 }
 
 func newResponseWriter(rw http.ResponseWriter, shouldRetry bool) responseWriter {
@@ -132,6 +438,11 @@ type responseWriterWithoutCloseNotify struct {
 	responseWriter http.ResponseWriter
 	headers        http.Header
 	shouldRetry    bool
+	lastStatusCode int
+	lastBody       bytes.Buffer
+	// holding is true once the request reached the server, while the response is held back until its body is sent.
+	holding   bool
+	responded bool
 }
 
 func (r *responseWriterWithoutCloseNotify) ShouldRetry() bool {
@@ -140,6 +451,66 @@ func (r *responseWriterWithoutCloseNotify) ShouldRetry() bool {
 
 func (r *responseWriterWithoutCloseNotify) DisableRetries() {
 	r.shouldRetry = false
+	r.holding = false
+}
+
+// HoldResponse keeps the attempt retryable after the request reached the server,
+// until the first bytes of the response body are sent to the client.
+func (r *responseWriterWithoutCloseNotify) HoldResponse() {
+	if r.shouldRetry {
+		r.holding = true
+	}
+}
+
+// GotResponse records that the server started to respond.
+func (r *responseWriterWithoutCloseNotify) GotResponse() {
+	r.responded = true
+}
+
+func (r *responseWriterWithoutCloseNotify) Holding() bool {
+	return r.holding
+}
+
+// EndHeldAttempt sends the held response to the client, unless the server did not respond,
+// or its response body could not be read, in which case the attempt is retried as a failed one.
+func (r *responseWriterWithoutCloseNotify) EndHeldAttempt(aborted bool) {
+	if r.responded && !aborted {
+		r.sendHeldResponse()
+		return
+	}
+
+	r.holding = false
+	if aborted {
+		// The headers of the truncated response must not be sent along the error of the last attempt.
+		r.headers = make(http.Header)
+		r.lastStatusCode = http.StatusBadGateway
+		r.lastBody.Reset()
+	}
+}
+
+func (r *responseWriterWithoutCloseNotify) sendHeldResponse() {
+	code := r.lastStatusCode
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	r.DisableRetries()
+	r.WriteHeader(code)
+}
+
+// WriteLastAttempt disables the retries, and writes the response of the attempt that was held back to the client.
+func (r *responseWriterWithoutCloseNotify) WriteLastAttempt() {
+	r.DisableRetries()
+
+	code := r.lastStatusCode
+	if code == 0 {
+		code = http.StatusGatewayTimeout
+	}
+
+	r.WriteHeader(code)
+	if _, err := r.responseWriter.Write(r.lastBody.Bytes()); err != nil {
+		log.WithoutContext().Errorf("Unable to write the response of the last attempt: %v", err)
+	}
 }
 
 func (r *responseWriterWithoutCloseNotify) Header() http.Header {
@@ -147,8 +518,13 @@ func (r *responseWriterWithoutCloseNotify) Header() http.Header {
 }
 
 func (r *responseWriterWithoutCloseNotify) Write(buf []byte) (int, error) {
+	// Until the server responds, the errors of the attempt are held back like the ones of the attempts not sent.
+	if r.holding && r.responded && len(buf) > 0 {
+		r.sendHeldResponse()
+	}
+
 	if r.ShouldRetry() {
-		return len(buf), nil
+		return r.lastBody.Write(buf)
 	}
 	return r.responseWriter.Write(buf)
 }
@@ -164,6 +540,7 @@ func (r *responseWriterWithoutCloseNotify) WriteHeader(code int) {
 	}
 
 	if r.ShouldRetry() {
+		r.lastStatusCode = code
 		return
 	}
 
@@ -180,6 +557,10 @@ func (r *responseWriterWithoutCloseNotify) WriteHeader(code int) {
 }
 
 func (r *responseWriterWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if r.holding {
+		r.DisableRetries()
+	}
+
 	hijacker, ok := r.responseWriter.(http.Hijacker)
 	if !ok {
 		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.responseWriter)
@@ -188,6 +569,15 @@ func (r *responseWriterWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter
 }
 
 func (r *responseWriterWithoutCloseNotify) Flush() {
+	if r.holding && r.responded {
+		r.sendHeldResponse()
+	}
+
+	// The response of an attempt which could be retried is held back: flushing it would send its headers to the client.
+	if r.ShouldRetry() {
+		return
+	}
+
 	if flusher, ok := r.responseWriter.(http.Flusher); ok {
 		flusher.Flush()
 	}
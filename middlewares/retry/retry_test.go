@@ -1,13 +1,22 @@
 package retry
 
 import (
+	"bufio"
 	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httptrace"
+	"net/url"
+	"os"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/middlewares/emptybackendhandler"
@@ -17,6 +26,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/vulcand/oxy/forward"
 	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
 )
 
 func TestRetry(t *testing.T) {
@@ -136,6 +146,707 @@ func TestRetryEmptyServerList(t *testing.T) {
 	assert.Equal(t, 0, retryListener.timesCalled)
 }
 
+func TestNewRetry(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.Retry
+		expectedError bool
+	}{
+		{
+			desc:   "attempts only",
+			config: config.Retry{Attempts: 3},
+		},
+		{
+			desc:   "with timeouts",
+			config: config.Retry{Attempts: 3, PerTryTimeout: "1s", Timeout: "5s"},
+		},
+		{
+			desc:          "no attempts",
+			config:        config.Retry{},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid per try timeout",
+			config:        config.Retry{Attempts: 3, PerTryTimeout: "foo"},
+			expectedError: true,
+		},
+		{
+			desc:          "negative timeout",
+			config:        config.Retry{Attempts: 3, Timeout: "-1s"},
+			expectedError: true,
+		},
+		{
+			desc:   "with backoff",
+			config: config.Retry{Attempts: 3, InitialInterval: "100ms", Jitter: "full"},
+		},
+		{
+			desc:          "invalid initial interval",
+			config:        config.Retry{Attempts: 3, InitialInterval: "foo"},
+			expectedError: true,
+		},
+		{
+			desc:          "unknown jitter",
+			config:        config.Retry{Attempts: 3, Jitter: "foo"},
+			expectedError: true,
+		},
+		{
+			desc:   "with methods",
+			config: config.Retry{Attempts: 3, Methods: []string{"GET", "post"}},
+		},
+		{
+			desc:          "empty method",
+			config:        config.Retry{Attempts: 3, Methods: []string{"GET", " "}},
+			expectedError: true,
+		},
+		{
+			desc:   "with network errors",
+			config: config.Retry{Attempts: 3, NetworkErrors: []string{"connectionRefused", "timeout"}},
+		},
+		{
+			desc:          "unknown network error",
+			config:        config.Retry{Attempts: 3, NetworkErrors: []string{"foo"}},
+			expectedError: true,
+		},
+		{
+			desc:   "with budget",
+			config: config.Retry{Attempts: 3, Budget: &config.RetryBudget{Ratio: 0.2, Window: "1m", MinRetries: 5}},
+		},
+		{
+			desc:          "negative budget ratio",
+			config:        config.Retry{Attempts: 3, Budget: &config.RetryBudget{Ratio: -1}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid budget window",
+			config:        config.Retry{Attempts: 3, Budget: &config.RetryBudget{Window: "foo"}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+			handler, err := New(context.Background(), next, test.config, &countingRetryListener{}, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, handler)
+			}
+		})
+	}
+}
+
+func TestRetryPerTryTimeout(t *testing.T) {
+	attempt := 0
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		attempt++
+		if attempt < 3 {
+			// Simulate a backend that cannot be reached before the attempt times out.
+			<-req.Context().Done()
+			http.Error(rw, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+			return
+		}
+
+		httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	retryListener := &countingRetryListener{}
+	retry, err := New(context.Background(), next, config.Retry{Attempts: 3, PerTryTimeout: "20ms"}, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil)
+
+	start := time.Now()
+	retry.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 2, retryListener.timesCalled)
+	assert.True(t, time.Since(start) < time.Second, "attempts were not bounded by the per try timeout")
+}
+
+func TestRetryPerTryTimeout_AfterRequestSent(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		method           string
+		stall            bool
+		expectedCode     int
+		expectedAttempts int
+	}{
+		{
+			desc:             "stalled server",
+			method:           http.MethodGet,
+			stall:            true,
+			expectedCode:     http.StatusOK,
+			expectedAttempts: 3,
+		},
+		{
+			desc:             "stalled server with a non-idempotent method",
+			method:           http.MethodPost,
+			stall:            true,
+			expectedCode:     http.StatusGatewayTimeout,
+			expectedAttempts: 1,
+		},
+		{
+			desc:             "failed server",
+			method:           http.MethodGet,
+			expectedCode:     http.StatusBadGateway,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			attempts := 0
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				attempts++
+				httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+				if attempts < 3 {
+					if !test.stall {
+						http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+						return
+					}
+
+					// Simulate a server which received the request, then stalls past the per try timeout.
+					<-req.Context().Done()
+					http.Error(rw, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+					return
+				}
+
+				httptrace.ContextClientTrace(req.Context()).GotFirstResponseByte()
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			retry, err := New(context.Background(), next, config.Retry{Attempts: 3, PerTryTimeout: "20ms", Methods: []string{test.method}}, &countingRetryListener{}, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, "http://localhost:3000/ok", nil)
+
+			start := time.Now()
+			retry.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedCode, recorder.Code)
+			assert.Equal(t, test.expectedAttempts, attempts)
+			assert.True(t, time.Since(start) < time.Second, "attempts were not bounded by the per try timeout")
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		jitter      string
+		attempts    int
+		expectedMin time.Duration
+		expectedMax time.Duration
+	}{
+		{
+			desc:        "default to equal jitter",
+			attempts:    1,
+			expectedMin: 50 * time.Millisecond,
+			expectedMax: 100 * time.Millisecond,
+		},
+		{
+			desc:        "equal jitter",
+			jitter:      "equal",
+			attempts:    3,
+			expectedMin: 200 * time.Millisecond,
+			expectedMax: 400 * time.Millisecond,
+		},
+		{
+			desc:        "full jitter",
+			jitter:      "full",
+			attempts:    2,
+			expectedMin: 0,
+			expectedMax: 200 * time.Millisecond,
+		},
+		{
+			desc:        "no jitter",
+			jitter:      "none",
+			attempts:    2,
+			expectedMin: 200 * time.Millisecond,
+			expectedMax: 200 * time.Millisecond,
+		},
+		{
+			desc:        "capped interval",
+			jitter:      "none",
+			attempts:    100,
+			expectedMin: time.Minute,
+			expectedMax: time.Minute,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+			handler, err := New(context.Background(), next, config.Retry{Attempts: 3, InitialInterval: "100ms", Jitter: test.jitter}, &countingRetryListener{}, "traefikTest")
+			require.NoError(t, err)
+
+			r, ok := handler.(*retry)
+			require.True(t, ok)
+
+			intervals := make(map[time.Duration]struct{})
+			for i := 0; i < 100; i++ {
+				interval := r.backoff(test.attempts)
+				assert.True(t, interval >= test.expectedMin && interval <= test.expectedMax, "interval %s not in [%s, %s]", interval, test.expectedMin, test.expectedMax)
+				intervals[interval] = struct{}{}
+			}
+
+			if test.expectedMin != test.expectedMax {
+				assert.True(t, len(intervals) > 1, "intervals are not randomized")
+			}
+		})
+	}
+}
+
+func TestRetryWaitsBetweenAttempts(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "attempt failed", http.StatusBadGateway)
+	})
+
+	retryListener := &countingRetryListener{}
+	retry, err := New(context.Background(), next, config.Retry{Attempts: 3, InitialInterval: "20ms", Jitter: "none"}, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil)
+
+	start := time.Now()
+	retry.ServeHTTP(recorder, req)
+
+	// 20ms before the second attempt, then 40ms before the third one.
+	assert.True(t, time.Since(start) >= 60*time.Millisecond, "attempts did not wait: %s", time.Since(start))
+	assert.Equal(t, 2, retryListener.timesCalled)
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+}
+
+func TestRetryMethods(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		methods         []string
+		method          string
+		expectedRetries int
+	}{
+		{
+			desc:            "GET retried by default",
+			method:          http.MethodGet,
+			expectedRetries: 2,
+		},
+		{
+			desc:            "POST not retried by default",
+			method:          http.MethodPost,
+			expectedRetries: 0,
+		},
+		{
+			desc:            "POST explicitly retried",
+			methods:         []string{"GET", "POST"},
+			method:          http.MethodPost,
+			expectedRetries: 2,
+		},
+		{
+			desc:            "GET not retried when not listed",
+			methods:         []string{"POST"},
+			method:          http.MethodGet,
+			expectedRetries: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				calls++
+				http.Error(rw, "attempt failed", http.StatusBadGateway)
+			})
+
+			retryListener := &countingRetryListener{}
+			retry, err := New(context.Background(), next, config.Retry{Attempts: 3, Methods: test.methods}, retryListener, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			retry.ServeHTTP(recorder, httptest.NewRequest(test.method, "http://localhost:3000/ok", nil))
+
+			assert.Equal(t, test.expectedRetries, retryListener.timesCalled)
+			assert.Equal(t, test.expectedRetries+1, calls)
+			assert.Equal(t, http.StatusBadGateway, recorder.Code)
+		})
+	}
+}
+
+func TestRetryFlushedAttempts(t *testing.T) {
+	var calls int
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		http.Error(rw, "attempt failed", http.StatusBadGateway)
+		rw.(http.Flusher).Flush()
+	})
+
+	retryListener := &countingRetryListener{}
+	retry, err := New(context.Background(), next, config.Retry{Attempts: 3}, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil))
+
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.Equal(t, "attempt failed\n", recorder.Body.String())
+}
+
+type fakeBalancer []*url.URL
+
+func (b fakeBalancer) Servers() []*url.URL {
+	return b
+}
+
+func TestRetryHealthyServersAttempts(t *testing.T) {
+	servers := fakeBalancer{{Scheme: "http", Host: "10.0.0.1"}, {Scheme: "http", Host: "10.0.0.2"}}
+
+	testCases := []struct {
+		desc             string
+		healthyServers   bool
+		balancer         Balancer
+		expectedAttempts int
+	}{
+		{
+			desc:             "not capped",
+			balancer:         servers,
+			expectedAttempts: 5,
+		},
+		{
+			desc:             "capped at the healthy servers",
+			healthyServers:   true,
+			balancer:         servers,
+			expectedAttempts: 2,
+		},
+		{
+			desc:             "capped without any healthy server",
+			healthyServers:   true,
+			balancer:         fakeBalancer{},
+			expectedAttempts: 1,
+		},
+		{
+			desc:             "no load-balancer reported",
+			healthyServers:   true,
+			expectedAttempts: 5,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				calls++
+				if test.balancer != nil {
+					SetBalancer(req, test.balancer)
+				}
+				http.Error(rw, "attempt failed", http.StatusBadGateway)
+			})
+
+			retry, err := New(context.Background(), next, config.Retry{Attempts: 5, HealthyServersAttempts: test.healthyServers}, &countingRetryListener{}, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			retry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil))
+
+			assert.Equal(t, test.expectedAttempts, calls)
+			assert.Equal(t, http.StatusBadGateway, recorder.Code)
+			assert.Equal(t, "attempt failed\n", recorder.Body.String())
+		})
+	}
+}
+
+func TestRetryNetworkErrors(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	defer tlsServer.Close()
+
+	closedServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	closedServer.Close()
+
+	testCases := []struct {
+		desc             string
+		networkErrors    []string
+		serverURL        string
+		expectedAttempts int
+		expectedStatus   int
+	}{
+		{
+			desc:             "connection refused retried",
+			networkErrors:    []string{"connectionRefused"},
+			serverURL:        closedServer.URL,
+			expectedAttempts: 3,
+			expectedStatus:   http.StatusBadGateway,
+		},
+		{
+			desc:             "TLS verification failure not retried",
+			networkErrors:    []string{"connectionRefused"},
+			serverURL:        tlsServer.URL,
+			expectedAttempts: 1,
+			expectedStatus:   http.StatusInternalServerError,
+		},
+		{
+			desc:             "TLS verification failure retried",
+			networkErrors:    []string{"tls"},
+			serverURL:        tlsServer.URL,
+			expectedAttempts: 3,
+			expectedStatus:   http.StatusInternalServerError,
+		},
+		{
+			desc:             "connection refused not retried",
+			networkErrors:    []string{"tls"},
+			serverURL:        closedServer.URL,
+			expectedAttempts: 1,
+			expectedStatus:   http.StatusBadGateway,
+		},
+		{
+			desc:             "all errors retried by default",
+			serverURL:        tlsServer.URL,
+			expectedAttempts: 3,
+			expectedStatus:   http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			forwarder, err := forward.New(forward.ErrorHandler(utils.ErrorHandlerFunc(func(rw http.ResponseWriter, req *http.Request, err error) {
+				SetError(req, err)
+				utils.DefaultHandler.ServeHTTP(rw, req, err)
+			})))
+			require.NoError(t, err)
+
+			serverURL := testhelpers.MustParseURL(test.serverURL)
+
+			var calls int
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				calls++
+				req.URL = serverURL
+				forwarder.ServeHTTP(rw, req)
+			})
+
+			retry, err := New(context.Background(), next, config.Retry{Attempts: 3, NetworkErrors: test.networkErrors}, &countingRetryListener{}, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			retry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil))
+
+			assert.Equal(t, test.expectedAttempts, calls)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestRetryResponseErrors(t *testing.T) {
+	const (
+		closed      = ""
+		headersOnly = "HTTP/1.1 200 OK\r\nContent-Length: 10\r\n\r\n"
+		partialBody = "HTTP/1.1 200 OK\r\nContent-Length: 10\r\n\r\nOK"
+		ok          = "HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nOK"
+	)
+
+	testCases := []struct {
+		desc             string
+		config           config.Retry
+		method           string
+		responses        []string
+		expectedAttempts int
+		expectedStatus   int
+		expectedError    bool
+	}{
+		{
+			desc:             "server closing before writing retried",
+			config:           config.Retry{Attempts: 2, ResponseErrors: true},
+			method:           http.MethodGet,
+			responses:        []string{closed, ok},
+			expectedAttempts: 2,
+			expectedStatus:   http.StatusOK,
+		},
+		{
+			desc:             "server closing before writing not retried by default",
+			config:           config.Retry{Attempts: 2},
+			method:           http.MethodGet,
+			responses:        []string{closed, ok},
+			expectedAttempts: 1,
+			expectedStatus:   http.StatusBadGateway,
+		},
+		{
+			desc:             "server closing before the body retried",
+			config:           config.Retry{Attempts: 2, ResponseErrors: true},
+			method:           http.MethodGet,
+			responses:        []string{headersOnly, ok},
+			expectedAttempts: 2,
+			expectedStatus:   http.StatusOK,
+		},
+		{
+			desc:             "server closing after sending body bytes not retried",
+			config:           config.Retry{Attempts: 2, ResponseErrors: true},
+			method:           http.MethodGet,
+			responses:        []string{partialBody, ok},
+			expectedAttempts: 1,
+			expectedError:    true,
+		},
+		{
+			desc:             "attempts exhausted",
+			config:           config.Retry{Attempts: 2, ResponseErrors: true},
+			method:           http.MethodGet,
+			responses:        []string{closed, headersOnly},
+			expectedAttempts: 2,
+			expectedError:    true,
+		},
+		{
+			desc:             "last attempt failed",
+			config:           config.Retry{Attempts: 2, ResponseErrors: true},
+			method:           http.MethodGet,
+			responses:        []string{headersOnly, closed},
+			expectedAttempts: 2,
+			expectedStatus:   http.StatusBadGateway,
+		},
+		{
+			desc:             "non idempotent request not retried",
+			config:           config.Retry{Attempts: 2, ResponseErrors: true, Methods: []string{http.MethodPost}},
+			method:           http.MethodPost,
+			responses:        []string{closed, ok},
+			expectedAttempts: 1,
+			expectedStatus:   http.StatusBadGateway,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer listener.Close()
+
+			attempts := make(chan int, len(test.responses))
+			go func() {
+				for i := 0; ; i++ {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+
+					if _, err := http.ReadRequest(bufio.NewReader(conn)); err == nil && i < len(test.responses) {
+						attempts <- i + 1
+						_, _ = conn.Write([]byte(test.responses[i]))
+					}
+					_ = conn.Close()
+				}
+			}()
+
+			forwarder, err := forward.New(forward.RoundTripper(&http.Transport{DisableKeepAlives: true}))
+			require.NoError(t, err)
+
+			serverURL := testhelpers.MustParseURL("http://" + listener.Addr().String())
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				req.URL = serverURL
+				forwarder.ServeHTTP(rw, req)
+			})
+
+			retry, err := New(context.Background(), next, test.config, &countingRetryListener{}, "traefikTest")
+			require.NoError(t, err)
+
+			// The forwarder aborts the responses whose body cannot be read when it runs in a server.
+			server := httptest.NewServer(retry)
+			defer server.Close()
+
+			req, err := http.NewRequest(test.method, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err == nil {
+				var body []byte
+				body, err = ioutil.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+
+				if !test.expectedError {
+					require.NoError(t, err)
+					assert.Equal(t, test.expectedStatus, resp.StatusCode)
+					if test.expectedStatus == http.StatusOK {
+						assert.Equal(t, "OK", string(body))
+					}
+				}
+			}
+			if test.expectedError {
+				assert.Error(t, err)
+			}
+
+			assert.Len(t, attempts, test.expectedAttempts)
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	refused := &url.Error{Op: "Get", URL: "http://foo", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}
+
+	assert.True(t, isRetryable(refused, map[string]bool{"connectionRefused": true}))
+	assert.False(t, isRetryable(refused, map[string]bool{"connectionReset": true, "tls": true}))
+	assert.True(t, isRetryable(x509.UnknownAuthorityError{}, map[string]bool{"tls": true}))
+	assert.True(t, isRetryable(&net.DNSError{Err: "no such host", Name: "foo"}, map[string]bool{"dns": true}))
+	assert.False(t, isRetryable(errors.New("foo"), map[string]bool{"connectionRefused": true}))
+}
+
+func TestRetryTimeout(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+		http.Error(rw, "attempt failed", http.StatusBadGateway)
+	})
+
+	retryListener := &countingRetryListener{}
+	retry, err := New(context.Background(), next, config.Retry{Attempts: 100, PerTryTimeout: "30ms", Timeout: "100ms"}, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil)
+
+	start := time.Now()
+	retry.ServeHTTP(recorder, req)
+
+	assert.True(t, time.Since(start) < time.Second, "attempts were not bounded by the overall timeout")
+	assert.True(t, retryListener.timesCalled < 5, "too many retries: %d", retryListener.timesCalled)
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.Equal(t, "attempt failed\n", recorder.Body.String())
+}
+
+func TestRetryCanceledRequest(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+	})
+
+	retryListener := &countingRetryListener{}
+	retry, err := New(context.Background(), next, config.Retry{Attempts: 3}, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil).WithContext(ctx)
+
+	retry.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.Equal(t, 0, retryListener.timesCalled)
+}
+
 func TestRetryListeners(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	retryListeners := Listeners{&countingRetryListener{}, &countingRetryListener{}}
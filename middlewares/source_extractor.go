@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/containous/traefik/ip"
+	"github.com/vulcand/oxy/utils"
+)
+
+// NewSourceExtractor creates the source extractor of the limiting middlewares.
+// The client IP source is selected with the IP strategy, as for the other middlewares.
+func NewSourceExtractor(variable string, strategy ip.Strategy) (utils.SourceExtractor, error) {
+	if variable != "client.ip" {
+		return utils.NewExtractor(variable)
+	}
+
+	return utils.ExtractorFunc(func(req *http.Request) (string, int64, error) {
+		clientIP := ip.ClientIP(strategy, req)
+		if len(clientIP) == 0 {
+			return "", 0, fmt.Errorf("failed to get client IP: %v", req.RemoteAddr)
+		}
+		return clientIP, 1, nil
+	}), nil
+}
@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/ip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSourceExtractor(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		variable      string
+		strategy      ip.Strategy
+		expected      string
+		expectedError bool
+	}{
+		{
+			desc:     "client IP with remote address strategy",
+			variable: "client.ip",
+			strategy: &ip.RemoteAddrStrategy{},
+			expected: "10.0.0.1",
+		},
+		{
+			desc:     "client IP with depth strategy",
+			variable: "client.ip",
+			strategy: &ip.DepthStrategy{Depth: 1},
+			expected: "192.0.2.2",
+		},
+		{
+			desc:          "client IP not found",
+			variable:      "client.ip",
+			strategy:      &ip.DepthStrategy{Depth: 3},
+			expectedError: true,
+		},
+		{
+			desc:     "request host",
+			variable: "request.host",
+			strategy: &ip.DepthStrategy{Depth: 1},
+			expected: "foo",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			extractor, err := NewSourceExtractor(test.variable, test.strategy)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set("X-Forwarded-For", "192.0.2.1, 192.0.2.2")
+
+			source, amount, err := extractor.Extract(req)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, source)
+			assert.EqualValues(t, 1, amount)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package statuscoderewrite
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "StatusCodeRewrite"
+)
+
+// statusCodeRewrite is a middleware that replaces the status codes of the responses, leaving their body intact.
+type statusCodeRewrite struct {
+	next     http.Handler
+	rewrites map[int]int
+	name     string
+}
+
+// New creates a middleware rewriting the status codes of the responses.
+func New(ctx context.Context, next http.Handler, config config.StatusCodeRewrite, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug("Creating middleware")
+
+	if len(config.Rewrites) == 0 {
+		return nil, errors.New("no status code to rewrite")
+	}
+
+	rewrites := make(map[int]int)
+	for _, rewrite := range config.Rewrites {
+		if !isValidStatusCode(rewrite.From) || !isValidStatusCode(rewrite.To) {
+			return nil, fmt.Errorf("invalid rewrite from %d to %d: the status codes must be between 200 and 599", rewrite.From, rewrite.To)
+		}
+		if _, exists := rewrites[rewrite.From]; exists {
+			return nil, fmt.Errorf("duplicated rewrite of the status code %d", rewrite.From)
+		}
+		rewrites[rewrite.From] = rewrite.To
+	}
+
+	logger.Debugf("Status code rewrites: %v", rewrites)
+
+	return &statusCodeRewrite{
+		next:     next,
+		rewrites: rewrites,
+		name:     name,
+	}, nil
+}
+
+func isValidStatusCode(code int) bool {
+	return code >= http.StatusOK && code <= 599
+}
+
+func (s *statusCodeRewrite) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return s.name, tracing.SpanKindNoneEnum
+}
+
+func (s *statusCodeRewrite) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.next.ServeHTTP(newRewriteResponseWriter(rw, s.rewrites), req)
+}
+
+type rewriteResponseWriter interface {
+	http.ResponseWriter
+	http.Hijacker
+	http.Flusher
+}
+
+type rewriteResponseWriterWithoutCloseNotify struct {
+	rw          http.ResponseWriter
+	rewrites    map[int]int
+	wroteHeader bool
+}
+
+func (r *rewriteResponseWriterWithoutCloseNotify) Header() http.Header {
+	return r.rw.Header()
+}
+
+// WriteHeader writes the replacement of the status code, if any.
+func (r *rewriteResponseWriterWithoutCloseNotify) WriteHeader(code int) {
+	if r.wroteHeader {
+		return
+	}
+
+	// The informational responses are not final, so they are written as is.
+	if code >= http.StatusOK {
+		r.wroteHeader = true
+		if rewritten, ok := r.rewrites[code]; ok {
+			code = rewritten
+		}
+	}
+
+	r.rw.WriteHeader(code)
+}
+
+func (r *rewriteResponseWriterWithoutCloseNotify) Write(buf []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.rw.Write(buf)
+}
+
+// Hijack hijacks the connection.
+func (r *rewriteResponseWriterWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.rw)
+	}
+	return hijacker.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (r *rewriteResponseWriterWithoutCloseNotify) Flush() {
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type rewriteResponseWriterWithCloseNotify struct {
+	*rewriteResponseWriterWithoutCloseNotify
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (r *rewriteResponseWriterWithCloseNotify) CloseNotify() <-chan bool {
+	return r.rw.(http.CloseNotifier).CloseNotify()
+}
+
+func newRewriteResponseWriter(rw http.ResponseWriter, rewrites map[int]int) rewriteResponseWriter {
+	writer := &rewriteResponseWriterWithoutCloseNotify{rw: rw, rewrites: rewrites}
+	if _, ok := rw.(http.CloseNotifier); ok {
+		return &rewriteResponseWriterWithCloseNotify{writer}
+	}
+	return writer
+}
@@ -0,0 +1,116 @@
+package statuscoderewrite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStatusCodeRewrite(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.StatusCodeRewrite
+		expectedError bool
+	}{
+		{
+			desc:   "rewrites",
+			config: config.StatusCodeRewrite{Rewrites: []config.StatusCodeMapping{{From: 418, To: 200}, {From: 502, To: 503}}},
+		},
+		{
+			desc:          "no rewrite",
+			config:        config.StatusCodeRewrite{},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid status code",
+			config:        config.StatusCodeRewrite{Rewrites: []config.StatusCodeMapping{{From: 418, To: 600}}},
+			expectedError: true,
+		},
+		{
+			desc:          "informational status code",
+			config:        config.StatusCodeRewrite{Rewrites: []config.StatusCodeMapping{{From: 100, To: 200}}},
+			expectedError: true,
+		},
+		{
+			desc:          "duplicated rewrite",
+			config:        config.StatusCodeRewrite{Rewrites: []config.StatusCodeMapping{{From: 418, To: 200}, {From: 418, To: 204}}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, handler)
+			}
+		})
+	}
+}
+
+func TestStatusCodeRewrite_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		backendCode    int
+		expectedStatus int
+	}{
+		{
+			desc:           "rewritten status code",
+			backendCode:    http.StatusTeapot,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "implicit status code",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			desc:           "other status code",
+			backendCode:    http.StatusNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("X-Backend", "legacy")
+				if test.backendCode != 0 {
+					rw.WriteHeader(test.backendCode)
+				}
+				fmt.Fprint(rw, "I'm a teapot")
+			})
+
+			handler, err := New(context.Background(), next, config.StatusCodeRewrite{
+				Rewrites: []config.StatusCodeMapping{
+					{From: http.StatusTeapot, To: http.StatusOK},
+					{From: http.StatusOK, To: http.StatusNoContent},
+				},
+			}, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, "legacy", recorder.Header().Get("X-Backend"))
+			assert.Equal(t, "I'm a teapot", recorder.Body.String())
+		})
+	}
+}
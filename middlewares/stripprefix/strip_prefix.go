@@ -21,14 +21,25 @@ const (
 type stripPrefix struct {
 	next     http.Handler
 	prefixes []string
+	header   string
 	name     string
 }
 
 // New creates a new strip prefix middleware.
 func New(ctx context.Context, next http.Handler, config config.StripPrefix, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	header := ForwardedPrefixHeader
+	if config.ForwardedPrefixHeader != "" {
+		header = http.CanonicalHeaderKey(strings.TrimSpace(config.ForwardedPrefixHeader))
+	}
+	if config.DisableForwardedPrefixHeader {
+		header = ""
+	}
+
 	return &stripPrefix{
 		prefixes: config.Prefixes,
+		header:   header,
 		next:     next,
 		name:     name,
 	}, nil
@@ -53,7 +64,9 @@ func (s *stripPrefix) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 }
 
 func (s *stripPrefix) serveRequest(rw http.ResponseWriter, req *http.Request, prefix string) {
-	req.Header.Add(ForwardedPrefixHeader, prefix)
+	if s.header != "" {
+		req.Header.Add(s.header, prefix)
+	}
 	req.RequestURI = req.URL.RequestURI()
 	s.next.ServeHTTP(rw, req)
 }
@@ -167,3 +167,47 @@ func TestStripPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestStripPrefix_ForwardedPrefixHeader(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		config          config.StripPrefix
+		expectedHeaders http.Header
+	}{
+		{
+			desc:            "default header",
+			config:          config.StripPrefix{Prefixes: []string{"/stat"}},
+			expectedHeaders: http.Header{ForwardedPrefixHeader: {"/stat"}},
+		},
+		{
+			desc:            "custom header",
+			config:          config.StripPrefix{Prefixes: []string{"/stat"}, ForwardedPrefixHeader: "x-script-name"},
+			expectedHeaders: http.Header{"X-Script-Name": {"/stat"}},
+		},
+		{
+			desc:            "disabled header",
+			config:          config.StripPrefix{Prefixes: []string{"/stat"}, ForwardedPrefixHeader: "X-Script-Name", DisableForwardedPrefixHeader: true},
+			expectedHeaders: http.Header{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var actualHeaders http.Header
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actualHeaders = r.Header
+			})
+
+			handler, err := New(context.Background(), next, test.config, "foo-strip-prefix")
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost/stat/foo", nil)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, test.expectedHeaders, actualHeaders)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+type disabler struct {
+	next http.Handler
+}
+
+// NewDisabler creates a middleware which drops the trace of the requests: the span of the entry point is not sampled.
+// The middlewares after it must be built WithTracingDisabled, so that they do not create their own spans.
+func NewDisabler(next http.Handler) http.Handler {
+	return &disabler{next: next}
+}
+
+func (d *disabler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if span := tracing.GetSpan(req); span != nil {
+		ext.SamplingPriority.Set(span, 0)
+	}
+
+	d.next.ServeHTTP(rw, req)
+}
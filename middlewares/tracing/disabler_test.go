@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisabler(t *testing.T) {
+	span := &MockSpan{Tags: make(map[string]interface{})}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "http://www.test.com/health", nil)
+	req = req.WithContext(opentracing.ContextWithSpan(req.Context(), span))
+
+	recorder := httptest.NewRecorder()
+	NewDisabler(next).ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, uint16(0), span.Tags[string(ext.SamplingPriority)])
+}
+
+func TestWrap_TracingDisabled(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+	constructor := func(next http.Handler) (http.Handler, error) {
+		return &tracableHandler{next: next}, nil
+	}
+
+	handler, err := Wrap(context.Background(), constructor)(next)
+	require.NoError(t, err)
+	assert.IsType(t, &Wrapper{}, handler)
+
+	handler, err = Wrap(WithTracingDisabled(context.Background()), constructor)(next)
+	require.NoError(t, err)
+	assert.IsType(t, &tracableHandler{}, handler)
+}
+
+type tracableHandler struct {
+	next http.Handler
+}
+
+func (t *tracableHandler) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return "tracable", ext.SpanKindRPCServerEnum
+}
+
+func (t *tracableHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	t.next.ServeHTTP(rw, req)
+}
@@ -2,10 +2,13 @@ package tracing
 
 import (
 	"context"
+	"encoding/hex"
 	"net/http"
 
 	"github.com/containous/alice"
+	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/middlewares/accesslog"
 	"github.com/containous/traefik/tracing"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
@@ -33,7 +36,22 @@ type entryPointMiddleware struct {
 }
 
 func (e *entryPointMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	spanCtx, _ := e.Extract(opentracing.HTTPHeaders, tracing.HTTPHeadersCarrier(req.Header))
+	var spanCtx opentracing.SpanContext
+
+	var incoming *tracing.TraceContext
+	if e.PropagatesW3C() {
+		tc, err := tracing.ParseTraceContext(req.Header)
+		if err == nil {
+			incoming = &tc
+			spanCtx = e.SpanContextFromTraceContext(tc)
+		} else if req.Header.Get(tracing.TraceParentHeader) != "" {
+			log.FromContext(req.Context()).Debugf("Starting a new trace: %v", err)
+		}
+	}
+
+	if spanCtx == nil && e.PropagatesVendor() {
+		spanCtx, _ = e.Extract(opentracing.HTTPHeaders, tracing.HTTPHeadersCarrier(req.Header))
+	}
 
 	span, req, finish := e.StartSpanf(req, ext.SpanKindRPCServerEnum, "EntryPoint", []string{e.entryPoint, req.Host}, " ", ext.RPCServerOption(spanCtx))
 	defer finish()
@@ -43,12 +61,59 @@ func (e *entryPointMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Reque
 
 	req = req.WithContext(tracing.WithTracing(req.Context(), e.Tracing))
 
+	traceID, spanID := tracing.GetSpanIDs(span)
+
+	if e.PropagatesW3C() {
+		tc := e.traceContext(req, span, incoming)
+		req = req.WithContext(tracing.WithTraceContext(req.Context(), tc))
+
+		if traceID == "" {
+			traceID, spanID = hex.EncodeToString(tc.TraceID[:]), hex.EncodeToString(tc.SpanID[:])
+		}
+	}
+
+	if logData := accesslog.GetLogData(req); logData != nil && traceID != "" {
+		logData.Core[accesslog.TraceID] = traceID
+		logData.Core[accesslog.SpanID] = spanID
+	}
+
 	recorder := newStatusCodeRecoder(rw, http.StatusOK)
 	e.next.ServeHTTP(recorder, req)
 
 	tracing.LogResponseCode(span, recorder.Status())
 }
 
+// traceContext returns the W3C Trace Context of the entry point span, which continues the incoming Trace Context if any.
+// For a tracing backend which cannot continue the incoming Trace Context,
+// the sampling decision of the incoming Trace Context is applied to the span, and the Trace Context is forwarded as is.
+func (e *entryPointMiddleware) traceContext(req *http.Request, span opentracing.Span, incoming *tracing.TraceContext) tracing.TraceContext {
+	parent := tracing.TraceContext{Sampled: true}
+	if incoming != nil {
+		parent = *incoming
+	} else if sc, ok := span.Context().(interface{ IsSampled() bool }); ok {
+		parent.Sampled = sc.IsSampled()
+	}
+
+	if tc, ok := tracing.TraceContextFromSpan(span, parent); ok {
+		return tc
+	}
+
+	if incoming != nil {
+		if incoming.Sampled {
+			ext.SamplingPriority.Set(span, 1)
+		} else {
+			ext.SamplingPriority.Set(span, 0)
+		}
+		return *incoming
+	}
+
+	tc, err := tracing.NewTraceContext(parent.Sampled)
+	if err != nil {
+		log.FromContext(req.Context()).Errorf("Unable to create a trace context: %v", err)
+	}
+	return tc
+}
+
 // WrapEntryPointHandler Wraps tracing to alice.Constructor.
 func WrapEntryPointHandler(ctx context.Context, tracer *tracing.Tracing, entryPointName string) alice.Constructor {
 	return func(next http.Handler) (http.Handler, error) {
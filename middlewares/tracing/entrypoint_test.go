@@ -2,14 +2,23 @@ package tracing
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/containous/traefik/middlewares/accesslog"
 	"github.com/containous/traefik/tracing"
+	"github.com/containous/traefik/types"
+	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
 )
 
 func TestEntryPointMiddleware(t *testing.T) {
@@ -66,7 +75,7 @@ func TestEntryPointMiddleware(t *testing.T) {
 	for _, test := range testCases {
 		t.Run(test.desc, func(t *testing.T) {
 
-			newTracing, err := tracing.NewTracing("", test.spanNameLimit, test.tracing)
+			newTracing, err := tracing.NewTracing("", test.spanNameLimit, "", test.tracing)
 			require.NoError(t, err)
 
 			req := httptest.NewRequest(http.MethodGet, "http://www.test.com", nil)
@@ -85,3 +94,78 @@ func TestEntryPointMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestEntryPointMiddleware_AccessLogIDs(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		sampled     bool
+		propagation string
+		tracer      func() opentracing.Tracer
+	}{
+		{
+			desc:    "sampled",
+			sampled: true,
+		},
+		{
+			desc: "sampled out",
+		},
+		{
+			desc:        "trace context of an unknown backend",
+			propagation: tracing.PropagationW3C,
+			tracer: func() opentracing.Tracer {
+				return &MockTracer{Span: &MockSpan{Tags: make(map[string]interface{})}}
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			tmpDir, err := ioutil.TempDir("", "traefik-tracing")
+			require.NoError(t, err)
+			defer os.RemoveAll(tmpDir)
+
+			logFilePath := filepath.Join(tmpDir, "access.log")
+
+			var tracer opentracing.Tracer
+			if test.tracer != nil {
+				tracer = test.tracer()
+			} else {
+				jaegerTracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(test.sampled), jaeger.NewNullReporter())
+				defer closer.Close()
+				tracer = jaegerTracer
+			}
+
+			newTracing, err := tracing.NewTracing("", 0, test.propagation, &trackingBackenMock{tracer: tracer})
+			require.NoError(t, err)
+
+			var expectedTraceID, expectedSpanID string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if tc, ok := tracing.TraceContextFromContext(req.Context()); ok {
+					expectedTraceID, expectedSpanID = hex.EncodeToString(tc.TraceID[:]), hex.EncodeToString(tc.SpanID[:])
+					return
+				}
+
+				sc := tracing.GetSpan(req).Context().(jaeger.SpanContext)
+				assert.Equal(t, test.sampled, sc.IsSampled())
+				expectedTraceID, expectedSpanID = sc.TraceID().String(), sc.SpanID().String()
+			})
+
+			accessLog, err := accesslog.NewHandler(&types.AccessLog{FilePath: logFilePath, Format: accesslog.JSONFormat}, nil)
+			require.NoError(t, err)
+
+			handler := NewEntryPoint(context.Background(), newTracing, "test", next)
+			accessLog.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://www.test.com", nil), handler.ServeHTTP)
+			require.NoError(t, accessLog.Close())
+
+			logData, err := ioutil.ReadFile(logFilePath)
+			require.NoError(t, err)
+
+			jsonData := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal(logData, &jsonData))
+
+			require.NotEmpty(t, expectedTraceID)
+			assert.Equal(t, expectedTraceID, jsonData[accesslog.TraceID])
+			assert.Equal(t, expectedSpanID, jsonData[accesslog.SpanID])
+		})
+	}
+}
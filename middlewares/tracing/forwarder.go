@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/middlewares"
 	"github.com/containous/traefik/tracing"
 	"github.com/opentracing/opentracing-go/ext"
@@ -48,7 +49,23 @@ func (f *forwarderMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Reques
 	ext.HTTPUrl.Set(span, req.URL.String())
 	span.SetTag("http.host", req.Host)
 
-	tracing.InjectRequestHeaders(req)
+	if tr.PropagatesVendor() {
+		tracing.InjectRequestHeaders(req)
+	}
+
+	if tc, ok := tracing.TraceContextFromContext(req.Context()); ok {
+		// The parent of the forwarded request is the forward span, unless the tracing backend is unknown.
+		child, ok := tracing.TraceContextFromSpan(span, tc)
+		if !ok {
+			child, err = tc.Child()
+		}
+
+		if err != nil {
+			log.FromContext(req.Context()).Errorf("Unable to create a trace context: %v", err)
+		} else {
+			child.Inject(req.Header)
+		}
+	}
 
 	recorder := newStatusCodeRecoder(rw, 200)
 
@@ -110,7 +110,7 @@ func TestNewForwarder(t *testing.T) {
 	for _, test := range testCases {
 		t.Run(test.desc, func(t *testing.T) {
 
-			newTracing, err := tracing.NewTracing("", test.spanNameLimit, test.tracing)
+			newTracing, err := tracing.NewTracing("", test.spanNameLimit, "", test.tracing)
 			require.NoError(t, err)
 
 			req := httptest.NewRequest(http.MethodGet, "http://www.test.com/toto", nil)
@@ -0,0 +1,171 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+)
+
+func TestTraceContextPropagation(t *testing.T) {
+	testCases := []struct {
+		desc                     string
+		propagation              string
+		traceParent              string
+		traceState               string
+		expectedTraceParent      string
+		expectedTraceState       string
+		expectedSamplingPriority interface{}
+	}{
+		{
+			desc:                     "incoming sampled trace is continued",
+			propagation:              tracing.PropagationW3C,
+			traceParent:              "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			traceState:               "congo=t61rcWkgMzE",
+			expectedTraceParent:      `^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01$`,
+			expectedTraceState:       "congo=t61rcWkgMzE",
+			expectedSamplingPriority: uint16(1),
+		},
+		{
+			desc:                     "incoming not sampled trace is continued",
+			propagation:              tracing.PropagationAll,
+			traceParent:              "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			expectedTraceParent:      `^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-00$`,
+			expectedSamplingPriority: uint16(0),
+		},
+		{
+			desc:                "absent trace is generated",
+			propagation:         tracing.PropagationW3C,
+			expectedTraceParent: `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`,
+		},
+		{
+			desc:                "invalid trace is replaced",
+			propagation:         tracing.PropagationW3C,
+			traceParent:         "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			traceState:          "congo=t61rcWkgMzE",
+			expectedTraceParent: `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`,
+		},
+		{
+			desc:                "vendor propagation only",
+			propagation:         tracing.PropagationVendor,
+			traceParent:         "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expectedTraceParent: `^00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01$`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			backend := &trackingBackenMock{
+				tracer: &MockTracer{Span: &MockSpan{Tags: make(map[string]interface{})}},
+			}
+
+			newTracing, err := tracing.NewTracing("", 0, test.propagation, backend)
+			require.NoError(t, err)
+
+			var forwarded *http.Request
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req
+			})
+
+			handler := NewEntryPoint(context.Background(), newTracing, "web", NewForwarder(context.Background(), "router", "service", next))
+
+			req := httptest.NewRequest(http.MethodGet, "http://www.test.com", nil)
+			if test.traceParent != "" {
+				req.Header.Set(tracing.TraceParentHeader, test.traceParent)
+			}
+			if test.traceState != "" {
+				req.Header.Set(tracing.TraceStateHeader, test.traceState)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.NotNil(t, forwarded)
+			assert.Regexp(t, test.expectedTraceParent, forwarded.Header.Get(tracing.TraceParentHeader))
+			assert.Equal(t, test.expectedTraceState, forwarded.Header.Get(tracing.TraceStateHeader))
+
+			assert.Equal(t, test.expectedSamplingPriority, backend.tracer.(*MockTracer).Span.Tags[string(ext.SamplingPriority)])
+		})
+	}
+}
+
+func TestTraceContextPropagation_Jaeger(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		traceParent     string
+		expectedTraceID string
+		expectedSampled bool
+	}{
+		{
+			desc:            "incoming sampled trace is continued by the spans",
+			traceParent:     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expectedTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedSampled: true,
+		},
+		{
+			desc:            "incoming not sampled trace is continued by the spans",
+			traceParent:     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			expectedTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			desc:            "absent trace is the one of the spans",
+			expectedSampled: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			tracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+			defer closer.Close()
+
+			newTracing, err := tracing.NewTracing("", 0, tracing.PropagationW3C, &trackingBackenMock{tracer: tracer})
+			require.NoError(t, err)
+
+			var forwarded *http.Request
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req
+			})
+
+			handler := NewEntryPoint(context.Background(), newTracing, "web", NewForwarder(context.Background(), "router", "service", next))
+
+			req := httptest.NewRequest(http.MethodGet, "http://www.test.com", nil)
+			if test.traceParent != "" {
+				req.Header.Set(tracing.TraceParentHeader, test.traceParent)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.NotNil(t, forwarded)
+
+			sc, ok := tracing.GetSpan(forwarded).Context().(jaeger.SpanContext)
+			require.True(t, ok)
+			assert.Equal(t, test.expectedSampled, sc.IsSampled())
+
+			traceID := fmt.Sprintf("%016x%016x", sc.TraceID().High, sc.TraceID().Low)
+			if test.expectedTraceID != "" {
+				assert.Equal(t, test.expectedTraceID, traceID)
+			}
+
+			flags := "00"
+			if test.expectedSampled {
+				flags = "01"
+			}
+
+			// The forward span is the parent of the forwarded request.
+			expected := fmt.Sprintf("00-%s-%016x-%s", traceID, uint64(sc.SpanID()), flags)
+			assert.Equal(t, expected, forwarded.Header.Get(tracing.TraceParentHeader))
+			assert.Empty(t, forwarded.Header.Get("Uber-Trace-Id"))
+		})
+	}
+}
+
+func TestNewTracing_InvalidPropagation(t *testing.T) {
+	_, err := tracing.NewTracing("", 0, "b3", &trackingBackenMock{tracer: &MockTracer{Span: &MockSpan{}}})
+	assert.Error(t, err)
+}
@@ -10,6 +10,20 @@ import (
 	"github.com/opentracing/opentracing-go/ext"
 )
 
+type contextKey int
+
+const disabledKey contextKey = iota
+
+// WithTracingDisabled returns a context in which Wrap does not add tracing to the middlewares.
+func WithTracingDisabled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, disabledKey, true)
+}
+
+func isTracingDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(disabledKey).(bool)
+	return disabled
+}
+
 // Tracable embeds tracing information.
 type Tracable interface {
 	GetTracingInformation() (name string, spanKind ext.SpanKindEnum)
@@ -17,6 +31,10 @@ type Tracable interface {
 
 // Wrap adds tracability to an alice.Constructor.
 func Wrap(ctx context.Context, constructor alice.Constructor) alice.Constructor {
+	if isTracingDisabled(ctx) {
+		return constructor
+	}
+
 	return func(next http.Handler) (http.Handler, error) {
 		if constructor == nil {
 			return nil, nil
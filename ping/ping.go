@@ -4,15 +4,23 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync/atomic"
+	"time"
 
+	"github.com/containous/flaeg/parse"
 	"github.com/containous/mux"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
 )
 
 // Handler expose ping routes.
 type Handler struct {
-	EntryPoint  string   `description:"Ping entryPoint" export:"true"`
-	Middlewares []string `description:"Middleware list" export:"true"`
-	terminating bool
+	EntryPoint           string         `description:"Ping entryPoint" export:"true"`
+	Middlewares          []string       `description:"Middleware list" export:"true"`
+	WaitForConfiguration bool           `description:"Serve non 200 responses until a first configuration from a provider is loaded" export:"true"`
+	MaxWait              parse.Duration `description:"Maximum duration of the wait for the first configuration, unlimited if zero" export:"true"`
+	terminating          bool
+	configured           int32
 }
 
 // WithContext causes the ping endpoint to serve non 200 responses.
@@ -21,6 +29,29 @@ func (h *Handler) WithContext(ctx context.Context) {
 		<-ctx.Done()
 		h.terminating = true
 	}()
+
+	if h.WaitForConfiguration && h.MaxWait > 0 {
+		go func() {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(h.MaxWait)):
+				if !h.isConfigured() {
+					log.WithoutContext().Warnf("No configuration loaded after %s, the ping endpoint is ready anyway", time.Duration(h.MaxWait))
+					atomic.StoreInt32(&h.configured, 1)
+				}
+			}
+		}()
+	}
+}
+
+// ListenConfiguration is called when a configuration from a provider is loaded,
+// which makes the ping endpoint ready when waiting for the first configuration.
+func (h *Handler) ListenConfiguration(_ config.Configuration) {
+	atomic.StoreInt32(&h.configured, 1)
+}
+
+func (h *Handler) isConfigured() bool {
+	return atomic.LoadInt32(&h.configured) == 1
 }
 
 // Append adds ping routes on a router.
@@ -28,7 +59,7 @@ func (h *Handler) Append(router *mux.Router) {
 	router.Methods(http.MethodGet, http.MethodHead).Path("/ping").
 		HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
 			statusCode := http.StatusOK
-			if h.terminating {
+			if h.terminating || h.WaitForConfiguration && !h.isConfigured() {
 				statusCode = http.StatusServiceUnavailable
 			}
 			response.WriteHeader(statusCode)
@@ -0,0 +1,81 @@
+package ping
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/mux"
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_WaitForConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		handler              *Handler
+		configured           bool
+		wait                 time.Duration
+		expectedStatusBefore int
+		expectedStatusAfter  int
+	}{
+		{
+			desc:                 "no wait",
+			handler:              &Handler{},
+			expectedStatusBefore: http.StatusOK,
+			expectedStatusAfter:  http.StatusOK,
+		},
+		{
+			desc:                 "first configuration",
+			handler:              &Handler{WaitForConfiguration: true},
+			configured:           true,
+			expectedStatusBefore: http.StatusServiceUnavailable,
+			expectedStatusAfter:  http.StatusOK,
+		},
+		{
+			desc:                 "no configuration",
+			handler:              &Handler{WaitForConfiguration: true},
+			wait:                 50 * time.Millisecond,
+			expectedStatusBefore: http.StatusServiceUnavailable,
+			expectedStatusAfter:  http.StatusServiceUnavailable,
+		},
+		{
+			desc:                 "maximum wait",
+			handler:              &Handler{WaitForConfiguration: true, MaxWait: parse.Duration(20 * time.Millisecond)},
+			wait:                 200 * time.Millisecond,
+			expectedStatusBefore: http.StatusServiceUnavailable,
+			expectedStatusAfter:  http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			test.handler.WithContext(ctx)
+
+			router := mux.NewRouter()
+			test.handler.Append(router)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ping", nil))
+			assert.Equal(t, test.expectedStatusBefore, recorder.Code)
+
+			if test.configured {
+				test.handler.ListenConfiguration(config.Configuration{})
+			}
+			time.Sleep(test.wait)
+
+			recorder = httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ping", nil))
+			assert.Equal(t, test.expectedStatusAfter, recorder.Code)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package acme
 
 import (
 	"crypto/tls"
+	"sync"
+	"time"
 
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/types"
@@ -9,10 +11,15 @@ import (
 	"github.com/xenolf/lego/challenge/tlsalpn01"
 )
 
+// tlsChallengeCacheTTL is how long a temp certificate read from the store is served without reading the store again.
+// It is kept short, so that the instances which are not the leader do not serve the certificate of a former challenge.
+const tlsChallengeCacheTTL = 10 * time.Second
+
 var _ challenge.Provider = (*challengeTLSALPN)(nil)
 
 type challengeTLSALPN struct {
 	Store Store
+	cache *tlsChallengeCache
 }
 
 func (c *challengeTLSALPN) Present(domain, token, keyAuth string) error {
@@ -25,21 +32,41 @@ func (c *challengeTLSALPN) Present(domain, token, keyAuth string) error {
 	}
 
 	cert := &Certificate{Certificate: certPEMBlock, Key: keyPEMBlock, Domain: types.Domain{Main: "TEMP-" + domain}}
-	return c.Store.AddTLSChallenge(domain, cert)
+	if err := c.Store.AddTLSChallenge(domain, cert); err != nil {
+		return err
+	}
+
+	certificate, err := tls.X509KeyPair(certPEMBlock, keyPEMBlock)
+	if err != nil {
+		return err
+	}
+
+	c.cache.set(domain, &certificate)
+	return nil
 }
 
 func (c *challengeTLSALPN) CleanUp(domain, token, keyAuth string) error {
 	log.WithoutContext().WithField(log.ProviderName, "acme").
 		Debugf("TLS Challenge CleanUp temp certificate for %s", domain)
 
+	c.cache.remove(domain)
 	return c.Store.RemoveTLSChallenge(domain)
 }
 
 // GetTLSALPNCertificate Get the temp certificate for ACME TLS-ALPN-O1 challenge.
+// The certificates are cached, and an error of the store is handled as a missing certificate,
+// to not fail the TLS handshakes when the KV store of the cluster is unavailable.
 func (p *Provider) GetTLSALPNCertificate(domain string) (*tls.Certificate, error) {
+	if certificate := p.tlsChallenges.get(domain); certificate != nil {
+		return certificate, nil
+	}
+
+	logger := log.WithoutContext().WithField(log.ProviderName, "acme")
+
 	cert, err := p.Store.GetTLSChallenge(domain)
 	if err != nil {
-		return nil, err
+		logger.Errorf("Unable to get the TLS challenge certificate for %s: %v", domain, err)
+		return nil, nil
 	}
 
 	if cert == nil {
@@ -51,5 +78,51 @@ func (p *Provider) GetTLSALPNCertificate(domain string) (*tls.Certificate, error
 		return nil, err
 	}
 
+	p.tlsChallenges.set(domain, &certificate)
 	return &certificate, nil
 }
+
+type cachedTLSChallenge struct {
+	certificate *tls.Certificate
+	expiration  time.Time
+}
+
+// tlsChallengeCache holds the temp certificates of the ACME TLS-ALPN-01 challenges,
+// to spare reading the store at each TLS handshake of the ACME server.
+type tlsChallengeCache struct {
+	lock  sync.Mutex
+	certs map[string]cachedTLSChallenge
+}
+
+func (c *tlsChallengeCache) get(domain string) *tls.Certificate {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	cached, ok := c.certs[domain]
+	if !ok {
+		return nil
+	}
+
+	if time.Now().After(cached.expiration) {
+		delete(c.certs, domain)
+		return nil
+	}
+	return cached.certificate
+}
+
+func (c *tlsChallengeCache) set(domain string, certificate *tls.Certificate) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.certs == nil {
+		c.certs = make(map[string]cachedTLSChallenge)
+	}
+	c.certs[domain] = cachedTLSChallenge{certificate: certificate, expiration: time.Now().Add(tlsChallengeCacheTTL)}
+}
+
+func (c *tlsChallengeCache) remove(domain string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.certs, domain)
+}
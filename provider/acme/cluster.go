@@ -0,0 +1,157 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/abronan/valkeyrie"
+	"github.com/abronan/valkeyrie/store"
+	"github.com/abronan/valkeyrie/store/consul"
+	etcdv3 "github.com/abronan/valkeyrie/store/etcd/v3"
+	"github.com/abronan/valkeyrie/store/zookeeper"
+	"github.com/containous/traefik/cluster"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/old/types"
+	"github.com/containous/traefik/safe"
+)
+
+// clusterRefreshInterval is the interval at which the instances which are not the leader reload the certificates of the KV store.
+const clusterRefreshInterval = time.Minute
+
+func init() {
+	consul.Register()
+	etcdv3.Register()
+	zookeeper.Register()
+}
+
+// Cluster contains the configuration of the KV store shared by the instances,
+// in which they elect the leader performing the ACME operations.
+type Cluster struct {
+	Node      string   `description:"Name of the instance in the election. Defaults to the hostname"`
+	Backend   string   `description:"KV store backend: consul, etcdv3 or zk"`
+	Endpoints []string `description:"KV store endpoints"`
+	Prefix    string   `description:"Prefix of the keys in the KV store. Defaults to traefik"`
+}
+
+// InitCluster connects to the KV store of the cluster, which then holds the ACME data,
+// and sets up the election of the leader.
+func (p *Provider) InitCluster() error {
+	if p.Cluster == nil {
+		return errors.New("no cluster configuration found for the ACME provider")
+	}
+
+	if len(p.Cluster.Endpoints) == 0 {
+		return errors.New("no endpoint found for the KV store of the cluster")
+	}
+
+	node := p.Cluster.Node
+	if len(node) == 0 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("unable to get the node name of the instance: %v", err)
+		}
+		node = hostname
+	}
+
+	prefix := p.Cluster.Prefix
+	if len(prefix) == 0 {
+		prefix = "traefik"
+	}
+
+	kv, err := valkeyrie.NewStore(store.Backend(p.Cluster.Backend), p.Cluster.Endpoints, &store.Config{ConnectionTimeout: 30 * time.Second})
+	if err != nil {
+		return fmt.Errorf("unable to connect to the KV store of the cluster: %v", err)
+	}
+
+	p.Store = NewKVStore(kv, prefix+"/acme")
+	p.Leadership = cluster.NewLeadership(context.Background(), &types.Cluster{
+		Node:  node,
+		Store: &types.Store{Store: kv, Prefix: prefix + "/acme"},
+	})
+
+	return nil
+}
+
+// isLeader returns true if the instance performs the ACME operations, which is always the case without a cluster.
+func (p *Provider) isLeader() bool {
+	return p.Leadership == nil || p.Leadership.IsLeader()
+}
+
+// participate runs the instance for the election of the leader.
+// Once elected, the instance reloads the ACME data saved by the former leader,
+// then obtains the missing certificates and renews the expiring ones.
+func (p *Provider) participate(ctx context.Context) {
+	p.Leadership.AddListener(func(elected bool) error {
+		if !elected {
+			return nil
+		}
+
+		safe.Go(func() {
+			if err := p.takeOver(ctx); err != nil {
+				log.FromContext(ctx).Errorf("Unable to take over the ACME operations: %v", err)
+			}
+		})
+		return nil
+	})
+
+	p.Leadership.Participate(p.pool)
+}
+
+func (p *Provider) takeOver(ctx context.Context) error {
+	account, err := p.Store.GetAccount()
+	if err != nil {
+		return fmt.Errorf("unable to get ACME account : %v", err)
+	}
+
+	p.clientMutex.Lock()
+	if account != nil {
+		p.account = account
+	}
+	p.client = nil
+	p.clientMutex.Unlock()
+
+	done := make(chan struct{})
+	select {
+	case p.reloadChan <- done:
+		<-done
+	case <-p.pool.Ctx().Done():
+		return nil
+	}
+
+	for i := 0; i < len(p.Domains); i++ {
+		domain := p.Domains[i]
+		if _, err := p.resolveCertificate(ctx, domain, true); err != nil {
+			log.FromContext(ctx).Errorf("Unable to obtain ACME certificate for domains %q : %v", strings.Join(domain.ToStrArray(), ","), err)
+		}
+	}
+
+	if conf, ok := p.lastConfiguration.Get().(*config.Configuration); ok && conf != nil {
+		p.resolveRouterDomains(ctx, *conf)
+	}
+
+	p.renewCertificates(ctx)
+
+	return nil
+}
+
+// reloadCertificates replaces the certificates by the ones of the store, which are saved by the leader.
+func (p *Provider) reloadCertificates(ctx context.Context) {
+	certificates, err := p.Store.GetCertificates()
+	if err != nil {
+		log.FromContext(ctx).Errorf("Unable to reload the ACME certificates: %v", err)
+		return
+	}
+
+	if reflect.DeepEqual(certificates, p.certificates) {
+		return
+	}
+
+	p.certificates = certificates
+	p.refreshCertificates()
+}
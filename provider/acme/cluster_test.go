@@ -0,0 +1,295 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/containous/traefik/cluster"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/old/types"
+	"github.com/containous/traefik/safe"
+	traefiktls "github.com/containous/traefik/tls"
+	traefiktypes "github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryKV is an in-memory KV store, whose locks are held until they are unlocked or expired.
+type memoryKV struct {
+	store.Store
+
+	mu      sync.Mutex
+	values  map[string][]byte
+	slots   map[string]chan struct{}
+	holders map[string]*memoryLock
+}
+
+func newMemoryKV() *memoryKV {
+	return &memoryKV{
+		values:  make(map[string][]byte),
+		slots:   make(map[string]chan struct{}),
+		holders: make(map[string]*memoryLock),
+	}
+}
+
+func (kv *memoryKV) Put(key string, value []byte, _ *store.WriteOptions) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	kv.values[key] = value
+	return nil
+}
+
+func (kv *memoryKV) Get(key string, _ *store.ReadOptions) (*store.KVPair, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	value, ok := kv.values[key]
+	if !ok {
+		return nil, store.ErrKeyNotFound
+	}
+	return &store.KVPair{Key: key, Value: value}, nil
+}
+
+func (kv *memoryKV) Delete(key string) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	if _, ok := kv.values[key]; !ok {
+		return store.ErrKeyNotFound
+	}
+	delete(kv.values, key)
+	return nil
+}
+
+func (kv *memoryKV) NewLock(key string, options *store.LockOptions) (store.Locker, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	if _, ok := kv.slots[key]; !ok {
+		kv.slots[key] = make(chan struct{}, 1)
+	}
+	return &memoryLock{kv: kv, key: key, value: options.Value}, nil
+}
+
+// expire expires the lease of the holder of the lock, as if it stopped renewing it.
+func (kv *memoryKV) expire(key string) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	if holder, ok := kv.holders[key]; ok {
+		delete(kv.holders, key)
+		close(holder.lost)
+		<-kv.slots[key]
+	}
+}
+
+type memoryLock struct {
+	kv    *memoryKV
+	key   string
+	value []byte
+	lost  chan struct{}
+}
+
+func (l *memoryLock) Lock(stopChan chan struct{}) (<-chan struct{}, error) {
+	l.kv.mu.Lock()
+	slot := l.kv.slots[l.key]
+	l.kv.mu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+	case <-stopChan:
+		return nil, store.ErrCannotLock
+	}
+
+	l.kv.mu.Lock()
+	defer l.kv.mu.Unlock()
+
+	l.lost = make(chan struct{})
+	l.kv.holders[l.key] = l
+	l.kv.values[l.key] = l.value
+	return l.lost, nil
+}
+
+func (l *memoryLock) Unlock() error {
+	l.kv.mu.Lock()
+	defer l.kv.mu.Unlock()
+
+	if l.kv.holders[l.key] == l {
+		delete(l.kv.holders, l.key)
+		<-l.kv.slots[l.key]
+	}
+	return nil
+}
+
+func TestKVStore(t *testing.T) {
+	kvStore := NewKVStore(newMemoryKV(), "traefik/acme")
+
+	account, err := kvStore.GetAccount()
+	require.NoError(t, err)
+	assert.Nil(t, account)
+
+	certificates, err := kvStore.GetCertificates()
+	require.NoError(t, err)
+	assert.Empty(t, certificates)
+
+	require.NoError(t, kvStore.SaveAccount(&Account{Email: "foo@bar.com"}))
+	account, err = kvStore.GetAccount()
+	require.NoError(t, err)
+	assert.Equal(t, &Account{Email: "foo@bar.com"}, account)
+
+	cert := &Certificate{Domain: traefiktypes.Domain{Main: "foo.bar"}, Certificate: []byte("cert"), Key: []byte("key")}
+	require.NoError(t, kvStore.SaveCertificates([]*Certificate{cert}))
+	certificates, err = kvStore.GetCertificates()
+	require.NoError(t, err)
+	assert.Equal(t, []*Certificate{cert}, certificates)
+
+	_, err = kvStore.GetHTTPChallengeToken("token", "foo.bar")
+	assert.Error(t, err)
+	require.NoError(t, kvStore.SetHTTPChallengeToken("token", "foo.bar", []byte("keyAuth")))
+	keyAuth, err := kvStore.GetHTTPChallengeToken("token", "foo.bar")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("keyAuth"), keyAuth)
+	require.NoError(t, kvStore.RemoveHTTPChallengeToken("token", "foo.bar"))
+	_, err = kvStore.GetHTTPChallengeToken("token", "foo.bar")
+	assert.Error(t, err)
+
+	require.NoError(t, kvStore.AddTLSChallenge("foo.bar", cert))
+	challenge, err := kvStore.GetTLSChallenge("foo.bar")
+	require.NoError(t, err)
+	assert.Equal(t, cert, challenge)
+	require.NoError(t, kvStore.RemoveTLSChallenge("foo.bar"))
+	challenge, err = kvStore.GetTLSChallenge("foo.bar")
+	require.NoError(t, err)
+	assert.Nil(t, challenge)
+}
+
+// unavailableKV is a KV store whose reads fail, as when it cannot be reached.
+type unavailableKV struct {
+	store.Store
+}
+
+func (unavailableKV) Get(key string, _ *store.ReadOptions) (*store.KVPair, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestGetTLSALPNCertificate(t *testing.T) {
+	kv := newMemoryKV()
+
+	leader := &Provider{Store: NewKVStore(kv, "traefik/acme")}
+	follower := &Provider{Store: NewKVStore(kv, "traefik/acme")}
+
+	certificate, err := follower.GetTLSALPNCertificate("foo.bar")
+	require.NoError(t, err)
+	assert.Nil(t, certificate)
+
+	solver := &challengeTLSALPN{Store: leader.Store, cache: &leader.tlsChallenges}
+	require.NoError(t, solver.Present("foo.bar", "token", "keyAuth"))
+
+	// The leader serves the certificate it presented without reading the store.
+	leader.Store = NewKVStore(unavailableKV{}, "traefik/acme")
+	certificate, err = leader.GetTLSALPNCertificate("foo.bar")
+	require.NoError(t, err)
+	assert.NotNil(t, certificate)
+
+	certificate, err = follower.GetTLSALPNCertificate("foo.bar")
+	require.NoError(t, err)
+	require.NotNil(t, certificate)
+
+	// Once read from the store, the certificate is served from the cache.
+	follower.Store = NewKVStore(unavailableKV{}, "traefik/acme")
+	cached, err := follower.GetTLSALPNCertificate("foo.bar")
+	require.NoError(t, err)
+	assert.Equal(t, certificate, cached)
+
+	// A store error is handled as a missing certificate.
+	certificate, err = follower.GetTLSALPNCertificate("other.bar")
+	require.NoError(t, err)
+	assert.Nil(t, certificate)
+
+	solver.Store = NewKVStore(kv, "traefik/acme")
+	require.NoError(t, solver.CleanUp("foo.bar", "token", "keyAuth"))
+	certificate, err = leader.GetTLSALPNCertificate("foo.bar")
+	require.NoError(t, err)
+	assert.Nil(t, certificate)
+}
+
+func TestProvider_Cluster(t *testing.T) {
+	kv := newMemoryKV()
+
+	// The CA servers count the ACME operations of each instance, which all fail.
+	var operations [2]int32
+	var providers [2]*Provider
+	for i := range providers {
+		i := i
+		caServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&operations[i], 1)
+			rw.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer caServer.Close()
+
+		providers[i] = &Provider{
+			Configuration: &Configuration{
+				CAServer:     caServer.URL,
+				KeyType:      "EC256",
+				TLSChallenge: &TLSChallenge{},
+				Domains:      []traefiktypes.Domain{{Main: "foo.bar"}},
+			},
+			Store: NewKVStore(kv, "traefik/acme"),
+			Leadership: cluster.NewLeadership(context.Background(), &types.Cluster{
+				Node:  []string{"node1", "node2"}[i],
+				Store: &types.Store{Store: kv, Prefix: "traefik/acme"},
+			}),
+			certificateStore: traefiktls.NewCertificateStore(),
+		}
+		require.NoError(t, providers[i].Init())
+
+		configurationChan := make(chan config.Message)
+		go func() {
+			for range configurationChan {
+			}
+		}()
+
+		pool := safe.NewPool(context.Background())
+		defer pool.Stop()
+
+		require.NoError(t, providers[i].Provide(configurationChan, pool))
+	}
+
+	waitForOperations := func() int {
+		t.Helper()
+
+		for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			for i := range providers {
+				if providers[i].isLeader() && atomic.LoadInt32(&operations[i]) > 0 {
+					// Leaves the time to the other instance to wrongly perform ACME operations.
+					time.Sleep(100 * time.Millisecond)
+					return i
+				}
+			}
+		}
+		t.Fatal("no ACME operation performed by a leader")
+		return -1
+	}
+
+	leader := waitForOperations()
+	follower := 1 - leader
+	assert.True(t, providers[leader].isLeader())
+	assert.False(t, providers[follower].isLeader())
+	assert.Zero(t, atomic.LoadInt32(&operations[follower]))
+
+	// Once the lease of the leader expires, the other instance takes over the ACME operations.
+	atomic.StoreInt32(&operations[leader], 0)
+	kv.expire("traefik/acme/leader")
+
+	assert.Equal(t, follower, waitForOperations())
+	assert.False(t, providers[leader].isLeader())
+	assert.Zero(t, atomic.LoadInt32(&operations[leader]))
+}
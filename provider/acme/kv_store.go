@@ -0,0 +1,130 @@
+package acme
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/abronan/valkeyrie/store"
+)
+
+var _ Store = (*KVStore)(nil)
+
+// KVStore Store implementation for a KV store shared by several instances.
+// The data is read from the KV store at each call, to get the data saved by the other instances.
+type KVStore struct {
+	kv     store.Store
+	prefix string
+}
+
+// NewKVStore initializes a new KVStore with the prefix of its keys
+func NewKVStore(kv store.Store, prefix string) *KVStore {
+	return &KVStore{kv: kv, prefix: prefix}
+}
+
+func (s *KVStore) get(key string, object interface{}) (bool, error) {
+	pair, err := s.kv.Get(s.prefix+key, nil)
+	if err == store.ErrKeyNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal(pair.Value, object); err != nil {
+		return false, fmt.Errorf("unable to unmarshal the key %s: %v", s.prefix+key, err)
+	}
+	return true, nil
+}
+
+func (s *KVStore) put(key string, object interface{}) error {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	return s.kv.Put(s.prefix+key, data, nil)
+}
+
+func (s *KVStore) delete(key string) error {
+	err := s.kv.Delete(s.prefix + key)
+	if err == store.ErrKeyNotFound {
+		return nil
+	}
+	return err
+}
+
+// GetAccount returns ACME Account
+func (s *KVStore) GetAccount() (*Account, error) {
+	account := &Account{}
+	found, err := s.get("/account", account)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// SaveAccount stores ACME Account
+func (s *KVStore) SaveAccount(account *Account) error {
+	return s.put("/account", account)
+}
+
+// GetCertificates returns ACME Certificates list
+func (s *KVStore) GetCertificates() ([]*Certificate, error) {
+	var certificates []*Certificate
+	if _, err := s.get("/certificates", &certificates); err != nil {
+		return nil, err
+	}
+
+	return certificates, nil
+}
+
+// SaveCertificates stores ACME Certificates list
+func (s *KVStore) SaveCertificates(certificates []*Certificate) error {
+	return s.put("/certificates", certificates)
+}
+
+// GetHTTPChallengeToken Get the http challenge token from the store
+func (s *KVStore) GetHTTPChallengeToken(token, domain string) ([]byte, error) {
+	var keyAuth []byte
+	found, err := s.get("/http-challenges/"+token+"/"+domain, &keyAuth)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("cannot find challenge for token %v", token)
+	}
+
+	return keyAuth, nil
+}
+
+// SetHTTPChallengeToken Set the http challenge token in the store
+func (s *KVStore) SetHTTPChallengeToken(token, domain string, keyAuth []byte) error {
+	return s.put("/http-challenges/"+token+"/"+domain, keyAuth)
+}
+
+// RemoveHTTPChallengeToken Remove the http challenge token in the store
+func (s *KVStore) RemoveHTTPChallengeToken(token, domain string) error {
+	return s.delete("/http-challenges/" + token + "/" + domain)
+}
+
+// AddTLSChallenge Add a certificate to the ACME TLS-ALPN-01 certificates storage
+func (s *KVStore) AddTLSChallenge(domain string, cert *Certificate) error {
+	return s.put("/tls-challenges/"+domain, cert)
+}
+
+// GetTLSChallenge Get a certificate from the ACME TLS-ALPN-01 certificates storage
+func (s *KVStore) GetTLSChallenge(domain string) (*Certificate, error) {
+	cert := &Certificate{}
+	found, err := s.get("/tls-challenges/"+domain, cert)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return cert, nil
+}
+
+// RemoveTLSChallenge Remove a certificate from the ACME TLS-ALPN-01 certificates storage
+func (s *KVStore) RemoveTLSChallenge(domain string) error {
+	return s.delete("/tls-challenges/" + domain)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	fmtlog "log"
+	"net/http"
 	"net/url"
 	"reflect"
 	"strings"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/cenkalti/backoff"
 	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/cluster"
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/rules"
@@ -40,18 +42,20 @@ var (
 
 // Configuration holds ACME configuration provided by users
 type Configuration struct {
-	Email         string         `description:"Email address used for registration"`
-	ACMELogging   bool           `description:"Enable debug logging of ACME actions."`
-	CAServer      string         `description:"CA server to use."`
-	Storage       string         `description:"Storage to use."`
-	EntryPoint    string         `description:"EntryPoint to use."`
-	KeyType       string         `description:"KeyType used for generating certificate private key. Allow value 'EC256', 'EC384', 'RSA2048', 'RSA4096', 'RSA8192'. Default to 'RSA4096'"`
-	OnHostRule    bool           `description:"Enable certificate generation on frontends Host rules."`
-	OnDemand      bool           `description:"Enable on demand certificate generation. This will request a certificate from Let's Encrypt during the first TLS handshake for a hostname that does not yet have a certificate."` // Deprecated
-	DNSChallenge  *DNSChallenge  `description:"Activate DNS-01 Challenge"`
-	HTTPChallenge *HTTPChallenge `description:"Activate HTTP-01 Challenge"`
-	TLSChallenge  *TLSChallenge  `description:"Activate TLS-ALPN-01 Challenge"`
-	Domains       []types.Domain `description:"CN and SANs (alternative domains) to each main domain using format: --acme.domains='main.com,san1.com,san2.com' --acme.domains='*.main.net'. No SANs for wildcards domain. Wildcard domains only accepted with DNSChallenge"`
+	Email          string                     `description:"Email address used for registration"`
+	ACMELogging    bool                       `description:"Enable debug logging of ACME actions."`
+	CAServer       string                     `description:"CA server to use."`
+	CACertificates traefiktls.FilesOrContents `description:"Certificates of the authorities trusted to verify the CA server, instead of the system ones."`
+	Storage        string                     `description:"Storage to use."`
+	EntryPoint     string                     `description:"EntryPoint to use."`
+	KeyType        string                     `description:"KeyType used for generating certificate private key. Allow value 'EC256', 'EC384', 'RSA2048', 'RSA4096', 'RSA8192'. Default to 'RSA4096'"`
+	OnHostRule     bool                       `description:"Enable certificate generation on frontends Host rules."`
+	OnDemand       bool                       `description:"Enable on demand certificate generation. This will request a certificate from Let's Encrypt during the first TLS handshake for a hostname that does not yet have a certificate."` // Deprecated
+	DNSChallenge   *DNSChallenge              `description:"Activate DNS-01 Challenge"`
+	HTTPChallenge  *HTTPChallenge             `description:"Activate HTTP-01 Challenge"`
+	TLSChallenge   *TLSChallenge              `description:"Activate TLS-ALPN-01 Challenge"`
+	Domains        []types.Domain             `description:"CN and SANs (alternative domains) to each main domain using format: --acme.domains='main.com,san1.com,san2.com' --acme.domains='*.main.net'. No SANs for wildcards domain. Wildcard domains only accepted with DNSChallenge"`
+	Cluster        *Cluster                   `description:"Share the ACME data between the instances in a KV store, where the elected leader performs the ACME operations"`
 }
 
 // Certificate is a struct which contains all data needed from an ACME certificate
@@ -84,17 +88,21 @@ type TLSChallenge struct{}
 type Provider struct {
 	*Configuration
 	Store                  Store
+	Leadership             *cluster.Leadership
 	certificates           []*Certificate
 	account                *Account
 	client                 *lego.Client
 	certsChan              chan *Certificate
+	reloadChan             chan chan struct{}
 	configurationChan      chan<- config.Message
 	certificateStore       *traefiktls.CertificateStore
 	clientMutex            sync.Mutex
 	configFromListenerChan chan config.Configuration
+	lastConfiguration      *safe.Safe
 	pool                   *safe.Pool
 	resolvingDomains       map[string]struct{}
 	resolvingDomainsMutex  sync.RWMutex
+	tlsChallenges          tlsChallengeCache
 }
 
 // SetConfigListenerChan initializes the configFromListenerChan
@@ -161,6 +169,8 @@ func (p *Provider) Init() error {
 	// Init the currently resolved domain map
 	p.resolvingDomains = make(map[string]struct{})
 
+	p.lastConfiguration = safe.New((*config.Configuration)(nil))
+
 	return nil
 }
 
@@ -195,6 +205,10 @@ func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.P
 	p.configurationChan = configurationChan
 	p.refreshCertificates()
 
+	if p.Leadership != nil {
+		p.participate(ctx)
+	}
+
 	p.deleteUnnecessaryDomains(ctx)
 	for i := 0; i < len(p.Domains); i++ {
 		domain := p.Domains[i]
@@ -224,6 +238,45 @@ func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.P
 	return nil
 }
 
+// createLegoConfig returns the configuration of the ACME client of the account.
+func (p *Provider) createLegoConfig(ctx context.Context, account *Account) (*lego.Config, error) {
+	caServer := "https://acme-v02.api.letsencrypt.org/directory"
+	if len(p.CAServer) > 0 {
+		caServer = p.CAServer
+	}
+	log.FromContext(ctx).Debug(caServer)
+
+	config := lego.NewConfig(account)
+	config.CADirURL = caServer
+	config.Certificate.KeyType = account.KeyType
+	config.UserAgent = fmt.Sprintf("containous-traefik/%s", version.Version)
+
+	if len(p.CACertificates) > 0 {
+		transport, ok := config.HTTPClient.Transport.(*http.Transport)
+		if !ok {
+			return nil, errors.New("unable to configure the CA certificates of the ACME client")
+		}
+
+		pool := x509.NewCertPool()
+		for _, caCertificate := range p.CACertificates {
+			data, err := caCertificate.Read()
+			if err != nil {
+				return nil, err
+			}
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("invalid certificate(s) in %s", caCertificate)
+			}
+		}
+
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	return config, nil
+}
+
 func (p *Provider) getClient() (*lego.Client, error) {
 	p.clientMutex.Lock()
 	defer p.clientMutex.Unlock()
@@ -242,16 +295,10 @@ func (p *Provider) getClient() (*lego.Client, error) {
 
 	logger.Debug("Building ACME client...")
 
-	caServer := "https://acme-v02.api.letsencrypt.org/directory"
-	if len(p.CAServer) > 0 {
-		caServer = p.CAServer
+	config, err := p.createLegoConfig(ctx, account)
+	if err != nil {
+		return nil, err
 	}
-	logger.Debug(caServer)
-
-	config := lego.NewConfig(account)
-	config.CADirURL = caServer
-	config.Certificate.KeyType = account.KeyType
-	config.UserAgent = fmt.Sprintf("containous-traefik/%s", version.Version)
 
 	client, err := lego.NewClient(config)
 	if err != nil {
@@ -322,7 +369,7 @@ func (p *Provider) getClient() (*lego.Client, error) {
 	case p.TLSChallenge != nil:
 		logger.Debug("Using TLS Challenge provider.")
 
-		err = client.Challenge.SetTLSALPN01Provider(&challengeTLSALPN{Store: p.Store})
+		err = client.Challenge.SetTLSALPN01Provider(&challengeTLSALPN{Store: p.Store, cache: &p.tlsChallenges})
 		if err != nil {
 			return nil, err
 		}
@@ -357,44 +404,60 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 		for {
 			select {
 			case config := <-p.configFromListenerChan:
-				for routerName, route := range config.Routers {
-					logger := log.FromContext(ctx).WithField(log.RouterName, routerName)
+				// Kept for the instance elected leader afterwards, which resolves the domains left to the former leader.
+				p.lastConfiguration.Set(&config)
+				p.resolveRouterDomains(ctx, config)
+			case <-stop:
+				return
+			}
+		}
+	})
+}
 
-					domains, err := rules.ParseDomains(route.Rule)
-					if err != nil {
-						logger.Errorf("Error parsing domains in provider ACME: %v", err)
-						continue
-					}
+func (p *Provider) resolveRouterDomains(ctx context.Context, config config.Configuration) {
+	for routerName, route := range config.Routers {
+		if route.CatchAll {
+			continue
+		}
 
-					if len(domains) == 0 {
-						logger.Debugf("No domain parsed in rule %q in provider ACME", route.Rule)
-						continue
-					}
+		logger := log.FromContext(ctx).WithField(log.RouterName, routerName)
 
-					logger.Debugf("Try to challenge certificate for domain %v founded in Host rule", domains)
+		domains, err := rules.ParseDomains(route.Rule)
+		if err != nil {
+			logger.Errorf("Error parsing domains in provider ACME: %v", err)
+			continue
+		}
 
-					var domain types.Domain
-					if len(domains) > 0 {
-						domain = types.Domain{Main: domains[0]}
-						if len(domains) > 1 {
-							domain.SANs = domains[1:]
-						}
+		if len(domains) == 0 {
+			logger.Debugf("No domain parsed in rule %q in provider ACME", route.Rule)
+			continue
+		}
 
-						safe.Go(func() {
-							if _, err := p.resolveCertificate(ctx, domain, false); err != nil {
-								logger.Errorf("Unable to obtain ACME certificate for domains %q detected thanks to rule %q : %v", strings.Join(domains, ","), route.Rule, err)
-							}
-						})
-					}
-				}
-			case <-stop:
-				return
+		logger.Debugf("Try to challenge certificate for domain %v founded in Host rule", domains)
+
+		var domain types.Domain
+		if len(domains) > 0 {
+			domain = types.Domain{Main: domains[0]}
+			if len(domains) > 1 {
+				domain.SANs = domains[1:]
 			}
+
+			rule := route.Rule
+			safe.Go(func() {
+				if _, err := p.resolveCertificate(ctx, domain, false); err != nil {
+					logger.Errorf("Unable to obtain ACME certificate for domains %q detected thanks to rule %q : %v", strings.Join(domains, ","), rule, err)
+				}
+			})
 		}
-	})
+	}
 }
 
 func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain, domainFromConfigurationFile bool) (*certificate.Resource, error) {
+	if !p.isLeader() {
+		log.FromContext(ctx).Debugf("Leaving the certificate of the domains %q to the leader of the cluster", domain.ToStrArray())
+		return nil, nil
+	}
+
 	domains, err := p.getValidDomains(ctx, domain, domainFromConfigurationFile)
 	if err != nil {
 		return nil, err
@@ -594,10 +657,26 @@ func (p *Provider) deleteUnnecessaryDomains(ctx context.Context) {
 
 func (p *Provider) watchCertificate(ctx context.Context) {
 	p.certsChan = make(chan *Certificate)
+	p.reloadChan = make(chan chan struct{})
 
 	p.pool.Go(func(stop chan bool) {
+		// Without a cluster, the certificates are never reloaded from the store.
+		var reload <-chan time.Time
+		if p.Leadership != nil {
+			ticker := time.NewTicker(clusterRefreshInterval)
+			defer ticker.Stop()
+			reload = ticker.C
+		}
+
 		for {
 			select {
+			case <-reload:
+				if !p.isLeader() {
+					p.reloadCertificates(ctx)
+				}
+			case done := <-p.reloadChan:
+				p.reloadCertificates(ctx)
+				close(done)
 			case cert := <-p.certsChan:
 				certUpdated := false
 				for _, domainsCertificate := range p.certificates {
@@ -652,6 +731,11 @@ func (p *Provider) refreshCertificates() {
 func (p *Provider) renewCertificates(ctx context.Context) {
 	logger := log.FromContext(ctx)
 
+	if !p.isLeader() {
+		logger.Debug("Leaving the certificate renew to the leader of the cluster")
+		return
+	}
+
 	logger.Info("Testing certificate renew...")
 	for _, cert := range p.certificates {
 		crt, err := getX509Certificate(ctx, cert)
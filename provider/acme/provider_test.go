@@ -3,13 +3,19 @@ package acme
 import (
 	"context"
 	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/containous/traefik/safe"
 	traefiktls "github.com/containous/traefik/tls"
 	"github.com/containous/traefik/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/xenolf/lego/certcrypto"
+	"github.com/xenolf/lego/lego"
 )
 
 func TestGetUncheckedCertificates(t *testing.T) {
@@ -658,3 +664,58 @@ func TestInitAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateLegoConfig_CACertificates(t *testing.T) {
+	caServer := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(rw, `{"newNonce":"https://ca.example.com/nonce","newAccount":"https://ca.example.com/account","newOrder":"https://ca.example.com/order"}`)
+	}))
+	defer caServer.Close()
+
+	caCertificate := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caServer.Certificate().Raw}))
+
+	testCases := []struct {
+		desc                string
+		caCertificates      traefiktls.FilesOrContents
+		expectedConfigError bool
+		expectedClientError bool
+	}{
+		{
+			desc:           "custom CA",
+			caCertificates: traefiktls.FilesOrContents{traefiktls.FileOrContent(caCertificate)},
+		},
+		{
+			desc:                "system CAs",
+			expectedClientError: true,
+		},
+		{
+			desc:                "invalid CA",
+			caCertificates:      traefiktls.FilesOrContents{"foo"},
+			expectedConfigError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			account, err := NewAccount(context.Background(), "foo@foo.net", "EC256")
+			require.NoError(t, err)
+
+			provider := &Provider{Configuration: &Configuration{CAServer: caServer.URL, CACertificates: test.caCertificates}}
+
+			config, err := provider.createLegoConfig(context.Background(), account)
+			if test.expectedConfigError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			_, err = lego.NewClient(config)
+			if test.expectedClientError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -35,6 +35,10 @@ func NewProviderAggregator(conf static.Providers) ProviderAggregator {
 		p.quietAddProvider(conf.Rest)
 	}
 
+	if conf.Env != nil {
+		p.quietAddProvider(conf.Env)
+	}
+
 	return p
 }
 
@@ -91,6 +91,10 @@ func AddService(configuration *config.Configuration, serviceName string, service
 		return true
 	}
 
+	if configuration.Services[serviceName].LoadBalancer == nil || service.LoadBalancer == nil {
+		return reflect.DeepEqual(configuration.Services[serviceName], service)
+	}
+
 	if !configuration.Services[serviceName].LoadBalancer.Mergeable(service.LoadBalancer) {
 		return false
 	}
@@ -146,7 +150,7 @@ func BuildRouterConfiguration(ctx context.Context, configuration *config.Configu
 
 	for routerName, router := range configuration.Routers {
 		loggerRouter := logger.WithField(log.RouterName, routerName)
-		if len(router.Rule) == 0 {
+		if len(router.Rule) == 0 && !router.CatchAll {
 			writer := &bytes.Buffer{}
 			if err := defaultRuleTpl.Execute(writer, model); err != nil {
 				loggerRouter.Errorf("Error while parsing default rule: %v", err)
@@ -51,6 +51,14 @@ func (p *Provider) buildConfiguration(ctx context.Context, containersInspected [
 
 		provider.BuildRouterConfiguration(ctx, confFromLabel, serviceName, p.defaultRuleTpl, model)
 
+		if len(p.DefaultMiddlewares) > 0 {
+			for _, router := range confFromLabel.Routers {
+				if len(router.Middlewares) == 0 {
+					router.Middlewares = append([]string(nil), p.DefaultMiddlewares...)
+				}
+			}
+		}
+
 		configurations[containerName] = confFromLabel
 	}
 
@@ -312,6 +312,57 @@ func TestDefaultRule(t *testing.T) {
 	}
 }
 
+func TestDefaultMiddlewares(t *testing.T) {
+	container := func(name string, labels map[string]string) dockerData {
+		return dockerData{
+			ServiceName: name,
+			Name:        name,
+			Labels:      labels,
+			NetworkSettings: networkSettings{
+				Ports: nat.PortMap{
+					nat.Port("80/tcp"): []nat.PortBinding{},
+				},
+				Networks: map[string]*networkData{
+					"bridge": {
+						Name: "bridge",
+						Addr: "127.0.0.1",
+					},
+				},
+			},
+		}
+	}
+
+	containers := []dockerData{
+		container("Test", map[string]string{}),
+		container("Test2", map[string]string{
+			"traefik.routers.Test2.middlewares": "Middleware1",
+		}),
+	}
+
+	p := Provider{
+		ExposedByDefault:   true,
+		DefaultRule:        "Host(`{{ normalize .Name }}.traefik.wtf`)",
+		DefaultMiddlewares: []string{"file.secure-headers", "file.compress"},
+	}
+
+	err := p.Init()
+	require.NoError(t, err)
+
+	for i := 0; i < len(containers); i++ {
+		var err error
+		containers[i].ExtraConf, err = p.getConfiguration(containers[i])
+		require.NoError(t, err)
+	}
+
+	configuration := p.buildConfiguration(context.Background(), containers)
+
+	require.Contains(t, configuration.Routers, "Test")
+	assert.Equal(t, []string{"file.secure-headers", "file.compress"}, configuration.Routers["Test"].Middlewares)
+
+	require.Contains(t, configuration.Routers, "Test2")
+	assert.Equal(t, []string{"Middleware1"}, configuration.Routers["Test2"].Middlewares)
+}
+
 func Test_buildConfiguration(t *testing.T) {
 	testCases := []struct {
 		desc        string
@@ -0,0 +1,171 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/provider"
+	"github.com/containous/traefik/provider/label"
+	"github.com/containous/traefik/safe"
+)
+
+const providerName = "env"
+
+// Prefix is the prefix of the environment variables read by Traefik.
+const Prefix = "TRAEFIK_"
+
+var _ provider.Provider = (*Provider)(nil)
+
+// Provider reads the dynamic configuration from the environment variables, once when it starts.
+type Provider struct{}
+
+// Init the provider.
+func (p *Provider) Init() error {
+	return nil
+}
+
+// Provide allows the env provider to provide configurations to traefik
+// using the given configuration channel.
+func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.Pool) error {
+	configuration, err := DecodeConfiguration(os.Environ())
+	if err != nil {
+		return err
+	}
+
+	configurationChan <- config.Message{ProviderName: providerName, Configuration: configuration}
+	return nil
+}
+
+// DecodeConfiguration converts the TRAEFIK_ROUTERS_*, TRAEFIK_MIDDLEWARES_* and TRAEFIK_SERVICES_* variables to a configuration.
+// The URL of the server of a service, which the labels cannot set, is read from its TRAEFIK_SERVICES_*_LOADBALANCER_SERVER_URL variable.
+func DecodeConfiguration(environ []string) (*config.Configuration, error) {
+	conf := &config.Configuration{}
+
+	var variables []string
+	serverURLs := make(map[string]string)
+	for _, variable := range environ {
+		parts := strings.SplitN(variable, "=", 2)
+		if len(parts) == 2 && strings.HasPrefix(parts[0], Prefix) {
+			keys := splitKeys(strings.TrimPrefix(parts[0], Prefix))
+			if len(keys) == 5 && keys[0] == "services" && keys[2] == "loadbalancer" && keys[3] == "server" && keys[4] == "url" {
+				serverURLs[keys[1]] = parts[1]
+				continue
+			}
+		}
+		variables = append(variables, variable)
+	}
+
+	if err := Decode(variables, conf); err != nil {
+		return nil, err
+	}
+
+	for serviceName, serverURL := range serverURLs {
+		setServerURL(conf, serviceName, serverURL)
+	}
+
+	return conf, nil
+}
+
+// setServerURL sets the URL of the server of the service, which is created with its defaults if needed.
+func setServerURL(conf *config.Configuration, serviceName, serverURL string) {
+	if conf.Services == nil {
+		conf.Services = make(map[string]*config.Service)
+	}
+
+	service := conf.Services[serviceName]
+	if service == nil {
+		service = &config.Service{}
+		conf.Services[serviceName] = service
+	}
+
+	if service.LoadBalancer == nil {
+		service.LoadBalancer = &config.LoadBalancerService{}
+		service.LoadBalancer.SetDefaults()
+	}
+
+	if len(service.LoadBalancer.Servers) == 0 {
+		server := config.Server{}
+		server.SetDefaults()
+		service.LoadBalancer.Servers = []config.Server{server}
+	}
+
+	service.LoadBalancer.Servers[0].URL = serverURL
+}
+
+// Decode fills the structure pointed by element with the prefixed variables of environ.
+// The name of a variable is the path of its field, whose keys are separated by underscores
+// and match the field names or the map keys case-insensitively, e.g. TRAEFIK_ROUTERS_FOO_RULE.
+// A double underscore stands for an underscore in a key, and the lists are comma-separated.
+// The variables whose first key is not a field of the structure are ignored.
+func Decode(environ []string, element interface{}) error {
+	labels := make(map[string]string)
+	for _, variable := range environ {
+		parts := strings.SplitN(variable, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], Prefix) {
+			continue
+		}
+
+		keys := splitKeys(strings.TrimPrefix(parts[0], Prefix))
+		if len(keys) == 0 || !hasField(element, keys[0]) {
+			continue
+		}
+
+		labels["traefik."+strings.Join(keys, ".")] = parts[1]
+	}
+
+	if len(labels) == 0 {
+		return nil
+	}
+
+	return label.Decode(labels, element)
+}
+
+// splitKeys splits a variable name into lower-case keys.
+func splitKeys(name string) []string {
+	var keys []string
+	var key strings.Builder
+
+	for i := 0; i < len(name); i++ {
+		if name[i] != '_' {
+			key.WriteByte(name[i])
+			continue
+		}
+
+		if i+1 < len(name) && name[i+1] == '_' {
+			key.WriteByte('_')
+			i++
+			continue
+		}
+
+		if key.Len() == 0 {
+			return nil
+		}
+		keys = append(keys, strings.ToLower(key.String()))
+		key.Reset()
+	}
+
+	if key.Len() == 0 {
+		return nil
+	}
+	return append(keys, strings.ToLower(key.String()))
+}
+
+func hasField(element interface{}, name string) bool {
+	rType := reflect.TypeOf(element)
+	for rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+	if rType.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < rType.NumField(); i++ {
+		field := rType.Field(i)
+		if field.PkgPath == "" && field.Tag.Get("label") != "-" && strings.EqualFold(field.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		environ  []string
+		expected *config.Configuration
+	}{
+		{
+			desc:     "no variable",
+			environ:  []string{"PATH=/usr/bin", "HOME=/root"},
+			expected: &config.Configuration{},
+		},
+		{
+			desc: "router, service and middleware",
+			environ: []string{
+				"TRAEFIK_ROUTERS_APP_RULE=Host:foo.bar",
+				"TRAEFIK_ROUTERS_APP_SERVICE=app",
+				"TRAEFIK_ROUTERS_APP_ENTRYPOINTS=web,websecure",
+				"TRAEFIK_ROUTERS_APP_MIDDLEWARES=prefix",
+				"TRAEFIK_SERVICES_APP_LOADBALANCER_SERVER_URL=http://10.0.0.1:8080",
+				"TRAEFIK_SERVICES_APP_LOADBALANCER_PASSHOSTHEADER=true",
+				"TRAEFIK_MIDDLEWARES_PREFIX_STRIPPREFIX_PREFIXES=/foo,/bar",
+			},
+			expected: &config.Configuration{
+				Routers: map[string]*config.Router{
+					"app": {
+						Rule:        "Host:foo.bar",
+						Service:     "app",
+						EntryPoints: []string{"web", "websecure"},
+						Middlewares: []string{"prefix"},
+					},
+				},
+				Services: map[string]*config.Service{
+					"app": {
+						LoadBalancer: &config.LoadBalancerService{
+							Servers:        []config.Server{{URL: "http://10.0.0.1:8080", Scheme: "http", Weight: 1}},
+							Method:         "wrr",
+							PassHostHeader: true,
+						},
+					},
+				},
+				Middlewares: map[string]*config.Middleware{
+					"prefix": {
+						StripPrefix: &config.StripPrefix{Prefixes: []string{"/foo", "/bar"}},
+					},
+				},
+			},
+		},
+		{
+			desc:    "server URL only",
+			environ: []string{"TRAEFIK_SERVICES_APP_LOADBALANCER_SERVER_URL=http://10.0.0.1:8080"},
+			expected: &config.Configuration{
+				Services: map[string]*config.Service{
+					"app": {
+						LoadBalancer: &config.LoadBalancerService{
+							Servers:        []config.Server{{URL: "http://10.0.0.1:8080", Scheme: "http", Weight: 1}},
+							Method:         "wrr",
+							PassHostHeader: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "server URL and weight",
+			environ: []string{
+				"TRAEFIK_SERVICES_APP_LOADBALANCER_SERVER_URL=http://10.0.0.1:8080",
+				"TRAEFIK_SERVICES_APP_LOADBALANCER_SERVER_WEIGHT=5",
+			},
+			expected: &config.Configuration{
+				Services: map[string]*config.Service{
+					"app": {
+						LoadBalancer: &config.LoadBalancerService{
+							Servers:        []config.Server{{URL: "http://10.0.0.1:8080", Scheme: "http", Weight: 5}},
+							Method:         "wrr",
+							PassHostHeader: true,
+						},
+					},
+				},
+			},
+		},
+		{
+			desc: "underscore in a name",
+			environ: []string{
+				"TRAEFIK_ROUTERS_MY__APP_RULE=Host:foo.bar",
+				"TRAEFIK_ROUTERS_MY__APP_SERVICE=my_app",
+			},
+			expected: &config.Configuration{
+				Routers: map[string]*config.Router{
+					"my_app": {
+						Rule:    "Host:foo.bar",
+						Service: "my_app",
+					},
+				},
+			},
+		},
+		{
+			desc: "unknown variables",
+			environ: []string{
+				"TRAEFIK_SERVICE_HOST=10.0.0.1",
+				"TRAEFIK_PORT_80_TCP=tcp://10.0.0.1:80",
+				"TRAEFIK_=foo",
+				"TRAEFIK_ROUTERS_=foo",
+				"FOO_ROUTERS_APP_RULE=Host:foo.bar",
+			},
+			expected: &config.Configuration{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf, err := DecodeConfiguration(test.environ)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, conf)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type log struct {
+		LogLevel string
+		FilePath string
+	}
+
+	type entryPoint struct {
+		Address     string
+		Middlewares []string
+	}
+
+	type staticConfiguration struct {
+		EntryPoints map[string]*entryPoint
+		Log         *log
+		Ignored     string `label:"-"`
+	}
+
+	element := &staticConfiguration{Log: &log{LogLevel: "ERROR", FilePath: "/var/log/traefik.log"}}
+
+	err := Decode([]string{
+		"TRAEFIK_ENTRYPOINTS_WEB_ADDRESS=:80",
+		"TRAEFIK_ENTRYPOINTS_WEB_MIDDLEWARES=foo,bar",
+		"TRAEFIK_LOG_LOGLEVEL=DEBUG",
+		"TRAEFIK_IGNORED=foo",
+	}, element)
+	require.NoError(t, err)
+
+	expected := &staticConfiguration{
+		EntryPoints: map[string]*entryPoint{
+			"web": {Address: ":80", Middlewares: []string{"foo", "bar"}},
+		},
+		Log: &log{LogLevel: "DEBUG", FilePath: "/var/log/traefik.log"},
+	}
+	assert.Equal(t, expected, element)
+}
@@ -205,7 +205,7 @@ func (p *Provider) loadFileConfig(filename string, parseTemplate bool) (*config.
 	}
 	configuration.TLS = tlsConfigs
 
-	if configuration == nil || configuration.Routers == nil && configuration.Middlewares == nil && configuration.Services == nil && configuration.TLS == nil {
+	if configuration == nil || configuration.Routers == nil && configuration.Middlewares == nil && configuration.Services == nil && configuration.TLS == nil && configuration.TLSOptions == nil {
 		configuration = &config.Configuration{
 			Routers:     make(map[string]*config.Router),
 			Middlewares: make(map[string]*config.Middleware),
@@ -283,6 +283,8 @@ func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory st
 				configTLSMaps[conf] = struct{}{}
 			}
 		}
+
+		configuration.TLSOptions = append(configuration.TLSOptions, c.TLSOptions...)
 	}
 
 	for conf := range configTLSMaps {
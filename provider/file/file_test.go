@@ -358,3 +358,27 @@ entryPoints = ["https"]
 	require.Equal(t, "CONTENT", configuration.TLS[0].Certificate.CertFile.String())
 	require.Equal(t, "CONTENT", configuration.TLS[0].Certificate.KeyFile.String())
 }
+
+func TestServerWeight(t *testing.T) {
+	provider := &Provider{}
+	configuration, err := provider.DecodeConfiguration(`
+[services]
+  [services.service1.loadbalancer]
+    [[services.service1.loadbalancer.servers]]
+      url = "http://172.17.0.2:80"
+      weight = 10
+    [[services.service1.loadbalancer.servers]]
+      url = "http://172.17.0.3:80"
+    [[services.service1.loadbalancer.servers]]
+      url = "http://172.17.0.4:80"
+      weight = 0
+`)
+	require.NoError(t, err)
+
+	expected := []config.Server{
+		{URL: "http://172.17.0.2:80", Weight: 10},
+		{URL: "http://172.17.0.3:80", Weight: 1},
+		{URL: "http://172.17.0.4:80", Weight: 0},
+	}
+	assert.Equal(t, expected, configuration.Services["service1"].LoadBalancer.Servers)
+}
@@ -117,6 +117,7 @@ func TestDecodeConfiguration(t *testing.T) {
 
 		"traefik.services.Service0.loadbalancer.healthcheck.headers.name0":        "foobar",
 		"traefik.services.Service0.loadbalancer.healthcheck.headers.name1":        "foobar",
+		"traefik.services.Service0.loadbalancer.healthcheck.critical":             "true",
 		"traefik.services.Service0.loadbalancer.healthcheck.hostname":             "foobar",
 		"traefik.services.Service0.loadbalancer.healthcheck.interval":             "foobar",
 		"traefik.services.Service0.loadbalancer.healthcheck.path":                 "foobar",
@@ -125,13 +126,20 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.services.Service0.loadbalancer.healthcheck.timeout":              "foobar",
 		"traefik.services.Service0.loadbalancer.method":                           "foobar",
 		"traefik.services.Service0.loadbalancer.passhostheader":                   "true",
+		"traefik.services.Service0.loadbalancer.scheme":                           "https",
 		"traefik.services.Service0.loadbalancer.responseforwarding.flushinterval": "foobar",
 		"traefik.services.Service0.loadbalancer.server.scheme":                    "foobar",
 		"traefik.services.Service0.loadbalancer.server.port":                      "8080",
 		"traefik.services.Service0.loadbalancer.server.weight":                    "42",
 		"traefik.services.Service0.loadbalancer.stickiness.cookiename":            "foobar",
+		"traefik.services.Service0.loadbalancer.stickiness.secure":                "true",
+		"traefik.services.Service0.loadbalancer.stickiness.httponly":              "true",
+		"traefik.services.Service0.loadbalancer.stickiness.samesite":              "foobar",
+		"traefik.services.Service0.loadbalancer.stickiness.partitioned":           "true",
+		"traefik.services.Service0.loadbalancer.stickiness.consistentfailover":    "true",
 		"traefik.services.Service1.loadbalancer.healthcheck.headers.name0":        "foobar",
 		"traefik.services.Service1.loadbalancer.healthcheck.headers.name1":        "foobar",
+		"traefik.services.Service1.loadbalancer.healthcheck.critical":             "true",
 		"traefik.services.Service1.loadbalancer.healthcheck.hostname":             "foobar",
 		"traefik.services.Service1.loadbalancer.healthcheck.interval":             "foobar",
 		"traefik.services.Service1.loadbalancer.healthcheck.path":                 "foobar",
@@ -410,7 +418,12 @@ func TestDecodeConfiguration(t *testing.T) {
 			"Service0": {
 				LoadBalancer: &config.LoadBalancerService{
 					Stickiness: &config.Stickiness{
-						CookieName: "foobar",
+						CookieName:         "foobar",
+						Secure:             true,
+						HTTPOnly:           true,
+						SameSite:           "foobar",
+						Partitioned:        true,
+						ConsistentFailover: true,
 					},
 					Servers: []config.Server{
 						{
@@ -426,6 +439,7 @@ func TestDecodeConfiguration(t *testing.T) {
 						Port:     42,
 						Interval: "foobar",
 						Timeout:  "foobar",
+						Critical: true,
 						Hostname: "foobar",
 						Headers: map[string]string{
 							"name0": "foobar",
@@ -433,6 +447,7 @@ func TestDecodeConfiguration(t *testing.T) {
 						},
 					},
 					PassHostHeader: true,
+					Scheme:         "https",
 					ResponseForwarding: &config.ResponseForwarding{
 						FlushInterval: "foobar",
 					},
@@ -454,6 +469,7 @@ func TestDecodeConfiguration(t *testing.T) {
 						Port:     42,
 						Interval: "foobar",
 						Timeout:  "foobar",
+						Critical: true,
 						Hostname: "foobar",
 						Headers: map[string]string{
 							"name0": "foobar",
@@ -609,7 +625,7 @@ func TestEncodeConfiguration(t *testing.T) {
 				},
 			},
 			"Middleware19": {
-				Compress: &config.Compress{},
+				Compress: &config.Compress{Level: 6},
 			},
 			"Middleware2": {
 				Buffering: &config.Buffering{
@@ -733,7 +749,12 @@ func TestEncodeConfiguration(t *testing.T) {
 			"Service0": {
 				LoadBalancer: &config.LoadBalancerService{
 					Stickiness: &config.Stickiness{
-						CookieName: "foobar",
+						CookieName:         "foobar",
+						Secure:             true,
+						HTTPOnly:           true,
+						SameSite:           "foobar",
+						Partitioned:        true,
+						ConsistentFailover: true,
 					},
 					Servers: []config.Server{
 						{
@@ -749,6 +770,7 @@ func TestEncodeConfiguration(t *testing.T) {
 						Port:     42,
 						Interval: "foobar",
 						Timeout:  "foobar",
+						Critical: true,
 						Hostname: "foobar",
 						Headers: map[string]string{
 							"name0": "foobar",
@@ -756,6 +778,7 @@ func TestEncodeConfiguration(t *testing.T) {
 						},
 					},
 					PassHostHeader: true,
+					Scheme:         "https",
 					ResponseForwarding: &config.ResponseForwarding{
 						FlushInterval: "foobar",
 					},
@@ -777,6 +800,7 @@ func TestEncodeConfiguration(t *testing.T) {
 						Port:     42,
 						Interval: "foobar",
 						Timeout:  "foobar",
+						Critical: true,
 						Hostname: "foobar",
 						Headers: map[string]string{
 							"name0": "foobar",
@@ -819,6 +843,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.Middlewares.Middleware6.Errors.Status":                                   "foobar, fiibar",
 		"traefik.Middlewares.Middleware7.ForwardAuth.Address":                             "foobar",
 		"traefik.Middlewares.Middleware7.ForwardAuth.AuthResponseHeaders":                 "foobar, fiibar",
+		"traefik.Middlewares.Middleware7.ForwardAuth.RemoveHeader":                        "false",
 		"traefik.Middlewares.Middleware7.ForwardAuth.TLS.CA":                              "foobar",
 		"traefik.Middlewares.Middleware7.ForwardAuth.TLS.CAOptional":                      "true",
 		"traefik.Middlewares.Middleware7.ForwardAuth.TLS.Cert":                            "foobar",
@@ -855,6 +880,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.Middlewares.Middleware9.IPWhiteList.SourceRange":                         "foobar, fiibar",
 		"traefik.Middlewares.Middleware10.MaxConn.Amount":                                 "42",
 		"traefik.Middlewares.Middleware10.MaxConn.ExtractorFunc":                          "foobar",
+		"traefik.Middlewares.Middleware10.MaxConn.RejectionStatusCode":                    "0",
 		"traefik.Middlewares.Middleware11.PassTLSClientCert.Info.NotAfter":                "true",
 		"traefik.Middlewares.Middleware11.PassTLSClientCert.Info.NotBefore":               "true",
 		"traefik.Middlewares.Middleware11.PassTLSClientCert.Info.Sans":                    "true",
@@ -873,6 +899,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.Middlewares.Middleware12.RateLimit.RateSet.Rate1.Average":                "42",
 		"traefik.Middlewares.Middleware12.RateLimit.RateSet.Rate1.Burst":                  "42",
 		"traefik.Middlewares.Middleware12.RateLimit.RateSet.Rate1.Period":                 "42",
+		"traefik.Middlewares.Middleware12.RateLimit.RejectionStatusCode":                  "0",
 		"traefik.Middlewares.Middleware13.RedirectRegex.Regex":                            "foobar",
 		"traefik.Middlewares.Middleware13.RedirectRegex.Replacement":                      "foobar",
 		"traefik.Middlewares.Middleware13.RedirectRegex.Permanent":                        "true",
@@ -883,15 +910,20 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.Middlewares.Middleware15.ReplacePathRegex.Regex":                         "foobar",
 		"traefik.Middlewares.Middleware15.ReplacePathRegex.Replacement":                   "foobar",
 		"traefik.Middlewares.Middleware16.Retry.Attempts":                                 "42",
+		"traefik.Middlewares.Middleware16.Retry.HealthyServersAttempts":                   "false",
+		"traefik.Middlewares.Middleware16.Retry.ResponseErrors":                           "false",
+		"traefik.Middlewares.Middleware17.StripPrefix.DisableForwardedPrefixHeader":       "false",
 		"traefik.Middlewares.Middleware17.StripPrefix.Prefixes":                           "foobar, fiibar",
 		"traefik.Middlewares.Middleware18.StripPrefixRegex.Regex":                         "foobar, fiibar",
-		"traefik.Middlewares.Middleware19.Compress":                                       "true",
+		"traefik.Middlewares.Middleware19.Compress.Level":                                 "6",
 
+		"traefik.Routers.Router0.CatchAll":    "false",
 		"traefik.Routers.Router0.EntryPoints": "foobar, fiibar",
 		"traefik.Routers.Router0.Middlewares": "foobar, fiibar",
 		"traefik.Routers.Router0.Priority":    "42",
 		"traefik.Routers.Router0.Rule":        "foobar",
 		"traefik.Routers.Router0.Service":     "foobar",
+		"traefik.Routers.Router1.CatchAll":    "false",
 		"traefik.Routers.Router1.EntryPoints": "foobar, fiibar",
 		"traefik.Routers.Router1.Middlewares": "foobar, fiibar",
 		"traefik.Routers.Router1.Priority":    "42",
@@ -899,6 +931,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.Routers.Router1.Service":     "foobar",
 
 		"traefik.Services.Service0.LoadBalancer.HealthCheck.Headers.name1":        "foobar",
+		"traefik.Services.Service0.LoadBalancer.HealthCheck.Critical":             "true",
 		"traefik.Services.Service0.LoadBalancer.HealthCheck.Hostname":             "foobar",
 		"traefik.Services.Service0.LoadBalancer.HealthCheck.Interval":             "foobar",
 		"traefik.Services.Service0.LoadBalancer.HealthCheck.Path":                 "foobar",
@@ -907,13 +940,20 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.Services.Service0.LoadBalancer.HealthCheck.Timeout":              "foobar",
 		"traefik.Services.Service0.LoadBalancer.Method":                           "foobar",
 		"traefik.Services.Service0.LoadBalancer.PassHostHeader":                   "true",
+		"traefik.Services.Service0.LoadBalancer.Scheme":                           "https",
 		"traefik.Services.Service0.LoadBalancer.ResponseForwarding.FlushInterval": "foobar",
 		"traefik.Services.Service0.LoadBalancer.server.Port":                      "8080",
 		"traefik.Services.Service0.LoadBalancer.server.Scheme":                    "foobar",
 		"traefik.Services.Service0.LoadBalancer.server.Weight":                    "42",
 		"traefik.Services.Service0.LoadBalancer.Stickiness.CookieName":            "foobar",
+		"traefik.Services.Service0.LoadBalancer.Stickiness.Secure":                "true",
+		"traefik.Services.Service0.LoadBalancer.Stickiness.HTTPOnly":              "true",
+		"traefik.Services.Service0.LoadBalancer.Stickiness.SameSite":              "foobar",
+		"traefik.Services.Service0.LoadBalancer.Stickiness.Partitioned":           "true",
+		"traefik.Services.Service0.LoadBalancer.Stickiness.ConsistentFailover":    "true",
 		"traefik.Services.Service1.LoadBalancer.HealthCheck.Headers.name0":        "foobar",
 		"traefik.Services.Service1.LoadBalancer.HealthCheck.Headers.name1":        "foobar",
+		"traefik.Services.Service1.LoadBalancer.HealthCheck.Critical":             "true",
 		"traefik.Services.Service1.LoadBalancer.HealthCheck.Hostname":             "foobar",
 		"traefik.Services.Service1.LoadBalancer.HealthCheck.Interval":             "foobar",
 		"traefik.Services.Service1.LoadBalancer.HealthCheck.Path":                 "foobar",
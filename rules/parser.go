@@ -42,6 +42,18 @@ func parseDomain(tree *tree) []string {
 	case "and", "or":
 		return append(parseDomain(tree.ruleLeft), parseDomain(tree.ruleRight)...)
 	case "Host":
+		// The certificates of the domains cover all their ports.
+		var domains []string
+		seen := make(map[string]bool)
+		for _, host := range tree.value {
+			name, _ := splitHostPort(host)
+			if !seen[name] {
+				seen[name] = true
+				domains = append(domains, name)
+			}
+		}
+		return domains
+	case "HostSNI":
 		return tree.value
 	default:
 		return nil
@@ -2,6 +2,7 @@ package rules
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 
@@ -12,8 +13,10 @@ import (
 )
 
 var funcs = map[string]func(*mux.Route, ...string) error{
+	"ALPN":          alpn,
 	"Host":          host,
 	"HostRegexp":    hostRegexp,
+	"HostSNI":       hostSNI,
 	"Path":          path,
 	"PathPrefix":    pathPrefix,
 	"Method":        methods,
@@ -93,8 +96,12 @@ func pathPrefix(route *mux.Route, paths ...string) error {
 }
 
 func host(route *mux.Route, hosts ...string) error {
+	names := make([]string, len(hosts))
+	rawNames := make([]string, len(hosts))
+	ports := make([]string, len(hosts))
 	for i, host := range hosts {
-		hosts[i] = strings.ToLower(host)
+		rawNames[i], ports[i] = splitHostPort(host)
+		names[i] = strings.ToLower(rawNames[i])
 	}
 
 	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
@@ -104,19 +111,89 @@ func host(route *mux.Route, hosts ...string) error {
 			return false
 		}
 
+		reqPort := requestPort(req)
+
 		flatH := requestdecorator.GetCNAMEFlatten(req.Context())
 		if len(flatH) > 0 {
-			for _, host := range hosts {
-				if strings.EqualFold(reqHost, host) || strings.EqualFold(flatH, host) {
+			for i, host := range names {
+				if (strings.EqualFold(reqHost, host) || strings.EqualFold(flatH, host)) && matchPort(ports[i], reqPort) {
 					return true
 				}
-				log.FromContext(req.Context()).Debugf("CNAMEFlattening: request %s which resolved to %s, is not matched to route %s", reqHost, flatH, host)
+				log.FromContext(req.Context()).Debugf("CNAMEFlattening: request %s which resolved to %s, is not matched to route %s", reqHost, flatH, hosts[i])
+			}
+			return false
+		}
+
+		hostNames := names
+		if requestdecorator.IsCaseSensitiveHost(req.Context()) {
+			hostNames = rawNames
+		}
+
+		for i, host := range hostNames {
+			if reqHost == host && matchPort(ports[i], reqPort) {
+				return true
 			}
+		}
+		return false
+	})
+	return nil
+}
+
+// splitHostPort returns the name and the port of a host, the port being empty when the host has none.
+func splitHostPort(host string) (string, string) {
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return host, ""
+	}
+	return name, port
+}
+
+// requestPort returns the port of the Host header of the request,
+// or the port of the entry point on which the request arrived, when the Host header has none.
+func requestPort(req *http.Request) string {
+	if _, port := splitHostPort(req.Host); len(port) > 0 {
+		return port
+	}
+
+	if addr, ok := req.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		_, port := splitHostPort(addr.String())
+		return port
+	}
+	return ""
+}
+
+// matchPort returns true if the host of a rule has no port, or the same port as the request.
+func matchPort(port, reqPort string) bool {
+	return len(port) == 0 || port == reqPort
+}
+
+// hostSNI matches the TLS requests whose server name, announced through SNI, is one of the hosts, whatever their Host header.
+func hostSNI(route *mux.Route, hosts ...string) error {
+	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		if req.TLS == nil {
 			return false
 		}
 
 		for _, host := range hosts {
-			if reqHost == host {
+			if strings.EqualFold(req.TLS.ServerName, host) {
+				return true
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// alpn matches the TLS requests whose protocol, negotiated through ALPN with the entry point terminating TLS, is one of the protocols.
+// The protocols advertised by the client in its ClientHello but not negotiated are not matched.
+func alpn(route *mux.Route, protocols ...string) error {
+	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		if req.TLS == nil {
+			return false
+		}
+
+		for _, protocol := range protocols {
+			if req.TLS.NegotiatedProtocol == protocol {
 				return true
 			}
 		}
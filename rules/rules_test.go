@@ -1,6 +1,8 @@
 package rules
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -8,6 +10,7 @@ import (
 	"github.com/containous/mux"
 	"github.com/containous/traefik/middlewares/requestdecorator"
 	"github.com/containous/traefik/testhelpers"
+	"github.com/containous/traefik/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -50,6 +53,16 @@ func Test_addRoute(t *testing.T) {
 				"http://localhost/foo": http.StatusOK,
 			},
 		},
+		{
+			desc: "Host with mixed case and port",
+			rule: "Host(`Example.com`)",
+			expected: map[string]int{
+				"http://example.com/foo":     http.StatusOK,
+				"http://EXAMPLE.com:443/foo": http.StatusOK,
+				"http://Example.com:80/foo":  http.StatusOK,
+				"http://example.org:443/foo": http.StatusNotFound,
+			},
+		},
 		{
 			desc: "wrong Host",
 			rule: "Host(`nope`)",
@@ -647,6 +660,330 @@ func TestHostRegexp(t *testing.T) {
 	}
 }
 
+func TestHostRegexp_HostHeader(t *testing.T) {
+	rt := &mux.Route{}
+	err := hostRegexp(rt, "{subdomain:[a-z]+}.bar.com")
+	require.NoError(t, err)
+
+	hosts := map[string]bool{
+		"foo.bar.com":     true,
+		"Foo.Bar.com:443": true,
+		"FOO.BAR.COM:80":  true,
+		"foo.bar.org:443": false,
+	}
+
+	for host, match := range hosts {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = host
+		assert.Equal(t, match, rt.Match(req, &mux.RouteMatch{}), host)
+	}
+}
+
+func TestHost_Port(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		rule          string
+		host          string
+		localAddr     string
+		expectedMatch bool
+	}{
+		{
+			desc:          "port of the host header",
+			rule:          "Host(`example.com:8080`)",
+			host:          "example.com:8080",
+			localAddr:     "10.0.0.1:80",
+			expectedMatch: true,
+		},
+		{
+			desc:      "other port of the host header",
+			rule:      "Host(`example.com:8080`)",
+			host:      "example.com:8081",
+			localAddr: "10.0.0.1:8080",
+		},
+		{
+			desc:          "port of the entry point",
+			rule:          "Host(`example.com:8080`)",
+			host:          "example.com",
+			localAddr:     "10.0.0.1:8080",
+			expectedMatch: true,
+		},
+		{
+			desc:      "other port of the entry point",
+			rule:      "Host(`example.com:8080`)",
+			host:      "example.com",
+			localAddr: "10.0.0.1:80",
+		},
+		{
+			desc:      "other host",
+			rule:      "Host(`example.com:8080`)",
+			host:      "example.org:8080",
+			localAddr: "10.0.0.1:8080",
+		},
+		{
+			desc:          "insensitive host with port",
+			rule:          "Host(`Example.Com:8080`)",
+			host:          "EXAMPLE.com:8080",
+			expectedMatch: true,
+		},
+		{
+			desc:          "host without port",
+			rule:          "Host(`example.com`)",
+			host:          "example.com:8081",
+			localAddr:     "10.0.0.1:8080",
+			expectedMatch: true,
+		},
+		{
+			desc:          "one of the hosts",
+			rule:          "Host(`example.com:8080`, `example.com:8443`)",
+			host:          "example.com",
+			localAddr:     "10.0.0.1:8443",
+			expectedMatch: true,
+		},
+		{
+			desc:          "IPv6 host with port",
+			rule:          "Host(`[::1]:8080`)",
+			host:          "[::1]:8080",
+			expectedMatch: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router, err := NewRouter()
+			require.NoError(t, err)
+
+			err = router.AddRoute(test.rule, 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = test.host
+			if len(test.localAddr) > 0 {
+				addr, err := net.ResolveTCPAddr("tcp", test.localAddr)
+				require.NoError(t, err)
+				req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, addr))
+			}
+
+			recorder := httptest.NewRecorder()
+			requestdecorator.New(nil).ServeHTTP(recorder, req, router.ServeHTTP)
+
+			if test.expectedMatch {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			} else {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestHost_CaseSensitive(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		rule          string
+		host          string
+		caseSensitive bool
+		expectedMatch bool
+	}{
+		{
+			desc:          "mixed-case host",
+			rule:          "Host(`example.com`)",
+			host:          "Example.COM",
+			expectedMatch: true,
+		},
+		{
+			desc:          "mixed-case host with port",
+			rule:          "Host(`Example.com`)",
+			host:          "EXAMPLE.com:443",
+			expectedMatch: true,
+		},
+		{
+			desc:          "case-sensitive host",
+			rule:          "Host(`Example.com`)",
+			host:          "Example.com:443",
+			caseSensitive: true,
+			expectedMatch: true,
+		},
+		{
+			desc:          "case-sensitive host with another case",
+			rule:          "Host(`example.com`)",
+			host:          "Example.COM",
+			caseSensitive: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router, err := NewRouter()
+			require.NoError(t, err)
+
+			err = router.AddRoute(test.rule, 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = test.host
+
+			recorder := httptest.NewRecorder()
+			requestdecorator.New(&types.HostResolverConfig{CaseSensitive: test.caseSensitive}).ServeHTTP(recorder, req, router.ServeHTTP)
+
+			if test.expectedMatch {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			} else {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestHostSNI(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		rule           string
+		host           string
+		serverName     string
+		withoutTLS     bool
+		expectedStatus int
+	}{
+		{
+			desc:           "server name different from the host",
+			rule:           "HostSNI(`foo.bar`)",
+			host:           "other.bar",
+			serverName:     "foo.bar",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "insensitive server name",
+			rule:           "HostSNI(`Foo.Bar`)",
+			host:           "foo.bar",
+			serverName:     "FOO.bar",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "host matching without the server name",
+			rule:           "HostSNI(`foo.bar`)",
+			host:           "foo.bar",
+			serverName:     "other.bar",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "request without TLS",
+			rule:           "HostSNI(`foo.bar`)",
+			host:           "foo.bar",
+			withoutTLS:     true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "server name and path",
+			rule:           "HostSNI(`foo.bar`,`baz.bar`) && PathPrefix(`/foo`)",
+			host:           "other.bar",
+			serverName:     "baz.bar",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router, err := NewRouter()
+			require.NoError(t, err)
+
+			err = router.AddRoute(test.rule, 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "https://"+test.host+"/foo", nil)
+			if test.withoutTLS {
+				req.TLS = nil
+			} else {
+				req.TLS.ServerName = test.serverName
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestALPN(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		negotiatedProtocol string
+		withoutTLS         bool
+		expectedBody       string
+		expectedStatus     int
+	}{
+		{
+			desc:               "h2",
+			negotiatedProtocol: "h2",
+			expectedBody:       "h2 service",
+			expectedStatus:     http.StatusOK,
+		},
+		{
+			desc:               "http/1.1",
+			negotiatedProtocol: "http/1.1",
+			expectedBody:       "http/1.1 service",
+			expectedStatus:     http.StatusOK,
+		},
+		{
+			desc:               "custom protocol",
+			negotiatedProtocol: "foo",
+			expectedBody:       "http/1.1 service",
+			expectedStatus:     http.StatusOK,
+		},
+		{
+			desc:           "no negotiated protocol",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "request without TLS",
+			withoutTLS:     true,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router, err := NewRouter()
+			require.NoError(t, err)
+
+			err = router.AddRoute("ALPN(`h2`)", 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("h2 service"))
+			}))
+			require.NoError(t, err)
+
+			err = router.AddRoute("ALPN(`http/1.1`,`foo`)", 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("http/1.1 service"))
+			}))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "https://foo.bar/foo", nil)
+			if test.withoutTLS {
+				req.TLS = nil
+			} else {
+				req.TLS.NegotiatedProtocol = test.negotiatedProtocol
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedStatus == http.StatusOK {
+				assert.Equal(t, test.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
 func TestParseDomains(t *testing.T) {
 	testCases := []struct {
 		description   string
@@ -677,6 +1014,18 @@ func TestParseDomains(t *testing.T) {
 			domain:        []string{"foo.bar"},
 			errorExpected: false,
 		},
+		{
+			description:   "HostSNI rule",
+			expression:    "HostSNI(`Foo.Bar`) && Path(`/test`)",
+			domain:        []string{"foo.bar"},
+			errorExpected: false,
+		},
+		{
+			description:   "Host rule with ports",
+			expression:    "Host(`foo.bar:8080`,`foo.bar:8443`,`test.bar`)",
+			domain:        []string{"foo.bar", "test.bar"},
+			errorExpected: false,
+		},
 		{
 			description:   "Host rule with no domain",
 			expression:    "Host() && Path(`/test`)",
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/go-kit/kit/metrics"
+)
+
+// connectionLimitListener bounds the number of open connections of a listener.
+// Beyond the limit, an accepted connection waits for the queue timeout that a connection closes,
+// and is closed if none does.
+type connectionLimitListener struct {
+	net.Listener
+	slots        chan struct{}
+	queueTimeout time.Duration
+
+	lock  sync.Mutex
+	count int
+	gauge metrics.Gauge
+}
+
+func newConnectionLimitListener(inner net.Listener, maxConnections int, queueTimeout time.Duration) *connectionLimitListener {
+	return &connectionLimitListener{
+		Listener:     inner,
+		slots:        make(chan struct{}, maxConnections),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// setGauge sets the gauge of the open connections, before the listener accepts connections.
+func (l *connectionLimitListener) setGauge(gauge metrics.Gauge) {
+	l.gauge = gauge
+}
+
+// Accept returns the next connection within the limit.
+func (l *connectionLimitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if l.acquire() {
+			l.updateCount(1)
+			return &limitedConn{Conn: conn, listener: l}, nil
+		}
+
+		log.WithoutContext().Debugf("Connection limit of %d reached, closing the connection from %s", cap(l.slots), conn.RemoteAddr())
+		_ = conn.Close()
+	}
+}
+
+func (l *connectionLimitListener) acquire() bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if l.queueTimeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (l *connectionLimitListener) release() {
+	<-l.slots
+	l.updateCount(-1)
+}
+
+func (l *connectionLimitListener) updateCount(delta int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.count += delta
+	if l.gauge != nil {
+		l.gauge.Set(float64(l.count))
+	}
+}
+
+// limitedConn releases its slot of the connection limit when closed.
+type limitedConn struct {
+	net.Conn
+	listener *connectionLimitListener
+	once     sync.Once
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.listener.release)
+	return err
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryPoint_MaxConnections(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		queueTimeout time.Duration
+	}{
+		{
+			desc: "without queue",
+		},
+		{
+			desc:         "with queue",
+			queueTimeout: 2 * time.Second,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+				Address:                    "127.0.0.1:0",
+				Transport:                  &static.EntryPointsTransport{},
+				ForwardedHeaders:           &static.ForwardedHeaders{},
+				MaxConnections:             2,
+				MaxConnectionsQueueTimeout: parse.Duration(test.queueTimeout),
+			})
+			require.NoError(t, err)
+
+			gauge := &testhelpers.CollectingGauge{}
+			entryPoint.listener.(*connectionLimitListener).setGauge(gauge)
+
+			go entryPoint.Start(context.Background())
+			defer entryPoint.httpServer.Close()
+
+			addr := entryPoint.listener.Addr().String()
+
+			var conns []net.Conn
+			for i := 0; i < 2; i++ {
+				conn, err := net.Dial("tcp", addr)
+				require.NoError(t, err)
+				defer conn.Close()
+
+				assert.Equal(t, http.StatusNotFound, get(t, conn))
+				conns = append(conns, conn)
+			}
+			assert.Equal(t, float64(2), gauge.GaugeValue)
+
+			refused, err := net.Dial("tcp", addr)
+			require.NoError(t, err)
+			defer refused.Close()
+
+			if test.queueTimeout == 0 {
+				// The connection beyond the limit is closed without being served.
+				_, err = fmt.Fprint(refused, "GET / HTTP/1.1\r\nHost: foo\r\n\r\n")
+				require.NoError(t, err)
+				require.NoError(t, refused.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+				_, err = refused.Read(make([]byte, 1))
+				require.Error(t, err)
+				netErr, ok := err.(net.Error)
+				assert.False(t, ok && netErr.Timeout(), "the connection beyond the limit was not closed")
+			} else {
+				// The queued connection is served once another one closes.
+				require.NoError(t, conns[0].Close())
+				assert.Equal(t, http.StatusNotFound, get(t, refused))
+			}
+		})
+	}
+}
+
+func get(t *testing.T, conn net.Conn) int {
+	t.Helper()
+
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	_, err := fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: foo\r\n\r\n")
+	require.NoError(t, err)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	return resp.StatusCode
+}
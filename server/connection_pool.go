@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/metrics"
+	"github.com/containous/traefik/server/service"
+)
+
+// connectionPool keeps track of the open and in use connections per backend host,
+// and bounds the time spent waiting for a connection when the per-host limit is reached.
+// The gauges of the connections are labeled with the name of the pool transport.
+type connectionPool struct {
+	name            string
+	transport       http.RoundTripper
+	waitTimeout     time.Duration
+	metricsRegistry metrics.Registry
+
+	lock  sync.Mutex
+	hosts map[string]*hostConnections
+}
+
+type hostConnections struct {
+	open   int
+	active int
+}
+
+func newConnectionPool(name string, waitTimeout time.Duration, metricsRegistry metrics.Registry) *connectionPool {
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	return &connectionPool{
+		name:            name,
+		waitTimeout:     waitTimeout,
+		metricsRegistry: metricsRegistry,
+		hosts:           make(map[string]*hostConnections),
+	}
+}
+
+// wrapDialContext tracks the connections opened by the given dial function.
+func (p *connectionPool) wrapDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		p.update(addr, 1, 0)
+
+		return &trackedConn{Conn: conn, onClose: func() { p.update(addr, -1, 0) }}, nil
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *connectionPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := canonicalAddr(req.URL)
+
+	ctx, cancel := context.WithCancel(req.Context())
+
+	var gotConn, timedOut int32
+	var timer *time.Timer
+	if p.waitTimeout > 0 {
+		timer = time.AfterFunc(p.waitTimeout, func() {
+			if atomic.LoadInt32(&gotConn) == 0 {
+				atomic.StoreInt32(&timedOut, 1)
+				cancel()
+			}
+		})
+	}
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) {
+			if atomic.CompareAndSwapInt32(&gotConn, 0, 1) {
+				if timer != nil {
+					timer.Stop()
+				}
+				p.update(host, 0, 1)
+			}
+		},
+	}
+
+	resp, err := p.transport.RoundTrip(req.WithContext(httptrace.WithClientTrace(ctx, trace)))
+
+	if timer != nil {
+		timer.Stop()
+	}
+
+	if err != nil {
+		cancel()
+		if atomic.LoadInt32(&gotConn) == 1 {
+			p.update(host, 0, -1)
+		}
+		if atomic.LoadInt32(&timedOut) == 1 {
+			return nil, service.ErrConnectionPoolTimeout
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		// The connection is handed over to the upgraded protocol, and the body must stay an io.ReadWriteCloser.
+		cancel()
+		p.update(host, 0, -1)
+		return resp, nil
+	}
+
+	resp.Body = &trackedBody{ReadCloser: resp.Body, onClose: func() {
+		cancel()
+		p.update(host, 0, -1)
+	}}
+
+	return resp, nil
+}
+
+func (p *connectionPool) update(host string, openDelta, activeDelta int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	conns, ok := p.hosts[host]
+	if !ok {
+		conns = &hostConnections{}
+		p.hosts[host] = conns
+	}
+
+	conns.open += openDelta
+	conns.active += activeDelta
+
+	idle := conns.open - conns.active
+	if idle < 0 {
+		idle = 0
+	}
+
+	p.metricsRegistry.BackendServerActiveConnsGauge().With("transport", p.name, "host", host).Set(float64(conns.active))
+	p.metricsRegistry.BackendServerIdleConnsGauge().With("transport", p.name, "host", host).Set(float64(idle))
+
+	if conns.open <= 0 && conns.active <= 0 {
+		delete(p.hosts, host)
+	}
+}
+
+type trackedConn struct {
+	net.Conn
+	once    sync.Once
+	onClose func()
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(c.onClose)
+	return c.Conn.Close()
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	once    sync.Once
+	onClose func()
+}
+
+func (b *trackedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.onClose)
+	return err
+}
+
+// canonicalAddr returns the host:port of the URL, with the default port of the scheme if missing.
+func canonicalAddr(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https":
+			port = "443"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
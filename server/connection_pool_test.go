@@ -0,0 +1,205 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/metrics"
+	"github.com/containous/traefik/server/service"
+	"github.com/containous/traefik/testhelpers"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionPool_WaitTimeout(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-release
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	roundTripper, err := createHTTPTransport(&static.ServersTransport{
+		MaxConnsPerHost:     1,
+		MaxConnsWaitTimeout: parse.Duration(50 * time.Millisecond),
+	}, nil)
+	require.NoError(t, err)
+
+	pool, ok := roundTripper.(*connectionPool)
+	require.True(t, ok)
+
+	firstDone := make(chan error, 1)
+	go func() {
+		req := httptest.NewRequest(http.MethodGet, backend.URL, nil)
+		req.RequestURI = ""
+		resp, errRT := roundTripper.RoundTrip(req)
+		if errRT == nil {
+			_, _ = ioutil.ReadAll(resp.Body)
+			errRT = resp.Body.Close()
+		}
+		firstDone <- errRT
+	}()
+
+	// Wait for the first request to hold the only connection.
+	deadline := time.Now().Add(time.Second)
+	for !hasActiveConnection(pool) {
+		require.True(t, time.Now().Before(deadline), "the first request did not get a connection")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, backend.URL, nil)
+	req.RequestURI = ""
+	_, err = roundTripper.RoundTrip(req)
+	assert.Equal(t, service.ErrConnectionPoolTimeout, err)
+
+	close(release)
+	require.NoError(t, <-firstDone)
+
+	assert.False(t, hasActiveConnection(pool))
+}
+
+func TestConnectionPool_HeaderTransportLimit(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Tenant") == "limited" {
+			<-release
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	roundTripper, err := createHTTPTransport(&static.ServersTransport{
+		HeaderTransports: &static.HeaderTransports{
+			Header: "X-Tenant",
+			Transports: map[string]*static.ServersTransport{
+				"limited": {
+					MaxConnsPerHost:     1,
+					MaxConnsWaitTimeout: parse.Duration(50 * time.Millisecond),
+				},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	pool, ok := roundTripper.(*headerRoundTripper).transports["limited"].(*connectionPool)
+	require.True(t, ok)
+
+	newRequest := func(tenant string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, backend.URL, nil)
+		req.RequestURI = ""
+		req.Header.Set("X-Tenant", tenant)
+		return req
+	}
+
+	firstDone := make(chan error, 1)
+	go func() {
+		resp, errRT := roundTripper.RoundTrip(newRequest("limited"))
+		if errRT == nil {
+			_, _ = ioutil.ReadAll(resp.Body)
+			errRT = resp.Body.Close()
+		}
+		firstDone <- errRT
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for !hasActiveConnection(pool) {
+		require.True(t, time.Now().Before(deadline), "the first request did not get a connection")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The limit of the transport of the header value does not apply to the default transport.
+	resp, err := roundTripper.RoundTrip(newRequest("other"))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = roundTripper.RoundTrip(newRequest("limited"))
+	assert.Equal(t, service.ErrConnectionPoolTimeout, err)
+
+	close(release)
+	require.NoError(t, <-firstDone)
+}
+
+type connsRegistry struct {
+	metrics.Registry
+	active *testhelpers.CollectingGauge
+}
+
+func (r *connsRegistry) BackendServerActiveConnsGauge() gokitmetrics.Gauge {
+	return r.active
+}
+
+func TestConnectionPool_GaugesWithoutLimit(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	registry := &connsRegistry{Registry: metrics.NewVoidRegistry(), active: &testhelpers.CollectingGauge{}}
+
+	roundTripper, err := createHTTPTransport(&static.ServersTransport{}, registry)
+	require.NoError(t, err)
+
+	pool, ok := roundTripper.(*connectionPool)
+	require.True(t, ok)
+
+	// The gauges are updated under the lock of the pool, also by the goroutines of the connections.
+	activeGauge := func() (float64, []string) {
+		pool.lock.Lock()
+		defer pool.lock.Unlock()
+		return registry.active.GaugeValue, registry.active.LastLabelValues
+	}
+
+	req := httptest.NewRequest(http.MethodGet, backend.URL, nil)
+	req.RequestURI = ""
+	resp, err := roundTripper.RoundTrip(req)
+	require.NoError(t, err)
+
+	value, labels := activeGauge()
+	assert.Equal(t, float64(1), value)
+	assert.Equal(t, []string{"transport", "default", "host", canonicalAddr(req.URL)}, labels)
+
+	require.NoError(t, resp.Body.Close())
+	value, _ = activeGauge()
+	assert.Equal(t, float64(0), value)
+}
+
+func hasActiveConnection(pool *connectionPool) bool {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	for _, conns := range pool.hosts {
+		if conns.active > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCanonicalAddr(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{url: "http://foo", expected: "foo:80"},
+		{url: "https://foo", expected: "foo:443"},
+		{url: "http://foo:8080", expected: "foo:8080"},
+		{url: "http://[::1]", expected: "[::1]:80"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.url, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			assert.Equal(t, test.expected, canonicalAddr(req.URL))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package cookie
 import (
 	"crypto/sha1"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/containous/traefik/log"
@@ -10,6 +11,82 @@ import (
 
 const cookieNameLength = 6
 
+const (
+	securePrefix = "__Secure-"
+	hostPrefix   = "__Host-"
+)
+
+// sameSiteNoneMode is the value of http.SameSiteNoneMode, which only exists since Go 1.13.
+const sameSiteNoneMode http.SameSite = http.SameSiteStrictMode + 1
+
+// Options holds the attributes of a cookie.
+type Options struct {
+	Secure      bool
+	HTTPOnly    bool
+	SameSite    http.SameSite
+	Partitioned bool
+}
+
+// NewOptions creates the options of the named cookie,
+// and checks that they are consistent with the name prefix and with the SameSite mode.
+func NewOptions(name string, secure, httpOnly bool, sameSite string, partitioned bool) (Options, error) {
+	options := Options{
+		Secure:      secure,
+		HTTPOnly:    httpOnly,
+		Partitioned: partitioned,
+	}
+
+	switch strings.ToLower(sameSite) {
+	case "":
+	case "none":
+		options.SameSite = sameSiteNoneMode
+	case "lax":
+		options.SameSite = http.SameSiteLaxMode
+	case "strict":
+		options.SameSite = http.SameSiteStrictMode
+	default:
+		return Options{}, fmt.Errorf("invalid SameSite mode %q for cookie %s", sameSite, name)
+	}
+
+	if secure {
+		return options, nil
+	}
+
+	switch {
+	case options.SameSite == sameSiteNoneMode:
+		return Options{}, fmt.Errorf("cookie %s with SameSite=None must be secure", name)
+	case partitioned:
+		return Options{}, fmt.Errorf("partitioned cookie %s must be secure", name)
+	case strings.HasPrefix(name, securePrefix), strings.HasPrefix(name, hostPrefix):
+		return Options{}, fmt.Errorf("cookie %s with a %s or %s prefix must be secure", name, securePrefix, hostPrefix)
+	}
+
+	return options, nil
+}
+
+// IsDefault returns true if none of the attributes are set.
+func (o Options) IsDefault() bool {
+	return o == Options{}
+}
+
+// Apply sets the attributes on the cookie, and returns its serialization for a Set-Cookie header.
+func (o Options) Apply(cookie *http.Cookie) string {
+	cookie.Secure = cookie.Secure || o.Secure
+	cookie.HttpOnly = cookie.HttpOnly || o.HTTPOnly
+	if o.SameSite != 0 && o.SameSite != sameSiteNoneMode {
+		cookie.SameSite = o.SameSite
+	}
+
+	value := cookie.String()
+	if o.SameSite == sameSiteNoneMode {
+		value += "; SameSite=None"
+	}
+	if o.Partitioned {
+		value += "; Partitioned"
+	}
+	return value
+}
+
 // GetName of a cookie
 func GetName(cookieName string, backendName string) string {
 	if len(cookieName) != 0 {
@@ -1,9 +1,11 @@
 package cookie
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetName(t *testing.T) {
@@ -81,3 +83,122 @@ func TestGenerateName(t *testing.T) {
 	assert.Len(t, "_8a7bc", 6)
 	assert.Equal(t, "_8a7bc", cookieName)
 }
+
+func TestNewOptions(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		name          string
+		secure        bool
+		sameSite      string
+		partitioned   bool
+		expected      Options
+		expectedError bool
+	}{
+		{
+			desc: "default",
+			name: "foo",
+		},
+		{
+			desc:     "SameSite lax",
+			name:     "foo",
+			sameSite: "Lax",
+			expected: Options{SameSite: http.SameSiteLaxMode},
+		},
+		{
+			desc:     "SameSite strict",
+			name:     "foo",
+			sameSite: "strict",
+			expected: Options{SameSite: http.SameSiteStrictMode},
+		},
+		{
+			desc:     "SameSite none with secure",
+			name:     "foo",
+			secure:   true,
+			sameSite: "none",
+			expected: Options{Secure: true, SameSite: sameSiteNoneMode},
+		},
+		{
+			desc:          "SameSite none without secure",
+			name:          "foo",
+			sameSite:      "none",
+			expectedError: true,
+		},
+		{
+			desc:          "invalid SameSite",
+			name:          "foo",
+			sameSite:      "foo",
+			expectedError: true,
+		},
+		{
+			desc:          "partitioned without secure",
+			name:          "foo",
+			partitioned:   true,
+			expectedError: true,
+		},
+		{
+			desc:     "__Host- prefix with secure",
+			name:     "__Host-foo",
+			secure:   true,
+			expected: Options{Secure: true},
+		},
+		{
+			desc:          "__Host- prefix without secure",
+			name:          "__Host-foo",
+			expectedError: true,
+		},
+		{
+			desc:          "__Secure- prefix without secure",
+			name:          "__Secure-foo",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			options, err := NewOptions(test.name, test.secure, false, test.sameSite, test.partitioned)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, options)
+		})
+	}
+}
+
+func TestOptions_Apply(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		options  Options
+		expected string
+	}{
+		{
+			desc:     "default",
+			expected: "foo=bar; Path=/",
+		},
+		{
+			desc:     "all attributes",
+			options:  Options{Secure: true, HTTPOnly: true, SameSite: sameSiteNoneMode, Partitioned: true},
+			expected: "foo=bar; Path=/; HttpOnly; Secure; SameSite=None; Partitioned",
+		},
+		{
+			desc:     "SameSite strict",
+			options:  Options{SameSite: http.SameSiteStrictMode},
+			expected: "foo=bar; Path=/; SameSite=Strict",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			value := test.options.Apply(&http.Cookie{Name: "foo", Value: "bar", Path: "/"})
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
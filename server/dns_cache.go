@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+)
+
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// dnsCache caches the addresses of the backend hosts for a fixed TTL, whatever the TTL of their DNS records.
+// Once expired, the addresses are still used while they are refreshed in the background,
+// and they are kept when the refresh fails.
+type dnsCache struct {
+	resolver hostResolver
+	ttl      time.Duration
+	now      func() time.Time
+
+	lock    sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+type dnsEntry struct {
+	addrs      []string
+	expiration time.Time
+	refreshing bool
+}
+
+func newDNSCache(ttl time.Duration, resolver hostResolver) *dnsCache {
+	return &dnsCache{
+		resolver: resolver,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  make(map[string]*dnsEntry),
+	}
+}
+
+// wrapDialContext resolves the hosts through the cache, and dials their addresses in order until one succeeds.
+func (c *dnsCache) wrapDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+
+		addrs, err := c.lookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var dialErr error
+		for _, ip := range addrs {
+			conn, err := dial(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			dialErr = err
+		}
+		return nil, dialErr
+	}
+}
+
+func (c *dnsCache) lookupHost(ctx context.Context, host string) ([]string, error) {
+	c.lock.Lock()
+	entry, ok := c.entries[host]
+	if ok {
+		if !entry.refreshing && c.now().After(entry.expiration) {
+			entry.refreshing = true
+			go c.refresh(host)
+		}
+		addrs := entry.addrs
+		c.lock.Unlock()
+		return addrs, nil
+	}
+	c.lock.Unlock()
+
+	addrs, err := c.resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no address found for %s", host)
+	}
+
+	c.lock.Lock()
+	c.entries[host] = &dnsEntry{addrs: addrs, expiration: c.now().Add(c.ttl)}
+	c.lock.Unlock()
+
+	return addrs, nil
+}
+
+func (c *dnsCache) refresh(host string) {
+	addrs, err := c.resolver.LookupHost(context.Background(), host)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := c.entries[host]
+	entry.refreshing = false
+	entry.expiration = c.now().Add(c.ttl)
+
+	if err != nil || len(addrs) == 0 {
+		log.WithoutContext().Debugf("Keeping the cached addresses of %s, which could not be resolved: %v", host, err)
+		return
+	}
+	entry.addrs = addrs
+}
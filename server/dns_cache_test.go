@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResolver struct {
+	lock    sync.Mutex
+	addrs   []string
+	err     error
+	lookups chan string
+}
+
+func (r *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.lookups <- host
+	return r.addrs, r.err
+}
+
+func (r *fakeResolver) set(addrs []string, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.addrs, r.err = addrs, err
+}
+
+func TestDNSCache(t *testing.T) {
+	resolver := &fakeResolver{addrs: []string{"10.0.0.1"}, lookups: make(chan string, 10)}
+
+	now := time.Now()
+	cache := newDNSCache(time.Minute, resolver)
+	cache.now = func() time.Time { return now }
+
+	dialed := make(chan string, 10)
+	dial := cache.wrapDialContext(func(_ context.Context, _, addr string) (net.Conn, error) {
+		dialed <- addr
+		client, server := net.Pipe()
+		_ = server.Close()
+		return client, nil
+	})
+
+	dialFoo := func() string {
+		conn, err := dial(context.Background(), "tcp", "foo.bar:80")
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+		return <-dialed
+	}
+
+	// Miss.
+	assert.Equal(t, "10.0.0.1:80", dialFoo())
+	assert.Equal(t, "foo.bar", <-resolver.lookups)
+
+	// Hit.
+	resolver.set([]string{"10.0.0.2"}, nil)
+	assert.Equal(t, "10.0.0.1:80", dialFoo())
+	assert.Len(t, resolver.lookups, 0)
+
+	// Expired: the cached address is used while it is refreshed in the background.
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, "10.0.0.1:80", dialFoo())
+	assert.Equal(t, "foo.bar", <-resolver.lookups)
+
+	waitForRefresh(t, cache, "foo.bar")
+	assert.Equal(t, "10.0.0.2:80", dialFoo())
+	assert.Len(t, resolver.lookups, 0)
+
+	// Failed refresh: the cached address is kept.
+	resolver.set(nil, errors.New("no such host"))
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, "10.0.0.2:80", dialFoo())
+	assert.Equal(t, "foo.bar", <-resolver.lookups)
+
+	waitForRefresh(t, cache, "foo.bar")
+	assert.Equal(t, "10.0.0.2:80", dialFoo())
+
+	// IP addresses are not resolved.
+	conn, err := dial(context.Background(), "tcp", "10.0.0.3:80")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	assert.Equal(t, "10.0.0.3:80", <-dialed)
+	assert.Len(t, resolver.lookups, 0)
+}
+
+func TestDNSCache_LookupError(t *testing.T) {
+	resolver := &fakeResolver{err: errors.New("no such host"), lookups: make(chan string, 10)}
+	cache := newDNSCache(time.Minute, resolver)
+
+	dial := cache.wrapDialContext(func(_ context.Context, _, addr string) (net.Conn, error) {
+		t.Fatalf("unexpected dial of %s", addr)
+		return nil, nil
+	})
+
+	_, err := dial(context.Background(), "tcp", "foo.bar:80")
+	assert.Error(t, err)
+
+	// The errors are not cached.
+	_, err = dial(context.Background(), "tcp", "foo.bar:80")
+	assert.Error(t, err)
+	assert.Len(t, resolver.lookups, 2)
+}
+
+func waitForRefresh(t *testing.T, cache *dnsCache, host string) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		cache.lock.Lock()
+		refreshing := cache.entries[host].refreshing
+		cache.lock.Unlock()
+
+		if !refreshing {
+			return
+		}
+	}
+	t.Fatalf("the addresses of %s were not refreshed", host)
+}
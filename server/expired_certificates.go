@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/log"
+)
+
+// expiredCertificatesDialer establishes the TLS connections to the servers and verifies their certificates
+// as the transport would, except that the expired certificates are accepted during the grace period after their expiry.
+type expiredCertificatesDialer struct {
+	transport   *http.Transport
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	gracePeriod time.Duration
+	now         func() time.Time
+}
+
+func newExpiredCertificatesDialer(transport *http.Transport, dialContext func(ctx context.Context, network, addr string) (net.Conn, error), gracePeriod time.Duration) *expiredCertificatesDialer {
+	return &expiredCertificatesDialer{
+		transport:   transport,
+		dialContext: dialContext,
+		gracePeriod: gracePeriod,
+		now:         time.Now,
+	}
+}
+
+func (d *expiredCertificatesDialer) dialTLS(network, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := d.dialContext(context.Background(), network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{}
+	if d.transport.TLSClientConfig != nil {
+		config = d.transport.TLSClientConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+
+	serverName := config.ServerName
+	roots := config.RootCAs
+	config.InsecureSkipVerify = true
+	config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		return d.verify(rawCerts, serverName, roots)
+	}
+
+	tlsConn := tls.Client(conn, config)
+
+	if timeout := d.transport.TLSHandshakeTimeout; timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(timeout))
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return tlsConn, nil
+}
+
+// verify verifies the certificate chain of the server at the current time,
+// or at the expiry of its first expired certificate if it expired during the grace period.
+func (d *expiredCertificatesDialer) verify(rawCerts [][]byte, serverName string, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("no certificate presented by the server")
+	}
+
+	var certs []*x509.Certificate
+	for _, rawCert := range rawCerts {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	now := d.now()
+	opts := x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	}
+
+	_, err := certs[0].Verify(opts)
+	if invalid, ok := err.(x509.CertificateInvalidError); !ok || invalid.Reason != x509.Expired {
+		return err
+	}
+
+	var expiry time.Time
+	for _, cert := range certs {
+		if cert.NotAfter.Before(now) && (expiry.IsZero() || cert.NotAfter.Before(expiry)) {
+			expiry = cert.NotAfter
+		}
+	}
+	if expiry.IsZero() || now.Sub(expiry) > d.gracePeriod {
+		return err
+	}
+
+	opts.CurrentTime = expiry
+	if _, errExpiry := certs[0].Verify(opts); errExpiry != nil {
+		return err
+	}
+
+	log.WithoutContext().Warnf("Accepting the TLS certificate of %s, which expired on %s, during the grace period of %s", serverName, expiry.UTC().Format(time.RFC3339), d.gracePeriod)
+	return nil
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config/static"
+	traefiktls "github.com/containous/traefik/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateHTTPTransport_ExpiredCertificatesGracePeriod(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		expiry        time.Duration
+		gracePeriod   time.Duration
+		serverName    string
+		expectedError bool
+	}{
+		{
+			desc:   "valid certificate",
+			expiry: time.Hour,
+		},
+		{
+			desc:          "expired certificate",
+			expiry:        -time.Hour,
+			expectedError: true,
+		},
+		{
+			desc:        "valid certificate with a grace period",
+			expiry:      time.Hour,
+			gracePeriod: 2 * time.Hour,
+		},
+		{
+			desc:        "expired certificate during the grace period",
+			expiry:      -time.Hour,
+			gracePeriod: 2 * time.Hour,
+		},
+		{
+			desc:          "expired certificate after the grace period",
+			expiry:        -time.Hour,
+			gracePeriod:   30 * time.Minute,
+			expectedError: true,
+		},
+		{
+			desc:          "expired certificate of another server name during the grace period",
+			expiry:        -time.Hour,
+			gracePeriod:   2 * time.Hour,
+			serverName:    "bar.foo",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			certPEM, keyPEM := generateServerCertificate(t, "foo.bar", time.Now().Add(test.expiry))
+			certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+			require.NoError(t, err)
+
+			backend := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			}))
+			backend.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+			backend.StartTLS()
+			defer backend.Close()
+
+			serverName := "foo.bar"
+			if test.serverName != "" {
+				serverName = test.serverName
+			}
+
+			roundTripper, err := newHTTPTransport(defaultTransportName, &static.ServersTransport{
+				RootCAs:                        traefiktls.FilesOrContents{traefiktls.FileOrContent(certPEM)},
+				ExpiredCertificatesGracePeriod: parse.Duration(test.gracePeriod),
+			}, serverName, nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, backend.URL, nil)
+			req.RequestURI = ""
+			resp, err := roundTripper.RoundTrip(req)
+			if test.expectedError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "x509: certificate")
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+// generateServerCertificate generates a self-signed certificate, valid from a day before its expiry.
+func generateServerCertificate(t *testing.T, domain string, expiry time.Time) ([]byte, []byte) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: domain},
+		NotBefore:             expiry.Add(-24 * time.Hour),
+		NotAfter:              expiry,
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{domain},
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+	return certPEM, keyPEM
+}
@@ -10,20 +10,28 @@ import (
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/middlewares/addprefix"
 	"github.com/containous/traefik/middlewares/auth"
+	"github.com/containous/traefik/middlewares/bandwidth"
 	"github.com/containous/traefik/middlewares/buffering"
+	"github.com/containous/traefik/middlewares/cache"
 	"github.com/containous/traefik/middlewares/chain"
 	"github.com/containous/traefik/middlewares/circuitbreaker"
 	"github.com/containous/traefik/middlewares/compress"
 	"github.com/containous/traefik/middlewares/customerrors"
+	"github.com/containous/traefik/middlewares/disablemethods"
+	"github.com/containous/traefik/middlewares/externalprocessor"
 	"github.com/containous/traefik/middlewares/headers"
+	"github.com/containous/traefik/middlewares/hostrewrite"
 	"github.com/containous/traefik/middlewares/ipwhitelist"
+	"github.com/containous/traefik/middlewares/maintenance"
 	"github.com/containous/traefik/middlewares/maxconnection"
 	"github.com/containous/traefik/middlewares/passtlsclientcert"
 	"github.com/containous/traefik/middlewares/ratelimiter"
 	"github.com/containous/traefik/middlewares/redirect"
 	"github.com/containous/traefik/middlewares/replacepath"
 	"github.com/containous/traefik/middlewares/replacepathregex"
+	"github.com/containous/traefik/middlewares/requesttimeout"
 	"github.com/containous/traefik/middlewares/retry"
+	"github.com/containous/traefik/middlewares/statuscoderewrite"
 	"github.com/containous/traefik/middlewares/stripprefix"
 	"github.com/containous/traefik/middlewares/stripprefixregex"
 	"github.com/containous/traefik/middlewares/tracing"
@@ -39,17 +47,33 @@ const (
 
 // Builder the middleware builder
 type Builder struct {
-	configs        map[string]*config.Middleware
-	serviceBuilder serviceBuilder
+	configs          map[string]*config.Middleware
+	serviceBuilder   serviceBuilder
+	clientIPStrategy *config.IPStrategy
+	errors           map[string]error
+	// retryBudgets are shared by all the instances of the retry middlewares, by middleware name.
+	retryBudgets map[string]*retry.Budget
 }
 
 type serviceBuilder interface {
 	Build(ctx context.Context, serviceName string, responseModifier func(*http.Response) error) (http.Handler, error)
 }
 
-// NewBuilder creates a new Builder
-func NewBuilder(configs map[string]*config.Middleware, serviceBuilder serviceBuilder) *Builder {
-	return &Builder{configs: configs, serviceBuilder: serviceBuilder}
+// NewBuilder creates a new Builder.
+// The client IP strategy is used by the middlewares which do not define their own IP strategy.
+func NewBuilder(configs map[string]*config.Middleware, serviceBuilder serviceBuilder, clientIPStrategy *config.IPStrategy) *Builder {
+	return &Builder{
+		configs:          configs,
+		serviceBuilder:   serviceBuilder,
+		clientIPStrategy: clientIPStrategy,
+		errors:           make(map[string]error),
+		retryBudgets:     make(map[string]*retry.Budget),
+	}
+}
+
+// GetError returns the error which occurred while building the given middleware, if any.
+func (b *Builder) GetError(middlewareName string) error {
+	return b.errors[middlewareName]
 }
 
 // BuildChain creates a middleware chain
@@ -71,14 +95,48 @@ func (b *Builder) BuildChain(ctx context.Context, middlewares []string) *alice.C
 
 			constructor, err := b.buildConstructor(constructorContext, middlewareName, *b.configs[middlewareName])
 			if err != nil {
-				return nil, fmt.Errorf("error during instanciation of %s: %v", middlewareName, err)
+				err = fmt.Errorf("error during instanciation of %s: %v", middlewareName, err)
+				b.errors[middlewareName] = err
+				return nil, err
 			}
-			return constructor(next)
+
+			handler, err := constructor(next)
+			if err != nil {
+				b.errors[middlewareName] = err
+				return nil, err
+			}
+			return handler, nil
 		})
 	}
 	return &chain
 }
 
+func (b *Builder) getIPStrategy(strategy *config.IPStrategy) *config.IPStrategy {
+	if strategy != nil {
+		return strategy
+	}
+	return b.clientIPStrategy
+}
+
+// getRetryBudget returns the budget of the retry middleware, shared by all its instances.
+func (b *Builder) getRetryBudget(middlewareName string, conf *config.RetryBudget) (*retry.Budget, error) {
+	if conf == nil {
+		return nil, nil
+	}
+
+	if budget, ok := b.retryBudgets[middlewareName]; ok {
+		return budget, nil
+	}
+
+	budget, err := retry.NewBudget(*conf)
+	if err != nil {
+		return nil, err
+	}
+
+	b.retryBudgets[middlewareName] = budget
+	return budget, nil
+}
+
 func checkRecursivity(ctx context.Context, middlewareName string) (context.Context, error) {
 	currentStack, ok := ctx.Value(middlewareStackKey).([]string)
 	if !ok {
@@ -105,6 +163,19 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// Bandwidth
+	if config.Bandwidth != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				conf := *config.Bandwidth
+				conf.IPStrategy = b.getIPStrategy(conf.IPStrategy)
+				return bandwidth.New(ctx, next, conf, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// BasicAuth
 	if config.BasicAuth != nil {
 		if middleware == nil {
@@ -127,6 +198,17 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// Cache
+	if config.Cache != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return cache.New(ctx, next, *config.Cache, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// Chain
 	if config.Chain != nil {
 		if middleware == nil {
@@ -142,7 +224,7 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 	if config.CircuitBreaker != nil {
 		if middleware == nil {
 			middleware = func(next http.Handler) (http.Handler, error) {
-				return circuitbreaker.New(ctx, next, *config.CircuitBreaker, middlewareName)
+				return circuitbreaker.New(ctx, next, *config.CircuitBreaker, b.serviceBuilder, middlewareName)
 			}
 		} else {
 			return nil, badConf
@@ -153,7 +235,7 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 	if config.Compress != nil {
 		if middleware == nil {
 			middleware = func(next http.Handler) (http.Handler, error) {
-				return compress.New(ctx, next, middlewareName)
+				return compress.New(ctx, next, *config.Compress, middlewareName)
 			}
 		} else {
 			return nil, badConf
@@ -182,6 +264,17 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// DisableMethods
+	if config.DisableMethods != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return disablemethods.New(ctx, next, *config.DisableMethods, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// ForwardAuth
 	if config.ForwardAuth != nil {
 		if middleware == nil {
@@ -193,6 +286,17 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// ExternalProcessor
+	if config.ExternalProcessor != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return externalprocessor.New(ctx, next, *config.ExternalProcessor, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// Headers
 	if config.Headers != nil {
 		if middleware == nil {
@@ -204,11 +308,37 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// HostRewrite
+	if config.HostRewrite != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return hostrewrite.New(ctx, next, *config.HostRewrite, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// IPWhiteList
 	if config.IPWhiteList != nil {
 		if middleware == nil {
 			middleware = func(next http.Handler) (http.Handler, error) {
-				return ipwhitelist.New(ctx, next, *config.IPWhiteList, middlewareName)
+				conf := *config.IPWhiteList
+				conf.IPStrategy = b.getIPStrategy(conf.IPStrategy)
+				return ipwhitelist.New(ctx, next, conf, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// Maintenance
+	if config.Maintenance != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				conf := *config.Maintenance
+				conf.IPStrategy = b.getIPStrategy(conf.IPStrategy)
+				return maintenance.New(ctx, next, conf, middlewareName)
 			}
 		} else {
 			return nil, badConf
@@ -219,7 +349,9 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 	if config.MaxConn != nil && config.MaxConn.Amount != 0 {
 		if middleware == nil {
 			middleware = func(next http.Handler) (http.Handler, error) {
-				return maxconnection.New(ctx, next, *config.MaxConn, middlewareName)
+				conf := *config.MaxConn
+				conf.IPStrategy = b.getIPStrategy(conf.IPStrategy)
+				return maxconnection.New(ctx, next, conf, middlewareName)
 			}
 		} else {
 			return nil, badConf
@@ -241,7 +373,9 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 	if config.RateLimit != nil {
 		if middleware == nil {
 			middleware = func(next http.Handler) (http.Handler, error) {
-				return ratelimiter.New(ctx, next, *config.RateLimit, middlewareName)
+				conf := *config.RateLimit
+				conf.IPStrategy = b.getIPStrategy(conf.IPStrategy)
+				return ratelimiter.New(ctx, next, conf, middlewareName)
 			}
 		} else {
 			return nil, badConf
@@ -292,12 +426,39 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// RequestTimeout
+	if config.RequestTimeout != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return requesttimeout.New(ctx, next, *config.RequestTimeout, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// Retry
 	if config.Retry != nil {
 		if middleware == nil {
 			middleware = func(next http.Handler) (http.Handler, error) {
+				budget, err := b.getRetryBudget(middlewareName, config.Retry.Budget)
+				if err != nil {
+					return nil, err
+				}
+
 				// FIXME missing metrics / accessLog
-				return retry.New(ctx, next, *config.Retry, retry.Listeners{}, middlewareName)
+				return retry.NewWithBudget(ctx, next, *config.Retry, budget, retry.Listeners{}, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// StatusCodeRewrite
+	if config.StatusCodeRewrite != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return statuscoderewrite.New(ctx, next, *config.StatusCodeRewrite, middlewareName)
 			}
 		} else {
 			return nil, badConf
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/containous/flaeg/parse"
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/server/internal"
 	"github.com/stretchr/testify/assert"
@@ -26,7 +28,7 @@ func TestBuilder_buildConstructorCircuitBreaker(t *testing.T) {
 			},
 		},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
 
 	emptyHandler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
 
@@ -69,7 +71,7 @@ func TestBuilder_BuildChainNilConfig(t *testing.T) {
 	testConfig := map[string]*config.Middleware{
 		"empty": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
 
 	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -80,7 +82,7 @@ func TestBuilder_BuildChainNonExistentChain(t *testing.T) {
 	testConfig := map[string]*config.Middleware{
 		"foobar": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
 
 	chain := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -101,7 +103,7 @@ func TestBuilder_buildConstructorAddPrefix(t *testing.T) {
 		},
 	}
 
-	middlewaresBuilder := NewBuilder(testConfig, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
 
 	testCases := []struct {
 		desc          string
@@ -368,7 +370,7 @@ func TestBuild_BuildChainWithContext(t *testing.T) {
 				ctx = internal.AddProviderInContext(ctx, test.contextProvider+".foobar")
 			}
 
-			builder := NewBuilder(test.configuration, nil)
+			builder := NewBuilder(test.configuration, nil, nil)
 
 			result := builder.BuildChain(ctx, test.buildChain)
 
@@ -389,3 +391,112 @@ func TestBuild_BuildChainWithContext(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilder_ClientIPStrategy(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		clientIPStrategy *config.IPStrategy
+		ipStrategy       *config.IPStrategy
+		remoteAddr       string
+		xForwardedFor    string
+		expectedIP       string
+	}{
+		{
+			desc:          "without client IP strategy",
+			remoteAddr:    "10.0.0.1:1234",
+			xForwardedFor: "192.0.2.1, 192.0.2.2",
+			expectedIP:    "10.0.0.1",
+		},
+		{
+			desc:             "with depth",
+			clientIPStrategy: &config.IPStrategy{Depth: 1},
+			remoteAddr:       "10.0.0.1:1234",
+			xForwardedFor:    "192.0.2.1, 192.0.2.2",
+			expectedIP:       "192.0.2.2",
+		},
+		{
+			desc:             "with excluded IPs",
+			clientIPStrategy: &config.IPStrategy{ExcludedIPs: []string{"192.0.2.2"}},
+			remoteAddr:       "10.0.0.1:1234",
+			xForwardedFor:    "192.0.2.1, 192.0.2.2",
+			expectedIP:       "192.0.2.1",
+		},
+		{
+			desc:             "with trusted proxy",
+			clientIPStrategy: &config.IPStrategy{Depth: 1, TrustedIPs: []string{"10.0.0.0/8"}},
+			remoteAddr:       "10.0.0.1:1234",
+			xForwardedFor:    "192.0.2.1, 192.0.2.2",
+			expectedIP:       "192.0.2.2",
+		},
+		{
+			desc:             "with untrusted proxy",
+			clientIPStrategy: &config.IPStrategy{Depth: 1, TrustedIPs: []string{"172.16.0.0/12"}},
+			remoteAddr:       "10.0.0.1:1234",
+			xForwardedFor:    "192.0.2.1, 192.0.2.2",
+			expectedIP:       "10.0.0.1",
+		},
+		{
+			desc:             "middleware IP strategy overrides the client IP strategy",
+			clientIPStrategy: &config.IPStrategy{Depth: 1},
+			ipStrategy:       &config.IPStrategy{Depth: 2},
+			remoteAddr:       "10.0.0.1:1234",
+			xForwardedFor:    "192.0.2.1, 192.0.2.2",
+			expectedIP:       "192.0.2.1",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			testConfig := map[string]*config.Middleware{
+				"allowed": {
+					IPWhiteList: &config.IPWhiteList{SourceRange: []string{test.expectedIP}, IPStrategy: test.ipStrategy},
+				},
+				"denied": {
+					IPWhiteList: &config.IPWhiteList{SourceRange: []string{"203.0.113.1"}, IPStrategy: test.ipStrategy},
+				},
+				"maintenance": {
+					Maintenance: &config.Maintenance{Enabled: true, SourceRange: []string{test.expectedIP}, IPStrategy: test.ipStrategy},
+				},
+				"ratelimit": {
+					RateLimit: &config.RateLimit{
+						ExtractorFunc: "client.ip",
+						RateSet:       map[string]*config.Rate{"rate": {Period: parse.Duration(time.Minute), Average: 1, Burst: 1}},
+						IPStrategy:    test.ipStrategy,
+					},
+				},
+			}
+			builder := NewBuilder(testConfig, nil, test.clientIPStrategy)
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			handlers := make(map[string]http.Handler)
+			for name := range testConfig {
+				handler, err := builder.BuildChain(context.Background(), []string{name}).Then(next)
+				require.NoError(t, err)
+				handlers[name] = handler
+			}
+
+			serve := func(middlewareName string, remoteAddr string, xForwardedFor string) int {
+				req := httptest.NewRequest(http.MethodGet, "http://foo", nil)
+				req.RemoteAddr = remoteAddr
+				req.Header.Set("X-Forwarded-For", xForwardedFor)
+
+				recorder := httptest.NewRecorder()
+				handlers[middlewareName].ServeHTTP(recorder, req)
+				return recorder.Code
+			}
+
+			assert.Equal(t, http.StatusOK, serve("allowed", test.remoteAddr, test.xForwardedFor))
+			assert.Equal(t, http.StatusForbidden, serve("denied", test.remoteAddr, test.xForwardedFor))
+			assert.Equal(t, http.StatusOK, serve("maintenance", test.remoteAddr, test.xForwardedFor))
+
+			// The rate is limited for the resolved client IP, whatever the port of the remote address.
+			assert.Equal(t, http.StatusOK, serve("ratelimit", test.remoteAddr, test.xForwardedFor))
+			assert.Equal(t, http.StatusTooManyRequests, serve("ratelimit", test.expectedIP+":5678", test.xForwardedFor))
+			assert.Equal(t, http.StatusOK, serve("ratelimit", "203.0.113.3:1234", "203.0.113.1, 203.0.113.2"))
+		})
+	}
+}
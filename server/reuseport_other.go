@@ -0,0 +1,11 @@
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package server
+
+import "syscall"
+
+const reusePortSupported = false
+
+func reusePortControl(network, address string, conn syscall.RawConn) error {
+	return nil
+}
@@ -0,0 +1,26 @@
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package server
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen_ReusePort(t *testing.T) {
+	first, err := listen(&static.EntryPoint{Address: "127.0.0.1:0", ReusePort: true})
+	require.NoError(t, err)
+	defer first.Close()
+
+	address := first.Addr().String()
+
+	second, err := listen(&static.EntryPoint{Address: address, ReusePort: true})
+	require.NoError(t, err)
+	defer second.Close()
+
+	_, err = listen(&static.EntryPoint{Address: address})
+	assert.Error(t, err, "the port should not be reusable without SO_REUSEPORT")
+}
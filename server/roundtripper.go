@@ -3,18 +3,26 @@ package server
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/containous/traefik/config/static"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/old/configuration"
+	"github.com/containous/traefik/server/service"
 	traefiktls "github.com/containous/traefik/tls"
 	"github.com/pkg/errors"
 	"golang.org/x/net/http2"
 )
 
+// defaultTransportName labels the connection gauges of the default transport,
+// the ones of the transports selected by a header are labeled with the header and its value, e.g. X-Tenant=foo.
+const defaultTransportName = "default"
+
 type h2cTransportWrapper struct {
 	*http2.Transport
 }
@@ -24,12 +32,24 @@ func (t *h2cTransportWrapper) RoundTrip(req *http.Request) (*http.Response, erro
 	return t.Transport.RoundTrip(req)
 }
 
-// createHTTPTransport creates an http.Transport configured with the Transport configuration settings.
+// createHTTPTransport creates an http.RoundTripper configured with the Transport configuration settings.
 // For the settings that can't be configured in Traefik it uses the default http.Transport settings.
-// An exception to this is the MaxIdleConns setting as we only provide the option MaxIdleConnsPerHost
-// in Traefik at this point in time. Setting this value to the default of 100 could lead to confusing
-// behavior and backwards compatibility issues.
-func createHTTPTransport(transportConfiguration *static.ServersTransport) (*http.Transport, error) {
+// An exception to this is the MaxIdleConns setting which defaults to no limit: setting this value
+// to the default of 100 could lead to confusing behavior and backwards compatibility issues.
+// When IdleConnTimeout is set, it overrides the default 90 seconds after which the idle connections are closed.
+// The response headers are awaited for DefaultResponseHeaderTimeout, unless the forwarding timeouts override it.
+// When DNSCacheTTL is set, the addresses of the backend hosts are cached for its duration.
+// The transport is wrapped to track the connections per host in the gauges of the connections,
+// and to bound the time spent waiting for a connection when MaxConnsPerHost is set.
+// When ExpiredCertificatesGracePeriod is set, the TLS connections are verified by the transport dialer,
+// which accepts the expired certificates of the servers during the grace period.
+func createHTTPTransport(transportConfiguration *static.ServersTransport, metricsRegistry metrics.Registry) (http.RoundTripper, error) {
+	return newHTTPTransport(defaultTransportName, transportConfiguration, "", metricsRegistry)
+}
+
+// newHTTPTransport creates the transport of createHTTPTransport, whose connection gauges are labeled with the given name.
+// When serverName is set, it overrides the SNI sent to the backend servers.
+func newHTTPTransport(name string, transportConfiguration *static.ServersTransport, serverName string, metricsRegistry metrics.Registry) (http.RoundTripper, error) {
 	if transportConfiguration == nil {
 		return nil, errors.New("no transport configuration given")
 	}
@@ -42,17 +62,42 @@ func createHTTPTransport(transportConfiguration *static.ServersTransport) (*http
 
 	if transportConfiguration.ForwardingTimeouts != nil {
 		dialer.Timeout = time.Duration(transportConfiguration.ForwardingTimeouts.DialTimeout)
+
+		if keepAlive := transportConfiguration.ForwardingTimeouts.DialKeepAlive; keepAlive != 0 {
+			dialer.KeepAlive = time.Duration(keepAlive)
+		}
 	}
 
+	dialContext := dialer.DialContext
+
+	if ttl := time.Duration(transportConfiguration.DNSCacheTTL); ttl > 0 {
+		dialContext = newDNSCache(ttl, net.DefaultResolver).wrapDialContext(dialContext)
+	}
+
+	var waitTimeout time.Duration
+	if transportConfiguration.MaxConnsPerHost > 0 {
+		waitTimeout = time.Duration(transportConfiguration.MaxConnsWaitTimeout)
+	}
+	pool := newConnectionPool(name, waitTimeout, metricsRegistry)
+	dialContext = pool.wrapDialContext(dialContext)
+
 	transport := &http.Transport{
 		Proxy:                 http.ProxyFromEnvironment,
-		DialContext:           dialer.DialContext,
+		DialContext:           dialContext,
+		MaxIdleConns:          transportConfiguration.MaxIdleConns,
 		MaxIdleConnsPerHost:   transportConfiguration.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       transportConfiguration.MaxConnsPerHost,
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 
+	if idleConnTimeout := transportConfiguration.IdleConnTimeout; idleConnTimeout > 0 {
+		transport.IdleConnTimeout = time.Duration(idleConnTimeout)
+	} else if idleConnTimeout < 0 {
+		transport.IdleConnTimeout = 0
+	}
+
 	transport.RegisterProtocol("h2c", &h2cTransportWrapper{
 		Transport: &http2.Transport{
 			DialTLS: func(netw, addr string, cfg *tls.Config) (net.Conn, error) {
@@ -62,8 +107,13 @@ func createHTTPTransport(transportConfiguration *static.ServersTransport) (*http
 		},
 	})
 
+	transport.ResponseHeaderTimeout = static.DefaultResponseHeaderTimeout
 	if transportConfiguration.ForwardingTimeouts != nil {
-		transport.ResponseHeaderTimeout = time.Duration(transportConfiguration.ForwardingTimeouts.ResponseHeaderTimeout)
+		if responseHeaderTimeout := transportConfiguration.ForwardingTimeouts.ResponseHeaderTimeout; responseHeaderTimeout > 0 {
+			transport.ResponseHeaderTimeout = time.Duration(responseHeaderTimeout)
+		} else {
+			transport.ResponseHeaderTimeout = 0
+		}
 	}
 
 	if transportConfiguration.InsecureSkipVerify {
@@ -76,11 +126,175 @@ func createHTTPTransport(transportConfiguration *static.ServersTransport) (*http
 		}
 	}
 
+	// The connection still goes to the server address, only the SNI and the verified name change.
+	if serverName != "" {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.ServerName = serverName
+	}
+
+	if len(transportConfiguration.Certificates) > 0 {
+		certificates, err := loadClientCertificates(transportConfiguration.Certificates)
+		if err != nil {
+			return nil, err
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.Certificates = certificates
+	}
+
 	err := http2.ConfigureTransport(transport)
 	if err != nil {
 		return nil, err
 	}
 
+	if gracePeriod := time.Duration(transportConfiguration.ExpiredCertificatesGracePeriod); gracePeriod > 0 && !transportConfiguration.InsecureSkipVerify {
+		transport.DialTLS = newExpiredCertificatesDialer(transport, dialContext, gracePeriod).dialTLS
+	}
+
+	pool.transport = transport
+
+	if transportConfiguration.HeaderTransports != nil {
+		return createHeaderRoundTripper(transportConfiguration, pool, serverName, metricsRegistry)
+	}
+
+	return pool, nil
+}
+
+func loadClientCertificates(certificates traefiktls.Certificates) ([]tls.Certificate, error) {
+	var tlsCertificates []tls.Certificate
+	for _, certificate := range certificates {
+		certContent, err := certificate.CertFile.Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client certificate %s: %v", certificate.CertFile, err)
+		}
+
+		keyContent, err := certificate.KeyFile.Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client key %s: %v", certificate.KeyFile, err)
+		}
+
+		tlsCertificate, err := tls.X509KeyPair(certContent, keyContent)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate %s: %v", certificate.CertFile, err)
+		}
+		tlsCertificates = append(tlsCertificates, tlsCertificate)
+	}
+	return tlsCertificates, nil
+}
+
+// headerRoundTripper forwards the requests through the transport selected by the value of a request header,
+// or through the default transport.
+type headerRoundTripper struct {
+	header     string
+	transports map[string]http.RoundTripper
+	fallback   http.RoundTripper
+}
+
+// The transports of the header values inherit the TLS verification settings of the default transport they do not set:
+// its root CAs, InsecureSkipVerify and expired certificates grace period.
+func createHeaderRoundTripper(parent *static.ServersTransport, defaultTransport http.RoundTripper, serverName string, metricsRegistry metrics.Registry) (http.RoundTripper, error) {
+	conf := parent.HeaderTransports
+	if conf.Header == "" {
+		return nil, errors.New("no header to select the transports")
+	}
+
+	rt := &headerRoundTripper{
+		header:     conf.Header,
+		transports: make(map[string]http.RoundTripper),
+		fallback:   defaultTransport,
+	}
+
+	for value, transportConfiguration := range conf.Transports {
+		if transportConfiguration == nil {
+			return nil, fmt.Errorf("no transport configuration given for the header value %q", value)
+		}
+		if transportConfiguration.HeaderTransports != nil {
+			return nil, fmt.Errorf("the transport of the header value %q cannot select other transports", value)
+		}
+
+		inherited := *transportConfiguration
+		if len(inherited.RootCAs) == 0 {
+			inherited.RootCAs = parent.RootCAs
+		}
+		if !inherited.InsecureSkipVerify {
+			inherited.InsecureSkipVerify = parent.InsecureSkipVerify
+		}
+		if inherited.ExpiredCertificatesGracePeriod == 0 {
+			inherited.ExpiredCertificatesGracePeriod = parent.ExpiredCertificatesGracePeriod
+		}
+
+		name := conf.Header + "=" + value
+		if serverName != "" {
+			name += ",serverName=" + serverName
+		}
+
+		transport, err := newHTTPTransport(name, &inherited, serverName, metricsRegistry)
+		if err != nil {
+			return nil, fmt.Errorf("error creating the transport of the header value %q: %v", value, err)
+		}
+		rt.transports[value] = transport
+	}
+
+	return rt, nil
+}
+
+func (h *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if transport, ok := h.transports[req.Header.Get(h.header)]; ok {
+		return transport.RoundTrip(req)
+	}
+	return h.fallback.RoundTrip(req)
+}
+
+// serverNameRoundTripper forwards the requests of the services overriding the server name of the TLS handshake
+// through a transport dedicated to this server name, created on first use, and the other requests through the default transport.
+type serverNameRoundTripper struct {
+	transportConfiguration *static.ServersTransport
+	metricsRegistry        metrics.Registry
+	fallback               http.RoundTripper
+
+	lock       sync.Mutex
+	transports map[string]http.RoundTripper
+}
+
+func createServerNameRoundTripper(transportConfiguration *static.ServersTransport, defaultTransport http.RoundTripper, metricsRegistry metrics.Registry) *serverNameRoundTripper {
+	return &serverNameRoundTripper{
+		transportConfiguration: transportConfiguration,
+		metricsRegistry:        metricsRegistry,
+		fallback:               defaultTransport,
+		transports:             make(map[string]http.RoundTripper),
+	}
+}
+
+func (s *serverNameRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	serverName := service.ServerNameFromContext(req.Context())
+	if serverName == "" {
+		return s.fallback.RoundTrip(req)
+	}
+
+	transport, err := s.getTransport(serverName)
+	if err != nil {
+		return nil, err
+	}
+	return transport.RoundTrip(req)
+}
+
+func (s *serverNameRoundTripper) getTransport(serverName string) (http.RoundTripper, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if transport, ok := s.transports[serverName]; ok {
+		return transport, nil
+	}
+
+	transport, err := newHTTPTransport(defaultTransportName+",serverName="+serverName, s.transportConfiguration, serverName, s.metricsRegistry)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the transport of the server name %q: %v", serverName, err)
+	}
+
+	s.transports[serverName] = transport
 	return transport, nil
 }
 
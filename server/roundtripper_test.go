@@ -0,0 +1,392 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/server/service"
+	traefiktls "github.com/containous/traefik/tls"
+	"github.com/containous/traefik/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/forward"
+)
+
+func TestServerNameRoundTripper(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		serverName         string
+		expectedServerName string
+	}{
+		{
+			desc:               "no override",
+			expectedServerName: "",
+		},
+		{
+			desc:               "override",
+			serverName:         "backend.example.com",
+			expectedServerName: "backend.example.com",
+		},
+		{
+			desc:               "other override",
+			serverName:         "other.example.com",
+			expectedServerName: "other.example.com",
+		},
+	}
+
+	serverNames := make(chan string, 1)
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	backend.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverNames <- hello.ServerName
+			return nil, nil
+		},
+	}
+	backend.StartTLS()
+	defer backend.Close()
+
+	transportConfiguration := &static.ServersTransport{InsecureSkipVerify: true}
+	transport, err := createHTTPTransport(transportConfiguration, nil)
+	require.NoError(t, err)
+
+	roundTripper := createServerNameRoundTripper(transportConfiguration, transport, nil)
+
+	// The services share the round tripper, each one is given the transport of its server name.
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			sm := service.NewManager(map[string]*config.Service{
+				"provider.foo": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:     "wrr",
+						Servers:    []config.Server{{URL: backend.URL, Weight: 1}},
+						ServerName: test.serverName,
+					},
+				},
+			}, roundTripper, nil)
+			serviceHandler, err := sm.Build(context.Background(), "provider.foo", nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			serviceHandler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			// No SNI is sent for an IP address.
+			assert.Equal(t, test.expectedServerName, <-serverNames)
+		})
+	}
+}
+
+func TestCreateHTTPTransport_DialTimeout(t *testing.T) {
+	roundTripper, err := createHTTPTransport(&static.ServersTransport{
+		ForwardingTimeouts: &static.ForwardingTimeouts{DialTimeout: parse.Duration(100 * time.Millisecond)},
+	}, nil)
+	require.NoError(t, err)
+
+	// 192.0.2.0/24 is reserved for documentation, so that nothing answers the connection.
+	req := httptest.NewRequest(http.MethodGet, "http://192.0.2.1", nil)
+	req.RequestURI = ""
+
+	start := time.Now()
+	_, err = roundTripper.RoundTrip(req)
+	require.Error(t, err)
+
+	assert.True(t, time.Since(start) < 5*time.Second, "the dial was not bounded by the dial timeout: %s", time.Since(start))
+}
+
+func TestCreateHTTPTransport_ResponseHeaderTimeout(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		forwardingTimeouts *static.ForwardingTimeouts
+		expectedTimeout    time.Duration
+	}{
+		{
+			desc:            "default",
+			expectedTimeout: static.DefaultResponseHeaderTimeout,
+		},
+		{
+			desc:               "zero",
+			forwardingTimeouts: &static.ForwardingTimeouts{},
+			expectedTimeout:    0,
+		},
+		{
+			desc:               "timeout",
+			forwardingTimeouts: &static.ForwardingTimeouts{ResponseHeaderTimeout: parse.Duration(5 * time.Second)},
+			expectedTimeout:    5 * time.Second,
+		},
+		{
+			desc:               "disabled",
+			forwardingTimeouts: &static.ForwardingTimeouts{ResponseHeaderTimeout: parse.Duration(-1)},
+			expectedTimeout:    0,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			roundTripper, err := createHTTPTransport(&static.ServersTransport{ForwardingTimeouts: test.forwardingTimeouts}, nil)
+			require.NoError(t, err)
+
+			pool, ok := roundTripper.(*connectionPool)
+			require.True(t, ok)
+			transport, ok := pool.transport.(*http.Transport)
+			require.True(t, ok)
+			assert.Equal(t, test.expectedTimeout, transport.ResponseHeaderTimeout)
+		})
+	}
+}
+
+func TestCreateHTTPTransport_ResponseHeaderTimeout_Unresponsive(t *testing.T) {
+	// The backend accepts the connections, but never responds.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				// Reads the request until the transport gives up and closes the connection.
+				_, _ = io.Copy(ioutil.Discard, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	roundTripper, err := createHTTPTransport(&static.ServersTransport{
+		ForwardingTimeouts: &static.ForwardingTimeouts{ResponseHeaderTimeout: parse.Duration(100 * time.Millisecond)},
+	}, nil)
+	require.NoError(t, err)
+
+	forwarder, err := forward.New(forward.RoundTripper(roundTripper))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://"+listener.Addr().String(), nil)
+	recorder := httptest.NewRecorder()
+
+	start := time.Now()
+	forwarder.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+	assert.True(t, time.Since(start) < 5*time.Second, "the response headers were awaited for %s", time.Since(start))
+}
+
+func TestCreateHTTPTransport_IdleConnTimeout(t *testing.T) {
+	closed := make(chan struct{})
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	backend.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			close(closed)
+		}
+	}
+	backend.Start()
+	defer backend.Close()
+
+	roundTripper, err := createHTTPTransport(&static.ServersTransport{
+		IdleConnTimeout: parse.Duration(100 * time.Millisecond),
+	}, nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, backend.URL, nil)
+	req.RequestURI = ""
+	resp, err := roundTripper.RoundTrip(req)
+	require.NoError(t, err)
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the idle connection was not closed after the idle timeout")
+	}
+}
+
+func TestCreateHTTPTransport_HeaderTransports(t *testing.T) {
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if len(req.TLS.PeerCertificates) == 0 {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = rw.Write([]byte(req.TLS.PeerCertificates[0].DNSNames[0]))
+	}))
+	backend.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	backend.StartTLS()
+	defer backend.Close()
+
+	clientCertificate := func(domain string) traefiktls.Certificates {
+		certPEM, keyPEM, err := generate.KeyPair(domain, time.Time{})
+		require.NoError(t, err)
+		return traefiktls.Certificates{{CertFile: traefiktls.FileOrContent(certPEM), KeyFile: traefiktls.FileOrContent(keyPEM)}}
+	}
+
+	roundTripper, err := createHTTPTransport(&static.ServersTransport{
+		InsecureSkipVerify: true,
+		Certificates:       clientCertificate("default.local"),
+		HeaderTransports: &static.HeaderTransports{
+			Header: "X-Tenant",
+			Transports: map[string]*static.ServersTransport{
+				"a": {InsecureSkipVerify: true, Certificates: clientCertificate("tenant-a.local")},
+				"b": {InsecureSkipVerify: true, Certificates: clientCertificate("tenant-b.local")},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc                string
+		tenant              string
+		expectedCertificate string
+	}{
+		{
+			desc:                "without header",
+			expectedCertificate: "default.local",
+		},
+		{
+			desc:                "tenant a",
+			tenant:              "a",
+			expectedCertificate: "tenant-a.local",
+		},
+		{
+			desc:                "tenant b",
+			tenant:              "b",
+			expectedCertificate: "tenant-b.local",
+		},
+		{
+			desc:                "unknown tenant",
+			tenant:              "c",
+			expectedCertificate: "default.local",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, backend.URL, nil)
+			req.RequestURI = ""
+			if len(test.tenant) > 0 {
+				req.Header.Set("X-Tenant", test.tenant)
+			}
+
+			resp, err := roundTripper.RoundTrip(req)
+			require.NoError(t, err)
+
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, test.expectedCertificate, string(body))
+		})
+	}
+}
+
+func TestCreateHTTPTransport_HeaderTransportsInheritance(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	defer backend.Close()
+
+	rootCA := traefiktls.FileOrContent(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: backend.Certificate().Raw}))
+
+	testCases := []struct {
+		desc            string
+		transport       *static.ServersTransport
+		tenantTransport *static.ServersTransport
+	}{
+		{
+			desc:            "inherited root CAs",
+			transport:       &static.ServersTransport{RootCAs: traefiktls.FilesOrContents{rootCA}},
+			tenantTransport: &static.ServersTransport{},
+		},
+		{
+			desc:            "inherited InsecureSkipVerify",
+			transport:       &static.ServersTransport{InsecureSkipVerify: true},
+			tenantTransport: &static.ServersTransport{},
+		},
+		{
+			desc:            "own root CAs",
+			transport:       &static.ServersTransport{},
+			tenantTransport: &static.ServersTransport{RootCAs: traefiktls.FilesOrContents{rootCA}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			test.transport.HeaderTransports = &static.HeaderTransports{
+				Header:     "X-Tenant",
+				Transports: map[string]*static.ServersTransport{"a": test.tenantTransport},
+			}
+
+			roundTripper, err := createHTTPTransport(test.transport, nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, backend.URL, nil)
+			req.RequestURI = ""
+			req.Header.Set("X-Tenant", "a")
+
+			resp, err := roundTripper.RoundTrip(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestCreateHTTPTransport_HeaderTransportsErrors(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		headerTransports *static.HeaderTransports
+	}{
+		{
+			desc:             "no header",
+			headerTransports: &static.HeaderTransports{Transports: map[string]*static.ServersTransport{"a": {}}},
+		},
+		{
+			desc: "nested header transports",
+			headerTransports: &static.HeaderTransports{
+				Header: "X-Tenant",
+				Transports: map[string]*static.ServersTransport{
+					"a": {HeaderTransports: &static.HeaderTransports{Header: "X-Other"}},
+				},
+			},
+		},
+		{
+			desc: "invalid client certificate",
+			headerTransports: &static.HeaderTransports{
+				Header: "X-Tenant",
+				Transports: map[string]*static.ServersTransport{
+					"a": {Certificates: traefiktls.Certificates{{CertFile: "foo", KeyFile: "bar"}}},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := createHTTPTransport(&static.ServersTransport{HeaderTransports: test.headerTransports}, nil)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/containous/traefik/log"
+)
+
+// requireClientCertificate rejects with a 403 the requests without a client certificate,
+// verified during the handshake against the client CAs of the entry point.
+func requireClientCertificate(next http.Handler, routerName string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 {
+			log.FromContext(req.Context()).Debugf("Rejecting request %s to the router %s: no verified client certificate", req.URL, routerName)
+			http.Error(rw, "A valid client certificate is required", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
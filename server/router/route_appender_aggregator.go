@@ -7,6 +7,7 @@ import (
 	"github.com/containous/mux"
 	"github.com/containous/traefik/api"
 	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/healthcheck"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/safe"
@@ -19,7 +20,8 @@ type chainBuilder interface {
 }
 
 // NewRouteAppenderAggregator Creates a new RouteAppenderAggregator
-func NewRouteAppenderAggregator(ctx context.Context, chainBuilder chainBuilder, conf static.Configuration, entryPointName string, currentConfiguration *safe.Safe) *RouteAppenderAggregator {
+// The router manager, if any, gives the errors of the current configuration and the statuses of its servers to the API.
+func NewRouteAppenderAggregator(ctx context.Context, chainBuilder chainBuilder, conf static.Configuration, entryPointName string, currentConfiguration *safe.Safe, routerManager *Manager) *RouteAppenderAggregator {
 	aggregator := &RouteAppenderAggregator{}
 
 	if conf.Providers != nil && conf.Providers.Rest != nil {
@@ -27,16 +29,26 @@ func NewRouteAppenderAggregator(ctx context.Context, chainBuilder chainBuilder,
 	}
 
 	if conf.API != nil && conf.API.EntryPoint == entryPointName {
+		apiHandler := api.Handler{
+			EntryPoint:            conf.API.EntryPoint,
+			Dashboard:             conf.API.Dashboard,
+			Statistics:            conf.API.Statistics,
+			DashboardAssets:       conf.API.DashboardAssets,
+			CurrentConfigurations: currentConfiguration,
+			HealthCheck:           healthcheck.GetHealthCheck(),
+			EntryPoints:           conf.EntryPoints,
+			Debug:                 conf.Global.Debug,
+		}
+		if routerManager != nil {
+			apiHandler.ConfigurationErrors = routerManager
+			if routerManager.serviceManager != nil {
+				apiHandler.ServerStatuses = routerManager.serviceManager
+			}
+		}
+
 		chain := chainBuilder.BuildChain(ctx, conf.API.Middlewares)
 		aggregator.AddAppender(&WithMiddleware{
-			appender: api.Handler{
-				EntryPoint:            conf.API.EntryPoint,
-				Dashboard:             conf.API.Dashboard,
-				Statistics:            conf.API.Statistics,
-				DashboardAssets:       conf.API.DashboardAssets,
-				CurrentConfigurations: currentConfiguration,
-				Debug:                 conf.Global.Debug,
-			},
+			appender:          apiHandler,
 			routerMiddlewares: chain,
 		})
 
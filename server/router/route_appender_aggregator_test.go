@@ -2,15 +2,22 @@ package router
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/containous/alice"
 	"github.com/containous/mux"
+	"github.com/containous/traefik/api"
+	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/config/static"
 	"github.com/containous/traefik/ping"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/server/service"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type ChainBuilderMock struct {
@@ -91,7 +98,7 @@ func TestNewRouteAppenderAggregator(t *testing.T) {
 
 			ctx := context.Background()
 
-			router := NewRouteAppenderAggregator(ctx, chainBuilder, test.staticConf, "traefik", nil)
+			router := NewRouteAppenderAggregator(ctx, chainBuilder, test.staticConf, "traefik", nil, nil)
 
 			internalMuxRouter := mux.NewRouter()
 			router.Append(internalMuxRouter)
@@ -112,3 +119,66 @@ func TestNewRouteAppenderAggregator(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRouteAppenderAggregator_ServerStatuses(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	services := map[string]*config.Service{
+		"foo.bar": {
+			LoadBalancer: &config.LoadBalancerService{
+				Method: "wrr",
+				CircuitBreaker: &config.ServerCircuitBreaker{
+					MinRequests:      2,
+					RecoveryDuration: "1m",
+				},
+				Servers: []config.Server{{URL: failing.URL, Weight: 1}},
+			},
+		},
+	}
+
+	serviceManager := service.NewManager(services, http.DefaultTransport, nil)
+	routerManager := NewManager(nil, serviceManager, nil, nil, nil, nil)
+
+	ctx := context.Background()
+
+	handler, err := serviceManager.Build(ctx, "foo.bar", nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo", nil))
+	}
+
+	currentConfigurations := &safe.Safe{}
+	currentConfigurations.Set(config.Configurations{
+		"foo": {Services: map[string]*config.Service{"bar": services["foo.bar"]}},
+	})
+
+	staticConf := static.Configuration{
+		Global:      &static.Global{},
+		API:         &static.API{EntryPoint: "traefik"},
+		EntryPoints: static.EntryPoints{"traefik": {}},
+	}
+
+	aggregator := NewRouteAppenderAggregator(ctx, &ChainBuilderMock{}, staticConf, "traefik", currentConfigurations, routerManager)
+
+	internalMuxRouter := mux.NewRouter()
+	aggregator.Append(internalMuxRouter)
+
+	recorder := httptest.NewRecorder()
+	internalMuxRouter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/providers/foo/services/bar/servers", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var representation api.ServerStatusesRepresentation
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &representation))
+	require.Len(t, representation.Servers, 1)
+
+	status := representation.Servers[0]
+	assert.Equal(t, failing.URL, status.URL)
+	assert.True(t, status.Ejected)
+	assert.Equal(t, 1, status.Ejections)
+	require.NotNil(t, status.RecoveryTime)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *status.RecoveryTime, 5*time.Second)
+}
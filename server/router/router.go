@@ -4,12 +4,17 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/containous/alice"
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/middlewares/accesslog"
-	"github.com/containous/traefik/middlewares/recovery"
+	"github.com/containous/traefik/middlewares/debugheaders"
+	metricsmiddleware "github.com/containous/traefik/middlewares/metrics"
+	"github.com/containous/traefik/middlewares/pathnormalization"
 	"github.com/containous/traefik/middlewares/tracing"
 	"github.com/containous/traefik/responsemodifiers"
 	"github.com/containous/traefik/rules"
@@ -18,34 +23,74 @@ import (
 	"github.com/containous/traefik/server/service"
 )
 
-const (
-	recoveryMiddlewareName = "traefik-internal-recovery"
-)
-
 // NewManager Creates a new Manager
+// The middlewares of an entry point are prepended to the middlewares of all the routers of this entry point.
+// The payload sizes of the routers are observed in the metrics registry, if not nil.
 func NewManager(routers map[string]*config.Router,
 	serviceManager *service.Manager, middlewaresBuilder *middleware.Builder, modifierBuilder *responsemodifiers.Builder,
+	entryPointsMiddlewares map[string][]string, metricsRegistry metrics.Registry,
 ) *Manager {
 	return &Manager{
-		routerHandlers:     make(map[string]http.Handler),
-		configs:            routers,
-		serviceManager:     serviceManager,
-		middlewaresBuilder: middlewaresBuilder,
-		modifierBuilder:    modifierBuilder,
+		routerHandlers:         make(map[string]http.Handler),
+		errors:                 make(map[string]error),
+		configs:                routers,
+		serviceManager:         serviceManager,
+		middlewaresBuilder:     middlewaresBuilder,
+		modifierBuilder:        modifierBuilder,
+		entryPointsMiddlewares: entryPointsMiddlewares,
+		metricsRegistry:        metricsRegistry,
 	}
 }
 
 // Manager A route/router manager
 type Manager struct {
 	routerHandlers     map[string]http.Handler
+	errors             map[string]error
 	configs            map[string]*config.Router
 	serviceManager     *service.Manager
 	middlewaresBuilder *middleware.Builder
 	modifierBuilder    *responsemodifiers.Builder
+
+	entryPointsMiddlewares map[string][]string
+	metricsRegistry        metrics.Registry
 }
 
 // BuildHandlers Builds handler for all entry points
 func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) map[string]http.Handler {
+	entryPointHandlers := m.buildEntryPointHandlers(rootCtx, entryPoints)
+
+	m.serviceManager.LaunchHealthCheck()
+
+	return entryPointHandlers
+}
+
+// Validate builds the handlers of all the routers, without launching the health checks,
+// and returns the errors which prevented routers from being built, by router name.
+// A router referencing an unknown entry point is reported as well.
+func (m *Manager) Validate(ctx context.Context, entryPoints []string) map[string]error {
+	m.buildEntryPointHandlers(ctx, entryPoints)
+
+	errors := make(map[string]error)
+	for routerName, err := range m.errors {
+		errors[routerName] = err
+	}
+
+	for routerName, rt := range m.configs {
+		if _, ok := errors[routerName]; ok {
+			continue
+		}
+		for _, entryPointName := range rt.EntryPoints {
+			if !contains(entryPoints, entryPointName) {
+				errors[routerName] = fmt.Errorf("entryPoint %q doesn't exist", entryPointName)
+				break
+			}
+		}
+	}
+
+	return errors
+}
+
+func (m *Manager) buildEntryPointHandlers(rootCtx context.Context, entryPoints []string) map[string]http.Handler {
 	entryPointsRouters := m.filteredRouters(rootCtx, entryPoints)
 
 	entryPointHandlers := make(map[string]http.Handler)
@@ -53,7 +98,7 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 		entryPointName := entryPointName
 		ctx := log.With(rootCtx, log.Str(log.EntryPointName, entryPointName))
 
-		handler, err := m.buildEntryPointHandler(ctx, routers)
+		handler, err := m.buildEntryPointHandler(ctx, entryPointName, routers)
 		if err != nil {
 			log.FromContext(ctx).Error(err)
 			continue
@@ -70,11 +115,24 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 		}
 	}
 
-	m.serviceManager.LaunchHealthCheck()
-
 	return entryPointHandlers
 }
 
+// GetErrors returns the errors which prevented routers from being built, by router name.
+func (m *Manager) GetErrors() map[string]error {
+	return m.errors
+}
+
+// GetRouterError returns the error which prevented the router of the given provider from being built, if any.
+func (m *Manager) GetRouterError(providerName, routerName string) error {
+	return m.errors[internal.MakeQualifiedName(providerName, routerName)]
+}
+
+// GetMiddlewareError returns the error which occurred while building the middleware of the given provider, if any.
+func (m *Manager) GetMiddlewareError(providerName, middlewareName string) error {
+	return m.middlewaresBuilder.GetError(internal.MakeQualifiedName(providerName, middlewareName))
+}
+
 func contains(entryPoints []string, entryPointName string) bool {
 	for _, name := range entryPoints {
 		if name == entryPointName {
@@ -110,78 +168,152 @@ func (m *Manager) filteredRouters(ctx context.Context, entryPoints []string) map
 	return entryPointsRouters
 }
 
-func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*config.Router) (http.Handler, error) {
+func (m *Manager) buildEntryPointHandler(ctx context.Context, entryPointName string, configs map[string]*config.Router) (http.Handler, error) {
 	router, err := rules.NewRouter()
 	if err != nil {
 		return nil, err
 	}
 
+	catchAllHandlers := make(map[string]http.Handler)
+
 	for routerName, routerConfig := range configs {
 		ctxRouter := log.With(ctx, log.Str(log.RouterName, routerName))
 		logger := log.FromContext(ctxRouter)
 
 		ctxRouter = internal.AddProviderInContext(ctxRouter, routerName)
 
-		handler, err := m.buildRouterHandler(ctxRouter, routerName)
+		if routerConfig.CatchAll && len(routerConfig.Rule) > 0 {
+			err = fmt.Errorf("the catch-all router cannot have the rule %s", routerConfig.Rule)
+			logger.Error(err)
+			m.errors[routerName] = err
+			continue
+		}
+
+		handler, err := m.buildRouterHandler(ctxRouter, entryPointName, routerName)
 		if err != nil {
 			logger.Error(err)
+			m.errors[routerName] = err
+			continue
+		}
+
+		if routerConfig.CatchAll {
+			catchAllHandlers[routerName] = handler
 			continue
 		}
 
 		err = router.AddRoute(routerConfig.Rule, routerConfig.Priority, handler)
 		if err != nil {
 			logger.Error(err)
+			m.errors[routerName] = err
 			continue
 		}
 	}
 
 	router.SortRoutes()
 
-	chain := alice.New()
-	chain = chain.Append(func(next http.Handler) (http.Handler, error) {
-		return recovery.New(ctx, next, recoveryMiddlewareName)
-	})
+	// The catch-all router receives the requests after all the routes failed to match them.
+	switch len(catchAllHandlers) {
+	case 0:
+	case 1:
+		for _, handler := range catchAllHandlers {
+			router.NotFoundHandler = handler
+		}
+	default:
+		var routerNames []string
+		for routerName := range catchAllHandlers {
+			routerNames = append(routerNames, routerName)
+		}
+		sort.Strings(routerNames)
+
+		for _, routerName := range routerNames {
+			err = fmt.Errorf("several catch-all routers on the entry point %s: %s", entryPointName, strings.Join(routerNames, ", "))
+			log.FromContext(log.With(ctx, log.Str(log.RouterName, routerName))).Error(err)
+			m.errors[routerName] = err
+		}
+	}
 
-	return chain.Then(router)
+	return router, nil
 }
 
-func (m *Manager) buildRouterHandler(ctx context.Context, routerName string) (http.Handler, error) {
-	if handler, ok := m.routerHandlers[routerName]; ok {
-		return handler, nil
-	}
+func (m *Manager) buildRouterHandler(ctx context.Context, entryPointName, routerName string) (http.Handler, error) {
+	entryPointMiddlewares := m.entryPointsMiddlewares[entryPointName]
 
 	configRouter, ok := m.configs[routerName]
 	if !ok {
 		return nil, fmt.Errorf("no configuration for %s", routerName)
 	}
 
-	handler, err := m.buildHandler(ctx, configRouter, routerName)
+	// The handler of a router can only be shared between the entry points without middlewares,
+	// and on which the router has its default middlewares.
+	_, overridden := configRouter.EntryPointsMiddlewares[entryPointName]
+	handlerKey := routerName
+	if len(entryPointMiddlewares) > 0 || overridden {
+		handlerKey = entryPointName + "@" + routerName
+	}
+
+	if handler, ok := m.routerHandlers[handlerKey]; ok {
+		return handler, nil
+	}
+
+	handler, err := m.buildHandler(ctx, configRouter, routerName, entryPointName, entryPointMiddlewares)
 	if err != nil {
 		return nil, err
 	}
 
+	// The paths normalized by the entry point for the rules are put back for the middlewares and the service.
+	handler = pathnormalization.Restore(handler)
+
+	if m.metricsRegistry != nil {
+		handler = metricsmiddleware.NewPayloadSizeHandler(handler, m.metricsRegistry, routerName, configRouter.Service)
+	}
+
+	if configRouter.ClientCertificateRequired() {
+		handler = requireClientCertificate(handler, routerName)
+	}
+
+	var applyFn accesslog.FieldApply
+	if configRouter.AccessLogsDisabled() {
+		applyFn = accesslog.DisableAccessLog
+	}
+
 	handlerWithAccessLog, err := alice.New(func(next http.Handler) (http.Handler, error) {
-		return accesslog.NewFieldHandler(next, accesslog.RouterName, routerName, nil), nil
+		return accesslog.NewFieldHandler(next, accesslog.RouterName, routerName, applyFn), nil
 	}).Then(handler)
 	if err != nil {
 		log.FromContext(ctx).Error(err)
-		m.routerHandlers[routerName] = handler
+		m.routerHandlers[handlerKey] = handler
 	} else {
-		m.routerHandlers[routerName] = handlerWithAccessLog
+		m.routerHandlers[handlerKey] = handlerWithAccessLog
 	}
 
-	return m.routerHandlers[routerName], nil
+	if configRouter.DebugHeadersEnabled() {
+		m.routerHandlers[handlerKey] = debugheaders.NewRouterHandler(m.routerHandlers[handlerKey], routerName)
+	}
+
+	return m.routerHandlers[handlerKey], nil
 }
 
-func (m *Manager) buildHandler(ctx context.Context, router *config.Router, routerName string) (http.Handler, error) {
-	rm := m.modifierBuilder.Build(ctx, router.Middlewares)
+func (m *Manager) buildHandler(ctx context.Context, router *config.Router, routerName, entryPointName string, entryPointMiddlewares []string) (http.Handler, error) {
+	middlewares := append(append([]string{}, entryPointMiddlewares...), router.MiddlewaresOn(entryPointName)...)
+
+	rm := m.modifierBuilder.Build(ctx, middlewares)
 
 	sHandler, err := m.serviceManager.Build(ctx, router.Service, rm)
 	if err != nil {
 		return nil, err
 	}
 
-	mHandler := m.middlewaresBuilder.BuildChain(ctx, router.Middlewares)
+	if router.TracingDisabled() {
+		mHandler := m.middlewaresBuilder.BuildChain(tracing.WithTracingDisabled(ctx), middlewares)
+
+		dHandler := func(next http.Handler) (http.Handler, error) {
+			return tracing.NewDisabler(next), nil
+		}
+
+		return alice.New(dHandler).Extend(*mHandler).Then(sHandler)
+	}
+
+	mHandler := m.middlewaresBuilder.BuildChain(ctx, middlewares)
 
 	tHandler := func(next http.Handler) (http.Handler, error) {
 		return tracing.NewForwarder(ctx, routerName, router.Service, next), nil
@@ -2,12 +2,19 @@ package router
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/middlewares/accesslog"
+	"github.com/containous/traefik/middlewares/debugheaders"
 	"github.com/containous/traefik/middlewares/requestdecorator"
 	"github.com/containous/traefik/responsemodifiers"
 	"github.com/containous/traefik/server/middleware"
@@ -312,11 +319,11 @@ func TestRouterManager_Get(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			serviceManager := service.NewManager(test.serviceConfig, http.DefaultTransport)
-			middlewaresBuilder := middleware.NewBuilder(test.middlewaresConfig, serviceManager)
+			serviceManager := service.NewManager(test.serviceConfig, http.DefaultTransport, nil)
+			middlewaresBuilder := middleware.NewBuilder(test.middlewaresConfig, serviceManager, nil)
 			responseModifierFactory := responsemodifiers.NewBuilder(test.middlewaresConfig)
 
-			routerManager := NewManager(test.routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory)
+			routerManager := NewManager(test.routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, nil, nil)
 
 			handlers := routerManager.BuildHandlers(context.Background(), test.entryPoints)
 
@@ -411,11 +418,11 @@ func TestAccessLog(t *testing.T) {
 	for _, test := range testCases {
 		t.Run(test.desc, func(t *testing.T) {
 
-			serviceManager := service.NewManager(test.serviceConfig, http.DefaultTransport)
-			middlewaresBuilder := middleware.NewBuilder(test.middlewaresConfig, serviceManager)
+			serviceManager := service.NewManager(test.serviceConfig, http.DefaultTransport, nil)
+			middlewaresBuilder := middleware.NewBuilder(test.middlewaresConfig, serviceManager, nil)
 			responseModifierFactory := responsemodifiers.NewBuilder(test.middlewaresConfig)
 
-			routerManager := NewManager(test.routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory)
+			routerManager := NewManager(test.routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, nil, nil)
 
 			handlers := routerManager.BuildHandlers(context.Background(), test.entryPoints)
 
@@ -424,7 +431,7 @@ func TestAccessLog(t *testing.T) {
 
 			accesslogger, err := accesslog.NewHandler(&types.AccessLog{
 				Format: "json",
-			})
+			}, nil)
 			require.NoError(t, err)
 
 			reqHost := requestdecorator.New(nil)
@@ -440,3 +447,658 @@ func TestAccessLog(t *testing.T) {
 		})
 	}
 }
+
+func TestAccessLog_DisabledOnRouter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	routersConfig := map[string]*config.Router{
+		"foo": {
+			EntryPoints: []string{"web"},
+			Service:     "foo-service",
+			Rule:        "Path(`/foo`)",
+		},
+		"health": {
+			EntryPoints:   []string{"web"},
+			Service:       "foo-service",
+			Rule:          "Path(`/health`)",
+			Observability: &config.RouterObservability{DisableAccessLogs: true},
+		},
+	}
+	serviceConfig := map[string]*config.Service{
+		"foo-service": {
+			LoadBalancer: &config.LoadBalancerService{
+				Servers: []config.Server{{URL: server.URL, Weight: 1}},
+				Method:  "wrr",
+			},
+		},
+	}
+
+	serviceManager := service.NewManager(serviceConfig, http.DefaultTransport, nil)
+	middlewaresBuilder := middleware.NewBuilder(nil, serviceManager, nil)
+	responseModifierFactory := responsemodifiers.NewBuilder(nil)
+
+	routerManager := NewManager(routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, nil, nil)
+
+	handlers := routerManager.BuildHandlers(context.Background(), []string{"web"})
+
+	dir, err := ioutil.TempDir("", "traefik-accesslog")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	logFile := filepath.Join(dir, "access.log")
+	accesslogger, err := accesslog.NewHandler(&types.AccessLog{FilePath: logFile, Format: "json"}, nil)
+	require.NoError(t, err)
+
+	for _, path := range []string{"/health", "/foo", "/health"} {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://foo.bar"+path, nil)
+		accesslogger.ServeHTTP(httptest.NewRecorder(), req, handlers["web"].ServeHTTP)
+	}
+
+	require.NoError(t, accesslogger.Close())
+
+	logs, err := ioutil.ReadFile(logFile)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(logs)), "\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"RouterName":"foo"`)
+}
+
+func TestDebugHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	routersConfig := map[string]*config.Router{
+		"foo": {
+			EntryPoints: []string{"web"},
+			Service:     "foo-service",
+			Rule:        "Path(`/foo`)",
+		},
+		"debug": {
+			EntryPoints:   []string{"web"},
+			Service:       "foo-service",
+			Rule:          "Path(`/debug`)",
+			Observability: &config.RouterObservability{DebugHeaders: true},
+		},
+	}
+	serviceConfig := map[string]*config.Service{
+		"foo-service": {
+			LoadBalancer: &config.LoadBalancerService{
+				Servers: []config.Server{{URL: server.URL, Weight: 1}},
+				Method:  "wrr",
+			},
+		},
+	}
+
+	serviceManager := service.NewManager(serviceConfig, http.DefaultTransport, nil)
+	middlewaresBuilder := middleware.NewBuilder(nil, serviceManager, nil)
+	responseModifierFactory := responsemodifiers.NewBuilder(nil)
+
+	routerManager := NewManager(routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, nil, nil)
+
+	handlers := routerManager.BuildHandlers(context.Background(), []string{"web"})
+
+	recorder := httptest.NewRecorder()
+	handlers["web"].ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://foo.bar/debug", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "debug", recorder.Header().Get(debugheaders.RouterHeader))
+	assert.Equal(t, "foo-service", recorder.Header().Get(debugheaders.ServiceHeader))
+	assert.Equal(t, server.URL, recorder.Header().Get(debugheaders.BackendHeader))
+
+	recorder = httptest.NewRecorder()
+	handlers["web"].ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://foo.bar/foo", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(debugheaders.RouterHeader))
+	assert.Empty(t, recorder.Header().Get(debugheaders.ServiceHeader))
+	assert.Empty(t, recorder.Header().Get(debugheaders.BackendHeader))
+}
+
+func TestClientCertificateRequired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	routersConfig := map[string]*config.Router{
+		"admin": {
+			EntryPoints: []string{"websecure"},
+			Service:     "foo-service",
+			Rule:        "Path(`/admin`)",
+			TLS:         &config.RouterTLSConfig{ClientCertificateRequired: true},
+		},
+		"public": {
+			EntryPoints: []string{"websecure"},
+			Service:     "foo-service",
+			Rule:        "Path(`/public`)",
+		},
+	}
+	serviceConfig := map[string]*config.Service{
+		"foo-service": {
+			LoadBalancer: &config.LoadBalancerService{
+				Servers: []config.Server{{URL: server.URL, Weight: 1}},
+				Method:  "wrr",
+			},
+		},
+	}
+
+	serviceManager := service.NewManager(serviceConfig, http.DefaultTransport, nil)
+	middlewaresBuilder := middleware.NewBuilder(nil, serviceManager, nil)
+	responseModifierFactory := responsemodifiers.NewBuilder(nil)
+
+	routerManager := NewManager(routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, nil, nil)
+
+	handlers := routerManager.BuildHandlers(context.Background(), []string{"websecure"})
+
+	testCases := []struct {
+		desc           string
+		path           string
+		tlsState       *tls.ConnectionState
+		expectedStatus int
+	}{
+		{
+			desc:           "no client certificate",
+			path:           "/admin",
+			tlsState:       &tls.ConnectionState{},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "no TLS",
+			path:           "/admin",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "verified client certificate",
+			path:           "/admin",
+			tlsState:       &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "router without requirement",
+			path:           "/public",
+			tlsState:       &tls.ConnectionState{},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			req := testhelpers.MustNewRequest(http.MethodGet, "https://foo.bar"+test.path, nil)
+			req.TLS = test.tlsState
+
+			recorder := httptest.NewRecorder()
+			handlers["websecure"].ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestRouterManager_MiddlewareError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	routersConfig := map[string]*config.Router{
+		"provider.valid": {
+			EntryPoints: []string{"web"},
+			Service:     "foo-service",
+			Rule:        "Host(`valid.bar`)",
+			Middlewares: []string{"valid-middle"},
+		},
+		"provider.invalid": {
+			EntryPoints: []string{"web"},
+			Service:     "foo-service",
+			Rule:        "Host(`invalid.bar`)",
+			Middlewares: []string{"invalid-middle"},
+		},
+	}
+
+	serviceConfig := map[string]*config.Service{
+		"provider.foo-service": {
+			LoadBalancer: &config.LoadBalancerService{
+				Servers: []config.Server{{URL: server.URL, Weight: 1}},
+				Method:  "wrr",
+			},
+		},
+	}
+
+	middlewaresConfig := map[string]*config.Middleware{
+		"provider.valid-middle": {
+			ReplacePathRegex: &config.ReplacePathRegex{Regex: "^/foo", Replacement: "/bar"},
+		},
+		"provider.invalid-middle": {
+			ReplacePathRegex: &config.ReplacePathRegex{Regex: "^/foo(", Replacement: "/bar"},
+		},
+	}
+
+	serviceManager := service.NewManager(serviceConfig, http.DefaultTransport, nil)
+	middlewaresBuilder := middleware.NewBuilder(middlewaresConfig, serviceManager, nil)
+	responseModifierFactory := responsemodifiers.NewBuilder(middlewaresConfig)
+
+	routerManager := NewManager(routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, nil, nil)
+
+	handlers := routerManager.BuildHandlers(context.Background(), []string{"web"})
+	require.Contains(t, handlers, "web")
+
+	testCases := []struct {
+		host     string
+		expected int
+	}{
+		{host: "valid.bar", expected: http.StatusOK},
+		{host: "invalid.bar", expected: http.StatusNotFound},
+	}
+
+	for _, test := range testCases {
+		w := httptest.NewRecorder()
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://"+test.host+"/foo", nil)
+
+		reqHost := requestdecorator.New(nil)
+		reqHost.ServeHTTP(w, req, handlers["web"].ServeHTTP)
+
+		assert.Equal(t, test.expected, w.Code, test.host)
+	}
+
+	assert.NoError(t, routerManager.GetRouterError("provider", "valid"))
+	assert.Error(t, routerManager.GetRouterError("provider", "invalid"))
+
+	assert.NoError(t, routerManager.GetMiddlewareError("provider", "valid-middle"))
+	assert.Error(t, routerManager.GetMiddlewareError("provider", "invalid-middle"))
+}
+
+func TestRouterManager_EntryPointMiddlewares(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.Path)
+	}))
+	defer server.Close()
+
+	routersConfig := map[string]*config.Router{
+		"provider.foo": {
+			EntryPoints: []string{"web", "other"},
+			Service:     "foo-service",
+			Rule:        "Host(`foo.bar`)",
+		},
+		"provider.bar": {
+			EntryPoints: []string{"web"},
+			Service:     "foo-service",
+			Rule:        "Host(`bar.bar`)",
+			Middlewares: []string{"router-prefix"},
+		},
+	}
+
+	serviceConfig := map[string]*config.Service{
+		"provider.foo-service": {
+			LoadBalancer: &config.LoadBalancerService{
+				Servers: []config.Server{{URL: server.URL, Weight: 1}},
+				Method:  "wrr",
+			},
+		},
+	}
+
+	middlewaresConfig := map[string]*config.Middleware{
+		"file.secure-headers": {
+			Headers: &config.Headers{
+				CustomResponseHeaders: map[string]string{"X-Frame-Options": "DENY"},
+			},
+		},
+		"file.entrypoint-prefix": {
+			AddPrefix: &config.AddPrefix{Prefix: "/entrypoint"},
+		},
+		"provider.router-prefix": {
+			AddPrefix: &config.AddPrefix{Prefix: "/router"},
+		},
+	}
+
+	entryPointsMiddlewares := map[string][]string{
+		"web": {"file.secure-headers", "file.entrypoint-prefix"},
+	}
+
+	serviceManager := service.NewManager(serviceConfig, http.DefaultTransport, nil)
+	middlewaresBuilder := middleware.NewBuilder(middlewaresConfig, serviceManager, nil)
+	responseModifierFactory := responsemodifiers.NewBuilder(middlewaresConfig)
+
+	routerManager := NewManager(routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, entryPointsMiddlewares, nil)
+
+	handlers := routerManager.BuildHandlers(context.Background(), []string{"web", "other"})
+	require.Contains(t, handlers, "web")
+	require.Contains(t, handlers, "other")
+
+	testCases := []struct {
+		desc                 string
+		entryPoint           string
+		host                 string
+		expectedPath         string
+		expectedFrameOptions string
+	}{
+		{
+			desc:                 "router without middlewares",
+			entryPoint:           "web",
+			host:                 "foo.bar",
+			expectedPath:         "/entrypoint/foo",
+			expectedFrameOptions: "DENY",
+		},
+		{
+			desc:                 "router middlewares after the entry point ones",
+			entryPoint:           "web",
+			host:                 "bar.bar",
+			expectedPath:         "/router/entrypoint/foo",
+			expectedFrameOptions: "DENY",
+		},
+		{
+			desc:         "entry point without middlewares",
+			entryPoint:   "other",
+			host:         "foo.bar",
+			expectedPath: "/foo",
+		},
+	}
+
+	for _, test := range testCases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "http://"+test.host+"/foo", nil)
+
+		reqHost := requestdecorator.New(nil)
+		reqHost.ServeHTTP(w, req, handlers[test.entryPoint].ServeHTTP)
+
+		assert.Equal(t, http.StatusOK, w.Code, test.desc)
+		assert.Equal(t, test.expectedPath, w.Header().Get("X-Path"), test.desc)
+		assert.Equal(t, test.expectedFrameOptions, w.Header().Get("X-Frame-Options"), test.desc)
+	}
+}
+
+func TestRouterManager_RouterEntryPointsMiddlewares(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	routersConfig := map[string]*config.Router{
+		"provider.foo": {
+			EntryPoints: []string{"internal", "external"},
+			Service:     "foo-service",
+			Rule:        "Host(`foo.bar`)",
+			EntryPointsMiddlewares: map[string][]string{
+				"external": {"auth"},
+			},
+		},
+	}
+
+	serviceConfig := map[string]*config.Service{
+		"provider.foo-service": {
+			LoadBalancer: &config.LoadBalancerService{
+				Servers: []config.Server{{URL: server.URL, Weight: 1}},
+				Method:  "wrr",
+			},
+		},
+	}
+
+	middlewaresConfig := map[string]*config.Middleware{
+		"provider.auth": {
+			BasicAuth: &config.BasicAuth{
+				Users: []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+			},
+		},
+	}
+
+	serviceManager := service.NewManager(serviceConfig, http.DefaultTransport, nil)
+	middlewaresBuilder := middleware.NewBuilder(middlewaresConfig, serviceManager, nil)
+	responseModifierFactory := responsemodifiers.NewBuilder(middlewaresConfig)
+
+	routerManager := NewManager(routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, nil, nil)
+
+	handlers := routerManager.BuildHandlers(context.Background(), []string{"internal", "external"})
+	require.Contains(t, handlers, "internal")
+	require.Contains(t, handlers, "external")
+
+	testCases := []struct {
+		desc           string
+		entryPoint     string
+		credentials    bool
+		expectedStatus int
+	}{
+		{
+			desc:           "default middlewares",
+			entryPoint:     "internal",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "overridden middlewares without credentials",
+			entryPoint:     "external",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "overridden middlewares with credentials",
+			entryPoint:     "external",
+			credentials:    true,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "http://foo.bar/foo", nil)
+		if test.credentials {
+			req.SetBasicAuth("test", "test")
+		}
+
+		reqHost := requestdecorator.New(nil)
+		reqHost.ServeHTTP(w, req, handlers[test.entryPoint].ServeHTTP)
+
+		assert.Equal(t, test.expectedStatus, w.Code, test.desc)
+	}
+}
+
+func TestRouterManager_WeightedServiceOnPath(t *testing.T) {
+	stableServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "stable")
+	}))
+	defer stableServer.Close()
+
+	canaryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "canary")
+	}))
+	defer canaryServer.Close()
+
+	routersConfig := map[string]*config.Router{
+		"provider.new-feature": {
+			EntryPoints: []string{"web"},
+			Service:     "new-feature-split",
+			Rule:        "PathPrefix(`/new-feature`)",
+		},
+		"provider.default": {
+			EntryPoints: []string{"web"},
+			Service:     "stable",
+			Rule:        "PathPrefix(`/`)",
+		},
+	}
+
+	serviceConfig := map[string]*config.Service{
+		"provider.stable": {
+			LoadBalancer: &config.LoadBalancerService{
+				Servers: []config.Server{{URL: stableServer.URL, Weight: 1}},
+				Method:  "wrr",
+			},
+		},
+		"provider.canary": {
+			LoadBalancer: &config.LoadBalancerService{
+				Servers: []config.Server{{URL: canaryServer.URL, Weight: 1}},
+				Method:  "wrr",
+			},
+		},
+		"provider.new-feature-split": {
+			Weighted: &config.Weighted{
+				Services: []config.WeightedService{
+					{Name: "stable", Weight: 90},
+					{Name: "canary", Weight: 10},
+				},
+			},
+		},
+	}
+
+	serviceManager := service.NewManager(serviceConfig, http.DefaultTransport, nil)
+	middlewaresBuilder := middleware.NewBuilder(map[string]*config.Middleware{}, serviceManager, nil)
+	responseModifierFactory := responsemodifiers.NewBuilder(map[string]*config.Middleware{})
+
+	routerManager := NewManager(routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, nil, nil)
+
+	handlers := routerManager.BuildHandlers(context.Background(), []string{"web"})
+	require.Contains(t, handlers, "web")
+
+	count := func(path string) map[string]int {
+		counts := make(map[string]int)
+		for i := 0; i < 1000; i++ {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "http://foo.bar"+path, nil)
+
+			reqHost := requestdecorator.New(nil)
+			reqHost.ServeHTTP(w, req, handlers["web"].ServeHTTP)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			counts[w.Header().Get("X-From")]++
+		}
+		return counts
+	}
+
+	counts := count("/new-feature/foo")
+	assert.InDelta(t, 100, counts["canary"], 20)
+	assert.InDelta(t, 900, counts["stable"], 20)
+
+	assert.Equal(t, map[string]int{"stable": 1000}, count("/other"))
+}
+
+func TestRouterManager_CatchAll(t *testing.T) {
+	fooServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "foo")
+	}))
+	defer fooServer.Close()
+
+	fallbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "fallback")
+	}))
+	defer fallbackServer.Close()
+
+	testCases := []struct {
+		desc           string
+		routersConfig  map[string]*config.Router
+		expected       map[string]string
+		expectedErrors []string
+	}{
+		{
+			desc: "catch-all router",
+			routersConfig: map[string]*config.Router{
+				"provider.foo": {
+					EntryPoints: []string{"web"},
+					Service:     "foo",
+					Rule:        "Host(`foo.bar`)",
+					Priority:    1,
+				},
+				"provider.fallback": {
+					EntryPoints: []string{"web"},
+					Service:     "fallback",
+					CatchAll:    true,
+					Priority:    1000,
+				},
+			},
+			expected: map[string]string{
+				"http://foo.bar/":   "foo",
+				"http://other.bar/": "fallback",
+				"http://bar.foo/":   "fallback",
+			},
+		},
+		{
+			desc: "no catch-all router",
+			routersConfig: map[string]*config.Router{
+				"provider.foo": {
+					EntryPoints: []string{"web"},
+					Service:     "foo",
+					Rule:        "Host(`foo.bar`)",
+				},
+			},
+			expected: map[string]string{
+				"http://foo.bar/":   "foo",
+				"http://other.bar/": "",
+			},
+		},
+		{
+			desc: "several catch-all routers",
+			routersConfig: map[string]*config.Router{
+				"provider.foo": {
+					EntryPoints: []string{"web"},
+					Service:     "foo",
+					CatchAll:    true,
+				},
+				"provider.fallback": {
+					EntryPoints: []string{"web"},
+					Service:     "fallback",
+					CatchAll:    true,
+				},
+			},
+			expected: map[string]string{
+				"http://other.bar/": "",
+			},
+			expectedErrors: []string{"provider.fallback", "provider.foo"},
+		},
+		{
+			desc: "catch-all router with a rule",
+			routersConfig: map[string]*config.Router{
+				"provider.fallback": {
+					EntryPoints: []string{"web"},
+					Service:     "fallback",
+					Rule:        "Host(`foo.bar`)",
+					CatchAll:    true,
+				},
+			},
+			expected: map[string]string{
+				"http://foo.bar/": "",
+			},
+			expectedErrors: []string{"provider.fallback"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			serviceConfig := map[string]*config.Service{
+				"provider.foo": {
+					LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{{URL: fooServer.URL, Weight: 1}},
+						Method:  "wrr",
+					},
+				},
+				"provider.fallback": {
+					LoadBalancer: &config.LoadBalancerService{
+						Servers: []config.Server{{URL: fallbackServer.URL, Weight: 1}},
+						Method:  "wrr",
+					},
+				},
+			}
+
+			serviceManager := service.NewManager(serviceConfig, http.DefaultTransport, nil)
+			middlewaresBuilder := middleware.NewBuilder(map[string]*config.Middleware{}, serviceManager, nil)
+			responseModifierFactory := responsemodifiers.NewBuilder(map[string]*config.Middleware{})
+
+			routerManager := NewManager(test.routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, nil, nil)
+
+			handlers := routerManager.BuildHandlers(context.Background(), []string{"web"})
+			require.Contains(t, handlers, "web")
+
+			var routerErrors []string
+			for routerName := range routerManager.GetErrors() {
+				routerErrors = append(routerErrors, routerName)
+			}
+			assert.ElementsMatch(t, test.expectedErrors, routerErrors)
+
+			for url, expectedFrom := range test.expected {
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, url, nil)
+
+				reqHost := requestdecorator.New(nil)
+				reqHost.ServeHTTP(w, req, handlers["web"].ServeHTTP)
+
+				if expectedFrom == "" {
+					assert.Equal(t, http.StatusNotFound, w.Code, url)
+					continue
+				}
+				assert.Equal(t, http.StatusOK, w.Code, url)
+				assert.Equal(t, expectedFrom, w.Header().Get("X-From"), url)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/containous/traefik/cluster"
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/ip"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/middlewares/accesslog"
@@ -19,6 +20,8 @@ import (
 	"github.com/containous/traefik/provider"
 	"github.com/containous/traefik/safe"
 	"github.com/containous/traefik/server/middleware"
+	"github.com/containous/traefik/server/router"
+	"github.com/containous/traefik/server/service"
 	"github.com/containous/traefik/tracing"
 	"github.com/containous/traefik/tracing/datadog"
 	"github.com/containous/traefik/tracing/jaeger"
@@ -45,11 +48,18 @@ type Server struct {
 	configurationListeners     []func(config.Configuration)
 	requestDecorator           *requestdecorator.RequestDecorator
 	providersThrottleDuration  time.Duration
+	clientIPStrategy           *config.IPStrategy
+	// serviceManager is the service manager of the current configuration, whose load-balancers are reused by the next one.
+	serviceManager *service.Manager
+	// payloadSizesRegistry is the metrics registry when the payload sizes of the routers are observed, nil otherwise.
+	payloadSizesRegistry metrics.Registry
+	// transportHeader is the request header selecting the transport to the servers, which the clients cannot send.
+	transportHeader string
 }
 
 // RouteAppenderFactory the route appender factory interface
 type RouteAppenderFactory interface {
-	NewAppender(ctx context.Context, middlewaresBuilder *middleware.Builder, currentConfigurations *safe.Safe) types.RouteAppender
+	NewAppender(ctx context.Context, middlewaresBuilder *middleware.Builder, currentConfigurations *safe.Safe, routerManager *router.Manager) types.RouteAppender
 }
 
 func setupTracing(conf *static.Tracing) tracing.TrackingBackend {
@@ -85,20 +95,12 @@ func NewServer(staticConfiguration static.Configuration, provider provider.Provi
 		server.providersThrottleDuration = time.Duration(staticConfiguration.Providers.ProvidersThrottleDuration)
 	}
 
-	transport, err := createHTTPTransport(staticConfiguration.ServersTransport)
-	if err != nil {
-		log.WithoutContext().Errorf("Could not configure HTTP Transport, fallbacking on default transport: %v", err)
-		server.defaultRoundTripper = http.DefaultTransport
-	} else {
-		server.defaultRoundTripper = transport
-	}
-
 	server.routinesPool = safe.NewPool(context.Background())
 
 	if staticConfiguration.Tracing != nil {
 		trackingBackend := setupTracing(staticConfiguration.Tracing)
 		var err error
-		server.tracer, err = tracing.NewTracing(staticConfiguration.Tracing.ServiceName, staticConfiguration.Tracing.SpanNameLimit, trackingBackend)
+		server.tracer, err = tracing.NewTracing(staticConfiguration.Tracing.ServiceName, staticConfiguration.Tracing.SpanNameLimit, staticConfiguration.Tracing.Propagation, trackingBackend)
 		if err != nil {
 			log.WithoutContext().Warnf("Unable to create tracer: %v", err)
 		}
@@ -107,10 +109,35 @@ func NewServer(staticConfiguration static.Configuration, provider provider.Provi
 	server.requestDecorator = requestdecorator.New(staticConfiguration.HostResolver)
 
 	server.metricsRegistry = registerMetricClients(staticConfiguration.Metrics)
+	if staticConfiguration.Metrics != nil && staticConfiguration.Metrics.PayloadSizes {
+		server.payloadSizesRegistry = server.metricsRegistry
+	}
+
+	transport, err := createHTTPTransport(staticConfiguration.ServersTransport, server.metricsRegistry)
+	if err != nil {
+		log.WithoutContext().Errorf("Could not configure HTTP Transport, fallbacking on default transport: %v", err)
+		server.defaultRoundTripper = http.DefaultTransport
+	} else {
+		server.defaultRoundTripper = createServerNameRoundTripper(staticConfiguration.ServersTransport, transport, server.metricsRegistry)
+		if staticConfiguration.ServersTransport.HeaderTransports != nil {
+			server.transportHeader = staticConfiguration.ServersTransport.HeaderTransports.Header
+		}
+	}
+
+	var clientIPStrategy ip.Strategy
+	if staticConfiguration.ClientIPStrategy != nil {
+		clientIPStrategy, err = staticConfiguration.ClientIPStrategy.Get()
+		if err != nil {
+			log.WithoutContext().Errorf("Invalid client IP strategy, fallbacking on the remote address: %v", err)
+			clientIPStrategy = nil
+		} else {
+			server.clientIPStrategy = staticConfiguration.ClientIPStrategy
+		}
+	}
 
 	if staticConfiguration.AccessLog != nil {
 		var err error
-		server.accessLoggerMiddleware, err = accesslog.NewHandler(staticConfiguration.AccessLog)
+		server.accessLoggerMiddleware, err = accesslog.NewHandler(staticConfiguration.AccessLog, clientIPStrategy)
 		if err != nil {
 			log.WithoutContext().Warnf("Unable to create access logger : %v", err)
 		}
@@ -216,14 +243,26 @@ func (s *Server) stopLeadership() {
 
 func (s *Server) startHTTPServers() {
 	// Use an empty configuration in order to initialize the default handlers with internal routes
-	handlers := s.applyConfiguration(context.Background(), config.Configuration{})
+	handlers, _ := s.applyConfiguration(context.Background(), config.Configuration{})
 	for entryPointName, handler := range handlers {
 		s.entryPoints[entryPointName].switcher.UpdateHandler(handler)
 	}
 
 	for entryPointName, entryPoint := range s.entryPoints {
 		ctx := log.With(context.Background(), log.Str(log.EntryPointName, entryPointName))
+
+		if limitListener, ok := entryPoint.listener.(*connectionLimitListener); ok {
+			limitListener.setGauge(s.metricsRegistry.EntrypointConnectionsGauge().With("entrypoint", entryPointName))
+		}
+
 		go entryPoint.Start(ctx)
+
+		if entryPoint.sessionTicketKeys != nil {
+			sessionTicketKeys := entryPoint.sessionTicketKeys
+			s.routinesPool.Go(func(stop chan bool) {
+				sessionTicketKeys.Run(stop)
+			})
+		}
 	}
 }
 
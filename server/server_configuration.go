@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/containous/alice"
@@ -14,9 +15,13 @@ import (
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/middlewares/accesslog"
+	"github.com/containous/traefik/middlewares/pathnormalization"
+	"github.com/containous/traefik/middlewares/recovery"
 	"github.com/containous/traefik/middlewares/requestdecorator"
 	"github.com/containous/traefik/middlewares/tracing"
 	"github.com/containous/traefik/responsemodifiers"
+	"github.com/containous/traefik/rules"
+	"github.com/containous/traefik/server/internal"
 	"github.com/containous/traefik/server/middleware"
 	"github.com/containous/traefik/server/router"
 	"github.com/containous/traefik/server/service"
@@ -25,6 +30,8 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const recoveryMiddlewareName = "traefik-internal-recovery"
+
 // loadConfiguration manages dynamically routers, middlewares, servers and TLS configurations
 func (s *Server) loadConfiguration(configMsg config.Message) {
 	logger := log.FromContext(log.With(context.Background(), log.Str(log.ProviderName, configMsg.ProviderName)))
@@ -40,14 +47,23 @@ func (s *Server) loadConfiguration(configMsg config.Message) {
 
 	s.metricsRegistry.ConfigReloadsCounter().Add(1)
 
-	handlers, certificates := s.loadConfig(newConfigurations)
+	start := time.Now()
+	handlers, certificates, err := s.loadConfig(newConfigurations)
+	s.metricsRegistry.ConfigReloadDurationHistogram().Observe(time.Since(start).Seconds())
 
-	s.metricsRegistry.LastConfigReloadSuccessGauge().Set(float64(time.Now().Unix()))
+	if err != nil {
+		logger.Errorf("Configuration partially reloaded: %v", err)
+		s.metricsRegistry.ConfigReloadsFailureCounter().Add(1)
+		s.metricsRegistry.LastConfigReloadFailureGauge().Set(float64(time.Now().Unix()))
+	} else {
+		s.metricsRegistry.LastConfigReloadSuccessGauge().Set(float64(time.Now().Unix()))
+	}
 
 	for entryPointName, handler := range handlers {
 		s.entryPoints[entryPointName].switcher.UpdateHandler(handler)
 	}
 
+	entryPointsTLSOptions, entryPointsDomainsTLSOptions := s.loadTLSOptions(newConfigurations)
 	for entryPointName, entryPoint := range s.entryPoints {
 		eLogger := logger.WithField(log.EntryPointName, entryPointName)
 		if entryPoint.Certs == nil {
@@ -58,6 +74,9 @@ func (s *Server) loadConfiguration(configMsg config.Message) {
 			entryPoint.Certs.DynamicCerts.Set(certificates[entryPointName])
 			entryPoint.Certs.ResetCache()
 		}
+		if err := entryPoint.UpdateTLSOptions(entryPointsTLSOptions[entryPointName], entryPointsDomainsTLSOptions[entryPointName]); err != nil {
+			eLogger.Errorf("Cannot apply the TLS options: %v", err)
+		}
 		eLogger.Infof("Server configuration reloaded on %s", s.entryPoints[entryPointName].httpServer.Addr)
 	}
 
@@ -72,31 +91,37 @@ func (s *Server) loadConfiguration(configMsg config.Message) {
 
 // loadConfig returns a new gorilla.mux Route from the specified global configuration and the dynamic
 // provider configurations.
-func (s *Server) loadConfig(configurations config.Configurations) (map[string]http.Handler, map[string]map[string]*tls.Certificate) {
+// The error tells that some routers could not be built, the handlers serve the other ones.
+func (s *Server) loadConfig(configurations config.Configurations) (map[string]http.Handler, map[string]map[string]*tls.Certificate, error) {
 
 	ctx := context.TODO()
 
 	conf := mergeConfiguration(configurations)
-	handlers := s.applyConfiguration(ctx, conf)
+	handlers, err := s.applyConfiguration(ctx, conf)
 
 	// Get new certificates list sorted per entry points
 	// Update certificates
 	entryPointsCertificates := s.loadHTTPSConfiguration(configurations)
 
-	return handlers, entryPointsCertificates
+	return handlers, entryPointsCertificates, err
 }
 
-func (s *Server) applyConfiguration(ctx context.Context, configuration config.Configuration) map[string]http.Handler {
+func (s *Server) applyConfiguration(ctx context.Context, configuration config.Configuration) (map[string]http.Handler, error) {
 	var entryPoints []string
-	for entryPointName := range s.entryPoints {
+	entryPointsMiddlewares := make(map[string][]string)
+	for entryPointName, entryPoint := range s.entryPoints {
 		entryPoints = append(entryPoints, entryPointName)
+		entryPointsMiddlewares[entryPointName] = entryPoint.middlewares
 	}
 
-	serviceManager := service.NewManager(configuration.Services, s.defaultRoundTripper)
-	middlewaresBuilder := middleware.NewBuilder(configuration.Middlewares, serviceManager)
+	serviceManager := service.NewManager(configuration.Services, s.defaultRoundTripper, s.metricsRegistry)
+	serviceManager.ReuseBalancers(s.serviceManager)
+	s.serviceManager = serviceManager
+
+	middlewaresBuilder := middleware.NewBuilder(configuration.Middlewares, serviceManager, s.clientIPStrategy)
 	responseModifierFactory := responsemodifiers.NewBuilder(configuration.Middlewares)
 
-	routerManager := router.NewManager(configuration.Routers, serviceManager, middlewaresBuilder, responseModifierFactory)
+	routerManager := router.NewManager(configuration.Routers, serviceManager, middlewaresBuilder, responseModifierFactory, entryPointsMiddlewares, s.payloadSizesRegistry)
 
 	handlers := routerManager.BuildHandlers(ctx, entryPoints)
 
@@ -111,7 +136,7 @@ func (s *Server) applyConfiguration(ctx context.Context, configuration config.Co
 		factory := s.entryPoints[entryPointName].RouteAppenderFactory
 		if factory != nil {
 			// FIXME remove currentConfigurations
-			appender := factory.NewAppender(ctx, middlewaresBuilder, &s.currentConfigurations)
+			appender := factory.NewAppender(ctx, middlewaresBuilder, &s.currentConfigurations, routerManager)
 			appender.Append(internalMuxRouter)
 		}
 
@@ -133,6 +158,28 @@ func (s *Server) applyConfiguration(ctx context.Context, configuration config.Co
 			chain = chain.Append(tracing.WrapEntryPointHandler(ctx, s.tracer, entryPointName))
 		}
 
+		// The panics are recovered under the access logs and the tracing, which record the 500 answered.
+		panics := s.metricsRegistry.EntrypointPanicsCounter().With("entrypoint", entryPointName)
+		chain = chain.Append(func(next http.Handler) (http.Handler, error) {
+			return recovery.New(ctx, next, panics, recoveryMiddlewareName)
+		})
+
+		// The paths are normalized for the rules of the routers only, the internal routes are matched before.
+		if normalization := s.entryPoints[entryPointName].pathNormalization; normalization != nil {
+			chain = chain.Append(pathnormalization.WrapHandler(normalization.Forward))
+		}
+
+		// The header selecting the transport to the servers is removed from the incoming requests,
+		// so that only the middlewares of the routers can set it.
+		if header := s.transportHeader; header != "" {
+			chain = chain.Append(func(next http.Handler) (http.Handler, error) {
+				return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					req.Header.Del(header)
+					next.ServeHTTP(rw, req)
+				}), nil
+			})
+		}
+
 		chain = chain.Append(requestdecorator.WrapHandler(s.requestDecorator))
 
 		handler, err := chain.Then(internalMuxRouter.NotFoundHandler)
@@ -143,7 +190,12 @@ func (s *Server) applyConfiguration(ctx context.Context, configuration config.Co
 		internalMuxRouter.NotFoundHandler = handler
 	}
 
-	return routerHandlers
+	var err error
+	if routerErrors := routerManager.GetErrors(); len(routerErrors) > 0 {
+		err = fmt.Errorf("%d router(s) could not be built", len(routerErrors))
+	}
+
+	return routerHandlers, err
 }
 
 func (s *Server) preLoadConfiguration(configMsg config.Message) {
@@ -156,7 +208,7 @@ func (s *Server) preLoadConfiguration(configMsg config.Message) {
 		logger.Debugf("Configuration received from provider %s: %s", configMsg.ProviderName, string(jsonConf))
 	}
 
-	if configMsg.Configuration == nil || configMsg.Configuration.Routers == nil && configMsg.Configuration.Services == nil && configMsg.Configuration.Middlewares == nil && configMsg.Configuration.TLS == nil {
+	if configMsg.Configuration == nil || configMsg.Configuration.Routers == nil && configMsg.Configuration.Services == nil && configMsg.Configuration.Middlewares == nil && configMsg.Configuration.TLS == nil && configMsg.Configuration.TLSOptions == nil {
 		logger.Infof("Skipping empty Configuration for provider %s", configMsg.ProviderName)
 		return
 	}
@@ -290,6 +342,137 @@ func (s *Server) loadHTTPSConfiguration(configurations config.Configurations) ma
 	return newEPCertificates
 }
 
+// loadTLSOptions returns the TLS options of the providers per entry point, and per domain of the routers of each entry point.
+// The options of an entry point are defined once, the next ones are ignored.
+// The entry points without options get their default named options, if any.
+func (s *Server) loadTLSOptions(configurations config.Configurations) (map[string]*traefiktls.Options, map[string]map[string]*traefiktls.Options) {
+	var providerNames []string
+	for providerName := range configurations {
+		providerNames = append(providerNames, providerName)
+	}
+	sort.Strings(providerNames)
+
+	options := make(map[string]*traefiktls.Options)
+	namedOptions := make(map[string]*traefiktls.Options)
+	for _, providerName := range providerNames {
+		for _, tlsOptions := range configurations[providerName].TLSOptions {
+			if tlsOptions.Name != "" {
+				if _, ok := namedOptions[tlsOptions.Name]; ok {
+					log.WithoutContext().Warnf("TLS options %s of %s ignored: the TLS options %s are already defined", tlsOptions.Name, providerName, tlsOptions.Name)
+				} else {
+					namedOptions[tlsOptions.Name] = tlsOptions
+				}
+			}
+
+			for _, entryPointName := range tlsOptions.EntryPoints {
+				if _, ok := s.entryPoints[entryPointName]; !ok {
+					log.WithoutContext().Errorf("TLS options of %s on the unknown %s entryPoint", providerName, entryPointName)
+					continue
+				}
+				if _, ok := options[entryPointName]; ok {
+					log.WithoutContext().Warnf("TLS options of %s ignored: the TLS options of the %s entryPoint are already defined", providerName, entryPointName)
+					continue
+				}
+				options[entryPointName] = tlsOptions
+			}
+		}
+	}
+
+	for entryPointName, entryPoint := range s.entryPoints {
+		if _, ok := options[entryPointName]; ok || entryPoint.staticTLS == nil || entryPoint.staticTLS.DefaultOptions == "" {
+			continue
+		}
+
+		defaultOptions, ok := namedOptions[entryPoint.staticTLS.DefaultOptions]
+		if !ok {
+			log.WithoutContext().Errorf("Unknown default TLS options %s of the %s entryPoint", entryPoint.staticTLS.DefaultOptions, entryPointName)
+			continue
+		}
+		options[entryPointName] = defaultOptions
+	}
+
+	return options, s.loadRoutersTLSOptions(configurations, providerNames, namedOptions)
+}
+
+// loadRoutersTLSOptions returns the named TLS options referenced by the routers, per domain of their rule on each of their TLS entry points.
+// The options of a domain are defined once, the next ones are ignored.
+func (s *Server) loadRoutersTLSOptions(configurations config.Configurations, providerNames []string, namedOptions map[string]*traefiktls.Options) map[string]map[string]*traefiktls.Options {
+	domainsOptions := make(map[string]map[string]*traefiktls.Options)
+
+	for _, providerName := range providerNames {
+		routers := configurations[providerName].Routers
+
+		var routerNames []string
+		for routerName := range routers {
+			routerNames = append(routerNames, routerName)
+		}
+		sort.Strings(routerNames)
+
+		for _, routerName := range routerNames {
+			router := routers[routerName]
+			if router.TLS == nil || router.TLS.Options == "" {
+				continue
+			}
+
+			logger := log.WithoutContext().WithField(log.RouterName, internal.MakeQualifiedName(providerName, routerName))
+
+			routerOptions, ok := namedOptions[router.TLS.Options]
+			if !ok {
+				logger.Errorf("Unknown TLS options %s", router.TLS.Options)
+				continue
+			}
+
+			domains, err := rules.ParseDomains(router.Rule)
+			if err != nil {
+				logger.Errorf("Cannot apply the TLS options: %v", err)
+				continue
+			}
+			if len(domains) == 0 {
+				logger.Errorf("Cannot apply the TLS options %s without a Host or HostSNI rule", router.TLS.Options)
+				continue
+			}
+
+			entryPoints := router.EntryPoints
+			if len(entryPoints) == 0 {
+				for entryPointName := range s.entryPoints {
+					entryPoints = append(entryPoints, entryPointName)
+				}
+			}
+
+			for _, entryPointName := range entryPoints {
+				entryPoint, ok := s.entryPoints[entryPointName]
+				if !ok {
+					continue
+				}
+
+				if entryPoint.staticTLS == nil {
+					// The routers without entryPoints are on all of them, including the non-TLS ones.
+					if len(router.EntryPoints) > 0 {
+						logger.Warnf("TLS options %s ignored on the non-TLS %s entryPoint", router.TLS.Options, entryPointName)
+					}
+					continue
+				}
+
+				if _, ok := domainsOptions[entryPointName]; !ok {
+					domainsOptions[entryPointName] = make(map[string]*traefiktls.Options)
+				}
+
+				for _, domain := range domains {
+					if existing, ok := domainsOptions[entryPointName][domain]; ok {
+						if existing != routerOptions {
+							logger.Warnf("TLS options %s ignored: the TLS options of %s on the %s entryPoint are already defined", router.TLS.Options, domain, entryPointName)
+						}
+						continue
+					}
+					domainsOptions[entryPointName][domain] = routerOptions
+				}
+			}
+		}
+	}
+
+	return domainsOptions
+}
+
 func buildDefaultHTTPRouter() *mux.Router {
 	rt := mux.NewRouter()
 	rt.NotFoundHandler = http.HandlerFunc(http.NotFound)
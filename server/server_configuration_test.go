@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -8,9 +9,12 @@ import (
 
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/metrics"
 	th "github.com/containous/traefik/testhelpers"
 	"github.com/containous/traefik/tls"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // LocalhostCert is a PEM-encoded TLS cert with SAN IPs
@@ -75,12 +79,57 @@ func TestServerLoadCertificateWithTLSEntryPoints(t *testing.T) {
 			Certs: tls.NewCertificateStore(),
 		},
 	})
-	_, mapsCerts := srv.loadConfig(dynamicConfigs)
+	_, mapsCerts, _ := srv.loadConfig(dynamicConfigs)
 	if len(mapsCerts["https"]) == 0 || len(mapsCerts["https2"]) == 0 {
 		t.Fatal("got error: https entryPoint must have TLS certificates.")
 	}
 }
 
+func TestServerLoadTLSOptions_NonTLSEntryPoint(t *testing.T) {
+	web, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+		Address:          "127.0.0.1:0",
+		Transport:        &static.EntryPointsTransport{},
+		ForwardedHeaders: &static.ForwardedHeaders{},
+	})
+	require.NoError(t, err)
+	defer web.listener.Close()
+
+	websecure, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+		Address:          "127.0.0.1:0",
+		Transport:        &static.EntryPointsTransport{},
+		ForwardedHeaders: &static.ForwardedHeaders{},
+		TLS:              &tls.TLS{},
+	})
+	require.NoError(t, err)
+	defer websecure.listener.Close()
+
+	srv := NewServer(static.Configuration{}, nil, EntryPoints{"web": web, "websecure": websecure})
+
+	configurations := config.Configurations{
+		"file": &config.Configuration{
+			Routers: map[string]*config.Router{
+				"foo": {
+					Rule:    "Host(`foo.localhost`)",
+					Service: "foo",
+					TLS:     &config.RouterTLSConfig{Options: "intermediate"},
+				},
+			},
+			TLSOptions: []*tls.Options{
+				{Name: "intermediate", MinVersion: "VersionTLS12"},
+			},
+		},
+	}
+
+	options, domainsOptions := srv.loadTLSOptions(configurations)
+
+	assert.NotContains(t, domainsOptions, "web")
+	require.Contains(t, domainsOptions, "websecure")
+	assert.Equal(t, map[string]*tls.Options{"foo.localhost": {Name: "intermediate", MinVersion: "VersionTLS12"}}, domainsOptions["websecure"])
+
+	assert.NoError(t, web.UpdateTLSOptions(options["web"], domainsOptions["web"]))
+	assert.NoError(t, websecure.UpdateTLSOptions(options["websecure"], domainsOptions["websecure"]))
+}
+
 func TestReuseService(t *testing.T) {
 	testServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(http.StatusOK)
@@ -117,7 +166,8 @@ func TestReuseService(t *testing.T) {
 
 	srv := NewServer(staticConfig, nil, entryPoints)
 
-	entrypointsHandlers, _ := srv.loadConfig(dynamicConfigs)
+	entrypointsHandlers, _, err := srv.loadConfig(dynamicConfigs)
+	require.NoError(t, err)
 
 	// Test that the /ok path returns a status 200.
 	responseRecorderOk := &httptest.ResponseRecorder{}
@@ -135,6 +185,94 @@ func TestReuseService(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, responseRecorderUnauthorized.Result().StatusCode, "status code")
 }
 
+type reloadMetrics struct {
+	metrics.Registry
+	reloads     *th.CollectingCounter
+	failures    *th.CollectingCounter
+	durations   *th.CollectingHistogram
+	lastSuccess *th.CollectingGauge
+	lastFailure *th.CollectingGauge
+}
+
+func newReloadMetrics() *reloadMetrics {
+	return &reloadMetrics{
+		Registry:    metrics.NewVoidRegistry(),
+		reloads:     &th.CollectingCounter{},
+		failures:    &th.CollectingCounter{},
+		durations:   &th.CollectingHistogram{},
+		lastSuccess: &th.CollectingGauge{},
+		lastFailure: &th.CollectingGauge{},
+	}
+}
+
+func (r *reloadMetrics) ConfigReloadsCounter() gokitmetrics.Counter        { return r.reloads }
+func (r *reloadMetrics) ConfigReloadsFailureCounter() gokitmetrics.Counter { return r.failures }
+func (r *reloadMetrics) ConfigReloadDurationHistogram() gokitmetrics.Histogram {
+	return r.durations
+}
+func (r *reloadMetrics) LastConfigReloadSuccessGauge() gokitmetrics.Gauge { return r.lastSuccess }
+func (r *reloadMetrics) LastConfigReloadFailureGauge() gokitmetrics.Gauge { return r.lastFailure }
+
+func TestServerLoadConfigurationMetrics(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		rule             string
+		expectedFailures float64
+	}{
+		{
+			desc: "successful reload",
+			rule: "Path(`/ok`)",
+		},
+		{
+			desc:             "router failing to build",
+			rule:             "Invalid(`/ok`)",
+			expectedFailures: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+				Address:          "127.0.0.1:0",
+				Transport:        &static.EntryPointsTransport{},
+				ForwardedHeaders: &static.ForwardedHeaders{},
+			})
+			require.NoError(t, err)
+			defer entryPoint.listener.Close()
+
+			srv := NewServer(static.Configuration{}, nil, EntryPoints{"http": entryPoint})
+			registry := newReloadMetrics()
+			srv.metricsRegistry = registry
+
+			srv.loadConfiguration(config.Message{
+				ProviderName: "config",
+				Configuration: th.BuildConfiguration(
+					th.WithRouters(th.WithRouter("foo",
+						th.WithEntryPoints("http"),
+						th.WithServiceName("bar"),
+						th.WithRule(test.rule))),
+					th.WithLoadBalancerServices(th.WithService("bar",
+						th.WithLBMethod("wrr"),
+						th.WithServers(th.WithServer("http://127.0.0.1")))),
+				),
+			})
+
+			assert.Equal(t, float64(1), registry.reloads.CounterValue)
+			assert.Equal(t, test.expectedFailures, registry.failures.CounterValue)
+			assert.Len(t, registry.durations.Values, 1)
+
+			if test.expectedFailures > 0 {
+				assert.NotZero(t, registry.lastFailure.GaugeValue)
+				assert.Zero(t, registry.lastSuccess.GaugeValue)
+			} else {
+				assert.NotZero(t, registry.lastSuccess.GaugeValue)
+				assert.Zero(t, registry.lastFailure.GaugeValue)
+			}
+		})
+	}
+}
+
 func TestThrottleProviderConfigReload(t *testing.T) {
 	throttleDuration := 30 * time.Millisecond
 	publishConfig := make(chan config.Message)
@@ -189,3 +327,190 @@ func TestThrottleProviderConfigReload(t *testing.T) {
 		t.Error("Last config was not published in time")
 	}
 }
+
+type panicsMetrics struct {
+	metrics.Registry
+	panics *th.CollectingCounter
+}
+
+func (r *panicsMetrics) EntrypointPanicsCounter() gokitmetrics.Counter { return r.panics }
+
+type panickingTransport struct{}
+
+func (panickingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/panic" {
+		panic("boom")
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: make(http.Header), Request: req}, nil
+}
+
+func TestServerRecoversPanics(t *testing.T) {
+	srv := NewServer(static.Configuration{}, nil, EntryPoints{"http": &EntryPoint{}})
+	registry := &panicsMetrics{Registry: metrics.NewVoidRegistry(), panics: &th.CollectingCounter{}}
+	srv.metricsRegistry = registry
+	srv.defaultRoundTripper = panickingTransport{}
+
+	handlers, err := srv.applyConfiguration(context.Background(), *th.BuildConfiguration(
+		th.WithRouters(th.WithRouter("foo",
+			th.WithEntryPoints("http"),
+			th.WithServiceName("bar"),
+			th.WithRule("PathPrefix(`/`)"))),
+		th.WithLoadBalancerServices(th.WithService("bar",
+			th.WithLBMethod("wrr"),
+			th.WithServers(th.WithServer("http://127.0.0.1")))),
+	))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(handlers["http"])
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/panic")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/ok")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, float64(1), registry.panics.CounterValue)
+	assert.Equal(t, []string{"entrypoint", "http"}, registry.panics.LastLabelValues)
+}
+
+type pathRecorderTransport struct {
+	paths chan string
+}
+
+func (t pathRecorderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.paths <- req.URL.Path
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: make(http.Header), Request: req}, nil
+}
+
+func TestServerPathNormalization(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		normalization      *static.PathNormalization
+		path               string
+		expectedStatusCode int
+		expectedPath       string
+	}{
+		{
+			desc:               "without normalization",
+			path:               "/a/../admin",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:               "dot segments",
+			normalization:      &static.PathNormalization{},
+			path:               "/a/../admin",
+			expectedStatusCode: http.StatusOK,
+			expectedPath:       "/a/../admin",
+		},
+		{
+			desc:               "duplicate slashes forwarded",
+			normalization:      &static.PathNormalization{Forward: true},
+			path:               "/a/..//admin//users",
+			expectedStatusCode: http.StatusOK,
+			expectedPath:       "/admin/users",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srv := NewServer(static.Configuration{}, nil, EntryPoints{"http": &EntryPoint{pathNormalization: test.normalization}})
+
+			transport := pathRecorderTransport{paths: make(chan string, 1)}
+			srv.defaultRoundTripper = transport
+
+			handlers, err := srv.applyConfiguration(context.Background(), *th.BuildConfiguration(
+				th.WithRouters(th.WithRouter("foo",
+					th.WithEntryPoints("http"),
+					th.WithServiceName("bar"),
+					th.WithRule("PathPrefix(`/admin`)"))),
+				th.WithLoadBalancerServices(th.WithService("bar",
+					th.WithLBMethod("wrr"),
+					th.WithServers(th.WithServer("http://127.0.0.1")))),
+			))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handlers["http"].ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.bar"+test.path, nil))
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			if test.expectedPath != "" {
+				assert.Equal(t, test.expectedPath, <-transport.paths)
+			}
+		})
+	}
+}
+
+type headerRecorderTransport struct {
+	headers chan http.Header
+}
+
+func (t headerRecorderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.headers <- req.Header
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: make(http.Header), Request: req}, nil
+}
+
+func TestServerTransportHeader(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		middlewares    []string
+		expectedTenant string
+	}{
+		{
+			desc: "header sent by the client",
+		},
+		{
+			desc:           "header set by a middleware",
+			middlewares:    []string{"tenant"},
+			expectedTenant: "acme",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			staticConfiguration := static.Configuration{
+				ServersTransport: &static.ServersTransport{
+					HeaderTransports: &static.HeaderTransports{Header: "X-Tenant"},
+				},
+			}
+			srv := NewServer(staticConfiguration, nil, EntryPoints{"http": &EntryPoint{}})
+
+			transport := headerRecorderTransport{headers: make(chan http.Header, 1)}
+			srv.defaultRoundTripper = transport
+
+			handlers, err := srv.applyConfiguration(context.Background(), *th.BuildConfiguration(
+				th.WithRouters(th.WithRouter("foo",
+					th.WithEntryPoints("http"),
+					th.WithServiceName("bar"),
+					th.WithRouterMiddlewares(test.middlewares...),
+					th.WithRule("PathPrefix(`/`)"))),
+				th.WithMiddlewares(th.WithMiddleware("tenant", func(middleware *config.Middleware) {
+					middleware.Headers = &config.Headers{CustomRequestHeaders: map[string]string{"X-Tenant": "acme"}}
+				})),
+				th.WithLoadBalancerServices(th.WithService("bar",
+					th.WithLBMethod("wrr"),
+					th.WithServers(th.WithServer("http://127.0.0.1")))),
+			))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo.bar/", nil)
+			req.Header.Set("X-Tenant", "other")
+
+			recorder := httptest.NewRecorder()
+			handlers["http"].ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, test.expectedTenant, (<-transport.headers).Get("X-Tenant"))
+		})
+	}
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	stdlog "log"
 	"net"
 	"net/http"
+	"os"
+	"runtime"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/armon/go-proxyproto"
@@ -19,6 +24,7 @@ import (
 	"github.com/containous/traefik/middlewares"
 	"github.com/containous/traefik/middlewares/forwardedheaders"
 	"github.com/containous/traefik/old/configuration"
+	"github.com/containous/traefik/safe"
 	traefiktls "github.com/containous/traefik/tls"
 	"github.com/containous/traefik/tls/generate"
 	"github.com/containous/traefik/types"
@@ -37,11 +43,19 @@ func NewEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*Entr
 	handler, err := forwardedheaders.NewXForwarded(
 		configuration.ForwardedHeaders.Insecure,
 		configuration.ForwardedHeaders.TrustedIPs,
+		configuration.ForwardedHeaders.XForwardedForMode,
 		switcher)
 	if err != nil {
 		return nil, err
 	}
 
+	var router http.Handler = handler
+	var drainer *connectionDrainer
+	if configuration.Transport.LifeCycle != nil && configuration.Transport.LifeCycle.DrainConnections {
+		drainer = &connectionDrainer{next: handler}
+		router = drainer
+	}
+
 	tracker := newHijackConnectionTracker()
 
 	listener, err := buildListener(ctx, configuration)
@@ -51,6 +65,7 @@ func NewEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*Entr
 
 	var tlsConfig *tls.Config
 	var certificateStore *traefiktls.CertificateStore
+	var sessionTicketKeys *traefiktls.SessionTicketKeys
 	if configuration.TLS != nil {
 		certificateStore, err = buildCertificateStore(*configuration.TLS)
 		if err != nil {
@@ -61,6 +76,18 @@ func NewEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*Entr
 		if err != nil {
 			return nil, fmt.Errorf("error creating TLS config: %v", err)
 		}
+
+		if configuration.TLS.SessionTickets != nil {
+			sessionTicketKeys, err = traefiktls.NewSessionTicketKeys(*configuration.TLS.SessionTickets, tlsConfig)
+			if err != nil {
+				return nil, fmt.Errorf("error creating TLS session ticket keys: %v", err)
+			}
+		}
+	}
+
+	httpServer := buildServer(ctx, configuration, tlsConfig, router, tracker)
+	if err := configureHTTP2(httpServer, configuration); err != nil {
+		return nil, fmt.Errorf("error configuring HTTP/2: %v", err)
 	}
 
 	entryPoint := &EntryPoint{
@@ -68,12 +95,24 @@ func NewEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*Entr
 		transportConfiguration:  configuration.Transport,
 		hijackConnectionTracker: tracker,
 		listener:                listener,
-		httpServer:              buildServer(ctx, configuration, tlsConfig, handler, tracker),
+		httpServer:              httpServer,
 		Certs:                   certificateStore,
+		middlewares:             configuration.Middlewares,
+		sessionTicketKeys:       sessionTicketKeys,
+		drainer:                 drainer,
+		staticTLS:               configuration.TLS,
+		dynamicTLSConfig:        safe.New((*dynamicTLSConfigs)(nil)),
+		tlsHandshakeTimeout:     time.Duration(configuration.TLSHandshakeTimeout),
+		pathNormalization:       configuration.PathNormalization,
+	}
+
+	if entryPoint.tlsHandshakeTimeout == 0 {
+		entryPoint.tlsHandshakeTimeout = static.DefaultTLSHandshakeTimeout
 	}
 
 	if tlsConfig != nil {
 		tlsConfig.GetCertificate = entryPoint.getCertificate
+		tlsConfig.GetConfigForClient = entryPoint.getConfigForClient
 	}
 
 	return entryPoint, nil
@@ -90,6 +129,13 @@ type EntryPoint struct {
 	TLSALPNGetter           func(string) (*tls.Certificate, error)
 	hijackConnectionTracker *hijackConnectionTracker
 	transportConfiguration  *static.EntryPointsTransport
+	middlewares             []string
+	sessionTicketKeys       *traefiktls.SessionTicketKeys
+	drainer                 *connectionDrainer
+	staticTLS               *traefiktls.TLS
+	dynamicTLSConfig        *safe.Safe
+	tlsHandshakeTimeout     time.Duration
+	pathNormalization       *static.PathNormalization
 }
 
 // Start starts listening for traffic
@@ -99,7 +145,15 @@ func (s *EntryPoint) Start(ctx context.Context) {
 
 	var err error
 	if s.httpServer.TLSConfig != nil {
-		err = s.httpServer.ServeTLS(s.listener, "", "")
+		// The TLS listener uses the TLS config as is (ServeTLS would clone it),
+		// so that the rotations of the session ticket keys apply to it.
+		var listener net.Listener
+		if s.tlsHandshakeTimeout > 0 {
+			listener = newTLSHandshakeListener(s.listener, s.httpServer.TLSConfig, s.tlsHandshakeTimeout)
+		} else {
+			listener = tls.NewListener(s.listener, s.httpServer.TLSConfig)
+		}
+		err = s.httpServer.Server.Serve(listener)
 	} else {
 		err = s.httpServer.Serve(s.listener)
 	}
@@ -113,6 +167,10 @@ func (s *EntryPoint) Start(ctx context.Context) {
 func (s EntryPoint) Shutdown(ctx context.Context) {
 	logger := log.FromContext(ctx)
 
+	if s.drainer != nil {
+		s.drainer.start()
+	}
+
 	reqAcceptGraceTimeOut := time.Duration(s.transportConfiguration.LifeCycle.RequestAcceptGraceTimeout)
 	if reqAcceptGraceTimeOut > 0 {
 		logger.Infof("Waiting %s for incoming requests to cease", reqAcceptGraceTimeOut)
@@ -157,11 +215,117 @@ func (s EntryPoint) Shutdown(ctx context.Context) {
 	cancel()
 }
 
+// connectionDrainer asks the clients to close their connection once draining started.
+type connectionDrainer struct {
+	next     http.Handler
+	draining int32
+}
+
+func (d *connectionDrainer) start() {
+	atomic.StoreInt32(&d.draining, 1)
+}
+
+func (d *connectionDrainer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The Connection header is forbidden in HTTP/2, whose connections get a GOAWAY from the server shutdown.
+	if req.ProtoMajor == 1 && atomic.LoadInt32(&d.draining) == 1 {
+		rw.Header().Set("Connection", "close")
+	}
+	d.next.ServeHTTP(rw, req)
+}
+
+// dynamicTLSConfigs holds the TLS configs rebuilt from the dynamic TLS options,
+// the one of the entry point and the ones of the domains of the routers.
+type dynamicTLSConfigs struct {
+	defaultConfig *tls.Config
+	domains       map[string]*tls.Config
+}
+
+// UpdateTLSOptions rebuilds the TLS config of the new connections, from the static TLS configuration overridden by the options.
+// The domains options apply to the connections to these domains, and the options to the other ones.
+// The TLS config of the static configuration is restored without options.
+func (s *EntryPoint) UpdateTLSOptions(options *traefiktls.Options, domainsOptions map[string]*traefiktls.Options) error {
+	if s.staticTLS == nil {
+		if options != nil || len(domainsOptions) > 0 {
+			return errors.New("TLS options on a non-TLS entry point")
+		}
+		return nil
+	}
+
+	if options == nil && len(domainsOptions) == 0 {
+		s.dynamicTLSConfig.Set((*dynamicTLSConfigs)(nil))
+		return nil
+	}
+
+	configs := &dynamicTLSConfigs{domains: make(map[string]*tls.Config)}
+
+	// The domains sharing the same options share the same TLS config.
+	built := make(map[*traefiktls.Options]*tls.Config)
+	for domain, domainOptions := range domainsOptions {
+		tlsConfig, ok := built[domainOptions]
+		if !ok {
+			var err error
+			tlsConfig, err = s.buildDynamicTLSConfig(domainOptions)
+			if err != nil {
+				return fmt.Errorf("invalid TLS options of %s: %v", domain, err)
+			}
+			built[domainOptions] = tlsConfig
+		}
+		configs.domains[types.CanonicalDomain(domain)] = tlsConfig
+	}
+
+	if options != nil {
+		tlsConfig, err := s.buildDynamicTLSConfig(options)
+		if err != nil {
+			return err
+		}
+		configs.defaultConfig = tlsConfig
+	}
+
+	s.dynamicTLSConfig.Set(configs)
+	return nil
+}
+
+func (s *EntryPoint) buildDynamicTLSConfig(options *traefiktls.Options) (*tls.Config, error) {
+	tlsOption := *s.staticTLS
+	if options.MinVersion != "" {
+		if _, ok := traefiktls.MinVersion[options.MinVersion]; !ok {
+			return nil, fmt.Errorf("unknown TLS min version %q", options.MinVersion)
+		}
+		tlsOption.MinVersion = options.MinVersion
+	}
+	if len(options.CipherSuites) > 0 {
+		tlsOption.CipherSuites = options.CipherSuites
+	}
+
+	tlsConfig, err := buildTLSConfig(tlsOption)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.GetCertificate = s.getCertificate
+
+	return tlsConfig, nil
+}
+
+// getConfigForClient returns the TLS config rebuilt from the dynamic TLS options of the requested domain, or of the entry point,
+// or nil to use the TLS config of the static configuration.
+func (s *EntryPoint) getConfigForClient(clientHello *tls.ClientHelloInfo) (*tls.Config, error) {
+	configs := s.dynamicTLSConfig.Get().(*dynamicTLSConfigs)
+	if configs == nil {
+		return nil, nil
+	}
+
+	if tlsConfig, ok := configs.domains[types.CanonicalDomain(clientHello.ServerName)]; ok {
+		return tlsConfig, nil
+	}
+	return configs.defaultConfig, nil
+}
+
 // getCertificate allows to customize tlsConfig.GetCertificate behavior to get the certificates inserted dynamically
 func (s *EntryPoint) getCertificate(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
 	domainToCheck := types.CanonicalDomain(clientHello.ServerName)
 
-	if s.TLSALPNGetter != nil {
+	// Only the ACME server announces the acme-tls/1 protocol, so the other clients never need a challenge certificate.
+	if s.TLSALPNGetter != nil && supportsProtocol(clientHello.SupportedProtos, tlsalpn01.ACMETLS1Protocol) {
 		cert, err := s.TLSALPNGetter(domainToCheck)
 		if err != nil {
 			return nil, err
@@ -314,22 +478,116 @@ func buildServerTimeouts(entryPointsTransport static.EntryPointsTransport) (read
 }
 
 func buildListener(ctx context.Context, entryPoint *static.EntryPoint) (net.Listener, error) {
-	listener, err := net.Listen("tcp", entryPoint.Address)
+	listener, err := listen(entryPoint)
 	if err != nil {
 		return nil, fmt.Errorf("error opening listener: %v", err)
 	}
 
-	listener = tcpKeepAliveListener{listener.(*net.TCPListener)}
-
 	if entryPoint.ProxyProtocol != nil {
 		listener, err = buildProxyProtocolListener(ctx, entryPoint, listener)
 		if err != nil {
 			return nil, fmt.Errorf("error creating proxy protocol listener: %v", err)
 		}
 	}
+
+	if entryPoint.MaxConnections > 0 {
+		listener = newConnectionLimitListener(listener, entryPoint.MaxConnections, time.Duration(entryPoint.MaxConnectionsQueueTimeout))
+	}
+	return listener, nil
+}
+
+func listen(entryPoint *static.EntryPoint) (net.Listener, error) {
+	if socketPath, ok := entryPoint.UnixSocketPath(); ok {
+		return buildUnixListener(socketPath, entryPoint.SocketMode)
+	}
+
+	network, err := listenNetwork(entryPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var listenConfig net.ListenConfig
+	if entryPoint.ReusePort {
+		if reusePortSupported {
+			listenConfig.Control = reusePortControl
+		} else {
+			log.WithoutContext().Warnf("SO_REUSEPORT is not supported on %s, %s is listened on without it", runtime.GOOS, entryPoint.Address)
+		}
+	}
+
+	listener, err := listenConfig.Listen(context.Background(), network, entryPoint.Address)
+	if err != nil {
+		return nil, err
+	}
+	return tcpKeepAliveListener{listener.(*net.TCPListener)}, nil
+}
+
+// listenNetwork returns the network matching the address family requested by the entry point:
+// an IPv4 address binds IPv4 only, the other addresses bind both IPv4 and IPv6, unless IPv6Only is set.
+func listenNetwork(entryPoint *static.EntryPoint) (string, error) {
+	host, _, err := net.SplitHostPort(entryPoint.Address)
+	if err != nil {
+		return "", err
+	}
+
+	if ip := net.ParseIP(host); ip != nil && ip.To4() != nil {
+		if entryPoint.IPv6Only {
+			return "", fmt.Errorf("IPv6Only is set on the IPv4 address %s", entryPoint.Address)
+		}
+		return "tcp4", nil
+	}
+
+	if entryPoint.IPv6Only {
+		return "tcp6", nil
+	}
+	return "tcp", nil
+}
+
+// buildUnixListener listens on the Unix socket, replacing the socket file left by a previous instance.
+// The socket file is removed when the listener is closed.
+func buildUnixListener(socketPath string, socketMode string) (*net.UnixListener, error) {
+	var mode os.FileMode
+	if socketMode != "" {
+		parsedMode, err := parseSocketMode(socketMode)
+		if err != nil {
+			return nil, err
+		}
+		mode = parsedMode
+	}
+
+	if info, err := os.Lstat(socketPath); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s already exists and is not a socket", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	listener.SetUnlinkOnClose(true)
+
+	if mode != 0 {
+		if err := os.Chmod(socketPath, mode); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("unable to set the mode of %s: %v", socketPath, err)
+		}
+	}
+
 	return listener, nil
 }
 
+func parseSocketMode(socketMode string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(socketMode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid socket mode %q: it must be an octal file mode, e.g. 0660", socketMode)
+	}
+	return os.FileMode(mode), nil
+}
+
 func buildCertificateStore(tlsOption traefiktls.TLS) (*traefiktls.CertificateStore, error) {
 	certificateStore := traefiktls.NewCertificateStore()
 	certificateStore.DynamicCerts.Set(make(map[string]*tls.Certificate))
@@ -364,13 +622,14 @@ func buildServer(ctx context.Context, configuration *static.EntryPoint, tlsConfi
 
 	return &h2c.Server{
 		Server: &http.Server{
-			Addr:         configuration.Address,
-			Handler:      router,
-			TLSConfig:    tlsConfig,
-			ReadTimeout:  readTimeout,
-			WriteTimeout: writeTimeout,
-			IdleTimeout:  idleTimeout,
-			ErrorLog:     stdlog.New(logger.WriterLevel(logrus.DebugLevel), "", 0),
+			Addr:           configuration.Address,
+			Handler:        router,
+			TLSConfig:      tlsConfig,
+			ReadTimeout:    readTimeout,
+			WriteTimeout:   writeTimeout,
+			IdleTimeout:    idleTimeout,
+			MaxHeaderBytes: configuration.MaxHeaderBytes,
+			ErrorLog:       stdlog.New(logger.WriterLevel(logrus.DebugLevel), "", 0),
 			ConnState: func(conn net.Conn, state http.ConnState) {
 				switch state {
 				case http.StateHijacked:
@@ -387,8 +646,7 @@ func buildServer(ctx context.Context, configuration *static.EntryPoint, tlsConfi
 func buildTLSConfig(tlsOption traefiktls.TLS) (*tls.Config, error) {
 	conf := &tls.Config{}
 
-	// ensure http2 enabled
-	conf.NextProtos = []string{"h2", "http/1.1", tlsalpn01.ACMETLS1Protocol}
+	conf.NextProtos = buildALPNProtocols(tlsOption.ALPNProtocols)
 
 	if len(tlsOption.ClientCA.Files) > 0 {
 		pool := x509.NewCertPool()
@@ -432,3 +690,31 @@ func buildTLSConfig(tlsOption traefiktls.TLS) (*tls.Config, error) {
 
 	return conf, nil
 }
+
+// buildALPNProtocols returns the protocols announced through ALPN, by order of preference.
+// The ACME TLS-ALPN-01 challenge protocol is always announced, so that the challenge keeps working.
+func buildALPNProtocols(protocols []string) []string {
+	if len(protocols) == 0 {
+		// ensure http2 enabled
+		protocols = []string{"h2", "http/1.1"}
+	}
+
+	var nextProtos []string
+	for _, protocol := range protocols {
+		if protocol != tlsalpn01.ACMETLS1Protocol {
+			nextProtos = append(nextProtos, protocol)
+		}
+	}
+
+	return append(nextProtos, tlsalpn01.ACMETLS1Protocol)
+}
+
+// supportsProtocol returns true if the protocol is one of the protocols announced by the client through ALPN.
+func supportsProtocol(protocols []string, protocol string) bool {
+	for _, p := range protocols {
+		if p == protocol {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/h2c"
+	"github.com/containous/traefik/log"
+	"golang.org/x/net/http2"
+	"golang.org/x/time/rate"
+)
+
+const http2FrameHeaderLen = 9
+
+var errTooManyResetStreams = errors.New("too many streams reset by the client")
+
+// configureHTTP2 serves the HTTP/2 connections of the entry point with its HTTP/2 limits, if any,
+// instead of the defaults of net/http, which keeps its bundled HTTP/2 server without limits.
+// The limits are set with http2.ConfigureServer, whose handler of the TLS connections is only replaced
+// to count the stream resets of the connections when MaxResetStreams is set.
+func configureHTTP2(server *h2c.Server, configuration *static.EntryPoint) error {
+	if configuration.MaxConcurrentStreams == 0 && configuration.MaxResetStreams == 0 {
+		return nil
+	}
+
+	h2Server := &http2.Server{MaxConcurrentStreams: configuration.MaxConcurrentStreams}
+
+	// http2.ConfigureServer registers the graceful shutdown of the connections,
+	// but the TLS configuration, e.g. its ALPN protocols, is kept as is.
+	tlsConfig := server.TLSConfig
+	server.TLSConfig = nil
+	err := http2.ConfigureServer(server.Server, h2Server)
+	server.TLSConfig = tlsConfig
+	if err != nil {
+		return err
+	}
+
+	server.HTTP2 = h2Server
+
+	if configuration.MaxResetStreams == 0 {
+		return nil
+	}
+
+	wrapConn := func(conn net.Conn) net.Conn {
+		return newResetLimitConn(conn, configuration.MaxResetStreams)
+	}
+
+	// As in the handler of http2.ConfigureServer, the server is the base configuration of the connections,
+	// so that their requests hold the http.ServerContextKey and http.LocalAddrContextKey values.
+	server.TLSNextProto[http2.NextProtoTLS] = func(hs *http.Server, conn *tls.Conn, handler http.Handler) {
+		h2Server.ServeConn(wrapConn(conn), &http2.ServeConnOpts{Handler: handler, BaseConfig: hs})
+	}
+	server.WrapConn = wrapConn
+
+	return nil
+}
+
+// resetLimitConn fails the reads of an HTTP/2 connection, which is then closed,
+// once the client resets more streams per second than the maximum, e.g. during a rapid reset attack.
+// It follows the frame headers in the data read, which it does not alter.
+type resetLimitConn struct {
+	net.Conn
+	limiter *rate.Limiter
+
+	// skip is the number of bytes to read before the next frame header: the client preface, then the frame payloads.
+	skip   int
+	header []byte
+}
+
+// tlsResetLimitConn exposes the TLS state of the connection to the HTTP/2 server.
+type tlsResetLimitConn struct {
+	*resetLimitConn
+	tlsConn *tls.Conn
+}
+
+func (c *tlsResetLimitConn) ConnectionState() tls.ConnectionState {
+	return c.tlsConn.ConnectionState()
+}
+
+func newResetLimitConn(conn net.Conn, maxResetStreams int) net.Conn {
+	limitConn := &resetLimitConn{
+		Conn:    conn,
+		limiter: rate.NewLimiter(rate.Limit(maxResetStreams), maxResetStreams),
+		skip:    len(http2.ClientPreface),
+		header:  make([]byte, 0, http2FrameHeaderLen),
+	}
+
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		return &tlsResetLimitConn{resetLimitConn: limitConn, tlsConn: tlsConn}
+	}
+	return limitConn
+}
+
+func (c *resetLimitConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+
+	if resets := c.countResetStreams(p[:n]); resets > 0 && !c.limiter.AllowN(time.Now(), resets) {
+		log.WithoutContext().Debugf("Closing the HTTP/2 connection of %s: %v", c.RemoteAddr(), errTooManyResetStreams)
+		return 0, errTooManyResetStreams
+	}
+
+	return n, err
+}
+
+// countResetStreams returns the number of RST_STREAM frames whose header is in buf.
+func (c *resetLimitConn) countResetStreams(buf []byte) int {
+	var resets int
+	for len(buf) > 0 {
+		if c.skip > 0 {
+			n := c.skip
+			if n > len(buf) {
+				n = len(buf)
+			}
+			c.skip -= n
+			buf = buf[n:]
+			continue
+		}
+
+		n := http2FrameHeaderLen - len(c.header)
+		if n > len(buf) {
+			n = len(buf)
+		}
+		c.header = append(c.header, buf[:n]...)
+		buf = buf[n:]
+
+		if len(c.header) < http2FrameHeaderLen {
+			break
+		}
+
+		if http2.FrameType(c.header[3]) == http2.FrameRSTStream {
+			resets++
+		}
+		c.skip = int(c.header[0])<<16 | int(c.header[1])<<8 | int(c.header[2])
+		c.header = c.header[:0]
+	}
+	return resets
+}
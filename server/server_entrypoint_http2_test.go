@@ -0,0 +1,190 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config/static"
+	traefiktls "github.com/containous/traefik/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+func TestResetLimitConn_CountResetStreams(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString(http2.ClientPreface)
+
+	framer := http2.NewFramer(&buf, nil)
+	require.NoError(t, framer.WriteSettings())
+	require.NoError(t, framer.WriteData(1, false, make([]byte, 100)))
+	require.NoError(t, framer.WriteRSTStream(1, http2.ErrCodeCancel))
+	require.NoError(t, framer.WritePing(false, [8]byte{}))
+	require.NoError(t, framer.WriteRSTStream(3, http2.ErrCodeCancel))
+
+	conn := newResetLimitConn(nil, 10).(*resetLimitConn)
+
+	// The frames are split across several reads.
+	var resets int
+	data := buf.Bytes()
+	for len(data) > 0 {
+		n := 7
+		if n > len(data) {
+			n = len(data)
+		}
+		resets += conn.countResetStreams(data[:n])
+		data = data[n:]
+	}
+
+	assert.Equal(t, 2, resets)
+}
+
+func TestEntryPoint_HTTP2Limits(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		resets         int
+		expectedClosed bool
+	}{
+		{
+			desc:   "few stream resets",
+			resets: 5,
+		},
+		{
+			desc:           "excessive stream resets",
+			resets:         50,
+			expectedClosed: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+				Address:              "127.0.0.1:0",
+				Transport:            &static.EntryPointsTransport{},
+				ForwardedHeaders:     &static.ForwardedHeaders{},
+				TLS:                  &traefiktls.TLS{},
+				MaxConcurrentStreams: 100,
+				MaxResetStreams:      10,
+			})
+			require.NoError(t, err)
+
+			go entryPoint.Start(context.Background())
+			defer entryPoint.httpServer.Close()
+
+			conn, err := tls.Dial("tcp", entryPoint.listener.Addr().String(), &tls.Config{
+				InsecureSkipVerify: true,
+				NextProtos:         []string{http2.NextProtoTLS},
+			})
+			require.NoError(t, err)
+			defer conn.Close()
+
+			require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+			_, err = io.WriteString(conn, http2.ClientPreface)
+			require.NoError(t, err)
+
+			framer := http2.NewFramer(conn, conn)
+			require.NoError(t, framer.WriteSettings())
+
+			frame, err := framer.ReadFrame()
+			require.NoError(t, err)
+			settings, ok := frame.(*http2.SettingsFrame)
+			require.True(t, ok, "unexpected frame %v", frame)
+
+			maxConcurrentStreams, ok := settings.Value(http2.SettingMaxConcurrentStreams)
+			assert.True(t, ok)
+			assert.EqualValues(t, 100, maxConcurrentStreams)
+
+			var headers bytes.Buffer
+			encoder := hpack.NewEncoder(&headers)
+			for _, field := range []hpack.HeaderField{
+				{Name: ":method", Value: "GET"},
+				{Name: ":scheme", Value: "https"},
+				{Name: ":authority", Value: "foo"},
+				{Name: ":path", Value: "/"},
+			} {
+				require.NoError(t, encoder.WriteField(field))
+			}
+
+			// The writes fail once the connection is closed.
+			for i := 0; i < test.resets; i++ {
+				streamID := uint32(2*i + 1)
+				_ = framer.WriteHeaders(http2.HeadersFrameParam{StreamID: streamID, BlockFragment: headers.Bytes(), EndStream: true, EndHeaders: true})
+				_ = framer.WriteRSTStream(streamID, http2.ErrCodeCancel)
+			}
+			_ = framer.WritePing(false, [8]byte{1})
+
+			closed := true
+			for {
+				frame, err := framer.ReadFrame()
+				if err != nil {
+					break
+				}
+				if ping, ok := frame.(*http2.PingFrame); ok && ping.IsAck() {
+					closed = false
+					break
+				}
+			}
+
+			assert.Equal(t, test.expectedClosed, closed)
+		})
+	}
+}
+
+func TestEntryPoint_HTTP2LimitsContext(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		maxResetStreams int
+	}{
+		{
+			desc: "maximum concurrent streams",
+		},
+		{
+			desc:            "maximum stream resets",
+			maxResetStreams: 10,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+				Address:              "127.0.0.1:0",
+				Transport:            &static.EntryPointsTransport{},
+				ForwardedHeaders:     &static.ForwardedHeaders{},
+				TLS:                  &traefiktls.TLS{},
+				MaxConcurrentStreams: 100,
+				MaxResetStreams:      test.maxResetStreams,
+			})
+			require.NoError(t, err)
+
+			var requestServer interface{}
+			var localAddr interface{}
+			entryPoint.switcher.UpdateHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				requestServer = req.Context().Value(http.ServerContextKey)
+				localAddr = req.Context().Value(http.LocalAddrContextKey)
+			}))
+
+			go entryPoint.Start(context.Background())
+			defer entryPoint.httpServer.Close()
+
+			transport := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+			require.NoError(t, http2.ConfigureTransport(transport))
+
+			resp, err := (&http.Client{Transport: transport}).Get("https://" + entryPoint.listener.Addr().String())
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, 2, resp.ProtoMajor)
+			assert.Equal(t, entryPoint.httpServer.Server, requestServer)
+			require.NotNil(t, localAddr)
+			assert.Equal(t, entryPoint.listener.Addr().String(), localAddr.(net.Addr).String())
+		})
+	}
+}
@@ -0,0 +1,494 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config/static"
+	traefiktls "github.com/containous/traefik/tls"
+	"github.com/containous/traefik/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xenolf/lego/challenge/tlsalpn01"
+	"golang.org/x/net/http2"
+)
+
+func TestBuildTLSConfig_ALPNProtocols(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		alpnProtocols      []string
+		clientProtocols    []string
+		expectedNextProtos []string
+		expected           string
+	}{
+		{
+			desc:               "default protocols",
+			clientProtocols:    []string{"http/1.1", "h2"},
+			expectedNextProtos: []string{"h2", "http/1.1", tlsalpn01.ACMETLS1Protocol},
+			expected:           "h2",
+		},
+		{
+			desc:               "only HTTP/1.1",
+			alpnProtocols:      []string{"http/1.1"},
+			clientProtocols:    []string{"h2", "http/1.1"},
+			expectedNextProtos: []string{"http/1.1", tlsalpn01.ACMETLS1Protocol},
+			expected:           "http/1.1",
+		},
+		{
+			desc:               "server preference",
+			alpnProtocols:      []string{"http/1.1", "h2"},
+			clientProtocols:    []string{"h2", "http/1.1"},
+			expectedNextProtos: []string{"http/1.1", "h2", tlsalpn01.ACMETLS1Protocol},
+			expected:           "http/1.1",
+		},
+		{
+			desc:               "custom protocol",
+			alpnProtocols:      []string{"foo", "h2"},
+			clientProtocols:    []string{"foo"},
+			expectedNextProtos: []string{"foo", "h2", tlsalpn01.ACMETLS1Protocol},
+			expected:           "foo",
+		},
+		{
+			desc:               "ACME TLS-ALPN-01 challenge always allowed",
+			alpnProtocols:      []string{"http/1.1"},
+			clientProtocols:    []string{tlsalpn01.ACMETLS1Protocol},
+			expectedNextProtos: []string{"http/1.1", tlsalpn01.ACMETLS1Protocol},
+			expected:           tlsalpn01.ACMETLS1Protocol,
+		},
+		{
+			desc:               "ACME TLS-ALPN-01 challenge not duplicated",
+			alpnProtocols:      []string{tlsalpn01.ACMETLS1Protocol, "h2"},
+			clientProtocols:    []string{"h2"},
+			expectedNextProtos: []string{"h2", tlsalpn01.ACMETLS1Protocol},
+			expected:           "h2",
+		},
+	}
+
+	cert, err := generate.DefaultCertificate()
+	require.NoError(t, err)
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conf, err := buildTLSConfig(traefiktls.TLS{ALPNProtocols: test.alpnProtocols})
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedNextProtos, conf.NextProtos)
+
+			conf.Certificates = []tls.Certificate{*cert}
+
+			listener, err := tls.Listen("tcp", "127.0.0.1:0", conf)
+			require.NoError(t, err)
+			defer listener.Close()
+
+			go func() {
+				conn, errAccept := listener.Accept()
+				if errAccept != nil {
+					return
+				}
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}()
+
+			conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+				InsecureSkipVerify: true,
+				NextProtos:         test.clientProtocols,
+			})
+			require.NoError(t, err)
+			defer conn.Close()
+
+			assert.Equal(t, test.expected, conn.ConnectionState().NegotiatedProtocol)
+		})
+	}
+}
+
+func TestGetCertificate_TLSALPNChallenge(t *testing.T) {
+	challengeCert := &tls.Certificate{}
+
+	testCases := []struct {
+		desc            string
+		supportedProtos []string
+		expectedCalls   int
+	}{
+		{
+			desc:            "ACME server",
+			supportedProtos: []string{tlsalpn01.ACMETLS1Protocol},
+			expectedCalls:   1,
+		},
+		{
+			desc:            "other client",
+			supportedProtos: []string{"h2", "http/1.1"},
+		},
+		{
+			desc: "no ALPN",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			entryPoint := &EntryPoint{
+				Certs: traefiktls.NewCertificateStore(),
+				TLSALPNGetter: func(domain string) (*tls.Certificate, error) {
+					calls++
+					return challengeCert, nil
+				},
+			}
+
+			cert, err := entryPoint.getCertificate(&tls.ClientHelloInfo{ServerName: "foo.bar", SupportedProtos: test.supportedProtos})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedCalls, calls)
+			if test.expectedCalls > 0 {
+				assert.Equal(t, challengeCert, cert)
+			}
+		})
+	}
+}
+
+func TestEntryPoint_TLS(t *testing.T) {
+	entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+		Address:          "127.0.0.1:0",
+		Transport:        &static.EntryPointsTransport{},
+		ForwardedHeaders: &static.ForwardedHeaders{},
+		TLS: &traefiktls.TLS{
+			SessionTickets: &traefiktls.SessionTickets{},
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, entryPoint.sessionTicketKeys)
+
+	go entryPoint.Start(context.Background())
+	defer entryPoint.httpServer.Close()
+
+	addr := entryPoint.listener.Addr().String()
+
+	transport := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	require.NoError(t, http2.ConfigureTransport(transport))
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get("https://" + addr)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, 2, resp.ProtoMajor)
+
+	clientConfig := &tls.Config{
+		InsecureSkipVerify: true,
+		MaxVersion:         tls.VersionTLS12,
+		ClientSessionCache: tls.NewLRUClientSessionCache(1),
+	}
+
+	assert.False(t, dialTLS(t, addr, clientConfig))
+	assert.True(t, dialTLS(t, addr, clientConfig))
+
+	// The sessions encrypted with the previous key are still resumed after a rotation.
+	require.NoError(t, entryPoint.sessionTicketKeys.Rotate())
+	assert.True(t, dialTLS(t, addr, clientConfig))
+}
+
+// dialTLS sends a request on a new TLS connection, and tells whether the TLS session was resumed.
+func dialTLS(t *testing.T, addr string, clientConfig *tls.Config) bool {
+	t.Helper()
+
+	conn, err := tls.Dial("tcp", addr, clientConfig)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = io.WriteString(conn, "GET / HTTP/1.1\r\nHost: foo\r\nConnection: close\r\n\r\n")
+	require.NoError(t, err)
+
+	_, err = io.Copy(ioutil.Discard, conn)
+	require.NoError(t, err)
+
+	return conn.ConnectionState().DidResume
+}
+
+func TestEntryPoint_UnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-unix-socket")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "traefik.sock")
+
+	// A socket file left by a previous instance is replaced.
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	require.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+		Address:    "unix://" + socketPath,
+		SocketMode: "0600",
+		Transport: &static.EntryPointsTransport{
+			LifeCycle: &static.LifeCycle{GraceTimeOut: parse.Duration(time.Second)},
+		},
+		ForwardedHeaders: &static.ForwardedHeaders{},
+	})
+	require.NoError(t, err)
+
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entryPoint.switcher.UpdateHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(req.Host + req.URL.Path))
+	}))
+
+	go entryPoint.Start(context.Background())
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+
+	resp, err := client.Get("http://foo.bar/baz")
+	require.NoError(t, err)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "foo.bar/baz", string(body))
+
+	entryPoint.Shutdown(context.Background())
+
+	_, err = os.Stat(socketPath)
+	assert.True(t, os.IsNotExist(err), "the socket file should be removed on shutdown")
+}
+
+func TestBuildUnixListener_NotASocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-unix-socket")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "traefik.sock")
+	require.NoError(t, ioutil.WriteFile(socketPath, []byte("foo"), 0644))
+
+	_, err = buildUnixListener(socketPath, "")
+	assert.Error(t, err)
+
+	_, err = buildUnixListener(filepath.Join(dir, "other.sock"), "999")
+	assert.Error(t, err)
+}
+
+func TestListen_AddressFamily(t *testing.T) {
+	if listener, err := net.Listen("tcp6", "[::1]:0"); err != nil {
+		t.Skip("IPv6 is not supported")
+	} else {
+		listener.Close()
+	}
+
+	testCases := []struct {
+		desc         string
+		address      string
+		ipv6Only     bool
+		expectedErr  bool
+		expectedIPv4 bool
+		expectedIPv6 bool
+	}{
+		{
+			desc:         "IPv4 only",
+			address:      "0.0.0.0:0",
+			expectedIPv4: true,
+		},
+		{
+			desc:         "IPv6 only",
+			address:      "[::]:0",
+			ipv6Only:     true,
+			expectedIPv6: true,
+		},
+		{
+			desc:         "dual-stack IPv6 address",
+			address:      "[::]:0",
+			expectedIPv4: true,
+			expectedIPv6: true,
+		},
+		{
+			desc:         "dual-stack without host",
+			address:      ":0",
+			expectedIPv4: true,
+			expectedIPv6: true,
+		},
+		{
+			desc:        "IPv6 only on an IPv4 address",
+			address:     "0.0.0.0:0",
+			ipv6Only:    true,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			listener, err := listen(&static.EntryPoint{Address: test.address, IPv6Only: test.ipv6Only})
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer listener.Close()
+
+			_, port, err := net.SplitHostPort(listener.Addr().String())
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedIPv4, canDial("tcp4", net.JoinHostPort("127.0.0.1", port)), "IPv4")
+			assert.Equal(t, test.expectedIPv6, canDial("tcp6", net.JoinHostPort("::1", port)), "IPv6")
+		})
+	}
+}
+
+func canDial(network, address string) bool {
+	conn, err := net.DialTimeout(network, address, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func TestEntryPoint_DrainConnections(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		drainConnections bool
+		expectedClose    bool
+	}{
+		{
+			desc:             "connection closed while draining",
+			drainConnections: true,
+			expectedClose:    true,
+		},
+		{
+			desc: "connection kept alive while draining",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+				Address: "127.0.0.1:0",
+				Transport: &static.EntryPointsTransport{
+					LifeCycle: &static.LifeCycle{
+						RequestAcceptGraceTimeout: parse.Duration(300 * time.Millisecond),
+						GraceTimeOut:              parse.Duration(time.Second),
+						DrainConnections:          test.drainConnections,
+					},
+				},
+				ForwardedHeaders: &static.ForwardedHeaders{},
+			})
+			require.NoError(t, err)
+
+			entryPoint.switcher.UpdateHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			}))
+
+			go entryPoint.Start(context.Background())
+			defer entryPoint.httpServer.Close()
+
+			client := &http.Client{Transport: &http.Transport{}}
+			url := "http://" + entryPoint.listener.Addr().String()
+
+			resp, err := client.Get(url)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.False(t, resp.Close, "connection closed before draining")
+
+			shutdownDone := make(chan struct{})
+			go func() {
+				entryPoint.Shutdown(context.Background())
+				close(shutdownDone)
+			}()
+
+			time.Sleep(50 * time.Millisecond)
+
+			resp, err = client.Get(url)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, test.expectedClose, resp.Close)
+
+			<-shutdownDone
+		})
+	}
+}
+
+func TestEntryPoint_MaxHeaderBytes(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		maxHeaderBytes int
+		headerSize     int
+		expectedStatus int
+	}{
+		{
+			desc:           "default limit",
+			headerSize:     8 * 1024,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "headers under the limit",
+			maxHeaderBytes: 16 * 1024,
+			headerSize:     8 * 1024,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "headers over the limit",
+			maxHeaderBytes: 1024,
+			headerSize:     16 * 1024,
+			expectedStatus: http.StatusRequestHeaderFieldsTooLarge,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+				Address:          "127.0.0.1:0",
+				MaxHeaderBytes:   test.maxHeaderBytes,
+				Transport:        &static.EntryPointsTransport{},
+				ForwardedHeaders: &static.ForwardedHeaders{},
+			})
+			require.NoError(t, err)
+
+			entryPoint.switcher.UpdateHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			}))
+
+			go entryPoint.Start(context.Background())
+			defer entryPoint.httpServer.Close()
+
+			req, err := http.NewRequest(http.MethodGet, "http://"+entryPoint.listener.Addr().String(), nil)
+			require.NoError(t, err)
+			req.Header.Set("X-Large", strings.Repeat("a", test.headerSize))
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// +build go1.13
+
+// TLS 1.3 is enabled by default since Go 1.13.
+
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/static"
+	traefiktls "github.com/containous/traefik/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryPoint_UpdateTLSOptions(t *testing.T) {
+	entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+		Address:          "127.0.0.1:0",
+		Transport:        &static.EntryPointsTransport{},
+		ForwardedHeaders: &static.ForwardedHeaders{},
+		TLS:              &traefiktls.TLS{},
+	})
+	require.NoError(t, err)
+
+	go entryPoint.Start(context.Background())
+	defer entryPoint.httpServer.Close()
+
+	addr := entryPoint.listener.Addr().String()
+
+	tls12Config := &tls.Config{InsecureSkipVerify: true, MaxVersion: tls.VersionTLS12}
+	tls13Config := &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS13}
+
+	established, err := tls.Dial("tcp", addr, tls12Config)
+	require.NoError(t, err)
+	defer established.Close()
+
+	err = entryPoint.UpdateTLSOptions(&traefiktls.Options{MinVersion: "VersionTLS13"}, nil)
+	require.NoError(t, err)
+
+	_, err = tls.Dial("tcp", addr, tls12Config)
+	assert.Error(t, err)
+	dialTLS(t, addr, tls13Config)
+
+	// The connections established before the reload are kept.
+	_, err = io.WriteString(established, "GET / HTTP/1.1\r\nHost: foo\r\nConnection: close\r\n\r\n")
+	require.NoError(t, err)
+	resp, err := http.ReadResponse(bufio.NewReader(established), nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// The static TLS configuration is restored without options.
+	require.NoError(t, entryPoint.UpdateTLSOptions(nil, nil))
+	dialTLS(t, addr, tls12Config)
+
+	assert.Error(t, entryPoint.UpdateTLSOptions(&traefiktls.Options{MinVersion: "foo"}, nil))
+	assert.Error(t, entryPoint.UpdateTLSOptions(&traefiktls.Options{CipherSuites: []string{"foo"}}, nil))
+}
+
+func TestServerLoadTLSOptions_EntryPointDefault(t *testing.T) {
+	entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+		Address:          "127.0.0.1:0",
+		Transport:        &static.EntryPointsTransport{},
+		ForwardedHeaders: &static.ForwardedHeaders{},
+		TLS:              &traefiktls.TLS{DefaultOptions: "modern"},
+	})
+	require.NoError(t, err)
+
+	go entryPoint.Start(context.Background())
+	defer entryPoint.httpServer.Close()
+
+	srv := NewServer(static.Configuration{}, nil, EntryPoints{"https": entryPoint})
+
+	configurations := config.Configurations{
+		"file": &config.Configuration{
+			Routers: map[string]*config.Router{
+				"default": {
+					Rule:    "Host(`default.localhost`)",
+					Service: "foo",
+					TLS:     &config.RouterTLSConfig{},
+				},
+				"intermediate": {
+					Rule:    "Host(`intermediate.localhost`)",
+					Service: "foo",
+					TLS:     &config.RouterTLSConfig{Options: "intermediate"},
+				},
+			},
+			TLSOptions: []*traefiktls.Options{
+				{Name: "modern", MinVersion: "VersionTLS13"},
+				{Name: "intermediate", MinVersion: "VersionTLS12"},
+			},
+		},
+	}
+
+	options, domainsOptions := srv.loadTLSOptions(configurations)
+	require.NoError(t, entryPoint.UpdateTLSOptions(options["https"], domainsOptions["https"]))
+
+	addr := entryPoint.listener.Addr().String()
+
+	// The router without TLS options inherits the default ones of the entry point.
+	_, err = tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true, ServerName: "default.localhost", MaxVersion: tls.VersionTLS12})
+	assert.Error(t, err)
+	dialTLS(t, addr, &tls.Config{InsecureSkipVerify: true, ServerName: "default.localhost", MinVersion: tls.VersionTLS13})
+
+	// The TLS options of the router override the default ones.
+	dialTLS(t, addr, &tls.Config{InsecureSkipVerify: true, ServerName: "intermediate.localhost", MaxVersion: tls.VersionTLS12})
+}
+
+func TestEntryPoint_UpdateTLSOptions_Domains(t *testing.T) {
+	entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+		Address:          "127.0.0.1:0",
+		Transport:        &static.EntryPointsTransport{},
+		ForwardedHeaders: &static.ForwardedHeaders{},
+		TLS:              &traefiktls.TLS{},
+	})
+	require.NoError(t, err)
+
+	go entryPoint.Start(context.Background())
+	defer entryPoint.httpServer.Close()
+
+	addr := entryPoint.listener.Addr().String()
+
+	err = entryPoint.UpdateTLSOptions(nil, map[string]*traefiktls.Options{"Modern.localhost": {MinVersion: "VersionTLS13"}})
+	require.NoError(t, err)
+
+	_, err = tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true, ServerName: "modern.localhost", MaxVersion: tls.VersionTLS12})
+	assert.Error(t, err)
+
+	// The other domains keep the TLS config of the static configuration.
+	dialTLS(t, addr, &tls.Config{InsecureSkipVerify: true, ServerName: "other.localhost", MaxVersion: tls.VersionTLS12})
+
+	assert.Error(t, entryPoint.UpdateTLSOptions(nil, map[string]*traefiktls.Options{"modern.localhost": {MinVersion: "foo"}}))
+}
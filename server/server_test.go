@@ -257,7 +257,7 @@ func TestServerResponseEmptyBackend(t *testing.T) {
 			dynamicConfigs := config.Configurations{"config": test.config(testServer.URL)}
 
 			srv := NewServer(globalConfig, nil, entryPointsConfig)
-			entryPoints, _ := srv.loadConfig(dynamicConfigs)
+			entryPoints, _, _ := srv.loadConfig(dynamicConfigs)
 
 			responseRecorder := &httptest.ResponseRecorder{}
 			request := httptest.NewRequest(http.MethodGet, testServer.URL+requestPath, nil)
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/healthcheck"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+const (
+	defaultLatencyWeightingInterval    = 10 * time.Second
+	defaultLatencyWeightingSensitivity = 1.0
+
+	// latencyWindowSize is the number of latest samples of each server from which its latency percentiles are computed.
+	latencyWindowSize = 256
+
+	// latencyWeightingScale is the factor applied to the configured weight of the fastest server.
+	latencyWeightingScale = 10
+
+	// latencyWeightingMinRatio is the share of its configured weight that a server keeps however slow it is,
+	// so that it is never starved.
+	latencyWeightingMinRatio = 0.1
+)
+
+// latencyWeighting measures the latency of each server,
+// and periodically adjusts the weights of the load-balancer so that the fastest servers receive more traffic.
+type latencyWeighting struct {
+	next            http.Handler
+	lb              healthcheck.BalancerHandler
+	serviceName     string
+	interval        time.Duration
+	sensitivity     float64
+	metricsRegistry metrics.Registry
+
+	lock        sync.Mutex
+	baseWeights map[string]int
+	latencies   map[string]*latencyWindow
+	lastAdjust  time.Time
+}
+
+func newLatencyWeighting(ctx context.Context, serviceName string, next http.Handler, conf *config.LatencyWeighting, servers []config.Server, scheme string, metricsRegistry metrics.Registry) *latencyWeighting {
+	interval := defaultLatencyWeightingInterval
+	if conf.Interval != "" {
+		intervalOverride, err := time.ParseDuration(conf.Interval)
+		switch {
+		case err != nil:
+			log.FromContext(ctx).Errorf("Illegal latency weighting interval: %s", err)
+		case intervalOverride <= 0:
+			log.FromContext(ctx).Errorf("Latency weighting interval smaller than zero")
+		default:
+			interval = intervalOverride
+		}
+	}
+
+	sensitivity := defaultLatencyWeightingSensitivity
+	switch {
+	case conf.Sensitivity < 0:
+		log.FromContext(ctx).Errorf("Latency weighting sensitivity smaller than zero")
+	case conf.Sensitivity > 0:
+		sensitivity = conf.Sensitivity
+	}
+
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	baseWeights := make(map[string]int)
+	for _, srv := range servers {
+		u, err := parseServerURL(srv.URL, scheme)
+		if err != nil {
+			continue
+		}
+
+		weight := srv.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		baseWeights[serverKey(u)] = weight
+	}
+
+	return &latencyWeighting{
+		next:            next,
+		serviceName:     serviceName,
+		interval:        interval,
+		sensitivity:     sensitivity,
+		metricsRegistry: metricsRegistry,
+		baseWeights:     baseWeights,
+		latencies:       make(map[string]*latencyWindow),
+		lastAdjust:      time.Now(),
+	}
+}
+
+func (l *latencyWeighting) setBalancer(lb healthcheck.BalancerHandler) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.lb = lb
+}
+
+func (l *latencyWeighting) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	l.next.ServeHTTP(rw, req)
+	l.observe(req.URL, time.Since(start))
+}
+
+func (l *latencyWeighting) observe(u *url.URL, latency time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	key := serverKey(u)
+	window, ok := l.latencies[key]
+	if !ok {
+		window = &latencyWindow{}
+		l.latencies[key] = window
+	}
+	window.add(latency.Seconds())
+
+	if l.lb != nil && time.Since(l.lastAdjust) >= l.interval {
+		l.adjustWeights()
+		l.lastAdjust = time.Now()
+	}
+}
+
+// adjustWeights gives each server a weight proportional to its configured weight,
+// scaled by how fast it is compared to the fastest server, raised to the power of the sensitivity.
+// The latency of a server is the mean of its p50 and p95 latencies.
+// Servers without any latency sample yet are weighted as the fastest one.
+func (l *latencyWeighting) adjustWeights() {
+	scores := make(map[string]float64)
+	fastest := math.MaxFloat64
+	for key, window := range l.latencies {
+		score := (window.percentile(0.5) + window.percentile(0.95)) / 2
+		scores[key] = score
+		if score > 0 && score < fastest {
+			fastest = score
+		}
+	}
+
+	if fastest == math.MaxFloat64 {
+		return
+	}
+
+	for _, u := range l.lb.Servers() {
+		key := serverKey(u)
+
+		baseWeight, ok := l.baseWeights[key]
+		if !ok {
+			continue
+		}
+
+		ratio := 1.0
+		if score, ok := scores[key]; ok && score > 0 {
+			ratio = math.Max(math.Pow(fastest/score, l.sensitivity), latencyWeightingMinRatio)
+		}
+
+		weight := int(math.Round(float64(baseWeight*latencyWeightingScale) * ratio))
+		if weight < 1 {
+			weight = 1
+		}
+
+		// A load-balancer which already scales the weights is given the ratio,
+		// so that the weights are not scaled twice.
+		var err error
+		if factorBalancer, ok := l.lb.(weightFactorBalancer); ok {
+			err = factorBalancer.SetWeightFactor(u, ratio)
+		} else {
+			err = l.lb.UpsertServer(u, roundrobin.Weight(weight))
+		}
+		if err != nil {
+			log.WithoutContext().Errorf("Unable to adjust the weight of the server %s: %v", u, err)
+			continue
+		}
+
+		l.metricsRegistry.BackendServerWeightGauge().With("service", l.serviceName, "url", u.String()).Set(float64(weight))
+	}
+}
+
+// latencyWindow holds the latest latency samples of a server, in seconds.
+type latencyWindow struct {
+	samples []float64
+	next    int
+}
+
+func (w *latencyWindow) add(sample float64) {
+	if len(w.samples) < latencyWindowSize {
+		w.samples = append(w.samples, sample)
+		return
+	}
+
+	w.samples[w.next] = sample
+	w.next = (w.next + 1) % latencyWindowSize
+}
+
+// percentile returns the nearest-rank percentile of the samples, p being between 0 and 1.
+func (w *latencyWindow) percentile(p float64) float64 {
+	if len(w.samples) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(w.samples))
+	copy(sorted, w.samples)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func serverKey(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
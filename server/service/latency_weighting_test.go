@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/metrics"
+	"github.com/containous/traefik/testhelpers"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestLatencyWeighting(t *testing.T) {
+	servers := []config.Server{
+		{URL: "http://10.0.0.1:80", Weight: 1},
+		{URL: "http://10.0.0.2:80", Weight: 1},
+		{URL: "http://10.0.0.3:80", Weight: 2},
+	}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+	weighting := newLatencyWeighting(context.Background(), "foo", next, &config.LatencyWeighting{Interval: "1h"}, servers, "", nil)
+
+	lb, err := roundrobin.New(weighting)
+	require.NoError(t, err)
+	for _, srv := range servers {
+		require.NoError(t, lb.UpsertServer(testhelpers.MustParseURL(srv.URL), roundrobin.Weight(srv.Weight)))
+	}
+	weighting.setBalancer(lb)
+
+	weighting.observe(testhelpers.MustParseURL("http://10.0.0.1:80/foo"), 10*time.Millisecond)
+	weighting.observe(testhelpers.MustParseURL("http://10.0.0.2:80/foo"), 30*time.Millisecond)
+
+	// Not adjusted before the interval is elapsed.
+	weight, _ := lb.ServerWeight(testhelpers.MustParseURL(servers[0].URL))
+	assert.Equal(t, 1, weight)
+
+	weighting.lastAdjust = time.Now().Add(-2 * time.Hour)
+	weighting.observe(testhelpers.MustParseURL("http://10.0.0.1:80/bar"), 10*time.Millisecond)
+
+	expected := map[string]int{
+		servers[0].URL: 10,
+		servers[1].URL: 3,
+		servers[2].URL: 20,
+	}
+	for serverURL, expectedWeight := range expected {
+		weight, ok := lb.ServerWeight(testhelpers.MustParseURL(serverURL))
+		require.True(t, ok)
+		assert.Equal(t, expectedWeight, weight, serverURL)
+	}
+
+	recorder := httptest.NewRecorder()
+	lb.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestLatencyWeighting_Sensitivity(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		sensitivity float64
+		expected    map[string]int
+	}{
+		{
+			desc: "default sensitivity",
+			expected: map[string]int{
+				"http://10.0.0.1:80": 10,
+				"http://10.0.0.2:80": 5,
+				"http://10.0.0.3:80": 1,
+			},
+		},
+		{
+			desc:        "higher sensitivity",
+			sensitivity: 2,
+			expected: map[string]int{
+				"http://10.0.0.1:80": 10,
+				"http://10.0.0.2:80": 3,
+				"http://10.0.0.3:80": 1,
+			},
+		},
+		{
+			desc:        "lower sensitivity",
+			sensitivity: 0.5,
+			expected: map[string]int{
+				"http://10.0.0.1:80": 10,
+				"http://10.0.0.2:80": 7,
+				"http://10.0.0.3:80": 1,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			servers := []config.Server{
+				{URL: "http://10.0.0.1:80", Weight: 1},
+				{URL: "http://10.0.0.2:80", Weight: 1},
+				{URL: "http://10.0.0.3:80", Weight: 1},
+			}
+
+			gauge := &progressGauge{lock: &sync.Mutex{}, values: make(map[string]float64)}
+			registry := &latencyWeightingRegistry{Registry: metrics.NewVoidRegistry(), gauge: gauge}
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+			conf := &config.LatencyWeighting{Interval: "1h", Sensitivity: test.sensitivity}
+			weighting := newLatencyWeighting(context.Background(), "foo", next, conf, servers, "", registry)
+
+			lb, err := roundrobin.New(weighting)
+			require.NoError(t, err)
+			for _, srv := range servers {
+				require.NoError(t, lb.UpsertServer(testhelpers.MustParseURL(srv.URL), roundrobin.Weight(srv.Weight)))
+			}
+			weighting.setBalancer(lb)
+
+			weighting.observe(testhelpers.MustParseURL("http://10.0.0.2:80"), 20*time.Millisecond)
+			// The last server is far slower, but keeps a tenth of its weight so that it is not starved.
+			weighting.observe(testhelpers.MustParseURL("http://10.0.0.3:80"), 10*time.Second)
+
+			weighting.lastAdjust = time.Now().Add(-2 * time.Hour)
+			weighting.observe(testhelpers.MustParseURL("http://10.0.0.1:80"), 10*time.Millisecond)
+
+			for serverURL, expectedWeight := range test.expected {
+				weight, ok := lb.ServerWeight(testhelpers.MustParseURL(serverURL))
+				require.True(t, ok)
+				assert.Equal(t, expectedWeight, weight, serverURL)
+				assert.Equal(t, float64(expectedWeight), gauge.get("foo", serverURL), serverURL)
+			}
+		})
+	}
+}
+
+func TestLatencyWindow(t *testing.T) {
+	window := &latencyWindow{}
+	assert.Equal(t, 0.0, window.percentile(0.5))
+
+	for i := 1; i <= 100; i++ {
+		window.add(float64(i))
+	}
+	assert.Equal(t, 50.0, window.percentile(0.5))
+	assert.Equal(t, 95.0, window.percentile(0.95))
+
+	// Only the latest samples are kept.
+	for i := 0; i < latencyWindowSize; i++ {
+		window.add(1000)
+	}
+	assert.Len(t, window.samples, latencyWindowSize)
+	assert.Equal(t, 1000.0, window.percentile(0.5))
+}
+
+type latencyWeightingRegistry struct {
+	metrics.Registry
+	gauge *progressGauge
+}
+
+func (r *latencyWeightingRegistry) BackendServerWeightGauge() gokitmetrics.Gauge {
+	return r.gauge
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/safe"
+	"github.com/vulcand/oxy/utils"
+)
+
+const (
+	defaultMirrorTimeout     = 30 * time.Second
+	defaultMirrorMaxBodySize = 1 << 20
+
+	// maxInFlightMirrorRequests is the maximum number of mirrored requests being served at the same time by a mirroring service.
+	// The requests are not mirrored beyond it, so that slow mirrors do not pile up goroutines.
+	maxInFlightMirrorRequests = 100
+)
+
+type serviceStackType int
+
+const serviceStackKey serviceStackType = iota
+
+func (m *Manager) getMirroringServiceHandler(ctx context.Context, serviceName string, conf *config.Mirroring, responseModifier func(*http.Response) error) (http.Handler, error) {
+	ctx, err := checkServiceRecursivity(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Service == "" {
+		return nil, fmt.Errorf("the mirroring service %q has no main service", serviceName)
+	}
+
+	handler, err := m.Build(ctx, conf.Service, responseModifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var synchronousTimeout time.Duration
+	if conf.SynchronousTimeout != "" {
+		synchronousTimeout, err = time.ParseDuration(conf.SynchronousTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid synchronous timeout for the mirroring service %q: %v", serviceName, err)
+		}
+		if synchronousTimeout <= 0 {
+			return nil, fmt.Errorf("invalid synchronous timeout %s for the mirroring service %q: it must be positive", conf.SynchronousTimeout, serviceName)
+		}
+		log.FromContext(ctx).Warnf("The mirrors of %s are synchronous: this is meant for testing only", serviceName)
+	}
+
+	timeout := defaultMirrorTimeout
+	if conf.Timeout != "" {
+		timeout, err = time.ParseDuration(conf.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout for the mirroring service %q: %v", serviceName, err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %s for the mirroring service %q: it must be positive", conf.Timeout, serviceName)
+		}
+	}
+
+	maxBodySize := conf.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = defaultMirrorMaxBodySize
+	}
+
+	mirroring := &mirroring{
+		handler:            handler,
+		headers:            conf.Headers,
+		synchronousTimeout: synchronousTimeout,
+		timeout:            timeout,
+		maxBodySize:        maxBodySize,
+		inFlight:           make(chan struct{}, maxInFlightMirrorRequests),
+	}
+	for _, mirrorConf := range conf.Mirrors {
+		if mirrorConf.Percent < 0 || mirrorConf.Percent > 100 {
+			return nil, fmt.Errorf("invalid percentage %d for the mirror %q: it must be between 0 and 100", mirrorConf.Percent, mirrorConf.Name)
+		}
+
+		mirrorHandler, err := m.Build(ctx, mirrorConf.Name, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		mirroring.mirrors = append(mirroring.mirrors, &mirror{handler: mirrorHandler, percent: uint64(mirrorConf.Percent)})
+	}
+
+	return mirroring, nil
+}
+
+func checkServiceRecursivity(ctx context.Context, serviceName string) (context.Context, error) {
+	currentStack, _ := ctx.Value(serviceStackKey).([]string)
+	for _, name := range currentStack {
+		if name == serviceName {
+			return ctx, fmt.Errorf("could not instantiate service %s: recursion detected in %s", serviceName, strings.Join(append(currentStack, serviceName), "->"))
+		}
+	}
+	return context.WithValue(ctx, serviceStackKey, append(currentStack, serviceName)), nil
+}
+
+// mirroring forwards the requests to the main handler,
+// and sends a copy of a part of them to each mirror, whose responses are discarded.
+// With a synchronous timeout, the main handler is called once the mirrors are done, or when the timeout is reached.
+// The requests whose body is too large, or which arrive while too many mirrored requests are in flight, are not mirrored.
+type mirroring struct {
+	handler            http.Handler
+	mirrors            []*mirror
+	headers            map[string]string
+	synchronousTimeout time.Duration
+	timeout            time.Duration
+	maxBodySize        int64
+	inFlight           chan struct{}
+}
+
+type mirror struct {
+	handler http.Handler
+	percent uint64
+	count   uint64
+}
+
+// hit tells whether the current request has to be mirrored,
+// so that exactly percent out of every 100 requests are mirrored.
+func (m *mirror) hit() bool {
+	count := atomic.AddUint64(&m.count, 1)
+	return count*m.percent/100 != (count-1)*m.percent/100
+}
+
+func (m *mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The websocket connections cannot be duplicated.
+	if isWebsocketRequest(req) {
+		m.handler.ServeHTTP(rw, req)
+		return
+	}
+
+	var mirrors []*mirror
+	for _, mirror := range m.mirrors {
+		if mirror.hit() {
+			mirrors = append(mirrors, mirror)
+		}
+	}
+
+	if len(mirrors) == 0 {
+		m.handler.ServeHTTP(rw, req)
+		return
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = m.readBody(req)
+		if err != nil {
+			log.FromContext(req.Context()).Debugf("Unable to read the body of the request to mirror: %v", err)
+			utils.DefaultHandler.ServeHTTP(rw, req, err)
+			return
+		}
+
+		if body == nil {
+			log.FromContext(req.Context()).Debugf("Not mirroring the request %s, whose body is larger than %d bytes", req.URL, m.maxBodySize)
+			m.handler.ServeHTTP(rw, req)
+			return
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, mirror := range mirrors {
+		select {
+		case m.inFlight <- struct{}{}:
+		default:
+			log.FromContext(req.Context()).Debugf("Not mirroring the request %s: too many mirrored requests in flight", req.URL)
+			continue
+		}
+
+		mirrorHandler := mirror.handler
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		mirrorReq := m.newMirrorRequest(ctx, req, body)
+		wg.Add(1)
+		safe.Go(func() {
+			defer func() {
+				cancel()
+				<-m.inFlight
+				wg.Done()
+			}()
+			mirrorHandler.ServeHTTP(&discardResponseWriter{header: make(http.Header)}, mirrorReq)
+		})
+	}
+
+	if m.synchronousTimeout > 0 {
+		m.waitMirrors(req, &wg)
+	}
+
+	m.handler.ServeHTTP(rw, req)
+}
+
+func (m *mirroring) waitMirrors(req *http.Request, wg *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(m.synchronousTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		log.FromContext(req.Context()).Debugf("The mirrors of the request %s are not done after %s", req.URL, m.synchronousTimeout)
+	}
+}
+
+// readBody reads the body of the request, and puts it back so that it can still be read by the main handler.
+// It returns a nil body, with the request body left unread, if the body is larger than the maximum size.
+func (m *mirroring) readBody(req *http.Request) ([]byte, error) {
+	if m.maxBodySize < 0 {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return body, nil
+	}
+
+	if req.ContentLength > m.maxBodySize {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, m.maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > m.maxBodySize {
+		req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+		return nil, nil
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// newMirrorRequest copies the request, with the mirroring headers.
+// The copy does not depend on the context of the original request, which ends with its response,
+// but on the given context, which bounds the duration of the mirrored request.
+func (m *mirroring) newMirrorRequest(ctx context.Context, req *http.Request, body []byte) *http.Request {
+	mirrorReq := req.WithContext(ctx)
+	mirrorReq.URL = utils.CopyURL(req.URL)
+
+	mirrorReq.Header = make(http.Header, len(req.Header)+len(m.headers))
+	utils.CopyHeaders(mirrorReq.Header, req.Header)
+	for name, value := range m.headers {
+		mirrorReq.Header.Set(name, value)
+	}
+
+	mirrorReq.Body = http.NoBody
+	if body != nil {
+		mirrorReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return mirrorReq
+}
+
+func isWebsocketRequest(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get("Upgrade"), "websocket")
+}
+
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(code int) {}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedRequest struct {
+	header string
+	body   string
+}
+
+func TestMirroring(t *testing.T) {
+	mainRequests := make(chan receivedRequest, 10)
+	mainServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mainRequests <- receivedRequest{header: r.Header.Get("X-Traefik-Mirror"), body: string(body)}
+		w.Header().Set("X-From", "main")
+	}))
+	defer mainServer.Close()
+
+	mirrorRequests := make(chan receivedRequest, 10)
+	mirrorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mirrorRequests <- receivedRequest{header: r.Header.Get("X-Traefik-Mirror"), body: string(body)}
+		w.Header().Set("X-From", "mirror")
+	}))
+	defer mirrorServer.Close()
+
+	sm := NewManager(map[string]*config.Service{
+		"provider.main": {
+			LoadBalancer: &config.LoadBalancerService{
+				Method:  "wrr",
+				Servers: []config.Server{{URL: mainServer.URL, Weight: 1}},
+			},
+		},
+		"provider.shadow": {
+			LoadBalancer: &config.LoadBalancerService{
+				Method:  "wrr",
+				Servers: []config.Server{{URL: mirrorServer.URL, Weight: 1}},
+			},
+		},
+		"provider.mirrored": {
+			Mirroring: &config.Mirroring{
+				Service: "main",
+				Mirrors: []config.MirrorService{{Name: "shadow", Percent: 100}},
+				Headers: map[string]string{"X-Traefik-Mirror": "true"},
+			},
+		},
+	}, http.DefaultTransport, nil)
+
+	handler, err := sm.Build(context.Background(), "provider.mirrored", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := testhelpers.MustNewRequest(http.MethodPost, "http://callme", strings.NewReader("foo"))
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "main", recorder.Header().Get("X-From"))
+	assert.Empty(t, req.Header.Get("X-Traefik-Mirror"))
+
+	select {
+	case received := <-mainRequests:
+		assert.Equal(t, receivedRequest{body: "foo"}, received)
+	case <-time.After(time.Second):
+		t.Fatal("the main service did not receive the request")
+	}
+
+	select {
+	case received := <-mirrorRequests:
+		assert.Equal(t, receivedRequest{header: "true", body: "foo"}, received)
+	case <-time.After(time.Second):
+		t.Fatal("the mirror did not receive the request")
+	}
+}
+
+func TestMirroring_Synchronous(t *testing.T) {
+	mainServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer mainServer.Close()
+
+	mirrorRequests := make(chan receivedRequest, 10)
+	mirrorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mirrorRequests <- receivedRequest{header: r.Header.Get("X-Traefik-Mirror"), body: string(body)}
+	}))
+	defer mirrorServer.Close()
+
+	sm := NewManager(map[string]*config.Service{
+		"provider.main": {
+			LoadBalancer: &config.LoadBalancerService{
+				Method:  "wrr",
+				Servers: []config.Server{{URL: mainServer.URL, Weight: 1}},
+			},
+		},
+		"provider.shadow": {
+			LoadBalancer: &config.LoadBalancerService{
+				Method:  "wrr",
+				Servers: []config.Server{{URL: mirrorServer.URL, Weight: 1}},
+			},
+		},
+		"provider.mirrored": {
+			Mirroring: &config.Mirroring{
+				Service:            "main",
+				Mirrors:            []config.MirrorService{{Name: "shadow", Percent: 100}},
+				SynchronousTimeout: "5s",
+			},
+		},
+	}, http.DefaultTransport, nil)
+
+	handler, err := sm.Build(context.Background(), "provider.mirrored", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := testhelpers.MustNewRequest(http.MethodPost, "http://callme", strings.NewReader("foo"))
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	select {
+	case received := <-mirrorRequests:
+		assert.Equal(t, receivedRequest{body: "foo"}, received)
+	default:
+		t.Fatal("the mirror did not receive the request before the response")
+	}
+}
+
+func TestMirroring_MaxBodySize(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		body     string
+		mirrored bool
+	}{
+		{
+			desc:     "body within the limit",
+			body:     "foo",
+			mirrored: true,
+		},
+		{
+			desc:     "body at the limit",
+			body:     "foobar",
+			mirrored: true,
+		},
+		{
+			desc: "body above the limit",
+			body: "foobarbaz",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var mainBody string
+			mainHandler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, _ := ioutil.ReadAll(req.Body)
+				mainBody = string(body)
+			})
+
+			mirrorBodies := make(chan string, 1)
+			mirrorHandler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, _ := ioutil.ReadAll(req.Body)
+				mirrorBodies <- string(body)
+			})
+
+			handler := &mirroring{
+				handler:            mainHandler,
+				mirrors:            []*mirror{{handler: mirrorHandler, percent: 100}},
+				synchronousTimeout: time.Second,
+				timeout:            time.Second,
+				maxBodySize:        6,
+				inFlight:           make(chan struct{}, 1),
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "http://callme", ioutil.NopCloser(strings.NewReader(test.body)))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			// The main service always receives the whole body.
+			assert.Equal(t, test.body, mainBody)
+
+			select {
+			case body := <-mirrorBodies:
+				assert.True(t, test.mirrored)
+				assert.Equal(t, test.body, body)
+			default:
+				assert.False(t, test.mirrored)
+			}
+		})
+	}
+}
+
+func TestMirroring_InFlightLimit(t *testing.T) {
+	release := make(chan struct{})
+	mirrored := make(chan struct{}, 10)
+	mirrorHandler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mirrored <- struct{}{}
+		<-release
+	})
+
+	handler := &mirroring{
+		handler:     http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}),
+		mirrors:     []*mirror{{handler: mirrorHandler, percent: 100}},
+		timeout:     time.Second,
+		maxBodySize: defaultMirrorMaxBodySize,
+		inFlight:    make(chan struct{}, 2),
+	}
+
+	for i := 0; i < 5; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-mirrored:
+		case <-time.After(time.Second):
+			t.Fatal("the mirror did not receive the request")
+		}
+	}
+
+	// The requests beyond the in flight limit are not mirrored.
+	select {
+	case <-mirrored:
+		t.Fatal("the mirror received more requests than the in flight limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+}
+
+func TestMirroring_Timeout(t *testing.T) {
+	deadlines := make(chan bool, 1)
+	mirrorHandler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		deadline, ok := req.Context().Deadline()
+		deadlines <- ok && time.Until(deadline) <= 5*time.Second
+	})
+
+	handler := &mirroring{
+		handler:            http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}),
+		mirrors:            []*mirror{{handler: mirrorHandler, percent: 100}},
+		synchronousTimeout: time.Second,
+		timeout:            5 * time.Second,
+		maxBodySize:        defaultMirrorMaxBodySize,
+		inFlight:           make(chan struct{}, 1),
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://callme", nil))
+
+	select {
+	case ok := <-deadlines:
+		assert.True(t, ok)
+	default:
+		t.Fatal("the mirror did not receive the request")
+	}
+}
+
+func TestMirror_Hit(t *testing.T) {
+	testCases := []struct {
+		percent  uint64
+		expected int
+	}{
+		{percent: 0, expected: 0},
+		{percent: 10, expected: 20},
+		{percent: 50, expected: 100},
+		{percent: 100, expected: 200},
+	}
+
+	for _, test := range testCases {
+		mirror := &mirror{percent: test.percent}
+
+		var hits int
+		for i := 0; i < 200; i++ {
+			if mirror.hit() {
+				hits++
+			}
+		}
+
+		assert.Equal(t, test.expected, hits, "percent: %d", test.percent)
+	}
+}
+
+func TestGetMirroringServiceHandler_Errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		services map[string]*config.Service
+	}{
+		{
+			desc: "recursion",
+			services: map[string]*config.Service{
+				"provider.mirrored": {
+					Mirroring: &config.Mirroring{Service: "mirrored"},
+				},
+			},
+		},
+		{
+			desc: "missing main service",
+			services: map[string]*config.Service{
+				"provider.mirrored": {
+					Mirroring: &config.Mirroring{},
+				},
+			},
+		},
+		{
+			desc: "invalid percentage",
+			services: map[string]*config.Service{
+				"provider.main": {
+					LoadBalancer: &config.LoadBalancerService{Method: "wrr"},
+				},
+				"provider.mirrored": {
+					Mirroring: &config.Mirroring{
+						Service: "main",
+						Mirrors: []config.MirrorService{{Name: "main", Percent: 101}},
+					},
+				},
+			},
+		},
+		{
+			desc: "invalid synchronous timeout",
+			services: map[string]*config.Service{
+				"provider.main": {
+					LoadBalancer: &config.LoadBalancerService{Method: "wrr"},
+				},
+				"provider.mirrored": {
+					Mirroring: &config.Mirroring{Service: "main", SynchronousTimeout: "foo"},
+				},
+			},
+		},
+		{
+			desc: "negative synchronous timeout",
+			services: map[string]*config.Service{
+				"provider.main": {
+					LoadBalancer: &config.LoadBalancerService{Method: "wrr"},
+				},
+				"provider.mirrored": {
+					Mirroring: &config.Mirroring{Service: "main", SynchronousTimeout: "-1s"},
+				},
+			},
+		},
+		{
+			desc: "invalid timeout",
+			services: map[string]*config.Service{
+				"provider.main": {
+					LoadBalancer: &config.LoadBalancerService{Method: "wrr"},
+				},
+				"provider.mirrored": {
+					Mirroring: &config.Mirroring{Service: "main", Timeout: "foo"},
+				},
+			},
+		},
+		{
+			desc: "negative timeout",
+			services: map[string]*config.Service{
+				"provider.main": {
+					LoadBalancer: &config.LoadBalancerService{Method: "wrr"},
+				},
+				"provider.mirrored": {
+					Mirroring: &config.Mirroring{Service: "main", Timeout: "-1s"},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			sm := NewManager(test.services, http.DefaultTransport, nil)
+
+			_, err := sm.Build(context.Background(), "provider.mirrored", nil)
+			assert.Error(t, err)
+		})
+	}
+}
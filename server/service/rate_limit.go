@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"golang.org/x/time/rate"
+)
+
+// serviceRateLimiter caps the rate of the requests sent to a service, whatever their client.
+// The requests beyond the rate wait for their turn up to the maximum delay, and are rejected with a 503 after it.
+type serviceRateLimiter struct {
+	next     http.Handler
+	limiter  *rate.Limiter
+	maxDelay time.Duration
+}
+
+// newServiceRateLimiter creates a rate limiter, whose next handler is set once the load-balancer of the service is built.
+func newServiceRateLimiter(conf *config.ServiceRateLimit) (*serviceRateLimiter, error) {
+	if conf.Average <= 0 {
+		return nil, errors.New("the average rate must be positive")
+	}
+
+	burst := int64(1)
+	if conf.Burst != 0 {
+		if conf.Burst < 0 {
+			return nil, fmt.Errorf("invalid burst %d: it must be positive", conf.Burst)
+		}
+		burst = conf.Burst
+	}
+
+	var maxDelay time.Duration
+	if conf.MaxDelay != "" {
+		var err error
+		maxDelay, err = time.ParseDuration(conf.MaxDelay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max delay: %v", err)
+		}
+		if maxDelay < 0 {
+			return nil, fmt.Errorf("invalid max delay %s: it must be positive", conf.MaxDelay)
+		}
+	}
+
+	return &serviceRateLimiter{
+		limiter:  rate.NewLimiter(rate.Limit(conf.Average), int(burst)),
+		maxDelay: maxDelay,
+	}, nil
+}
+
+func (s *serviceRateLimiter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	reservation := s.limiter.Reserve()
+
+	delay := reservation.Delay()
+	if delay > s.maxDelay {
+		reservation.Cancel()
+
+		log.FromContext(req.Context()).Debugf("Rate limit of the service reached, rejecting request %s", req.URL)
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			reservation.Cancel()
+			return
+		}
+	}
+
+	s.next.ServeHTTP(rw, req)
+}
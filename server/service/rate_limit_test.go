@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServiceRateLimiter(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		conf          config.ServiceRateLimit
+		expectedError bool
+	}{
+		{
+			desc: "average, burst and max delay",
+			conf: config.ServiceRateLimit{Average: 10, Burst: 5, MaxDelay: "1s"},
+		},
+		{
+			desc:          "no average",
+			conf:          config.ServiceRateLimit{Burst: 5},
+			expectedError: true,
+		},
+		{
+			desc:          "negative burst",
+			conf:          config.ServiceRateLimit{Average: 10, Burst: -1},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid max delay",
+			conf:          config.ServiceRateLimit{Average: 10, MaxDelay: "foo"},
+			expectedError: true,
+		},
+		{
+			desc:          "negative max delay",
+			conf:          config.ServiceRateLimit{Average: 10, MaxDelay: "-1s"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newServiceRateLimiter(&test.conf)
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestServiceRateLimiter_Reject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	service := &config.LoadBalancerService{
+		Servers:   []config.Server{{URL: server.URL, Weight: 1}},
+		Method:    "wrr",
+		RateLimit: &config.ServiceRateLimit{Average: 1, Burst: 2},
+	}
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+	require.NoError(t, err)
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+		codes = append(codes, recorder.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusServiceUnavailable}, codes)
+}
+
+func TestServiceRateLimiter_Delay(t *testing.T) {
+	rateLimiter, err := newServiceRateLimiter(&config.ServiceRateLimit{Average: 10, MaxDelay: "1s"})
+	require.NoError(t, err)
+
+	rateLimiter.next = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		rateLimiter.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	// The second and third requests wait 100ms each for their turn.
+	assert.True(t, time.Since(start) >= 150*time.Millisecond, "the requests beyond the rate were not delayed: %s", time.Since(start))
+}
+
+func TestServiceRateLimiter_InvalidConfiguration(t *testing.T) {
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	service := &config.LoadBalancerService{
+		Servers:   []config.Server{{URL: "http://127.0.0.1", Weight: 1}},
+		Method:    "wrr",
+		RateLimit: &config.ServiceRateLimit{},
+	}
+
+	_, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+	require.Error(t, err)
+	assert.Empty(t, sm.balancers["test"])
+}
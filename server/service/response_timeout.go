@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/vulcand/oxy/forward"
+)
+
+// responseTimeout cancels the requests to the servers of a service past a deadline, and answers with a 504 instead.
+// The deadline is lifted once the headers of a streamed response, e.g. of server-sent events, are sent to the client,
+// and it does not apply to the websocket requests.
+type responseTimeout struct {
+	next    http.Handler
+	timeout time.Duration
+}
+
+func newResponseTimeout(next http.Handler, timeout string) (*responseTimeout, error) {
+	duration, err := time.ParseDuration(timeout)
+	if err != nil {
+		return nil, err
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("invalid timeout %s: it must be positive", timeout)
+	}
+
+	return &responseTimeout{next: next, timeout: duration}, nil
+}
+
+func (t *responseTimeout) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if forward.IsWebsocketRequest(req) {
+		t.next.ServeHTTP(rw, req)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	timeoutWriter := &timeoutResponseWriterWithoutCloseNotify{rw: rw}
+	timeoutWriter.timer = time.AfterFunc(t.timeout, func() {
+		timeoutWriter.expire()
+		cancel()
+	})
+	defer timeoutWriter.timer.Stop()
+
+	t.next.ServeHTTP(newTimeoutResponseWriter(timeoutWriter), req.WithContext(ctx))
+
+	if timeoutWriter.expiredBeforeHeaders() {
+		log.FromContext(req.Context()).Debugf("Response of request %s not received after %s", req.URL, t.timeout)
+		http.Error(rw, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+	}
+}
+
+// isStreamed returns true for the responses whose body is sent as it becomes available, whatever its length.
+func isStreamed(code int, header http.Header) bool {
+	return code == http.StatusSwitchingProtocols || header.Get("Content-Length") == ""
+}
+
+func newTimeoutResponseWriter(rw *timeoutResponseWriterWithoutCloseNotify) http.ResponseWriter {
+	if _, ok := rw.rw.(http.CloseNotifier); ok {
+		return &timeoutResponseWriterWithCloseNotify{timeoutResponseWriterWithoutCloseNotify: rw}
+	}
+	return rw
+}
+
+// timeoutResponseWriterWithoutCloseNotify discards the response of the expired requests
+// whose headers were not sent yet, and stops the timer once the headers of a streamed response are sent.
+type timeoutResponseWriterWithoutCloseNotify struct {
+	rw    http.ResponseWriter
+	timer *time.Timer
+
+	lock        sync.Mutex
+	expired     bool
+	wroteHeader bool
+}
+
+func (t *timeoutResponseWriterWithoutCloseNotify) expire() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.expired = true
+}
+
+func (t *timeoutResponseWriterWithoutCloseNotify) expiredBeforeHeaders() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.expired && !t.wroteHeader
+}
+
+func (t *timeoutResponseWriterWithoutCloseNotify) Header() http.Header {
+	return t.rw.Header()
+}
+
+func (t *timeoutResponseWriterWithoutCloseNotify) Write(buf []byte) (int, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !t.wroteHeader {
+		if t.expired {
+			return len(buf), nil
+		}
+		t.writeHeader(http.StatusOK)
+	}
+
+	return t.rw.Write(buf)
+}
+
+func (t *timeoutResponseWriterWithoutCloseNotify) WriteHeader(code int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.wroteHeader || t.expired {
+		return
+	}
+	t.writeHeader(code)
+}
+
+func (t *timeoutResponseWriterWithoutCloseNotify) writeHeader(code int) {
+	t.wroteHeader = true
+	if isStreamed(code, t.rw.Header()) {
+		t.timer.Stop()
+	}
+	t.rw.WriteHeader(code)
+}
+
+func (t *timeoutResponseWriterWithoutCloseNotify) Flush() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !t.wroteHeader {
+		if t.expired {
+			return
+		}
+		t.writeHeader(http.StatusOK)
+	}
+
+	if flusher, ok := t.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (t *timeoutResponseWriterWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := t.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", t.rw)
+	}
+
+	t.timer.Stop()
+	return hijacker.Hijack()
+}
+
+type timeoutResponseWriterWithCloseNotify struct {
+	*timeoutResponseWriterWithoutCloseNotify
+}
+
+func (t *timeoutResponseWriterWithCloseNotify) CloseNotify() <-chan bool {
+	return t.rw.(http.CloseNotifier).CloseNotify()
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResponseTimeout(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		timeout       string
+		expectedError bool
+	}{
+		{
+			desc:    "timeout",
+			timeout: "10s",
+		},
+		{
+			desc:          "invalid timeout",
+			timeout:       "foo",
+			expectedError: true,
+		},
+		{
+			desc:          "negative timeout",
+			timeout:       "-1s",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newResponseTimeout(http.NotFoundHandler(), test.timeout)
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestResponseTimeout(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		handler        http.HandlerFunc
+		expectedStatus int
+		expectedBody   string
+		maxDuration    time.Duration
+	}{
+		{
+			desc: "fast server",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte("OK"))
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "OK",
+			maxDuration:    100 * time.Millisecond,
+		},
+		{
+			desc: "slow server",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				select {
+				case <-time.After(time.Second):
+				case <-req.Context().Done():
+				}
+				_, _ = rw.Write([]byte("OK"))
+			},
+			expectedStatus: http.StatusGatewayTimeout,
+			expectedBody:   "Gateway Timeout\n",
+			maxDuration:    500 * time.Millisecond,
+		},
+		{
+			desc: "streamed response",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "text/event-stream")
+				rw.WriteHeader(http.StatusOK)
+				rw.(http.Flusher).Flush()
+
+				time.Sleep(300 * time.Millisecond)
+				_, _ = rw.Write([]byte("data: OK\n\n"))
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "data: OK\n\n",
+			maxDuration:    time.Second,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(test.handler)
+			defer server.Close()
+
+			sm := NewManager(nil, http.DefaultTransport, nil)
+
+			service := &config.LoadBalancerService{
+				Servers:            []config.Server{{URL: server.URL, Weight: 1}},
+				Method:             "wrr",
+				ResponseForwarding: &config.ResponseForwarding{Timeout: "100ms"},
+			}
+
+			handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+			require.NoError(t, err)
+
+			frontend := httptest.NewServer(handler)
+			defer frontend.Close()
+
+			start := time.Now()
+
+			resp, err := http.Get(frontend.URL)
+			require.NoError(t, err)
+
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, test.expectedBody, string(body))
+			assert.True(t, time.Since(start) < test.maxDuration, "elapsed %s", time.Since(start))
+		})
+	}
+}
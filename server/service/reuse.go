@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/healthcheck"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/middlewares"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// reusableBalancer is a load-balancer built by a manager, which the manager of the next configuration can reuse.
+// The handler behind the load-balancer is switched to the one of the next configuration.
+type reusableBalancer struct {
+	config   *config.LoadBalancerService
+	balancer healthcheck.BalancerHandler
+	next     *middlewares.HandlerSwitcher
+	// weights are the configured weights of the servers per URL.
+	weights map[string]int
+}
+
+// ReuseBalancers lets the manager reuse the load-balancers built by the previous manager,
+// for the services whose settings but the servers are unchanged.
+// Only the added, removed and reweighted servers of the reused load-balancers are updated,
+// so that their sticky sessions, their statistics and the slow start of their servers are kept.
+func (m *Manager) ReuseBalancers(previous *Manager) {
+	if previous == nil {
+		return
+	}
+	m.previousBalancers = previous.reusableBalancers
+}
+
+// popPreviousBalancer returns a load-balancer of the previous manager, which can be reused for the service, or nil.
+func (m *Manager) popPreviousBalancer(serviceName string, service *config.LoadBalancerService) *reusableBalancer {
+	candidates := m.previousBalancers[serviceName]
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	candidate := candidates[0]
+	m.previousBalancers[serviceName] = candidates[1:]
+
+	if !sameSettings(candidate.config, service) {
+		return nil
+	}
+	return candidate
+}
+
+// sameSettings returns true if the services have the same settings, whatever their servers.
+func sameSettings(a, b *config.LoadBalancerService) bool {
+	if a == nil || b == nil {
+		return false
+	}
+
+	withoutServersA, withoutServersB := *a, *b
+	withoutServersA.Servers, withoutServersB.Servers = nil, nil
+
+	return reflect.DeepEqual(withoutServersA, withoutServersB)
+}
+
+// updateServers updates the servers of a reused load-balancer, and returns the weights of its servers per URL.
+// The servers which are no longer configured are removed, and only the added servers, the reweighted ones,
+// and the ones removed by the health check, are upserted.
+func (m *Manager) updateServers(ctx context.Context, lb healthcheck.BalancerHandler, previousWeights map[string]int, servers []config.Server, scheme string) (map[string]int, error) {
+	logger := log.FromContext(ctx)
+
+	weights := make(map[string]int)
+	for _, srv := range servers {
+		u, err := parseServerURL(srv.URL, scheme)
+		if err != nil {
+			return nil, err
+		}
+		if srv.Weight != 0 {
+			weights[u.String()] = srv.Weight
+		}
+	}
+
+	current := make(map[string]bool)
+	for _, u := range lb.Servers() {
+		current[u.String()] = true
+
+		if _, ok := weights[u.String()]; ok {
+			continue
+		}
+
+		logger.WithField(log.ServerName, u.String()).Debugf("Removing server %s", u)
+		if err := lb.RemoveServer(u); err != nil {
+			return nil, fmt.Errorf("error removing server %s from load balancer: %v", u, err)
+		}
+	}
+
+	for name, srv := range servers {
+		u, err := parseServerURL(srv.URL, scheme)
+		if err != nil {
+			return nil, err
+		}
+
+		key := u.String()
+		if srv.Weight == 0 || current[key] && previousWeights[key] == srv.Weight {
+			continue
+		}
+
+		logger.WithField(log.ServerName, name).Debugf("Updating server %d at %s with weight %d", name, u, srv.Weight)
+
+		if err := lb.UpsertServer(u, roundrobin.Weight(srv.Weight)); err != nil {
+			return nil, fmt.Errorf("error adding server %s to load balancer: %v", srv.URL, err)
+		}
+	}
+
+	return weights, nil
+}
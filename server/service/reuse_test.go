@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestReuseBalancers_StickySession(t *testing.T) {
+	var servers []config.Server
+	for i := 0; i < 3; i++ {
+		name := fmt.Sprintf("server%d", i)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", name)
+		}))
+		defer server.Close()
+
+		servers = append(servers, config.Server{URL: server.URL, Weight: 1})
+	}
+
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	service := &config.LoadBalancerService{
+		Servers:    servers[:2],
+		Method:     "wrr",
+		Stickiness: &config.Stickiness{CookieName: "sticky"},
+	}
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	pinned := recorder.Header().Get("X-From")
+
+	// The reload adds a server.
+	reloaded := NewManager(nil, http.DefaultTransport, nil)
+	reloaded.ReuseBalancers(sm)
+
+	reloadedService := &config.LoadBalancerService{
+		Servers:    servers,
+		Method:     "wrr",
+		Stickiness: &config.Stickiness{CookieName: "sticky"},
+	}
+
+	handler, err = reloaded.getLoadBalancerServiceHandler(context.Background(), "test", reloadedService, nil)
+	require.NoError(t, err)
+
+	require.Len(t, reloaded.balancers["test"], 1)
+	assert.Equal(t, sm.balancers["test"][0], reloaded.balancers["test"][0])
+	assert.Len(t, reloaded.balancers["test"][0].Servers(), 3)
+
+	for i := 0; i < 20; i++ {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+		req.AddCookie(cookies[0])
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, pinned, recorder.Header().Get("X-From"))
+	}
+}
+
+func TestReuseBalancers_ChangedSettings(t *testing.T) {
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	service := &config.LoadBalancerService{
+		Servers: []config.Server{{URL: "http://10.0.0.1", Weight: 1}},
+		Method:  "wrr",
+	}
+
+	_, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+	require.NoError(t, err)
+
+	reloaded := NewManager(nil, http.DefaultTransport, nil)
+	reloaded.ReuseBalancers(sm)
+
+	reloadedService := &config.LoadBalancerService{
+		Servers: []config.Server{{URL: "http://10.0.0.1", Weight: 1}},
+		Method:  "drr",
+	}
+
+	_, err = reloaded.getLoadBalancerServiceHandler(context.Background(), "test", reloadedService, nil)
+	require.NoError(t, err)
+
+	require.Len(t, reloaded.balancers["test"], 1)
+	assert.NotEqual(t, sm.balancers["test"][0], reloaded.balancers["test"][0])
+}
+
+// recordingBalancer records the servers upserted into and removed from a round-robin load-balancer.
+type recordingBalancer struct {
+	*roundrobin.RoundRobin
+	upserted []string
+	removed  []string
+}
+
+func (b *recordingBalancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	b.upserted = append(b.upserted, u.String())
+	return b.RoundRobin.UpsertServer(u, options...)
+}
+
+func (b *recordingBalancer) RemoveServer(u *url.URL) error {
+	b.removed = append(b.removed, u.String())
+	return b.RoundRobin.RemoveServer(u)
+}
+
+func TestUpdateServers(t *testing.T) {
+	rr, err := roundrobin.New(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	require.NoError(t, err)
+
+	lb := &recordingBalancer{RoundRobin: rr}
+
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	weights, err := sm.upsertServers(context.Background(), lb, []config.Server{
+		{URL: "http://10.0.0.1", Weight: 1},
+		{URL: "http://10.0.0.2", Weight: 1},
+		{URL: "http://10.0.0.3", Weight: 1},
+		{URL: "http://10.0.0.4", Weight: 1},
+	}, "")
+	require.NoError(t, err)
+
+	// The health check removed a server.
+	require.NoError(t, lb.RoundRobin.RemoveServer(testhelpers.MustParseURL("http://10.0.0.4")))
+	lb.upserted = nil
+
+	weights, err = sm.updateServers(context.Background(), lb, weights, []config.Server{
+		{URL: "http://10.0.0.1", Weight: 1},
+		{URL: "http://10.0.0.2", Weight: 3},
+		{URL: "http://10.0.0.4", Weight: 1},
+		{URL: "http://10.0.0.5", Weight: 1},
+		{URL: "http://10.0.0.6", Weight: 0},
+	}, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"http://10.0.0.2", "http://10.0.0.4", "http://10.0.0.5"}, lb.upserted)
+	assert.Equal(t, []string{"http://10.0.0.3"}, lb.removed)
+	assert.Equal(t, map[string]int{"http://10.0.0.1": 1, "http://10.0.0.2": 3, "http://10.0.0.4": 1, "http://10.0.0.5": 1}, weights)
+
+	weight, ok := lb.ServerWeight(testhelpers.MustParseURL("http://10.0.0.2"))
+	require.True(t, ok)
+	assert.Equal(t, 3, weight)
+}
+
+func BenchmarkGetLoadBalancerServiceHandler_Reload(b *testing.B) {
+	var servers []config.Server
+	for i := 0; i < 500; i++ {
+		servers = append(servers, config.Server{URL: fmt.Sprintf("http://10.0.%d.%d", i/256, i%256), Weight: 1})
+	}
+
+	service := &config.LoadBalancerService{Servers: servers, Method: "wrr"}
+
+	for _, reuse := range []bool{false, true} {
+		b.Run(fmt.Sprintf("reuse=%t", reuse), func(b *testing.B) {
+			previous := NewManager(nil, http.DefaultTransport, nil)
+			_, err := previous.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+			require.NoError(b, err)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sm := NewManager(nil, http.DefaultTransport, nil)
+				if reuse {
+					sm.ReuseBalancers(previous)
+				}
+
+				if _, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil); err != nil {
+					b.Fatal(err)
+				}
+				previous = sm
+			}
+		})
+	}
+}
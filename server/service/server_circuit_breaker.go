@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/healthcheck"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
+	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
+)
+
+const (
+	defaultServerCircuitBreakerErrorRatio  = 0.5
+	defaultServerCircuitBreakerMinRequests = 10
+	defaultServerCircuitBreakerWindow      = 10 * time.Second
+	defaultServerCircuitBreakerRecovery    = 10 * time.Second
+)
+
+// serverCircuitBreaker measures the ratio of errors (5xx responses) of each server,
+// and ejects from the load-balancer the servers whose ratio reaches the threshold.
+// An ejected server is put back after the recovery duration, with fresh statistics.
+// When all the servers are ejected, the load-balancer has no server left and the service responds with a 503.
+type serverCircuitBreaker struct {
+	next            http.Handler
+	lb              healthcheck.BalancerHandler
+	serviceName     string
+	errorRatio      float64
+	minRequests     int
+	window          time.Duration
+	recovery        time.Duration
+	metricsRegistry metrics.Registry
+
+	lock        sync.Mutex
+	baseWeights map[string]int
+	urls        map[string]*url.URL
+	stats       map[string]*serverStats
+	ejected     map[string]time.Time
+	ejections   map[string]int
+}
+
+// serverStats holds the number of requests and errors of a server in the current window.
+type serverStats struct {
+	start    time.Time
+	requests int
+	errors   int
+}
+
+func newServerCircuitBreaker(ctx context.Context, serviceName string, next http.Handler, conf *config.ServerCircuitBreaker, servers []config.Server, scheme string, metricsRegistry metrics.Registry) *serverCircuitBreaker {
+	logger := log.FromContext(ctx)
+
+	errorRatio := defaultServerCircuitBreakerErrorRatio
+	if conf.ErrorRatio != 0 {
+		if conf.ErrorRatio < 0 || conf.ErrorRatio > 1 {
+			logger.Errorf("Illegal circuit breaker error ratio %v: it must be between 0 and 1", conf.ErrorRatio)
+		} else {
+			errorRatio = conf.ErrorRatio
+		}
+	}
+
+	minRequests := defaultServerCircuitBreakerMinRequests
+	if conf.MinRequests > 0 {
+		minRequests = conf.MinRequests
+	}
+
+	window := parseServerCircuitBreakerDuration(ctx, "window", conf.Window, defaultServerCircuitBreakerWindow)
+	recovery := parseServerCircuitBreakerDuration(ctx, "recovery duration", conf.RecoveryDuration, defaultServerCircuitBreakerRecovery)
+
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	baseWeights := make(map[string]int)
+	urls := make(map[string]*url.URL)
+	for _, srv := range servers {
+		u, err := parseServerURL(srv.URL, scheme)
+		if err != nil || srv.Weight == 0 {
+			continue
+		}
+
+		key := serverKey(u)
+		baseWeights[key] = srv.Weight
+		urls[key] = u
+	}
+
+	return &serverCircuitBreaker{
+		next:            next,
+		serviceName:     serviceName,
+		errorRatio:      errorRatio,
+		minRequests:     minRequests,
+		window:          window,
+		recovery:        recovery,
+		metricsRegistry: metricsRegistry,
+		baseWeights:     baseWeights,
+		urls:            urls,
+		stats:           make(map[string]*serverStats),
+		ejected:         make(map[string]time.Time),
+		ejections:       make(map[string]int),
+	}
+}
+
+func parseServerCircuitBreakerDuration(ctx context.Context, name, value string, defaultValue time.Duration) time.Duration {
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	switch {
+	case err != nil:
+		log.FromContext(ctx).Errorf("Illegal circuit breaker %s: %s", name, err)
+	case duration <= 0:
+		log.FromContext(ctx).Errorf("Circuit breaker %s smaller than zero", name)
+	default:
+		return duration
+	}
+	return defaultValue
+}
+
+func (c *serverCircuitBreaker) setBalancer(lb healthcheck.BalancerHandler) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.lb = lb
+}
+
+func (c *serverCircuitBreaker) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	pw := utils.NewProxyWriter(rw)
+	c.next.ServeHTTP(pw, req)
+	c.observe(req.URL, pw.StatusCode() >= http.StatusInternalServerError)
+}
+
+func (c *serverCircuitBreaker) observe(u *url.URL, failed bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := serverKey(u)
+	if _, ok := c.urls[key]; !ok {
+		return
+	}
+	if _, ok := c.ejected[key]; ok {
+		return
+	}
+
+	now := time.Now()
+	stats, ok := c.stats[key]
+	if !ok || now.Sub(stats.start) >= c.window {
+		stats = &serverStats{start: now}
+		c.stats[key] = stats
+	}
+
+	stats.requests++
+	if failed {
+		stats.errors++
+	}
+
+	if stats.requests >= c.minRequests && float64(stats.errors)/float64(stats.requests) >= c.errorRatio {
+		c.eject(key, stats)
+	}
+}
+
+// eject removes the server from the load-balancer, and schedules its recovery.
+func (c *serverCircuitBreaker) eject(key string, stats *serverStats) {
+	if c.lb == nil {
+		return
+	}
+
+	u := c.urls[key]
+	log.WithoutContext().Warnf("Ejecting the server %s of the service %s: %d errors out of %d requests", u, c.serviceName, stats.errors, stats.requests)
+
+	if err := c.lb.RemoveServer(u); err != nil {
+		log.WithoutContext().Debugf("Unable to remove the server %s: %v", u, err)
+	}
+
+	c.ejected[key] = time.Now().Add(c.recovery)
+	c.ejections[key]++
+	delete(c.stats, key)
+	c.setState(u, 1)
+
+	time.AfterFunc(c.recovery, func() {
+		c.recover(key)
+	})
+}
+
+// recover puts the ejected server back into the load-balancer.
+func (c *serverCircuitBreaker) recover(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.ejected[key]; !ok {
+		return
+	}
+
+	u := c.urls[key]
+	log.WithoutContext().Infof("Recovering the server %s of the service %s", u, c.serviceName)
+
+	if err := c.lb.UpsertServer(u, roundrobin.Weight(c.baseWeights[key])); err != nil {
+		log.WithoutContext().Errorf("Unable to recover the server %s: %v", u, err)
+		return
+	}
+
+	delete(c.ejected, key)
+	c.setState(u, 0)
+}
+
+// serverStatuses returns the statuses of the servers, sorted by URL.
+func (c *serverCircuitBreaker) serverStatuses() []healthcheck.ServerStatus {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var statuses []healthcheck.ServerStatus
+	for key, u := range c.urls {
+		status := healthcheck.ServerStatus{URL: u.String(), Ejections: c.ejections[key]}
+
+		if recoveryTime, ok := c.ejected[key]; ok {
+			status.Ejected = true
+			status.RecoveryTime = &recoveryTime
+		}
+
+		if stats, ok := c.stats[key]; ok && time.Since(stats.start) < c.window {
+			status.Requests = stats.requests
+			status.Errors = stats.errors
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].URL < statuses[j].URL
+	})
+
+	return statuses
+}
+
+func (c *serverCircuitBreaker) setState(u *url.URL, open float64) {
+	c.metricsRegistry.BackendServerCircuitBreakerGauge().With("service", c.serviceName, "url", u.String()).Set(open)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/metrics"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerCircuitBreaker(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	gauge := &progressGauge{lock: &sync.Mutex{}, values: make(map[string]float64)}
+	sm := NewManager(nil, http.DefaultTransport, &circuitBreakerRegistry{Registry: metrics.NewVoidRegistry(), gauge: gauge})
+
+	service := &config.LoadBalancerService{
+		Method: "wrr",
+		CircuitBreaker: &config.ServerCircuitBreaker{
+			ErrorRatio:       0.5,
+			MinRequests:      2,
+			RecoveryDuration: "100ms",
+		},
+		Servers: []config.Server{
+			{URL: failing.URL, Weight: 1},
+			{URL: healthy.URL, Weight: 1},
+		},
+	}
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "foo", service, nil)
+	require.NoError(t, err)
+
+	statusCodes := make(map[int]int)
+	for i := 0; i < 10; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo", nil))
+		statusCodes[recorder.Code]++
+	}
+
+	// The failing server is ejected after its second request, the other requests go to the healthy server.
+	assert.Equal(t, map[int]int{http.StatusInternalServerError: 2, http.StatusOK: 8}, statusCodes)
+	assert.Len(t, sm.balancers["foo"][0].Servers(), 1)
+	assert.Equal(t, 1.0, gauge.get("foo", failing.URL))
+	assert.Equal(t, 0.0, gauge.get("foo", healthy.URL))
+
+	// The failing server is put back after the recovery duration.
+	deadline := time.Now().Add(time.Second)
+	for len(sm.balancers["foo"][0].Servers()) != 2 {
+		require.True(t, time.Now().Before(deadline), "the failing server was not put back")
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0.0, gauge.get("foo", failing.URL))
+}
+
+func TestServerCircuitBreaker_AllServersEjected(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	service := &config.LoadBalancerService{
+		Method: "wrr",
+		CircuitBreaker: &config.ServerCircuitBreaker{
+			MinRequests:      3,
+			RecoveryDuration: "1m",
+		},
+		Servers: []config.Server{{URL: failing.URL, Weight: 1}},
+	}
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "foo", service, nil)
+	require.NoError(t, err)
+
+	var statusCodes []int
+	for i := 0; i < 5; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo", nil))
+		statusCodes = append(statusCodes, recorder.Code)
+	}
+
+	expected := []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusServiceUnavailable}
+	assert.Equal(t, expected, statusCodes)
+}
+
+type circuitBreakerRegistry struct {
+	metrics.Registry
+	gauge *progressGauge
+}
+
+func (r *circuitBreakerRegistry) BackendServerCircuitBreakerGauge() gokitmetrics.Gauge {
+	return r.gauge
+}
@@ -2,11 +2,15 @@ package service
 
 import (
 	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/containous/alice"
@@ -14,13 +18,20 @@ import (
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/healthcheck"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
+	"github.com/containous/traefik/middlewares"
 	"github.com/containous/traefik/middlewares/accesslog"
+	"github.com/containous/traefik/middlewares/debugheaders"
 	"github.com/containous/traefik/middlewares/emptybackendhandler"
+	"github.com/containous/traefik/middlewares/forwardedheaders"
+	"github.com/containous/traefik/middlewares/hostrewrite"
+	"github.com/containous/traefik/middlewares/retry"
 	"github.com/containous/traefik/old/middlewares/pipelining"
 	"github.com/containous/traefik/server/cookie"
 	"github.com/containous/traefik/server/internal"
 	"github.com/vulcand/oxy/forward"
 	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
 )
 
 const (
@@ -28,13 +39,20 @@ const (
 	defaultHealthCheckTimeout  = 5 * time.Second
 )
 
+// ErrConnectionPoolTimeout is returned by the round tripper when no connection
+// to the server became available in time.
+var ErrConnectionPoolTimeout = errors.New("timeout while waiting for a connection to the server")
+
 // NewManager creates a new Manager
-func NewManager(configs map[string]*config.Service, defaultRoundTripper http.RoundTripper) *Manager {
+func NewManager(configs map[string]*config.Service, defaultRoundTripper http.RoundTripper, metricsRegistry metrics.Registry) *Manager {
 	return &Manager{
 		bufferPool:          newBufferPool(),
 		defaultRoundTripper: defaultRoundTripper,
+		metricsRegistry:     metricsRegistry,
 		balancers:           make(map[string][]healthcheck.BalancerHandler),
+		breakers:            make(map[string][]*serverCircuitBreaker),
 		configs:             configs,
+		reusableBalancers:   make(map[string][]*reusableBalancer),
 	}
 }
 
@@ -42,8 +60,12 @@ func NewManager(configs map[string]*config.Service, defaultRoundTripper http.Rou
 type Manager struct {
 	bufferPool          httputil.BufferPool
 	defaultRoundTripper http.RoundTripper
+	metricsRegistry     metrics.Registry
 	balancers           map[string][]healthcheck.BalancerHandler
+	breakers            map[string][]*serverCircuitBreaker
 	configs             map[string]*config.Service
+	reusableBalancers   map[string][]*reusableBalancer
+	previousBalancers   map[string][]*reusableBalancer
 }
 
 // Build Creates a http.Handler for a service configuration.
@@ -58,6 +80,15 @@ func (m *Manager) Build(rootCtx context.Context, serviceName string, responseMod
 		if conf.LoadBalancer != nil {
 			return m.getLoadBalancerServiceHandler(ctx, serviceName, conf.LoadBalancer, responseModifier)
 		}
+		if conf.Mirroring != nil {
+			return m.getMirroringServiceHandler(ctx, serviceName, conf.Mirroring, responseModifier)
+		}
+		if conf.Weighted != nil {
+			return m.getWeightedServiceHandler(ctx, serviceName, conf.Weighted, responseModifier)
+		}
+		if conf.Static != nil {
+			return m.getStaticServiceHandler(ctx, serviceName, conf.Static)
+		}
 		return nil, fmt.Errorf("the service %q doesn't have any load balancer", serviceName)
 	}
 	return nil, fmt.Errorf("the service %q does not exits", serviceName)
@@ -70,30 +101,126 @@ func (m *Manager) getLoadBalancerServiceHandler(
 	responseModifier func(*http.Response) error,
 ) (http.Handler, error) {
 
-	fwd, err := m.buildForwarder(service.PassHostHeader, service.ResponseForwarding, responseModifier)
+	fwd, err := m.buildForwarder(serviceName, service.PassHostHeader, service.DefaultUserAgent, service.ResponseForwarding, responseModifier)
 	if err != nil {
 		return nil, err
 	}
 
+	if service.ServerName != "" {
+		fwd = withServerName(fwd, service.ServerName)
+	}
+
 	alHandler := func(next http.Handler) (http.Handler, error) {
 		return accesslog.NewFieldHandler(next, accesslog.ServiceName, serviceName, accesslog.AddServiceFields), nil
 	}
 
-	handler, err := alice.New().Append(alHandler).Then(pipelining.NewPipelining(fwd))
+	handler, err := alice.New().Append(alHandler).Then(debugheaders.NewBackendHandler(pipelining.NewPipelining(fwd), serviceName))
 	if err != nil {
 		return nil, err
 	}
 
+	var weighting *latencyWeighting
+	if service.LatencyWeighting != nil {
+		if service.Method == "drr" {
+			log.FromContext(ctx).Warn("Latency weighting is not supported with the 'drr' method, ignoring it")
+		} else {
+			weighting = newLatencyWeighting(ctx, serviceName, handler, service.LatencyWeighting, service.Servers, service.Scheme, m.metricsRegistry)
+			handler = weighting
+		}
+	}
+
+	var breaker *serverCircuitBreaker
+	if service.CircuitBreaker != nil {
+		breaker = newServerCircuitBreaker(ctx, serviceName, handler, service.CircuitBreaker, service.Servers, service.Scheme, m.metricsRegistry)
+		handler = breaker
+	}
+
+	var unavailable http.Handler
+	if service.Unavailable != nil {
+		unavailable, err = m.getUnavailableHandler(ctx, serviceName, service.Unavailable, responseModifier)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unavailable response for the service %q: %v", serviceName, err)
+		}
+	}
+
+	var rateLimiter *serviceRateLimiter
+	if service.RateLimit != nil {
+		rateLimiter, err = newServiceRateLimiter(service.RateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit for the service %q: %v", serviceName, err)
+		}
+	}
+
 	balancer, err := m.getLoadBalancer(ctx, serviceName, service, handler)
 	if err != nil {
 		return nil, err
 	}
 
+	if weighting != nil {
+		weighting.setBalancer(balancer)
+	}
+
+	if breaker != nil {
+		breaker.setBalancer(balancer)
+		m.breakers[serviceName] = append(m.breakers[serviceName], breaker)
+	}
+
 	// TODO rename and checks
 	m.balancers[serviceName] = append(m.balancers[serviceName], balancer)
 
 	// Empty (backend with no servers)
-	return emptybackendhandler.New(balancer), nil
+	emptyBackendHandler := emptybackendhandler.New(balancer)
+	if unavailable != nil {
+		emptyBackendHandler = emptybackendhandler.NewWithUnavailableHandler(balancer, unavailable)
+	}
+
+	var serviceHandler http.Handler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		retry.SetBalancer(req, balancer)
+		emptyBackendHandler.ServeHTTP(rw, req)
+	})
+
+	if service.ResponseForwarding != nil && service.ResponseForwarding.Timeout != "" {
+		serviceHandler, err = newResponseTimeout(serviceHandler, service.ResponseForwarding.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid response forwarding timeout for the service %q: %v", serviceName, err)
+		}
+	}
+
+	if rateLimiter != nil {
+		rateLimiter.next = serviceHandler
+		serviceHandler = rateLimiter
+	}
+
+	return serviceHandler, nil
+}
+
+// GetServerStatuses returns the passive health of the servers of the given service, sorted by URL.
+// The statuses of the load-balancers of the service on the different entry points are merged.
+// The boolean is false when the service has no circuit breaker.
+func (m *Manager) GetServerStatuses(serviceName string) ([]healthcheck.ServerStatus, bool) {
+	breakers, ok := m.breakers[serviceName]
+	if !ok {
+		return nil, false
+	}
+
+	statuses := breakers[0].serverStatuses()
+	for _, breaker := range breakers[1:] {
+		for i, status := range breaker.serverStatuses() {
+			merged := &statuses[i]
+			merged.Ejections += status.Ejections
+			merged.Requests += status.Requests
+			merged.Errors += status.Errors
+
+			if status.Ejected {
+				merged.Ejected = true
+				if merged.RecoveryTime == nil || status.RecoveryTime.After(*merged.RecoveryTime) {
+					merged.RecoveryTime = status.RecoveryTime
+				}
+			}
+		}
+	}
+
+	return statuses, true
 }
 
 // LaunchHealthCheck Launches the health checks.
@@ -115,6 +242,9 @@ func (m *Manager) LaunchHealthCheck() {
 			log.FromContext(ctx).Debugf("Setting up healthcheck for service %s with %s", serviceName, *hcOpts)
 
 			hcOpts.Transport = m.defaultRoundTripper
+			if service.ServerName != "" {
+				hcOpts.Transport = &serverNameTransport{next: m.defaultRoundTripper, serverName: service.ServerName}
+			}
 			backendHealthCheck = healthcheck.NewBackendConfig(*hcOpts, serviceName)
 		}
 
@@ -128,12 +258,24 @@ func (m *Manager) LaunchHealthCheck() {
 }
 
 func buildHealthCheckOptions(ctx context.Context, lb healthcheck.BalancerHandler, backend string, hc *config.HealthCheck) *healthcheck.Options {
-	if hc == nil || hc.Path == "" {
+	if hc == nil || hc.Path == "" && hc.Mode != healthcheck.ModeGRPC {
 		return nil
 	}
 
 	logger := log.FromContext(ctx)
 
+	mode := healthcheck.ModeHTTP
+	switch hc.Mode {
+	case "", healthcheck.ModeHTTP:
+	case healthcheck.ModeGRPC:
+		mode = healthcheck.ModeGRPC
+	default:
+		logger.Errorf("Illegal health check mode %q for service '%s', fallback to the '%s' mode", hc.Mode, backend, healthcheck.ModeHTTP)
+		if hc.Path == "" {
+			return nil
+		}
+	}
+
 	interval := defaultHealthCheckInterval
 	if hc.Interval != "" {
 		intervalOverride, err := time.ParseDuration(hc.Interval)
@@ -165,14 +307,16 @@ func buildHealthCheckOptions(ctx context.Context, lb healthcheck.BalancerHandler
 	}
 
 	return &healthcheck.Options{
-		Scheme:   hc.Scheme,
-		Path:     hc.Path,
-		Port:     hc.Port,
-		Interval: interval,
-		Timeout:  timeout,
-		LB:       lb,
-		Hostname: hc.Hostname,
-		Headers:  hc.Headers,
+		Mode:        mode,
+		GRPCService: hc.GRPCService,
+		Scheme:      hc.Scheme,
+		Path:        hc.Path,
+		Port:        hc.Port,
+		Interval:    interval,
+		Timeout:     timeout,
+		LB:          lb,
+		Hostname:    hc.Hostname,
+		Headers:     hc.Headers,
 	}
 }
 
@@ -181,11 +325,83 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 
 	var stickySession *roundrobin.StickySession
 	var cookieName string
+	var cookieOptions cookie.Options
 	if stickiness := service.Stickiness; stickiness != nil {
 		cookieName = cookie.GetName(stickiness.CookieName, serviceName)
 		stickySession = roundrobin.NewStickySession(cookieName)
+
+		var err error
+		cookieOptions, err = cookie.NewOptions(cookieName, stickiness.Secure, stickiness.HTTPOnly, stickiness.SameSite, stickiness.Partitioned)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	var lb healthcheck.BalancerHandler
+	var weights map[string]int
+
+	reused := m.popPreviousBalancer(serviceName, service)
+	if reused != nil {
+		logger.Debug("Reusing the load-balancer of the previous configuration")
+
+		reused.next.UpdateHandler(fwd)
+
+		var err error
+		weights, err = m.updateServers(ctx, reused.balancer, reused.weights, service.Servers, service.Scheme)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring load balancer for service %s: %v", serviceName, err)
+		}
+		lb = reused.balancer
+	} else {
+		reused = &reusableBalancer{next: middlewares.NewHandlerSwitcher(fwd)}
+
+		var next http.Handler = reused.next
+		if stickySession != nil {
+			if service.Stickiness.HeaderName != "" {
+				next = &stickyHeaderResponse{next: next, headerName: service.Stickiness.HeaderName, cookieName: cookieName}
+			} else if !cookieOptions.IsDefault() {
+				next = &stickyCookie{next: next, name: cookieName, options: cookieOptions}
+			}
+		}
+
+		var err error
+		lb, err = m.newLoadBalancer(ctx, serviceName, service, next, stickySession, cookieName)
+		if err != nil {
+			return nil, err
+		}
+
+		weights, err = m.upsertServers(ctx, lb, service.Servers, service.Scheme)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring load balancer for service %s: %v", serviceName, err)
+		}
+	}
+
+	if m.reusableBalancers == nil {
+		m.reusableBalancers = make(map[string][]*reusableBalancer)
+	}
+	m.reusableBalancers[serviceName] = append(m.reusableBalancers[serviceName], &reusableBalancer{
+		config:   service,
+		balancer: lb,
+		next:     reused.next,
+		weights:  weights,
+	})
+
+	if stickySession != nil && service.Stickiness.ConsistentFailover {
+		lb = newStickyFailover(lb, cookieName)
+	}
+
+	if stickySession != nil && service.Stickiness.HeaderName != "" {
+		logger.Debugf("Sticky session header name: %v", service.Stickiness.HeaderName)
+		lb = &stickyHeader{BalancerHandler: lb, headerName: service.Stickiness.HeaderName, cookieName: cookieName}
+	}
+
+	return lb, nil
+}
+
+// newLoadBalancer creates the load-balancer of the load-balancing method of the service, without any server.
+func (m *Manager) newLoadBalancer(ctx context.Context, serviceName string, service *config.LoadBalancerService, fwd http.Handler, stickySession *roundrobin.StickySession, cookieName string) (healthcheck.BalancerHandler, error) {
+	logger := log.FromContext(ctx)
+
 	var lb healthcheck.BalancerHandler
 
 	if service.Method == "drr" {
@@ -208,6 +424,18 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 				return nil, err
 			}
 		}
+	} else if service.Method == "wrandom" {
+		logger.Debug("Creating wrandom load-balancer")
+
+		if stickySession != nil {
+			logger.Debugf("Sticky session cookie name: %v", cookieName)
+		}
+
+		var err error
+		lb, err = newWeightedRandom(fwd, stickySession)
+		if err != nil {
+			return nil, err
+		}
 	} else {
 		if service.Method != "wrr" {
 			logger.Warnf("Invalid load-balancing method %q, fallback to 'wrr' method", service.Method)
@@ -232,50 +460,101 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 		}
 	}
 
-	if err := m.upsertServers(ctx, lb, service.Servers); err != nil {
-		return nil, fmt.Errorf("error configuring load balancer for service %s: %v", serviceName, err)
+	if service.SlowStart != nil {
+		if weighted, ok := lb.(weightedBalancer); ok {
+			lb = newSlowStart(ctx, serviceName, weighted, service.SlowStart, m.metricsRegistry)
+		} else {
+			logger.Warn("Slow start is not supported with the 'drr' method, ignoring it")
+		}
 	}
 
 	return lb, nil
 }
 
-func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHandler, servers []config.Server) error {
+// upsertServers adds the servers to the load-balancer, and returns the weights of the added servers per URL.
+func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHandler, servers []config.Server, scheme string) (map[string]int, error) {
 	logger := log.FromContext(ctx)
 
+	weights := make(map[string]int)
 	for name, srv := range servers {
-		u, err := url.Parse(srv.URL)
+		u, err := parseServerURL(srv.URL, scheme)
 		if err != nil {
-			return fmt.Errorf("error parsing server URL %s: %v", srv.URL, err)
+			return nil, err
+		}
+
+		if srv.Weight == 0 {
+			logger.WithField(log.ServerName, name).Debugf("Server %d at %s is drained", name, u)
+			continue
 		}
 
 		logger.WithField(log.ServerName, name).Debugf("Creating server %d at %s with weight %d", name, u, srv.Weight)
 
 		if err := lb.UpsertServer(u, roundrobin.Weight(srv.Weight)); err != nil {
-			return fmt.Errorf("error adding server %s to load balancer: %v", srv.URL, err)
+			return nil, fmt.Errorf("error adding server %s to load balancer: %v", srv.URL, err)
 		}
+		weights[u.String()] = srv.Weight
 
 		// FIXME Handle Metrics
 	}
-	return nil
+	return weights, nil
+}
+
+// parseServerURL parses the URL of a server, using the given default scheme if the URL has none.
+func parseServerURL(rawURL, defaultScheme string) (*url.URL, error) {
+	if !strings.Contains(rawURL, "://") {
+		if defaultScheme == "" {
+			defaultScheme = "http"
+		}
+		rawURL = defaultScheme + "://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing server URL %s: %v", rawURL, err)
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("missing host in server URL %s", rawURL)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "h2c":
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q for server URL %s", u.Scheme, rawURL)
+	}
+
+	return u, nil
 }
 
-func (m *Manager) buildForwarder(passHostHeader bool, responseForwarding *config.ResponseForwarding, responseModifier func(*http.Response) error) (http.Handler, error) {
+func (m *Manager) buildForwarder(serviceName string, passHostHeader bool, defaultUserAgent string, responseForwarding *config.ResponseForwarding, responseModifier func(*http.Response) error) (http.Handler, error) {
 
 	var flushInterval parse.Duration
-	if responseForwarding != nil {
+	if responseForwarding != nil && responseForwarding.FlushInterval != "" {
 		err := flushInterval.Set(responseForwarding.FlushInterval)
 		if err != nil {
 			return nil, fmt.Errorf("error creating flush interval: %v", err)
 		}
 	}
 
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
 	return forward.New(
 		forward.Stream(true),
-		forward.PassHostHeader(passHostHeader),
-		forward.RoundTripper(m.defaultRoundTripper),
+		// The Host header is handled by the rewriter, so that it can be overridden by the host rewrite middleware.
+		forward.PassHostHeader(true),
+		forward.Rewriter(&headerRewriter{
+			HeaderRewriter:   &forward.HeaderRewriter{TrustForwardHeader: true, Hostname: hostname},
+			passHostHeader:   passHostHeader,
+			defaultUserAgent: defaultUserAgent,
+		}),
+		forward.RoundTripper(&xForwardedForRemover{next: m.defaultRoundTripper}),
 		forward.ResponseModifier(responseModifier),
 		forward.BufferPool(m.bufferPool),
 		forward.StreamingFlushInterval(time.Duration(flushInterval)),
+		forward.ErrorHandler(forwardErrorHandler(serviceName)),
 		forward.WebsocketConnectionClosedHook(func(req *http.Request, conn net.Conn) {
 			server := req.Context().Value(http.ServerContextKey).(*http.Server)
 			if server != nil {
@@ -287,3 +566,118 @@ func (m *Manager) buildForwarder(passHostHeader bool, responseForwarding *config
 		}),
 	)
 }
+
+type serverNameKeyType int
+
+const serverNameKey serverNameKeyType = iota
+
+// withServerName adds the server name sent in the TLS handshake to the servers into the context of the forwarded requests.
+func withServerName(next http.Handler, serverName string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), serverNameKey, serverName)))
+	})
+}
+
+// serverNameTransport adds the server name sent in the TLS handshake to the servers into the context of the requests,
+// for the requests which do not go through the forwarder, such as the health checks.
+type serverNameTransport struct {
+	next       http.RoundTripper
+	serverName string
+}
+
+func (t *serverNameTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(req.WithContext(context.WithValue(req.Context(), serverNameKey, t.serverName)))
+}
+
+// ServerNameFromContext returns the server name to send in the TLS handshake (SNI) to the servers of the service forwarding the request,
+// or an empty string to send their host.
+func ServerNameFromContext(ctx context.Context) string {
+	serverName, _ := ctx.Value(serverNameKey).(string)
+	return serverName
+}
+
+// xForwardedForRemover removes the X-Forwarded-For header of the forwarded requests when their entry point removes it,
+// once the forwarder has appended the client IP.
+type xForwardedForRemover struct {
+	next http.RoundTripper
+}
+
+func (t *xForwardedForRemover) RoundTrip(req *http.Request) (*http.Response, error) {
+	if forwardedheaders.RemoveXForwardedFor(req.Context()) {
+		req.Header.Del(forward.XForwardedFor)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// headerRewriter sets the forwarded headers and the Host header of the outgoing request,
+// and then applies the host rewrite of the request, if any.
+// The User-Agent of the client is forwarded unchanged, the default one is only set when the client sent none.
+type headerRewriter struct {
+	*forward.HeaderRewriter
+	passHostHeader   bool
+	defaultUserAgent string
+}
+
+func (r *headerRewriter) Rewrite(req *http.Request) {
+	r.HeaderRewriter.Rewrite(req)
+
+	// The websocket requests do not go through the round-tripper removing the X-Forwarded-For header.
+	if forwardedheaders.RemoveXForwardedFor(req.Context()) {
+		req.Header.Del(forward.XForwardedFor)
+	}
+
+	if r.defaultUserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", r.defaultUserAgent)
+	}
+
+	if !r.passHostHeader {
+		req.Host = req.URL.Host
+		if req.Header.Get("Host") != "" {
+			// websocket requests
+			req.Header.Set("Host", req.Host)
+		}
+	}
+
+	hostrewrite.Rewrite(req)
+}
+
+// forwardErrorHandler answers with a 503 when no connection to the server could be obtained in time,
+// with a 502 when the TLS certificate of the server could not be verified,
+// and falls back on the default error handler otherwise.
+// The error is reported to the retry middleware, if any, which can classify it.
+func forwardErrorHandler(serviceName string) utils.ErrorHandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request, err error) {
+		retry.SetError(req, err)
+
+		if err == ErrConnectionPoolTimeout {
+			log.FromContext(req.Context()).Debugf("'%d %s' caused by: %v", http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable), err)
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+
+		if certErr := certificateError(err); certErr != nil {
+			log.FromContext(req.Context()).Errorf("Invalid TLS certificate of the server %s of the service %s: %v", req.URL.Host, serviceName, certErr)
+			http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+			return
+		}
+
+		utils.DefaultHandler.ServeHTTP(rw, req, err)
+	}
+}
+
+// certificateError returns the certificate verification error wrapped in err, if any.
+func certificateError(err error) error {
+	for err != nil {
+		switch err.(type) {
+		case x509.CertificateInvalidError, x509.HostnameError, x509.UnknownAuthorityError:
+			return err
+		}
+
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return nil
+		}
+		err = wrapper.Unwrap()
+	}
+	return nil
+}
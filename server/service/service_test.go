@@ -1,16 +1,28 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/middlewares/forwardedheaders"
+	"github.com/containous/traefik/middlewares/hostrewrite"
+	"github.com/containous/traefik/middlewares/retry"
 	"github.com/containous/traefik/server/internal"
 	"github.com/containous/traefik/testhelpers"
+	"github.com/containous/traefik/tls/generate"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/forward"
 )
 
 type MockForwarder struct{}
@@ -59,6 +71,15 @@ func TestGetLoadBalancer(t *testing.T) {
 			fwd:         &MockForwarder{},
 			expectError: false,
 		},
+		{
+			desc:        "Fails when the sticky cookie has SameSite=None without secure",
+			serviceName: "test",
+			service: &config.LoadBalancerService{
+				Stickiness: &config.Stickiness{SameSite: "none"},
+			},
+			fwd:         &MockForwarder{},
+			expectError: true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -79,7 +100,7 @@ func TestGetLoadBalancer(t *testing.T) {
 }
 
 func TestGetLoadBalancerServiceHandler(t *testing.T) {
-	sm := NewManager(nil, http.DefaultTransport)
+	sm := NewManager(nil, http.DefaultTransport, nil)
 
 	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-From", "first")
@@ -271,6 +292,510 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 	}
 }
 
+func TestGetLoadBalancerServiceHandler_StickyCookie(t *testing.T) {
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "backend", Value: "foo"})
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		desc       string
+		stickiness *config.Stickiness
+		expected   string
+	}{
+		{
+			desc:       "default attributes",
+			stickiness: &config.Stickiness{CookieName: "sticky"},
+			expected:   "sticky=" + server.URL + "; Path=/",
+		},
+		{
+			desc:       "SameSite lax and HTTPOnly",
+			stickiness: &config.Stickiness{CookieName: "sticky", HTTPOnly: true, SameSite: "lax"},
+			expected:   "sticky=" + server.URL + "; Path=/; HttpOnly; SameSite=Lax",
+		},
+		{
+			desc:       "SameSite none, secure and partitioned",
+			stickiness: &config.Stickiness{CookieName: "sticky", Secure: true, SameSite: "none", Partitioned: true},
+			expected:   "sticky=" + server.URL + "; Path=/; Secure; SameSite=None; Partitioned",
+		},
+		{
+			desc:       "__Host- prefix",
+			stickiness: &config.Stickiness{CookieName: "__Host-sticky", Secure: true},
+			expected:   "__Host-sticky=" + server.URL + "; Path=/; Secure",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			service := &config.LoadBalancerService{
+				Stickiness: test.stickiness,
+				Servers:    []config.Server{{URL: server.URL, Weight: 1}},
+				Method:     "wrr",
+			}
+
+			handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, []string{test.expected, "backend=foo"}, recorder.Header()["Set-Cookie"])
+		})
+	}
+}
+
+func TestGetLoadBalancerServiceHandler_Weights(t *testing.T) {
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	var servers []string
+	for _, name := range []string{"first", "second", "drained"} {
+		name := name
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", name)
+		}))
+		defer server.Close()
+
+		servers = append(servers, server.URL)
+	}
+
+	service := testhelpers.BuildConfiguration(
+		testhelpers.WithLoadBalancerServices(testhelpers.WithService("test",
+			testhelpers.WithLBMethod("wrr"),
+			testhelpers.WithServers(
+				testhelpers.WithServer(servers[0], testhelpers.WithWeight(3)),
+				testhelpers.WithServer(servers[1]),
+				testhelpers.WithServer(servers[2], testhelpers.WithWeight(0)),
+			),
+		)),
+	).Services["test"].LoadBalancer
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+	require.NoError(t, err)
+
+	from := make(map[string]int)
+	for i := 0; i < 400; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		from[recorder.Header().Get("X-From")]++
+	}
+
+	assert.InDelta(t, 300, from["first"], 10)
+	assert.InDelta(t, 100, from["second"], 10)
+	assert.Zero(t, from["drained"])
+}
+
+func TestGetLoadBalancerServiceHandler_WeightsSequence(t *testing.T) {
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	var servers []string
+	for _, name := range []string{"first", "second"} {
+		name := name
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", name)
+		}))
+		defer server.Close()
+
+		servers = append(servers, server.URL)
+	}
+
+	service := testhelpers.BuildConfiguration(
+		testhelpers.WithLoadBalancerServices(testhelpers.WithService("test",
+			testhelpers.WithLBMethod("wrr"),
+			testhelpers.WithServers(
+				testhelpers.WithServer(servers[0], testhelpers.WithWeight(3)),
+				testhelpers.WithServer(servers[1]),
+			),
+		)),
+	).Services["test"].LoadBalancer
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+	require.NoError(t, err)
+
+	var sequence []string
+	for i := 0; i < 8; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+		sequence = append(sequence, recorder.Header().Get("X-From"))
+	}
+
+	expected := []string{"first", "first", "first", "second", "first", "first", "first", "second"}
+	assert.Equal(t, expected, sequence)
+}
+
+func TestGetLoadBalancerServiceHandler_MixedSchemes(t *testing.T) {
+	serverTLS := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "https")
+	}))
+	defer serverTLS.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "http")
+	}))
+	defer server.Close()
+
+	sm := NewManager(nil, serverTLS.Client().Transport, nil)
+
+	testCases := []struct {
+		desc    string
+		service *config.LoadBalancerService
+	}{
+		{
+			desc: "schemes from the server URLs",
+			service: &config.LoadBalancerService{
+				Method: "wrr",
+				Servers: []config.Server{
+					{URL: serverTLS.URL, Weight: 1},
+					{URL: server.URL, Weight: 1},
+				},
+			},
+		},
+		{
+			desc: "default scheme of the service",
+			service: &config.LoadBalancerService{
+				Method: "wrr",
+				Scheme: "https",
+				Servers: []config.Server{
+					{URL: strings.TrimPrefix(serverTLS.URL, "https://"), Weight: 1},
+					{URL: server.URL, Weight: 1},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", test.service, nil)
+			require.NoError(t, err)
+
+			from := make(map[string]int)
+			for i := 0; i < 4; i++ {
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				from[recorder.Header().Get("X-From")]++
+			}
+
+			assert.Equal(t, map[string]int{"https": 2, "http": 2}, from)
+		})
+	}
+}
+
+type attemptsListener struct {
+	attempts int
+}
+
+func (l *attemptsListener) Retried(req *http.Request, attempt int) {
+	l.attempts = attempt
+}
+
+func TestGetLoadBalancerServiceHandler_RetryHealthyServers(t *testing.T) {
+	var servers []config.Server
+	for i := 0; i < 2; i++ {
+		// The servers are down: every attempt fails before sending the request.
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+		servers = append(servers, config.Server{URL: server.URL, Weight: 1})
+	}
+
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "foobar", &config.LoadBalancerService{Method: "wrr", Servers: servers}, nil)
+	require.NoError(t, err)
+
+	listener := &attemptsListener{}
+	handler, err = retry.New(context.Background(), handler, config.Retry{Attempts: 5, HealthyServersAttempts: true}, listener, "retry")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.Equal(t, 2, listener.attempts)
+}
+
+func TestParseServerURL(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		url           string
+		defaultScheme string
+		expected      string
+		expectedError bool
+	}{
+		{
+			desc:     "URL with scheme",
+			url:      "https://10.0.0.1:8443",
+			expected: "https://10.0.0.1:8443",
+		},
+		{
+			desc:          "scheme of the URL takes precedence",
+			url:           "http://10.0.0.1:8080",
+			defaultScheme: "https",
+			expected:      "http://10.0.0.1:8080",
+		},
+		{
+			desc:     "URL without scheme",
+			url:      "10.0.0.1:8080",
+			expected: "http://10.0.0.1:8080",
+		},
+		{
+			desc:          "URL without scheme and a default scheme",
+			url:           "10.0.0.1:8443",
+			defaultScheme: "https",
+			expected:      "https://10.0.0.1:8443",
+		},
+		{
+			desc:          "h2c default scheme",
+			url:           "10.0.0.1:8080",
+			defaultScheme: "h2c",
+			expected:      "h2c://10.0.0.1:8080",
+		},
+		{
+			desc:          "unsupported scheme",
+			url:           "ftp://10.0.0.1",
+			expectedError: true,
+		},
+		{
+			desc:          "unsupported default scheme",
+			url:           "10.0.0.1",
+			defaultScheme: "ftp",
+			expectedError: true,
+		},
+		{
+			desc:          "missing host",
+			url:           ":",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := parseServerURL(test.url, test.defaultScheme)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, u.String())
+		})
+	}
+}
+
+func TestGetLoadBalancerServiceHandler_HostRewrite(t *testing.T) {
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Host", r.Host)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		desc           string
+		passHostHeader bool
+		hostRewrite    *config.HostRewrite
+		expectedHost   string
+	}{
+		{
+			desc:           "pass the client host by default",
+			passHostHeader: true,
+			expectedHost:   "callme",
+		},
+		{
+			desc:           "fixed host",
+			passHostHeader: true,
+			hostRewrite:    &config.HostRewrite{Host: "foo.bar"},
+			expectedHost:   "foo.bar",
+		},
+		{
+			desc:           "fixed host without pass host header",
+			passHostHeader: false,
+			hostRewrite:    &config.HostRewrite{Host: "foo.bar"},
+			expectedHost:   "foo.bar",
+		},
+		{
+			desc:           "server host",
+			passHostHeader: true,
+			hostRewrite:    &config.HostRewrite{UseServerHost: true},
+			expectedHost:   testhelpers.MustParseURL(server.URL).Host,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			service := &config.LoadBalancerService{
+				PassHostHeader: test.passHostHeader,
+				Servers:        []config.Server{{URL: server.URL, Weight: 1}},
+				Method:         "wrr",
+			}
+
+			handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+			require.NoError(t, err)
+
+			if test.hostRewrite != nil {
+				handler, err = hostrewrite.New(context.Background(), handler, *test.hostRewrite, "test")
+				require.NoError(t, err)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, test.expectedHost, recorder.Header().Get("X-Host"))
+		})
+	}
+}
+
+func TestGetLoadBalancerServiceHandler_UserAgent(t *testing.T) {
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-User-Agent", r.UserAgent())
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		desc              string
+		userAgent         string
+		defaultUserAgent  string
+		expectedUserAgent string
+	}{
+		{
+			desc:              "client user agent",
+			userAgent:         "curl/7.64.0",
+			expectedUserAgent: "curl/7.64.0",
+		},
+		{
+			desc:              "client user agent with a default",
+			userAgent:         "curl/7.64.0",
+			defaultUserAgent:  "traefik",
+			expectedUserAgent: "curl/7.64.0",
+		},
+		{
+			desc:              "no user agent",
+			expectedUserAgent: "",
+		},
+		{
+			desc:              "default user agent",
+			defaultUserAgent:  "traefik",
+			expectedUserAgent: "traefik",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			service := &config.LoadBalancerService{
+				PassHostHeader:   true,
+				Servers:          []config.Server{{URL: server.URL, Weight: 1}},
+				Method:           "wrr",
+				DefaultUserAgent: test.defaultUserAgent,
+			}
+
+			handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+			if test.userAgent != "" {
+				req.Header.Set("User-Agent", test.userAgent)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, test.expectedUserAgent, recorder.Header().Get("X-User-Agent"))
+		})
+	}
+}
+
+func TestXForwardedFor_Remove(t *testing.T) {
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		headers = append(headers, req.Header)
+	}))
+	defer server.Close()
+
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	service := &config.LoadBalancerService{
+		Servers: []config.Server{{URL: server.URL, Weight: 1}},
+		Method:  "wrr",
+	}
+
+	lb, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+	require.NoError(t, err)
+
+	handler, err := forwardedheaders.NewXForwarded(true, nil, forwardedheaders.XForwardedForRemove, lb)
+	require.NoError(t, err)
+
+	req := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set(forward.XForwardedFor, "10.0.0.2")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, headers, 1)
+	assert.NotContains(t, headers[0], forward.XForwardedFor)
+	// The other forwarded headers are still set.
+	assert.Equal(t, "10.0.0.1", headers[0].Get(forward.XRealIp))
+}
+
+func TestHeaderRewriter_RemovedXForwardedFor(t *testing.T) {
+	rewriter := &headerRewriter{HeaderRewriter: &forward.HeaderRewriter{TrustForwardHeader: true}, passHostHeader: true}
+
+	var req *http.Request
+	m, err := forwardedheaders.NewXForwarded(true, nil, forwardedheaders.XForwardedForRemove, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		req = r
+	}))
+	require.NoError(t, err)
+
+	websocketReq := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+	websocketReq.RemoteAddr = "10.0.0.1:1234"
+	websocketReq.Header.Set("Connection", "Upgrade")
+	websocketReq.Header.Set("Upgrade", "websocket")
+	m.ServeHTTP(httptest.NewRecorder(), websocketReq)
+
+	rewriter.Rewrite(req)
+
+	assert.NotContains(t, req.Header, forward.XForwardedFor)
+}
+
+func TestServerNameTransport(t *testing.T) {
+	var serverName string
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		serverName = ServerNameFromContext(req.Context())
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	client := http.Client{Transport: &serverNameTransport{next: next, serverName: "backend.example.com"}}
+	resp, err := client.Get("https://10.0.0.1/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "backend.example.com", serverName)
+	assert.Empty(t, ServerNameFromContext(context.Background()))
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
 func TestManager_Build(t *testing.T) {
 	testCases := []struct {
 		desc         string
@@ -313,7 +838,7 @@ func TestManager_Build(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			manager := NewManager(test.configs, http.DefaultTransport)
+			manager := NewManager(test.configs, http.DefaultTransport, nil)
 
 			ctx := context.Background()
 			if len(test.providerName) > 0 {
@@ -327,3 +852,40 @@ func TestManager_Build(t *testing.T) {
 }
 
 // FIXME Add healthcheck tests
+
+func TestGetLoadBalancerServiceHandler_ExpiredCertificate(t *testing.T) {
+	certPEM, keyPEM, err := generate.KeyPair("foo.bar", time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	backend.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	backend.StartTLS()
+	defer backend.Close()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(certPEM)
+	transport := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, ServerName: "foo.bar"}}
+
+	var logs bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &logs
+	log.SetLogger(logger)
+	defer log.SetLogger(logrus.StandardLogger())
+
+	sm := NewManager(nil, transport, nil)
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "foo", &config.LoadBalancerService{
+		Method:  "wrr",
+		Servers: []config.Server{{URL: backend.URL, Weight: 1}},
+	}, nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.bar", nil))
+
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.Contains(t, logs.String(), "service foo")
+	assert.Contains(t, logs.String(), "certificate has expired")
+}
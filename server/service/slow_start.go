@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/healthcheck"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
+	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
+)
+
+const (
+	defaultSlowStartDuration = 30 * time.Second
+
+	// slowStartScale is the factor applied to the weights of all the servers,
+	// so that the weight of a starting server can be increased progressively.
+	slowStartScale = 10
+
+	// slowStartSteps is the number of weight adjustments during the slow start of a server.
+	slowStartSteps = 10
+)
+
+// weightedBalancer is a load-balancer which exposes the weights of its servers.
+type weightedBalancer interface {
+	healthcheck.BalancerHandler
+	ServerWeight(u *url.URL) (int, bool)
+}
+
+// weightFactorBalancer is a load-balancer which scales the weights of its servers by a factor,
+// so that the factor is applied along with its own scaling of the weights, instead of on already scaled weights.
+type weightFactorBalancer interface {
+	healthcheck.BalancerHandler
+	SetWeightFactor(u *url.URL, factor float64) error
+}
+
+// slowStart is a load-balancer which linearly increases the weight of the servers added to it,
+// from almost zero to their full weight over the configured duration.
+// It applies to the servers added at startup, as well as to the servers put back by the health check.
+type slowStart struct {
+	lb              weightedBalancer
+	serviceName     string
+	duration        time.Duration
+	metricsRegistry metrics.Registry
+
+	lock       sync.Mutex
+	weights    map[string]int
+	factors    map[string]float64
+	starts     map[string]time.Time
+	urls       map[string]*url.URL
+	lastAdjust time.Time
+}
+
+func newSlowStart(ctx context.Context, serviceName string, lb weightedBalancer, conf *config.SlowStart, metricsRegistry metrics.Registry) *slowStart {
+	duration := defaultSlowStartDuration
+	if conf.Duration != "" {
+		durationOverride, err := time.ParseDuration(conf.Duration)
+		switch {
+		case err != nil:
+			log.FromContext(ctx).Errorf("Illegal slow start duration: %s", err)
+		case durationOverride <= 0:
+			log.FromContext(ctx).Errorf("Slow start duration smaller than zero")
+		default:
+			duration = durationOverride
+		}
+	}
+
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	return &slowStart{
+		lb:              lb,
+		serviceName:     serviceName,
+		duration:        duration,
+		metricsRegistry: metricsRegistry,
+		weights:         make(map[string]int),
+		factors:         make(map[string]float64),
+		starts:          make(map[string]time.Time),
+		urls:            make(map[string]*url.URL),
+	}
+}
+
+func (s *slowStart) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.adjust()
+	s.lb.ServeHTTP(rw, req)
+}
+
+// Servers returns the servers of the load-balancer.
+func (s *slowStart) Servers() []*url.URL {
+	return s.lb.Servers()
+}
+
+// RemoveServer removes the server from the load-balancer.
+func (s *slowStart) RemoveServer(u *url.URL) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := serverKey(u)
+	delete(s.weights, key)
+	delete(s.starts, key)
+	delete(s.urls, key)
+	s.setProgress(u, 0)
+
+	return s.lb.RemoveServer(u)
+}
+
+// UpsertServer adds or updates the server of the load-balancer.
+// A server which was not part of the load-balancer starts with a reduced weight.
+func (s *slowStart) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, exists := s.lb.ServerWeight(u)
+
+	if err := s.lb.UpsertServer(u, options...); err != nil {
+		return err
+	}
+
+	key := serverKey(u)
+	s.weights[key], _ = s.lb.ServerWeight(u)
+
+	if !exists {
+		s.starts[key] = time.Now()
+		s.urls[key] = utils.CopyURL(u)
+	}
+
+	return s.lb.UpsertServer(u, roundrobin.Weight(s.weight(key, time.Now())))
+}
+
+// SetWeightFactor sets the factor by which the configured weight of the server is scaled.
+func (s *slowStart) SetWeightFactor(u *url.URL, factor float64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := serverKey(u)
+	s.factors[key] = factor
+
+	if _, ok := s.weights[key]; !ok {
+		return nil
+	}
+	return s.lb.UpsertServer(u, roundrobin.Weight(s.weight(key, time.Now())))
+}
+
+// adjust updates the weights of the starting servers, at most slowStartSteps times over the slow start duration.
+func (s *slowStart) adjust() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	if len(s.starts) == 0 || now.Sub(s.lastAdjust) < s.duration/slowStartSteps {
+		return
+	}
+	s.lastAdjust = now
+
+	for key, u := range s.urls {
+		if _, ok := s.starts[key]; !ok {
+			continue
+		}
+
+		if err := s.lb.UpsertServer(u, roundrobin.Weight(s.weight(key, now))); err != nil {
+			log.WithoutContext().Errorf("Unable to adjust the weight of the server %s: %v", u, err)
+		}
+	}
+}
+
+// weight returns the weight of the server given the progress of its slow start,
+// and forgets the start of the server once it is complete.
+func (s *slowStart) weight(key string, now time.Time) int {
+	weight := s.weights[key] * slowStartScale
+	if factor, ok := s.factors[key]; ok {
+		weight = int(math.Round(float64(weight) * factor))
+	}
+
+	progress := 1.0
+	if start, ok := s.starts[key]; ok {
+		progress = float64(now.Sub(start)) / float64(s.duration)
+	}
+
+	if progress >= 1 {
+		delete(s.starts, key)
+		progress = 1
+	} else {
+		weight = int(float64(weight) * progress)
+	}
+
+	if u, ok := s.urls[key]; ok {
+		s.setProgress(u, progress)
+	}
+
+	if weight < 1 {
+		return 1
+	}
+	return weight
+}
+
+func (s *slowStart) setProgress(u *url.URL, progress float64) {
+	s.metricsRegistry.BackendServerSlowStartGauge().With("service", s.serviceName, "url", u.String()).Set(progress)
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/metrics"
+	"github.com/containous/traefik/testhelpers"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestSlowStart(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+	rr, err := roundrobin.New(next)
+	require.NoError(t, err)
+
+	gauge := &progressGauge{lock: &sync.Mutex{}, values: make(map[string]float64)}
+	registry := &slowStartRegistry{Registry: metrics.NewVoidRegistry(), gauge: gauge}
+
+	lb := newSlowStart(context.Background(), "foo", rr, &config.SlowStart{Duration: "10s"}, registry)
+
+	first := testhelpers.MustParseURL("http://10.0.0.1:80")
+	second := testhelpers.MustParseURL("http://10.0.0.2:80")
+
+	require.NoError(t, lb.UpsertServer(first, roundrobin.Weight(2)))
+	assertWeight(t, rr, first, 1)
+
+	// Complete the slow start of the first server.
+	lb.starts[serverKey(first)] = time.Now().Add(-time.Minute)
+	lb.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo", nil))
+	assertWeight(t, rr, first, 20)
+	assert.Equal(t, 1.0, gauge.get("foo", first.String()))
+
+	require.NoError(t, lb.UpsertServer(second, roundrobin.Weight(1)))
+	assertWeight(t, rr, second, 1)
+	assert.InDelta(t, 0.0, gauge.get("foo", second.String()), 0.01)
+
+	// Half of the slow start of the second server.
+	lb.starts[serverKey(second)] = time.Now().Add(-5 * time.Second)
+	lb.lastAdjust = time.Time{}
+	lb.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo", nil))
+	assertWeight(t, rr, first, 20)
+	assertWeight(t, rr, second, 5)
+	assert.InDelta(t, 0.5, gauge.get("foo", second.String()), 0.01)
+
+	// Not adjusted before the next step.
+	lb.starts[serverKey(second)] = time.Now().Add(-8 * time.Second)
+	lb.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo", nil))
+	assertWeight(t, rr, second, 5)
+
+	// The server removed by the health check starts again when it is put back.
+	require.NoError(t, lb.RemoveServer(first))
+	assert.Equal(t, 0.0, gauge.get("foo", first.String()))
+
+	require.NoError(t, lb.UpsertServer(first, roundrobin.Weight(2)))
+	assertWeight(t, rr, first, 1)
+
+	assert.Len(t, lb.Servers(), 2)
+}
+
+func TestSlowStart_LatencyWeighting(t *testing.T) {
+	servers := []config.Server{
+		{URL: "http://10.0.0.1:80", Weight: 1},
+		{URL: "http://10.0.0.2:80", Weight: 1},
+	}
+
+	var lock sync.Mutex
+	counts := make(map[string]int)
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		counts[req.URL.Host]++
+		lock.Unlock()
+	})
+
+	weighting := newLatencyWeighting(context.Background(), "foo", next, &config.LatencyWeighting{Interval: "1h"}, servers, "", nil)
+	rr, err := roundrobin.New(weighting)
+	require.NoError(t, err)
+
+	lb := newSlowStart(context.Background(), "foo", rr, &config.SlowStart{Duration: "10s"}, nil)
+	weighting.setBalancer(lb)
+
+	for _, srv := range servers {
+		require.NoError(t, lb.UpsertServer(testhelpers.MustParseURL(srv.URL), roundrobin.Weight(srv.Weight)))
+	}
+
+	// The second server is twice as slow as the first one.
+	weighting.observe(testhelpers.MustParseURL("http://10.0.0.2:80"), 20*time.Millisecond)
+	weighting.lastAdjust = time.Now().Add(-2 * time.Hour)
+	weighting.observe(testhelpers.MustParseURL("http://10.0.0.1:80"), 10*time.Millisecond)
+
+	// Half of the slow start of the second server.
+	lb.starts[serverKey(testhelpers.MustParseURL(servers[0].URL))] = time.Now().Add(-time.Minute)
+	lb.starts[serverKey(testhelpers.MustParseURL(servers[1].URL))] = time.Now().Add(-5 * time.Second)
+	lb.lastAdjust = time.Time{}
+	lb.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo", nil))
+	assertWeight(t, rr, testhelpers.MustParseURL(servers[0].URL), 10)
+	assertWeight(t, rr, testhelpers.MustParseURL(servers[1].URL), 2)
+
+	// The weights are scaled once, by both the latency and the slow start,
+	// including when the health check puts a server back with its configured weight.
+	require.NoError(t, lb.RemoveServer(testhelpers.MustParseURL(servers[0].URL)))
+	require.NoError(t, lb.UpsertServer(testhelpers.MustParseURL(servers[0].URL), roundrobin.Weight(1)))
+	lb.starts[serverKey(testhelpers.MustParseURL(servers[0].URL))] = time.Now().Add(-time.Minute)
+	lb.starts[serverKey(testhelpers.MustParseURL(servers[1].URL))] = time.Now().Add(-time.Minute)
+	lb.lastAdjust = time.Time{}
+	lb.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo", nil))
+	assertWeight(t, rr, testhelpers.MustParseURL(servers[0].URL), 10)
+	assertWeight(t, rr, testhelpers.MustParseURL(servers[1].URL), 5)
+
+	lock.Lock()
+	counts = make(map[string]int)
+	lock.Unlock()
+
+	for i := 0; i < 150; i++ {
+		lb.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo", nil))
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 100, "10.0.0.2:80": 50}, counts)
+}
+
+func TestGetLoadBalancer_SlowStart(t *testing.T) {
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	testCases := []struct {
+		desc     string
+		method   string
+		expected bool
+	}{
+		{
+			desc:     "wrr",
+			method:   "wrr",
+			expected: true,
+		},
+		{
+			desc:     "drr",
+			method:   "drr",
+			expected: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			service := &config.LoadBalancerService{
+				Method:    test.method,
+				SlowStart: &config.SlowStart{},
+				Servers:   []config.Server{{URL: "http://10.0.0.1:80", Weight: 1}},
+			}
+
+			lb, err := sm.getLoadBalancer(context.Background(), "foo", service, &MockForwarder{})
+			require.NoError(t, err)
+
+			_, ok := lb.(*slowStart)
+			assert.Equal(t, test.expected, ok)
+			assert.Len(t, lb.Servers(), 1)
+		})
+	}
+}
+
+func assertWeight(t *testing.T, lb weightedBalancer, u fmt.Stringer, expected int) {
+	t.Helper()
+
+	weight, ok := lb.ServerWeight(testhelpers.MustParseURL(u.String()))
+	require.True(t, ok)
+	assert.Equal(t, expected, weight, u.String())
+}
+
+type slowStartRegistry struct {
+	metrics.Registry
+	gauge *progressGauge
+}
+
+func (r *slowStartRegistry) BackendServerSlowStartGauge() gokitmetrics.Gauge {
+	return r.gauge
+}
+
+// progressGauge records the last value set for each set of label values.
+type progressGauge struct {
+	lock   *sync.Mutex
+	values map[string]float64
+	labels []string
+}
+
+func (g *progressGauge) With(labelValues ...string) gokitmetrics.Gauge {
+	return &progressGauge{lock: g.lock, values: g.values, labels: append(g.labels, labelValues...)}
+}
+
+func (g *progressGauge) Set(value float64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.values[strings.Join(g.labels, ",")] = value
+}
+
+func (g *progressGauge) Add(delta float64) {}
+
+func (g *progressGauge) get(serviceName, serverURL string) float64 {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return g.values[strings.Join([]string{"service", serviceName, "url", serverURL}, ",")]
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/middlewares/accesslog"
+)
+
+const defaultIndexFile = "index.html"
+
+func (m *Manager) getStaticServiceHandler(ctx context.Context, serviceName string, conf *config.Static) (http.Handler, error) {
+	if conf.Root == "" {
+		return nil, fmt.Errorf("the static service %q has no root", serviceName)
+	}
+
+	info, err := os.Stat(conf.Root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root for the static service %q: %v", serviceName, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("invalid root %s for the static service %q: it is not a directory", conf.Root, serviceName)
+	}
+
+	indexFiles := conf.IndexFiles
+	if len(indexFiles) == 0 {
+		indexFiles = []string{defaultIndexFile}
+	}
+
+	for _, name := range indexFiles {
+		if name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("invalid index file %q for the static service %q", name, serviceName)
+		}
+	}
+
+	log.FromContext(ctx).Debugf("Serving the files of %s", conf.Root)
+
+	s := &static{
+		root:             http.Dir(conf.Root),
+		indexFiles:       indexFiles,
+		directoryListing: conf.DirectoryListing,
+		spaFallback:      conf.SPAFallback,
+	}
+
+	return accesslog.NewFieldHandler(s, accesslog.ServiceName, serviceName, accesslog.AddServiceFields), nil
+}
+
+// static serves the files of a directory.
+// The content types, the ranges and the conditional requests are handled by http.ServeContent,
+// with an ETag computed from the size and the modification time of the files.
+type static struct {
+	root             http.FileSystem
+	indexFiles       []string
+	directoryListing bool
+	spaFallback      bool
+}
+
+func (s *static) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		rw.Header().Set("Allow", "GET, HEAD")
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := path.Clean("/" + req.URL.Path)
+
+	if s.serveFile(rw, req, name) {
+		return
+	}
+
+	if s.spaFallback && s.serveIndex(rw, req, "/") {
+		return
+	}
+
+	http.NotFound(rw, req)
+}
+
+// serveFile serves the named file, or the index file or the listing of the named directory,
+// and returns false if there is nothing to serve.
+func (s *static) serveFile(rw http.ResponseWriter, req *http.Request, name string) bool {
+	f, err := s.root.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	if !info.IsDir() {
+		s.serveContent(rw, req, f, info)
+		return true
+	}
+
+	// The relative links of the index file, or of the listing, are relative to the directory.
+	if !strings.HasSuffix(req.URL.Path, "/") {
+		// The redirect is relative, as the path may have been rewritten by the middlewares.
+		location := path.Base(req.URL.Path) + "/"
+		if req.URL.RawQuery != "" {
+			location += "?" + req.URL.RawQuery
+		}
+		rw.Header().Set("Location", location)
+		rw.WriteHeader(http.StatusMovedPermanently)
+		return true
+	}
+
+	if s.serveIndex(rw, req, name) {
+		return true
+	}
+
+	if !s.directoryListing {
+		return false
+	}
+
+	http.FileServer(s.root).ServeHTTP(rw, req)
+	return true
+}
+
+// serveIndex serves the first existing index file of the named directory,
+// and returns false if there is none.
+func (s *static) serveIndex(rw http.ResponseWriter, req *http.Request, dir string) bool {
+	for _, indexFile := range s.indexFiles {
+		f, err := s.root.Open(path.Join(dir, indexFile))
+		if err != nil {
+			continue
+		}
+
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			f.Close()
+			continue
+		}
+
+		s.serveContent(rw, req, f, info)
+		f.Close()
+		return true
+	}
+
+	return false
+}
+
+func (s *static) serveContent(rw http.ResponseWriter, req *http.Request, f http.File, info os.FileInfo) {
+	rw.Header().Set("Etag", fmt.Sprintf(`W/"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	http.ServeContent(rw, req, info.Name(), info.ModTime(), f)
+}
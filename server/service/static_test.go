@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatic(t *testing.T) {
+	root := newStaticRoot(t)
+	defer os.RemoveAll(root)
+
+	testCases := []struct {
+		desc             string
+		conf             config.Static
+		method           string
+		path             string
+		expectedStatus   int
+		expectedType     string
+		expectedBody     string
+		expectedLocation string
+	}{
+		{
+			desc:           "file",
+			path:           "/style.css",
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/css; charset=utf-8",
+			expectedBody:   "body {}",
+		},
+		{
+			desc:           "index file",
+			path:           "/",
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/html; charset=utf-8",
+			expectedBody:   "<html>index</html>",
+		},
+		{
+			desc:           "custom index file",
+			conf:           config.Static{IndexFiles: []string{"default.txt", "index.html"}},
+			path:           "/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "<html>index</html>",
+		},
+		{
+			desc:             "directory without trailing slash",
+			path:             "/sub",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "sub/",
+		},
+		{
+			desc:           "directory without index file",
+			path:           "/sub/",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "directory listing",
+			conf:           config.Static{DirectoryListing: true},
+			path:           "/sub/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `<a href="a.txt">a.txt</a>`,
+		},
+		{
+			desc:           "not found",
+			path:           "/app/route",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "path outside of the root",
+			path:           "/../static.go",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "SPA fallback",
+			conf:           config.Static{SPAFallback: true},
+			path:           "/app/route",
+			expectedStatus: http.StatusOK,
+			expectedType:   "text/html; charset=utf-8",
+			expectedBody:   "<html>index</html>",
+		},
+		{
+			desc:           "method not allowed",
+			method:         http.MethodPost,
+			path:           "/style.css",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			conf := test.conf
+			conf.Root = root
+
+			sm := NewManager(map[string]*config.Service{"provider.static": {Static: &conf}}, http.DefaultTransport, nil)
+
+			handler, err := sm.Build(context.Background(), "provider.static", nil)
+			require.NoError(t, err)
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, "http://callme"+test.path, nil)
+			req.URL.Path = test.path
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedType != "" {
+				assert.Equal(t, test.expectedType, recorder.Header().Get("Content-Type"))
+			}
+			if test.expectedBody != "" {
+				assert.Contains(t, recorder.Body.String(), test.expectedBody)
+			}
+			assert.Equal(t, test.expectedLocation, recorder.Header().Get("Location"))
+		})
+	}
+}
+
+func TestStatic_ConditionalRequests(t *testing.T) {
+	root := newStaticRoot(t)
+	defer os.RemoveAll(root)
+
+	sm := NewManager(map[string]*config.Service{"provider.static": {Static: &config.Static{Root: root}}}, http.DefaultTransport, nil)
+
+	handler, err := sm.Build(context.Background(), "provider.static", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme/style.css", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	etag := recorder.Header().Get("Etag")
+	require.NotEmpty(t, etag)
+	lastModified := recorder.Header().Get("Last-Modified")
+	require.NotEmpty(t, lastModified)
+
+	req := httptest.NewRequest(http.MethodGet, "http://callme/style.css", nil)
+	req.Header.Set("If-None-Match", etag)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "http://callme/style.css", nil)
+	req.Header.Set("If-Modified-Since", lastModified)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "http://callme/style.css", nil)
+	req.Header.Set("If-None-Match", `W/"foo"`)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestStatic_Errors(t *testing.T) {
+	root := newStaticRoot(t)
+	defer os.RemoveAll(root)
+
+	testCases := []struct {
+		desc string
+		conf config.Static
+	}{
+		{
+			desc: "no root",
+		},
+		{
+			desc: "missing root",
+			conf: config.Static{Root: filepath.Join(root, "missing")},
+		},
+		{
+			desc: "file root",
+			conf: config.Static{Root: filepath.Join(root, "style.css")},
+		},
+		{
+			desc: "index file in a sub-directory",
+			conf: config.Static{Root: root, IndexFiles: []string{"sub/a.txt"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			sm := NewManager(map[string]*config.Service{"provider.static": {Static: &test.conf}}, http.DefaultTransport, nil)
+
+			_, err := sm.Build(context.Background(), "provider.static", nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// newStaticRoot creates a directory with an index.html and a style.css files, and a sub directory with a a.txt file.
+func newStaticRoot(t *testing.T) string {
+	t.Helper()
+
+	root, err := ioutil.TempDir("", "traefik-static")
+	require.NoError(t, err)
+
+	require.NoError(t, os.Mkdir(filepath.Join(root, "sub"), 0755))
+
+	files := map[string]string{
+		"index.html": "<html>index</html>",
+		"style.css":  "body {}",
+		"sub/a.txt":  "a",
+	}
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644))
+	}
+
+	return root
+}
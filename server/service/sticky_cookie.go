@@ -0,0 +1,28 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/containous/traefik/server/cookie"
+)
+
+// stickyCookie sets the configured attributes on the sticky session cookie,
+// which is added to the response headers by the load-balancer before forwarding the request.
+type stickyCookie struct {
+	next    http.Handler
+	name    string
+	options cookie.Options
+}
+
+func (s *stickyCookie) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if values := rw.Header()["Set-Cookie"]; len(values) > 0 {
+		for i, value := range values {
+			cookies := (&http.Response{Header: http.Header{"Set-Cookie": {value}}}).Cookies()
+			if len(cookies) == 1 && cookies[0].Name == s.name {
+				values[i] = s.options.Apply(cookies[0])
+			}
+		}
+	}
+
+	s.next.ServeHTTP(rw, req)
+}
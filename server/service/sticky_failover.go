@@ -0,0 +1,135 @@
+package service
+
+import (
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/containous/traefik/healthcheck"
+	"github.com/containous/traefik/log"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// stickyFailover is a sticky load-balancer which, when the pinned server of a request is down,
+// forwards it to a failover server chosen by consistent hashing of the sticky cookie.
+// The cookie is left unchanged, so that the client goes back to its pinned server once it recovers,
+// and a given cookie always fails over to the same server while the set of alive servers does not change.
+type stickyFailover struct {
+	healthcheck.BalancerHandler
+	cookieName string
+
+	lock sync.RWMutex
+	// known holds the servers which have been part of the load-balancer, including the ones currently down.
+	known map[string]bool
+}
+
+func newStickyFailover(lb healthcheck.BalancerHandler, cookieName string) *stickyFailover {
+	known := make(map[string]bool)
+	for _, u := range lb.Servers() {
+		known[serverKey(u)] = true
+	}
+
+	return &stickyFailover{
+		BalancerHandler: lb,
+		cookieName:      cookieName,
+		known:           known,
+	}
+}
+
+// UpsertServer adds or updates the server of the load-balancer, and remembers it as a server of the load-balancer,
+// so that the clients pinned to it fail over while it is down.
+func (s *stickyFailover) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	if err := s.BalancerHandler.UpsertServer(u, options...); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	s.known[serverKey(u)] = true
+	s.lock.Unlock()
+
+	return nil
+}
+
+func (s *stickyFailover) isKnown(u *url.URL) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.known[serverKey(u)]
+}
+
+func (s *stickyFailover) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	cookie, err := req.Cookie(s.cookieName)
+	if err != nil {
+		s.BalancerHandler.ServeHTTP(rw, req)
+		return
+	}
+
+	pinned, err := url.Parse(cookie.Value)
+	if err != nil || !s.isKnown(pinned) {
+		s.BalancerHandler.ServeHTTP(rw, req)
+		return
+	}
+
+	alive := s.Servers()
+	for _, u := range alive {
+		if serverKey(u) == serverKey(pinned) {
+			s.BalancerHandler.ServeHTTP(rw, req)
+			return
+		}
+	}
+
+	failover := consistentServer(cookie.Value, alive)
+	if failover == nil {
+		s.BalancerHandler.ServeHTTP(rw, req)
+		return
+	}
+
+	log.FromContext(req.Context()).Debugf("Pinned server %s is down, failing over to %s", cookie.Value, failover)
+
+	s.BalancerHandler.ServeHTTP(rw, withCookieValue(req, s.cookieName, failover.String()))
+}
+
+// consistentServer chooses a server for the key with a rendezvous hashing,
+// so that only the keys of a removed server are moved to other servers.
+func consistentServer(key string, servers []*url.URL) *url.URL {
+	var selected *url.URL
+	var selectedScore uint64
+
+	for _, u := range servers {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(serverKey(u)))
+
+		if score := h.Sum64(); selected == nil || score > selectedScore {
+			selected = u
+			selectedScore = score
+		}
+	}
+
+	return selected
+}
+
+// withCookieValue returns a copy of the request, in which the value of the cookie is replaced.
+func withCookieValue(req *http.Request, name, value string) *http.Request {
+	outReq := new(http.Request)
+	*outReq = *req
+
+	var cookies []string
+	for _, c := range req.Cookies() {
+		if c.Name == name {
+			c.Value = value
+		}
+		cookies = append(cookies, c.String())
+	}
+
+	outReq.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		outReq.Header[k] = v
+	}
+	outReq.Header.Set("Cookie", strings.Join(cookies, "; "))
+
+	return outReq
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStickyFailover(t *testing.T) {
+	servers := make(map[string]*httptest.Server)
+	names := make(map[string]string)
+	var serversConfig []config.Server
+	for _, name := range []string{"first", "second", "third", "fourth"} {
+		name := name
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", name)
+		}))
+		defer server.Close()
+
+		servers[server.URL] = server
+		names[server.URL] = name
+		serversConfig = append(serversConfig, config.Server{URL: server.URL, Weight: 1})
+	}
+
+	sm := NewManager(map[string]*config.Service{
+		"provider.sticky": {
+			LoadBalancer: &config.LoadBalancerService{
+				Method:     "wrr",
+				Stickiness: &config.Stickiness{CookieName: "sticky", ConsistentFailover: true},
+				Servers:    serversConfig,
+			},
+		},
+	}, http.DefaultTransport, nil)
+
+	handler, err := sm.Build(context.Background(), "provider.sticky", nil)
+	require.NoError(t, err)
+
+	balancer := sm.balancers["provider.sticky"][0]
+
+	serve := func(cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		return recorder
+	}
+
+	cookies := serve(nil).Result().Cookies()
+	require.Len(t, cookies, 1)
+	pinnedCookie := cookies[0]
+
+	pinned, err := url.Parse(pinnedCookie.Value)
+	require.NoError(t, err)
+	pinnedName := serve(pinnedCookie).Header().Get("X-From")
+
+	// The health check removes the killed server from the load-balancer.
+	servers[pinnedCookie.Value].Close()
+	require.NoError(t, balancer.RemoveServer(pinned))
+
+	failover := serve(pinnedCookie)
+	failoverName := failover.Header().Get("X-From")
+	assert.NotEqual(t, pinnedName, failoverName)
+	assert.Empty(t, failover.Header().Get("Set-Cookie"))
+
+	for i := 0; i < 10; i++ {
+		recorder := serve(pinnedCookie)
+		assert.Equal(t, failoverName, recorder.Header().Get("X-From"))
+		assert.Empty(t, recorder.Header().Get("Set-Cookie"))
+	}
+
+	// Another server going down does not move the clients of the failover server.
+	for u, name := range names {
+		if u == pinnedCookie.Value || name == failoverName {
+			continue
+		}
+
+		require.NoError(t, balancer.RemoveServer(testhelpers.MustParseURL(u)))
+		break
+	}
+	assert.Equal(t, failoverName, serve(pinnedCookie).Header().Get("X-From"))
+
+	// The clients go back to their server once it recovers.
+	listener, err := net.Listen("tcp", pinned.Host)
+	require.NoError(t, err)
+
+	recovered := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", pinnedName)
+	}))
+	recovered.Listener = listener
+	recovered.Start()
+	defer recovered.Close()
+
+	require.NoError(t, balancer.UpsertServer(pinned))
+	assert.Equal(t, pinnedName, serve(pinnedCookie).Header().Get("X-From"))
+}
+
+func TestStickyFailover_AddedServer(t *testing.T) {
+	var names []string
+	var serversConfig []config.Server
+	for _, name := range []string{"first", "second"} {
+		name := name
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", name)
+		}))
+		defer server.Close()
+
+		names = append(names, name)
+		serversConfig = append(serversConfig, config.Server{URL: server.URL, Weight: 1})
+	}
+
+	sm := NewManager(map[string]*config.Service{
+		"provider.sticky": {
+			LoadBalancer: &config.LoadBalancerService{
+				Method:     "wrr",
+				Stickiness: &config.Stickiness{CookieName: "sticky", ConsistentFailover: true},
+				Servers:    serversConfig[:1],
+			},
+		},
+	}, http.DefaultTransport, nil)
+
+	handler, err := sm.Build(context.Background(), "provider.sticky", nil)
+	require.NoError(t, err)
+
+	balancer := sm.balancers["provider.sticky"][0]
+
+	// The second server is added after the load-balancer is created, and then goes down.
+	added := testhelpers.MustParseURL(serversConfig[1].URL)
+	require.NoError(t, balancer.UpsertServer(added))
+	require.NoError(t, balancer.RemoveServer(added))
+
+	req := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+	req.AddCookie(&http.Cookie{Name: "sticky", Value: added.String()})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, names[0], recorder.Header().Get("X-From"))
+	// The client fails over instead of being pinned to another server.
+	assert.Empty(t, recorder.Header().Get("Set-Cookie"))
+}
+
+func TestConsistentServer(t *testing.T) {
+	var servers []*url.URL
+	for _, u := range []string{"http://10.0.0.1", "http://10.0.0.2", "http://10.0.0.3", "http://10.0.0.4"} {
+		servers = append(servers, testhelpers.MustParseURL(u))
+	}
+
+	assert.Nil(t, consistentServer("http://10.0.0.5", nil))
+
+	counts := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("http://10.0.1.%d", i)
+
+		selected := consistentServer(key, servers)
+		require.NotNil(t, selected)
+		assert.Equal(t, selected, consistentServer(key, servers))
+		counts[selected.String()]++
+
+		// Removing another server does not move the key.
+		for j := range servers {
+			if servers[j] == selected {
+				continue
+			}
+
+			remaining := append(append([]*url.URL{}, servers[:j]...), servers[j+1:]...)
+			assert.Equal(t, selected, consistentServer(key, remaining))
+		}
+	}
+
+	assert.Len(t, counts, 4)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/containous/traefik/healthcheck"
+)
+
+// stickyHeader is a sticky load-balancer pinning the clients to a server through a header instead of a cookie.
+// The header holds a hash of the server URL, which is translated to the sticky cookie of the load-balancer
+// before balancing the request.
+type stickyHeader struct {
+	healthcheck.BalancerHandler
+	headerName string
+	cookieName string
+}
+
+func (s *stickyHeader) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The cookie is internal: the server URL sent by a client is never trusted.
+	var serverURL string
+	if value := req.Header.Get(s.headerName); value != "" {
+		for _, u := range s.Servers() {
+			if stickyHash(u) == value {
+				serverURL = u.String()
+				break
+			}
+		}
+	}
+
+	s.BalancerHandler.ServeHTTP(rw, withOnlyCookie(req, s.cookieName, serverURL))
+}
+
+// stickyHeaderResponse replaces the sticky cookie set by the load-balancer for a new client with the sticky header,
+// and removes the sticky cookie from the forwarded request.
+type stickyHeaderResponse struct {
+	next       http.Handler
+	headerName string
+	cookieName string
+}
+
+func (s *stickyHeaderResponse) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if values := rw.Header()["Set-Cookie"]; len(values) > 0 {
+		var kept []string
+		for _, value := range values {
+			cookies := (&http.Response{Header: http.Header{"Set-Cookie": {value}}}).Cookies()
+			if len(cookies) != 1 || cookies[0].Name != s.cookieName {
+				kept = append(kept, value)
+				continue
+			}
+
+			if u, err := url.Parse(cookies[0].Value); err == nil {
+				rw.Header().Set(s.headerName, stickyHash(u))
+			}
+		}
+
+		if len(kept) > 0 {
+			rw.Header()["Set-Cookie"] = kept
+		} else {
+			rw.Header().Del("Set-Cookie")
+		}
+	}
+
+	s.next.ServeHTTP(rw, withOnlyCookie(req, s.cookieName, ""))
+}
+
+// stickyHash returns the opaque value of the sticky header for a server.
+func stickyHash(u *url.URL) string {
+	sum := sha256.Sum256([]byte(serverKey(u)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// withOnlyCookie returns a copy of the request, in which the cookie has the value, or is removed for an empty value.
+func withOnlyCookie(req *http.Request, name, value string) *http.Request {
+	outReq := new(http.Request)
+	*outReq = *req
+
+	var cookies []string
+	for _, c := range req.Cookies() {
+		if c.Name != name {
+			cookies = append(cookies, c.String())
+		}
+	}
+	if value != "" {
+		cookies = append(cookies, (&http.Cookie{Name: name, Value: value}).String())
+	}
+
+	outReq.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		outReq.Header[k] = v
+	}
+	if len(cookies) > 0 {
+		outReq.Header.Set("Cookie", strings.Join(cookies, "; "))
+	} else {
+		outReq.Header.Del("Cookie")
+	}
+
+	return outReq
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStickyHeader(t *testing.T) {
+	var serversConfig []config.Server
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", name)
+			w.Header().Set("X-Cookie", r.Header.Get("Cookie"))
+		}))
+		defer server.Close()
+
+		serversConfig = append(serversConfig, config.Server{URL: server.URL, Weight: 1})
+	}
+
+	sm := NewManager(map[string]*config.Service{
+		"provider.sticky": {
+			LoadBalancer: &config.LoadBalancerService{
+				Method:     "wrr",
+				Stickiness: &config.Stickiness{HeaderName: "X-Backend"},
+				Servers:    serversConfig,
+			},
+		},
+	}, http.DefaultTransport, nil)
+
+	handler, err := sm.Build(context.Background(), "provider.sticky", nil)
+	require.NoError(t, err)
+
+	serve := func(stickyValue string) *httptest.ResponseRecorder {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+		if stickyValue != "" {
+			req.Header.Set("X-Backend", stickyValue)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		return recorder
+	}
+
+	first := serve("")
+	stickyValue := first.Header().Get("X-Backend")
+	require.NotEmpty(t, stickyValue)
+	assert.False(t, strings.Contains(stickyValue, "127.0.0.1"), "the sticky header exposes the server URL: %s", stickyValue)
+	assert.Empty(t, first.Header().Get("Set-Cookie"))
+	assert.Empty(t, first.Header().Get("X-Cookie"))
+
+	pinnedName := first.Header().Get("X-From")
+	for i := 0; i < 10; i++ {
+		recorder := serve(stickyValue)
+		assert.Equal(t, pinnedName, recorder.Header().Get("X-From"))
+		assert.Empty(t, recorder.Header().Get("X-Backend"))
+		assert.Empty(t, recorder.Header().Get("X-Cookie"))
+	}
+
+	// An unknown value is balanced, and pinned to a new server.
+	unknown := serve("foo")
+	assert.NotEmpty(t, unknown.Header().Get("X-Backend"))
+	assert.NotEqual(t, "foo", unknown.Header().Get("X-Backend"))
+}
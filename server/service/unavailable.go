@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/containous/traefik/config"
+)
+
+// getUnavailableHandler creates the handler responding to the requests of a load-balancer without any available server.
+func (m *Manager) getUnavailableHandler(ctx context.Context, serviceName string, conf *config.Unavailable, responseModifier func(*http.Response) error) (http.Handler, error) {
+	switch {
+	case conf.Service != "" && conf.Redirect != "":
+		return nil, errors.New("a redirect and a fallback service cannot be both configured")
+
+	case conf.Service != "":
+		if conf.StatusCode != 0 {
+			return nil, errors.New("a status code cannot be configured with a fallback service")
+		}
+
+		ctx, err := checkServiceRecursivity(ctx, serviceName)
+		if err != nil {
+			return nil, err
+		}
+
+		return m.Build(ctx, conf.Service, responseModifier)
+
+	case conf.Redirect != "":
+		if _, err := url.Parse(conf.Redirect); err != nil {
+			return nil, fmt.Errorf("invalid redirect: %v", err)
+		}
+
+		statusCode := conf.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusFound
+		}
+		if statusCode < 300 || statusCode > 399 {
+			return nil, fmt.Errorf("invalid status code %d: a redirect status code must be between 300 and 399", statusCode)
+		}
+
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			http.Redirect(rw, req, conf.Redirect, statusCode)
+		}), nil
+
+	default:
+		statusCode := conf.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusServiceUnavailable
+		}
+		if statusCode < 400 || statusCode > 599 {
+			return nil, fmt.Errorf("invalid status code %d: it must be between 400 and 599", statusCode)
+		}
+
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			http.Error(rw, http.StatusText(statusCode), statusCode)
+		}), nil
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLoadBalancerServiceHandler_Unavailable(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		unavailable      *config.Unavailable
+		expectedStatus   int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{
+			desc:           "default",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "Service Unavailable",
+		},
+		{
+			desc:           "custom status code",
+			unavailable:    &config.Unavailable{StatusCode: http.StatusGatewayTimeout},
+			expectedStatus: http.StatusGatewayTimeout,
+			expectedBody:   "Gateway Timeout\n",
+		},
+		{
+			desc:             "redirect",
+			unavailable:      &config.Unavailable{Redirect: "https://status.foo.bar/"},
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "https://status.foo.bar/",
+		},
+		{
+			desc:             "permanent redirect",
+			unavailable:      &config.Unavailable{Redirect: "https://status.foo.bar/", StatusCode: http.StatusMovedPermanently},
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "https://status.foo.bar/",
+		},
+		{
+			desc:           "fallback service",
+			unavailable:    &config.Unavailable{Service: "maintenance"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "temporarily unavailable",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			app := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(rw, "app")
+			}))
+			defer app.Close()
+
+			maintenance := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(rw, "temporarily unavailable")
+			}))
+			defer maintenance.Close()
+
+			sm := NewManager(map[string]*config.Service{
+				"provider.app": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:      "wrr",
+						Servers:     []config.Server{{URL: app.URL, Weight: 1}},
+						Unavailable: test.unavailable,
+					},
+				},
+				"provider.maintenance": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:  "wrr",
+						Servers: []config.Server{{URL: maintenance.URL, Weight: 1}},
+					},
+				},
+			}, http.DefaultTransport, nil)
+
+			handler, err := sm.Build(context.Background(), "provider.app", nil)
+			require.NoError(t, err)
+
+			// The health check removes the only server of the service.
+			balancer := sm.balancers["provider.app"][0]
+			require.NoError(t, balancer.RemoveServer(testhelpers.MustParseURL(app.URL)))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://callme", nil))
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, recorder.Body.String())
+			}
+			assert.Equal(t, test.expectedLocation, recorder.Header().Get("Location"))
+		})
+	}
+}
+
+func TestGetLoadBalancerServiceHandler_UnavailableErrors(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		unavailable *config.Unavailable
+	}{
+		{
+			desc:        "redirect and fallback service",
+			unavailable: &config.Unavailable{Redirect: "https://status.foo.bar/", Service: "maintenance"},
+		},
+		{
+			desc:        "status code with a fallback service",
+			unavailable: &config.Unavailable{Service: "maintenance", StatusCode: http.StatusServiceUnavailable},
+		},
+		{
+			desc:        "unknown fallback service",
+			unavailable: &config.Unavailable{Service: "unknown"},
+		},
+		{
+			desc:        "fallback on the service itself",
+			unavailable: &config.Unavailable{Service: "app"},
+		},
+		{
+			desc:        "redirect with a non redirect status code",
+			unavailable: &config.Unavailable{Redirect: "https://status.foo.bar/", StatusCode: http.StatusOK},
+		},
+		{
+			desc:        "success status code",
+			unavailable: &config.Unavailable{StatusCode: http.StatusOK},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			sm := NewManager(map[string]*config.Service{
+				"provider.app": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:      "wrr",
+						Servers:     []config.Server{{URL: "http://127.0.0.1:8080", Weight: 1}},
+						Unavailable: test.unavailable,
+					},
+				},
+				"provider.maintenance": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:  "wrr",
+						Servers: []config.Server{{URL: "http://127.0.0.1:8081", Weight: 1}},
+					},
+				},
+			}, http.DefaultTransport, nil)
+
+			_, err := sm.Build(context.Background(), "provider.app", nil)
+			assert.Error(t, err)
+		})
+	}
+}
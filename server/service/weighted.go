@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/server/cookie"
+	"github.com/containous/traefik/server/internal"
+)
+
+func (m *Manager) getWeightedServiceHandler(ctx context.Context, serviceName string, conf *config.Weighted, responseModifier func(*http.Response) error) (http.Handler, error) {
+	ctx, err := checkServiceRecursivity(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	balancer := &weighted{}
+	for _, serviceConf := range conf.Services {
+		if serviceConf.Weight < 0 {
+			return nil, fmt.Errorf("invalid weight %d for the service %q: it must be positive", serviceConf.Weight, serviceConf.Name)
+		}
+		if serviceConf.Weight == 0 {
+			continue
+		}
+
+		handler, err := m.Build(ctx, serviceConf.Name, responseModifier)
+		if err != nil {
+			return nil, err
+		}
+
+		balancer.children = append(balancer.children, &weightedChild{name: serviceConf.Name, handler: handler, weight: serviceConf.Weight})
+		balancer.totalWeight += serviceConf.Weight
+	}
+
+	if len(balancer.children) == 0 {
+		return nil, fmt.Errorf("the weighted service %q has no service with a weight", serviceName)
+	}
+
+	if sticky := conf.Sticky; sticky != nil {
+		balancer.cookieName = cookie.GetName(sticky.CookieName, serviceName)
+
+		options, err := cookie.NewOptions(balancer.cookieName, sticky.Secure, sticky.HTTPOnly, sticky.SameSite, sticky.Partitioned)
+		if err != nil {
+			return nil, err
+		}
+		balancer.cookieOptions = options
+
+		for _, serviceConf := range conf.Services {
+			childName := internal.GetQualifiedName(ctx, serviceConf.Name)
+
+			childConf, ok := m.configs[childName]
+			if !ok || childConf.LoadBalancer == nil || childConf.LoadBalancer.Stickiness == nil {
+				continue
+			}
+
+			if cookie.GetName(childConf.LoadBalancer.Stickiness.CookieName, childName) == balancer.cookieName {
+				return nil, fmt.Errorf("the sticky cookie %s of the weighted service %q is also the one of the service %q", balancer.cookieName, serviceName, childName)
+			}
+		}
+	}
+
+	return balancer, nil
+}
+
+// weighted splits the requests between its children, according to their weights,
+// with a smooth weighted round-robin so that the split is exact over every totalWeight requests.
+// With a sticky cookie, a client keeps being sent to the child it was first sent to.
+// The cookie is resolved before the sticky cookie of the load-balancer of the child, which pins the client to a server.
+type weighted struct {
+	children      []*weightedChild
+	totalWeight   int
+	cookieName    string
+	cookieOptions cookie.Options
+
+	lock sync.Mutex
+}
+
+type weightedChild struct {
+	name    string
+	handler http.Handler
+	weight  int
+	current int
+}
+
+func (w *weighted) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if w.cookieName != "" {
+		if child := w.stickyChild(req); child != nil {
+			child.handler.ServeHTTP(rw, req)
+			return
+		}
+	}
+
+	child := w.next()
+
+	if w.cookieName != "" {
+		rw.Header().Add("Set-Cookie", w.cookieOptions.Apply(&http.Cookie{Name: w.cookieName, Value: child.name, Path: "/"}))
+	}
+
+	child.handler.ServeHTTP(rw, req)
+}
+
+func (w *weighted) stickyChild(req *http.Request) *weightedChild {
+	c, err := req.Cookie(w.cookieName)
+	if err != nil {
+		return nil
+	}
+
+	for _, child := range w.children {
+		if child.name == c.Value {
+			return child
+		}
+	}
+
+	log.FromContext(req.Context()).Debugf("Unknown service %q in the sticky cookie %s", c.Value, w.cookieName)
+	return nil
+}
+
+func (w *weighted) next() *weightedChild {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var selected *weightedChild
+	for _, child := range w.children {
+		child.current += child.weight
+		if selected == nil || child.current > selected.current {
+			selected = child
+		}
+	}
+	selected.current -= w.totalWeight
+
+	return selected
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
+)
+
+// The servers are picked from a seeded source, so that the Traefik instances do not all follow the same sequence,
+// and a rand.Rand being unsafe for concurrent use, the load-balancers share it under a lock.
+var (
+	pickRandLock sync.Mutex
+	pickRand     = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func randIntn(n int) int {
+	pickRandLock.Lock()
+	defer pickRandLock.Unlock()
+
+	return pickRand.Intn(n)
+}
+
+// weightedRandom is a load-balancer which forwards each request to a server picked at random,
+// with a probability proportional to its weight.
+// The servers and their weights are held by a round-robin load-balancer, which does not balance the requests.
+type weightedRandom struct {
+	*roundrobin.RoundRobin
+	next          http.Handler
+	stickySession *roundrobin.StickySession
+}
+
+func newWeightedRandom(next http.Handler, stickySession *roundrobin.StickySession) (*weightedRandom, error) {
+	rr, err := roundrobin.New(next)
+	if err != nil {
+		return nil, err
+	}
+
+	return &weightedRandom{
+		RoundRobin:    rr,
+		next:          next,
+		stickySession: stickySession,
+	}, nil
+}
+
+func (w *weightedRandom) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// make shallow copy of request before changing anything to avoid side effects
+	newReq := *req
+
+	if w.stickySession != nil {
+		if stickyURL, present, err := w.stickySession.GetBackend(&newReq, w.Servers()); err == nil && present {
+			newReq.URL = stickyURL
+			w.next.ServeHTTP(rw, &newReq)
+			return
+		}
+	}
+
+	u, err := w.nextServer()
+	if err != nil {
+		utils.DefaultHandler.ServeHTTP(rw, req, err)
+		return
+	}
+
+	if w.stickySession != nil {
+		w.stickySession.StickBackend(u, &rw)
+	}
+
+	newReq.URL = u
+	w.next.ServeHTTP(rw, &newReq)
+}
+
+// nextServer picks a server with a probability proportional to its weight, ignoring the servers without weight.
+func (w *weightedRandom) nextServer() (*url.URL, error) {
+	servers := w.Servers()
+
+	total := 0
+	weights := make([]int, len(servers))
+	for i, u := range servers {
+		if weight, ok := w.ServerWeight(u); ok && weight > 0 {
+			weights[i] = weight
+			total += weight
+		}
+	}
+
+	if total == 0 {
+		return nil, errors.New("no server with a weight")
+	}
+
+	n := randIntn(total)
+	for i, weight := range weights {
+		if n < weight {
+			return servers[i], nil
+		}
+		n -= weight
+	}
+
+	return nil, errors.New("no server with a weight")
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestWeightedRandom_Distribution(t *testing.T) {
+	sm := NewManager(nil, http.DefaultTransport, nil)
+
+	var servers []string
+	for _, name := range []string{"first", "second", "drained"} {
+		name := name
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", name)
+		}))
+		defer server.Close()
+
+		servers = append(servers, server.URL)
+	}
+
+	service := testhelpers.BuildConfiguration(
+		testhelpers.WithLoadBalancerServices(testhelpers.WithService("test",
+			testhelpers.WithLBMethod("wrandom"),
+			testhelpers.WithServers(
+				testhelpers.WithServer(servers[0], testhelpers.WithWeight(3)),
+				testhelpers.WithServer(servers[1]),
+				testhelpers.WithServer(servers[2], testhelpers.WithWeight(0)),
+			),
+		)),
+	).Services["test"].LoadBalancer
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", service, nil)
+	require.NoError(t, err)
+
+	from := make(map[string]int)
+	for i := 0; i < 4000; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		from[recorder.Header().Get("X-From")]++
+	}
+
+	// The standard deviation of the number of requests to each server is about 27.
+	assert.InDelta(t, 3000, from["first"], 150)
+	assert.InDelta(t, 1000, from["second"], 150)
+	assert.Zero(t, from["drained"])
+}
+
+func TestWeightedRandom_StickySession(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-From", req.URL.Host)
+	})
+
+	lb, err := newWeightedRandom(next, roundrobin.NewStickySession("sticky"))
+	require.NoError(t, err)
+
+	require.NoError(t, lb.UpsertServer(testhelpers.MustParseURL("http://first"), roundrobin.Weight(1)))
+	require.NoError(t, lb.UpsertServer(testhelpers.MustParseURL("http://second"), roundrobin.Weight(1)))
+
+	recorder := httptest.NewRecorder()
+	lb.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	pinned := recorder.Header().Get("X-From")
+
+	for i := 0; i < 20; i++ {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+		req.AddCookie(cookies[0])
+
+		recorder := httptest.NewRecorder()
+		lb.ServeHTTP(recorder, req)
+
+		assert.Equal(t, pinned, recorder.Header().Get("X-From"))
+		assert.Empty(t, recorder.Header().Get("Set-Cookie"))
+	}
+}
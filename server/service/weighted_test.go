@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeighted(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		services []config.WeightedService
+		expected map[string]int
+	}{
+		{
+			desc:     "exact split",
+			services: []config.WeightedService{{Name: "first", Weight: 3}, {Name: "second", Weight: 1}},
+			expected: map[string]int{"first": 75, "second": 25},
+		},
+		{
+			desc:     "service without weight",
+			services: []config.WeightedService{{Name: "first", Weight: 1}, {Name: "second"}},
+			expected: map[string]int{"first": 100},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			configs, closeServers := newWeightedServices(test.services, nil)
+			defer closeServers()
+
+			sm := NewManager(configs, http.DefaultTransport, nil)
+
+			handler, err := sm.Build(context.Background(), "provider.split", nil)
+			require.NoError(t, err)
+
+			counts := make(map[string]int)
+			for i := 0; i < 100; i++ {
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+				counts[recorder.Header().Get("X-From")]++
+			}
+
+			assert.Equal(t, test.expected, counts)
+		})
+	}
+}
+
+func TestWeighted_Sequence(t *testing.T) {
+	services := []config.WeightedService{{Name: "first", Weight: 3}, {Name: "second", Weight: 1}}
+	configs, closeServers := newWeightedServices(services, nil)
+	defer closeServers()
+
+	sm := NewManager(configs, http.DefaultTransport, nil)
+
+	handler, err := sm.Build(context.Background(), "provider.split", nil)
+	require.NoError(t, err)
+
+	var sequence []string
+	for i := 0; i < 8; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+		sequence = append(sequence, recorder.Header().Get("X-From"))
+	}
+
+	// The smooth weighted round-robin interleaves the children instead of sending bursts to the heaviest.
+	expected := []string{"first", "first", "second", "first", "first", "first", "second", "first"}
+	assert.Equal(t, expected, sequence)
+}
+
+func TestWeighted_Sticky(t *testing.T) {
+	services := []config.WeightedService{{Name: "first", Weight: 1}, {Name: "second", Weight: 1}}
+	configs, closeServers := newWeightedServices(services, &config.Stickiness{CookieName: "split", HTTPOnly: true})
+	defer closeServers()
+
+	sm := NewManager(configs, http.DefaultTransport, nil)
+
+	handler, err := sm.Build(context.Background(), "provider.split", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "split", cookies[0].Name)
+	assert.Equal(t, "first", cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+
+	for i := 0; i < 10; i++ {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+		req.AddCookie(&http.Cookie{Name: "split", Value: "first"})
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, "first", recorder.Header().Get("X-From"))
+		assert.Empty(t, recorder.Header().Get("Set-Cookie"))
+	}
+
+	req := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+	req.AddCookie(&http.Cookie{Name: "split", Value: "unknown"})
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, "second", recorder.Header().Get("X-From"))
+	assert.Contains(t, recorder.Header().Get("Set-Cookie"), "split=second")
+}
+
+func TestWeighted_StickyLoadBalancers(t *testing.T) {
+	services := []config.WeightedService{{Name: "first", Weight: 1}, {Name: "second", Weight: 1}}
+	configs, closeServers := newWeightedServices(services, &config.Stickiness{CookieName: "split"})
+	defer closeServers()
+
+	// Each service load-balances between two servers, with its own sticky cookie.
+	for _, name := range []string{"first", "second"} {
+		lb := configs["provider."+name].LoadBalancer
+		lb.Stickiness = &config.Stickiness{CookieName: name + "-server"}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", r.Host)
+		}))
+		defer server.Close()
+		lb.Servers = append(lb.Servers, config.Server{URL: server.URL, Weight: 1})
+	}
+
+	sm := NewManager(configs, http.DefaultTransport, nil)
+
+	handler, err := sm.Build(context.Background(), "provider.split", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+	cookies := make(map[string]string)
+	for _, c := range recorder.Result().Cookies() {
+		cookies[c.Name] = c.Value
+	}
+	require.Len(t, cookies, 2)
+	assert.Equal(t, "first", cookies["split"])
+	require.NotEmpty(t, cookies["first-server"])
+	from := recorder.Header().Get("X-From")
+
+	for i := 0; i < 10; i++ {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+		req.AddCookie(&http.Cookie{Name: "split", Value: cookies["split"]})
+		req.AddCookie(&http.Cookie{Name: "first-server", Value: cookies["first-server"]})
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, from, recorder.Header().Get("X-From"))
+		assert.Empty(t, recorder.Header().Get("Set-Cookie"))
+	}
+}
+
+func TestWeighted_StickyCookieConflict(t *testing.T) {
+	services := []config.WeightedService{{Name: "first", Weight: 1}, {Name: "second", Weight: 1}}
+	configs, closeServers := newWeightedServices(services, &config.Stickiness{CookieName: "sticky"})
+	defer closeServers()
+
+	configs["provider.second"].LoadBalancer.Stickiness = &config.Stickiness{CookieName: "sticky"}
+
+	sm := NewManager(configs, http.DefaultTransport, nil)
+
+	_, err := sm.Build(context.Background(), "provider.split", nil)
+	assert.Error(t, err)
+}
+
+func TestWeighted_Errors(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		services []config.WeightedService
+	}{
+		{
+			desc: "no service",
+		},
+		{
+			desc:     "no weight",
+			services: []config.WeightedService{{Name: "first"}},
+		},
+		{
+			desc:     "negative weight",
+			services: []config.WeightedService{{Name: "first", Weight: -1}},
+		},
+		{
+			desc:     "unknown service",
+			services: []config.WeightedService{{Name: "unknown", Weight: 1}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			configs, closeServers := newWeightedServices(test.services, nil)
+			defer closeServers()
+
+			sm := NewManager(configs, http.DefaultTransport, nil)
+
+			_, err := sm.Build(context.Background(), "provider.split", nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWeighted_Recursion(t *testing.T) {
+	sm := NewManager(map[string]*config.Service{
+		"provider.split": {
+			Weighted: &config.Weighted{
+				Services: []config.WeightedService{{Name: "split", Weight: 1}},
+			},
+		},
+	}, http.DefaultTransport, nil)
+
+	_, err := sm.Build(context.Background(), "provider.split", nil)
+	assert.Error(t, err)
+}
+
+// newWeightedServices creates the provider.split weighted service,
+// and the provider.first and provider.second services which respond with their name in the X-From header.
+func newWeightedServices(services []config.WeightedService, sticky *config.Stickiness) (map[string]*config.Service, func()) {
+	var servers []*httptest.Server
+	configs := map[string]*config.Service{
+		"provider.split": {
+			Weighted: &config.Weighted{Services: services, Sticky: sticky},
+		},
+	}
+
+	for _, name := range []string{"first", "second"} {
+		name := name
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", name)
+		}))
+		servers = append(servers, server)
+
+		configs["provider."+name] = &config.Service{
+			LoadBalancer: &config.LoadBalancerService{
+				Method:  "wrr",
+				Servers: []config.Server{{URL: server.URL, Weight: 1}},
+			},
+		}
+	}
+
+	return configs, func() {
+		for _, server := range servers {
+			server.Close()
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+)
+
+var errTLSHandshakeListenerClosed = errors.New("use of closed TLS listener")
+
+// tlsHandshakeListener is a TLS listener performing the handshakes of the accepted connections in their own goroutines,
+// and closing the connections whose handshake is not complete before the timeout.
+// The connections are returned by Accept once their handshake is complete.
+type tlsHandshakeListener struct {
+	net.Listener
+	config  *tls.Config
+	timeout time.Duration
+
+	conns     chan net.Conn
+	errs      chan error
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+// newTLSHandshakeListener creates a TLS listener which uses the TLS config as is, as tls.NewListener does.
+func newTLSHandshakeListener(inner net.Listener, config *tls.Config, timeout time.Duration) net.Listener {
+	l := &tlsHandshakeListener{
+		Listener: inner,
+		config:   config,
+		timeout:  timeout,
+		conns:    make(chan net.Conn),
+		errs:     make(chan error),
+		closing:  make(chan struct{}),
+	}
+
+	go l.acceptLoop()
+
+	return l
+}
+
+func (l *tlsHandshakeListener) acceptLoop() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			select {
+			case l.errs <- err:
+			case <-l.closing:
+				return
+			}
+
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				continue
+			}
+			return
+		}
+
+		go l.handshake(conn)
+	}
+}
+
+func (l *tlsHandshakeListener) handshake(conn net.Conn) {
+	tlsConn := tls.Server(conn, l.config)
+
+	if err := conn.SetDeadline(time.Now().Add(l.timeout)); err != nil {
+		log.WithoutContext().Debugf("Unable to set the TLS handshake deadline of the connection from %s: %v", conn.RemoteAddr(), err)
+	}
+
+	if err := tlsConn.Handshake(); err != nil {
+		log.WithoutContext().Debugf("TLS handshake error from %s: %v", conn.RemoteAddr(), err)
+		_ = conn.Close()
+		return
+	}
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		log.WithoutContext().Debugf("Unable to reset the deadline of the connection from %s: %v", conn.RemoteAddr(), err)
+	}
+
+	select {
+	case l.conns <- tlsConn:
+	case <-l.closing:
+		_ = conn.Close()
+	}
+}
+
+// Accept returns the next connection whose TLS handshake is complete.
+func (l *tlsHandshakeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case err := <-l.errs:
+		return nil, err
+	case <-l.closing:
+		return nil, errTLSHandshakeListenerClosed
+	}
+}
+
+// Close closes the listener, and the connections whose handshake completes afterwards.
+func (l *tlsHandshakeListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closing)
+	})
+	return l.Listener.Close()
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config/static"
+	traefiktls "github.com/containous/traefik/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryPoint_TLSHandshakeTimeout(t *testing.T) {
+	entryPoint, err := NewEntryPoint(context.Background(), &static.EntryPoint{
+		Address:             "127.0.0.1:0",
+		Transport:           &static.EntryPointsTransport{},
+		ForwardedHeaders:    &static.ForwardedHeaders{},
+		TLS:                 &traefiktls.TLS{},
+		TLSHandshakeTimeout: parse.Duration(200 * time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	go entryPoint.Start(context.Background())
+	defer entryPoint.httpServer.Close()
+
+	addr := entryPoint.listener.Addr().String()
+
+	stalled, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer stalled.Close()
+
+	// The client stalls after the header of its ClientHello record.
+	_, err = stalled.Write([]byte{0x16, 0x03, 0x01, 0x02, 0x00})
+	require.NoError(t, err)
+
+	start := time.Now()
+	require.NoError(t, stalled.SetReadDeadline(start.Add(5*time.Second)))
+
+	_, err = stalled.Read(make([]byte, 1))
+	require.Error(t, err)
+
+	netErr, ok := err.(net.Error)
+	assert.False(t, ok && netErr.Timeout(), "the connection was not closed by the entry point")
+	assert.True(t, time.Since(start) < 2*time.Second, "the connection was closed after %s", time.Since(start))
+
+	// The connections completing their handshake are served meanwhile.
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	assert.NoError(t, conn.Close())
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/ip"
+	"github.com/containous/traefik/metrics"
+	"github.com/containous/traefik/middlewares/forwardedheaders"
+	"github.com/containous/traefik/responsemodifiers"
+	"github.com/containous/traefik/server/internal"
+	"github.com/containous/traefik/server/middleware"
+	"github.com/containous/traefik/server/router"
+	"github.com/containous/traefik/server/service"
+	traefiktls "github.com/containous/traefik/tls"
+)
+
+// Kinds of the configuration elements reported by the validation.
+const (
+	KindStatic     = "static"
+	KindProvider   = "provider"
+	KindEntryPoint = "entryPoint"
+	KindRouter     = "router"
+	KindMiddleware = "middleware"
+	KindService    = "service"
+	KindTLS        = "tls"
+)
+
+// ConfigurationError is an error found by the validation of a configuration element.
+type ConfigurationError struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+func (e ConfigurationError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Kind, e.Name, e.Message)
+}
+
+// ValidateEntryPoints checks the entry points of the static configuration, without binding their addresses.
+func ValidateEntryPoints(entryPoints static.EntryPoints) []ConfigurationError {
+	var errs []ConfigurationError
+	for entryPointName, entryPoint := range entryPoints {
+		if err := validateEntryPoint(entryPoint); err != nil {
+			errs = append(errs, ConfigurationError{Kind: KindEntryPoint, Name: entryPointName, Message: err.Error()})
+		}
+	}
+
+	sortConfigurationErrors(errs)
+	return errs
+}
+
+func validateEntryPoint(entryPoint *static.EntryPoint) error {
+	if err := validateAddress(entryPoint); err != nil {
+		return fmt.Errorf("invalid address: %v", err)
+	}
+
+	if entryPoint.MaxHeaderBytes < 0 {
+		return fmt.Errorf("invalid max header bytes %d: it must be positive", entryPoint.MaxHeaderBytes)
+	}
+
+	if entryPoint.MaxResetStreams < 0 {
+		return fmt.Errorf("invalid max reset streams %d: it must be positive", entryPoint.MaxResetStreams)
+	}
+
+	if entryPoint.ForwardedHeaders != nil {
+		_, err := forwardedheaders.NewXForwarded(entryPoint.ForwardedHeaders.Insecure, entryPoint.ForwardedHeaders.TrustedIPs, entryPoint.ForwardedHeaders.XForwardedForMode, http.NotFoundHandler())
+		if err != nil {
+			return fmt.Errorf("invalid forwarded headers: %v", err)
+		}
+	}
+
+	if entryPoint.ProxyProtocol != nil && !entryPoint.ProxyProtocol.Insecure {
+		if _, err := ip.NewChecker(entryPoint.ProxyProtocol.TrustedIPs); err != nil {
+			return fmt.Errorf("invalid proxy protocol: %v", err)
+		}
+	}
+
+	if entryPoint.TLS == nil {
+		return nil
+	}
+
+	if _, err := buildCertificateStore(*entryPoint.TLS); err != nil {
+		return fmt.Errorf("error creating certificate store: %v", err)
+	}
+
+	tlsConfig, err := buildTLSConfig(*entryPoint.TLS)
+	if err != nil {
+		return fmt.Errorf("error creating TLS config: %v", err)
+	}
+
+	if entryPoint.TLS.SessionTickets != nil {
+		if _, err := traefiktls.NewSessionTicketKeys(*entryPoint.TLS.SessionTickets, tlsConfig); err != nil {
+			return fmt.Errorf("error creating TLS session ticket keys: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func validateAddress(entryPoint *static.EntryPoint) error {
+	if socketPath, ok := entryPoint.UnixSocketPath(); ok {
+		if socketPath == "" {
+			return errors.New("empty Unix socket path")
+		}
+		if entryPoint.SocketMode != "" {
+			_, err := parseSocketMode(entryPoint.SocketMode)
+			return err
+		}
+		return nil
+	}
+
+	_, port, err := net.SplitHostPort(entryPoint.Address)
+	if err != nil {
+		return err
+	}
+	if _, err = net.LookupPort("tcp", port); err != nil {
+		return err
+	}
+	_, err = listenNetwork(entryPoint)
+	return err
+}
+
+// ValidateConfigurations checks the dynamic configurations as they would be applied to the given entry points:
+// the rules must parse, the referenced services and middlewares must exist and build, and the certificates must load.
+// No health check is launched.
+func ValidateConfigurations(entryPoints static.EntryPoints, configurations config.Configurations) []ConfigurationError {
+	ctx := context.Background()
+
+	var entryPointNames []string
+	entryPointsMiddlewares := make(map[string][]string)
+	for entryPointName, entryPoint := range entryPoints {
+		entryPointNames = append(entryPointNames, entryPointName)
+		entryPointsMiddlewares[entryPointName] = entryPoint.Middlewares
+	}
+
+	conf := mergeConfiguration(configurations)
+
+	serviceManager := service.NewManager(conf.Services, http.DefaultTransport, metrics.NewVoidRegistry())
+	middlewaresBuilder := middleware.NewBuilder(conf.Middlewares, serviceManager, nil)
+	responseModifierFactory := responsemodifiers.NewBuilder(conf.Middlewares)
+	routerManager := router.NewManager(conf.Routers, serviceManager, middlewaresBuilder, responseModifierFactory, entryPointsMiddlewares, nil)
+
+	var errs []ConfigurationError
+
+	for routerName, err := range routerManager.Validate(ctx, entryPointNames) {
+		errs = append(errs, ConfigurationError{Kind: KindRouter, Name: routerName, Message: err.Error()})
+	}
+
+	// The client certificates required by a router are verified by the client CAs of its entry points.
+	for routerName, routerConf := range conf.Routers {
+		if !routerConf.ClientCertificateRequired() {
+			continue
+		}
+
+		for _, entryPointName := range routerConf.EntryPoints {
+			entryPoint, ok := entryPoints[entryPointName]
+			if ok && (entryPoint.TLS == nil || len(entryPoint.TLS.ClientCA.Files) == 0) {
+				message := fmt.Sprintf("client certificate required, but the entry point %s has no client CA", entryPointName)
+				errs = append(errs, ConfigurationError{Kind: KindRouter, Name: routerName, Message: message})
+			}
+		}
+	}
+
+	// The middlewares and services which are not used by any router are checked as well.
+	for middlewareName := range conf.Middlewares {
+		middlewareCtx := internal.AddProviderInContext(ctx, middlewareName)
+		if _, err := middlewaresBuilder.BuildChain(middlewareCtx, []string{middlewareName}).Then(http.NotFoundHandler()); err != nil {
+			errs = append(errs, ConfigurationError{Kind: KindMiddleware, Name: middlewareName, Message: err.Error()})
+		}
+	}
+
+	for serviceName := range conf.Services {
+		serviceCtx := internal.AddProviderInContext(ctx, serviceName)
+		if _, err := serviceManager.Build(serviceCtx, serviceName, nil); err != nil {
+			errs = append(errs, ConfigurationError{Kind: KindService, Name: serviceName, Message: err.Error()})
+		}
+	}
+
+	for providerName, configuration := range configurations {
+		if configuration == nil {
+			continue
+		}
+
+		for i, tlsConf := range configuration.TLS {
+			if err := validateTLSConfiguration(tlsConf, entryPoints); err != nil {
+				name := fmt.Sprintf("%s[%d]", providerName, i)
+				errs = append(errs, ConfigurationError{Kind: KindTLS, Name: name, Message: err.Error()})
+			}
+		}
+	}
+
+	sortConfigurationErrors(errs)
+	return errs
+}
+
+func validateTLSConfiguration(tlsConf *traefiktls.Configuration, entryPoints static.EntryPoints) error {
+	for _, entryPointName := range tlsConf.EntryPoints {
+		entryPoint, ok := entryPoints[entryPointName]
+		if !ok {
+			return fmt.Errorf("entryPoint %q doesn't exist", entryPointName)
+		}
+		if entryPoint.TLS == nil {
+			return fmt.Errorf("entryPoint %q has no TLS configuration", entryPointName)
+		}
+	}
+
+	if tlsConf.Certificate == nil {
+		return errors.New("no certificate")
+	}
+
+	return tlsConf.Certificate.AppendCertificates(make(map[string]map[string]*tls.Certificate), "validation")
+}
+
+func sortConfigurationErrors(errs []ConfigurationError) {
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].Kind != errs[j].Kind {
+			return errs[i].Kind < errs[j].Kind
+		}
+		return errs[i].Name < errs[j].Name
+	})
+}
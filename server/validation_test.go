@@ -0,0 +1,255 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/static"
+	th "github.com/containous/traefik/testhelpers"
+	"github.com/containous/traefik/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateEntryPoints(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		entryPoint  *static.EntryPoint
+		expectedErr bool
+	}{
+		{
+			desc:       "valid entry point",
+			entryPoint: &static.EntryPoint{Address: ":8080"},
+		},
+		{
+			desc:        "address without port",
+			entryPoint:  &static.EntryPoint{Address: "localhost"},
+			expectedErr: true,
+		},
+		{
+			desc:        "invalid port",
+			entryPoint:  &static.EntryPoint{Address: ":foo-bar"},
+			expectedErr: true,
+		},
+		{
+			desc:        "IPv6 only on an IPv4 address",
+			entryPoint:  &static.EntryPoint{Address: "0.0.0.0:8080", IPv6Only: true},
+			expectedErr: true,
+		},
+		{
+			desc:       "unix socket",
+			entryPoint: &static.EntryPoint{Address: "unix:///var/run/traefik.sock", SocketMode: "0660"},
+		},
+		{
+			desc:        "invalid socket mode",
+			entryPoint:  &static.EntryPoint{Address: "unix:///var/run/traefik.sock", SocketMode: "rw"},
+			expectedErr: true,
+		},
+		{
+			desc: "invalid forwarded headers trusted IPs",
+			entryPoint: &static.EntryPoint{
+				Address:          ":8080",
+				ForwardedHeaders: &static.ForwardedHeaders{TrustedIPs: []string{"10.0.0.300"}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "invalid proxy protocol trusted IPs",
+			entryPoint: &static.EntryPoint{
+				Address:       ":8080",
+				ProxyProtocol: &static.ProxyProtocol{TrustedIPs: []string{"foo"}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "valid TLS",
+			entryPoint: &static.EntryPoint{
+				Address: ":8443",
+				TLS: &tls.TLS{
+					DefaultCertificate: &tls.Certificate{CertFile: localhostCert, KeyFile: localhostKey},
+				},
+			},
+		},
+		{
+			desc: "unreadable default certificate",
+			entryPoint: &static.EntryPoint{
+				Address: ":8443",
+				TLS: &tls.TLS{
+					DefaultCertificate: &tls.Certificate{CertFile: "/does/not/exist.crt", KeyFile: localhostKey},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "invalid session ticket key",
+			entryPoint: &static.EntryPoint{
+				Address: ":8443",
+				TLS: &tls.TLS{
+					SessionTickets: &tls.SessionTickets{Keys: tls.FilesOrContents{"Zm9vYmFy"}},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			errs := ValidateEntryPoints(static.EntryPoints{"web": test.entryPoint})
+
+			if !test.expectedErr {
+				assert.Empty(t, errs)
+				return
+			}
+
+			require.Len(t, errs, 1)
+			assert.Equal(t, KindEntryPoint, errs[0].Kind)
+			assert.Equal(t, "web", errs[0].Name)
+		})
+	}
+}
+
+func TestValidateConfigurations(t *testing.T) {
+	entryPoints := static.EntryPoints{
+		"web":       &static.EntryPoint{Address: ":80"},
+		"websecure": &static.EntryPoint{Address: ":443", TLS: &tls.TLS{}},
+	}
+
+	testCases := []struct {
+		desc           string
+		configuration  *config.Configuration
+		expectedErrors []ConfigurationError
+	}{
+		{
+			desc: "valid configuration",
+			configuration: th.BuildConfiguration(
+				th.WithRouters(th.WithRouter("foo",
+					th.WithEntryPoints("web"),
+					th.WithServiceName("bar"),
+					th.WithRule("Path(`/foo`)"))),
+				th.WithLoadBalancerServices(th.WithService("bar",
+					th.WithLBMethod("wrr"),
+					th.WithServers(th.WithServer("http://127.0.0.1")))),
+			),
+		},
+		{
+			desc: "invalid rule",
+			configuration: th.BuildConfiguration(
+				th.WithRouters(th.WithRouter("foo",
+					th.WithEntryPoints("web"),
+					th.WithServiceName("bar"),
+					th.WithRule("Invalid(`/foo`)"))),
+				th.WithLoadBalancerServices(th.WithService("bar",
+					th.WithLBMethod("wrr"),
+					th.WithServers(th.WithServer("http://127.0.0.1")))),
+			),
+			expectedErrors: []ConfigurationError{{Kind: KindRouter, Name: "config.foo"}},
+		},
+		{
+			desc: "unknown service and entry point",
+			configuration: th.BuildConfiguration(
+				th.WithRouters(
+					th.WithRouter("foo",
+						th.WithEntryPoints("web"),
+						th.WithServiceName("unknown"),
+						th.WithRule("Path(`/foo`)")),
+					th.WithRouter("bar",
+						th.WithEntryPoints("unknown"),
+						th.WithServiceName("bar"),
+						th.WithRule("Path(`/bar`)"))),
+				th.WithLoadBalancerServices(th.WithService("bar",
+					th.WithLBMethod("wrr"),
+					th.WithServers(th.WithServer("http://127.0.0.1")))),
+			),
+			expectedErrors: []ConfigurationError{
+				{Kind: KindRouter, Name: "config.bar"},
+				{Kind: KindRouter, Name: "config.foo"},
+			},
+		},
+		{
+			desc: "unknown middleware and unused invalid middleware",
+			configuration: th.BuildConfiguration(
+				th.WithRouters(th.WithRouter("foo",
+					th.WithEntryPoints("web"),
+					th.WithServiceName("bar"),
+					th.WithRule("Path(`/foo`)"),
+					th.WithRouterMiddlewares("unknown"))),
+				th.WithLoadBalancerServices(th.WithService("bar",
+					th.WithLBMethod("wrr"),
+					th.WithServers(th.WithServer("http://127.0.0.1")))),
+				th.WithMiddlewares(th.WithMiddleware("whitelist", func(middleware *config.Middleware) {
+					middleware.IPWhiteList = &config.IPWhiteList{SourceRange: []string{"10.0.0.300"}}
+				})),
+			),
+			expectedErrors: []ConfigurationError{
+				{Kind: KindMiddleware, Name: "config.whitelist"},
+				{Kind: KindRouter, Name: "config.foo"},
+			},
+		},
+		{
+			desc: "client certificate required without client CA",
+			configuration: th.BuildConfiguration(
+				th.WithRouters(th.WithRouter("admin",
+					th.WithEntryPoints("websecure"),
+					th.WithServiceName("bar"),
+					th.WithRule("Path(`/admin`)"),
+					func(r *config.Router) {
+						r.TLS = &config.RouterTLSConfig{ClientCertificateRequired: true}
+					})),
+				th.WithLoadBalancerServices(th.WithService("bar",
+					th.WithLBMethod("wrr"),
+					th.WithServers(th.WithServer("http://127.0.0.1")))),
+			),
+			expectedErrors: []ConfigurationError{{Kind: KindRouter, Name: "config.admin"}},
+		},
+		{
+			desc: "unused invalid service",
+			configuration: &config.Configuration{
+				Services: map[string]*config.Service{
+					"bar": {Weighted: &config.Weighted{}},
+				},
+			},
+			expectedErrors: []ConfigurationError{{Kind: KindService, Name: "config.bar"}},
+		},
+		{
+			desc: "certificates",
+			configuration: &config.Configuration{
+				TLS: []*tls.Configuration{
+					{
+						EntryPoints: []string{"websecure"},
+						Certificate: &tls.Certificate{CertFile: localhostCert, KeyFile: localhostKey},
+					},
+					{
+						EntryPoints: []string{"web"},
+						Certificate: &tls.Certificate{CertFile: localhostCert, KeyFile: localhostKey},
+					},
+					{
+						Certificate: &tls.Certificate{CertFile: localhostCert, KeyFile: "/does/not/exist.key"},
+					},
+				},
+			},
+			expectedErrors: []ConfigurationError{
+				{Kind: KindTLS, Name: "config[1]"},
+				{Kind: KindTLS, Name: "config[2]"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			errs := ValidateConfigurations(entryPoints, config.Configurations{"config": test.configuration})
+
+			require.Len(t, errs, len(test.expectedErrors), "%v", errs)
+			for i, expected := range test.expectedErrors {
+				assert.Equal(t, expected.Kind, errs[i].Kind)
+				assert.Equal(t, expected.Name, errs[i].Name)
+				assert.NotEmpty(t, errs[i].Message)
+			}
+		})
+	}
+}
@@ -137,6 +137,13 @@ func WithServer(url string, opts ...func(*config.Server)) func(*config.Server) {
 	}
 }
 
+// WithWeight is a helper to create a configuration.
+func WithWeight(weight int) func(*config.Server) {
+	return func(s *config.Server) {
+		s.Weight = weight
+	}
+}
+
 // WithLBMethod is a helper to create a configuration.
 func WithLBMethod(method string) func(*config.LoadBalancerService) {
 	return func(b *config.LoadBalancerService) {
@@ -55,3 +55,20 @@ func (m *CollectingHealthCheckMetrics) BackendServerUpGauge() metrics.Gauge {
 func NewCollectingHealthCheckMetrics() *CollectingHealthCheckMetrics {
 	return &CollectingHealthCheckMetrics{&CollectingGauge{}}
 }
+
+// CollectingHistogram is a metrics.Histogram implementation that enables access to the observed values.
+type CollectingHistogram struct {
+	Values          []float64
+	LastLabelValues []string
+}
+
+// With is there to satisfy the metrics.Histogram interface.
+func (h *CollectingHistogram) With(labelValues ...string) metrics.Histogram {
+	h.LastLabelValues = labelValues
+	return h
+}
+
+// Observe is there to satisfy the metrics.Histogram interface.
+func (h *CollectingHistogram) Observe(value float64) {
+	h.Values = append(h.Values, value)
+}
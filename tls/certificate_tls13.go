@@ -0,0 +1,10 @@
+// +build go1.12
+
+package tls
+
+import "crypto/tls"
+
+// TLS 1.3 is only supported since Go 1.12.
+func init() {
+	MinVersion[`VersionTLS13`] = tls.VersionTLS13
+}
@@ -0,0 +1,155 @@
+package tls
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/log"
+)
+
+const (
+	defaultSessionTicketsRotationInterval = parse.Duration(12 * time.Hour)
+	sessionTicketKeyLength                = 32
+)
+
+// SessionTickets configures the keys which encrypt the TLS session tickets.
+// The Traefik instances sharing the same keys can resume the sessions of each other.
+// Without keys, a new random key is generated at every rotation.
+type SessionTickets struct {
+	// Keys are base64 encoded 32 bytes keys, read again at every rotation. The first one encrypts the new tickets.
+	Keys             FilesOrContents
+	RotationInterval parse.Duration `export:"true"`
+	// GracePeriod is how long the replaced keys can still decrypt the tickets, defaults to the rotation interval.
+	GracePeriod parse.Duration `export:"true"`
+}
+
+type retiredSessionTicketKey struct {
+	key     [sessionTicketKeyLength]byte
+	expires time.Time
+}
+
+// SessionTicketKeys rotates the session ticket keys of a TLS configuration.
+type SessionTicketKeys struct {
+	conf      SessionTickets
+	tlsConfig *tls.Config
+
+	lock    sync.Mutex
+	current [][sessionTicketKeyLength]byte
+	retired []retiredSessionTicketKey
+}
+
+// NewSessionTicketKeys sets the initial session ticket keys of the TLS configuration.
+func NewSessionTicketKeys(conf SessionTickets, tlsConfig *tls.Config) (*SessionTicketKeys, error) {
+	if conf.RotationInterval <= 0 {
+		conf.RotationInterval = defaultSessionTicketsRotationInterval
+	}
+	if conf.GracePeriod <= 0 {
+		conf.GracePeriod = conf.RotationInterval
+	}
+
+	keys := &SessionTicketKeys{conf: conf, tlsConfig: tlsConfig}
+	if err := keys.Rotate(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Run rotates the keys at the configured interval, until stopped.
+func (k *SessionTicketKeys) Run(stop chan bool) {
+	ticker := time.NewTicker(time.Duration(k.conf.RotationInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := k.Rotate(); err != nil {
+				log.WithoutContext().Errorf("Unable to rotate the TLS session ticket keys: %v", err)
+			}
+		}
+	}
+}
+
+// Rotate replaces the current keys by the configured keys, or by a new random key.
+// The replaced keys can still decrypt the session tickets during the grace period.
+func (k *SessionTicketKeys) Rotate() error {
+	keys, err := k.newKeys()
+	if err != nil {
+		return err
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	now := time.Now()
+
+	for _, key := range k.current {
+		k.retired = append(k.retired, retiredSessionTicketKey{key: key, expires: now.Add(time.Duration(k.conf.GracePeriod))})
+	}
+
+	var retired []retiredSessionTicketKey
+	for _, retiredKey := range k.retired {
+		if retiredKey.expires.After(now) && !containsSessionTicketKey(keys, retiredKey.key) {
+			retired = append(retired, retiredKey)
+		}
+	}
+
+	k.current = keys
+	k.retired = retired
+
+	allKeys := append([][sessionTicketKeyLength]byte{}, keys...)
+	for _, retiredKey := range retired {
+		allKeys = append(allKeys, retiredKey.key)
+	}
+	k.tlsConfig.SetSessionTicketKeys(allKeys)
+
+	return nil
+}
+
+func (k *SessionTicketKeys) newKeys() ([][sessionTicketKeyLength]byte, error) {
+	if len(k.conf.Keys) == 0 {
+		var key [sessionTicketKeyLength]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return nil, fmt.Errorf("unable to generate a session ticket key: %v", err)
+		}
+		return [][sessionTicketKeyLength]byte{key}, nil
+	}
+
+	var keys [][sessionTicketKeyLength]byte
+	for _, fileOrContent := range k.conf.Keys {
+		data, err := fileOrContent.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid session ticket key: %v", err)
+		}
+
+		if len(decoded) != sessionTicketKeyLength {
+			return nil, fmt.Errorf("invalid session ticket key: it must be %d bytes long, got %d", sessionTicketKeyLength, len(decoded))
+		}
+
+		var key [sessionTicketKeyLength]byte
+		copy(key[:], decoded)
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func containsSessionTicketKey(keys [][sessionTicketKeyLength]byte, key [sessionTicketKeyLength]byte) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package tls
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionTicketKeys_SharedKeys(t *testing.T) {
+	key := FileOrContent(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 32))))
+
+	first := newTicketServer(t, SessionTickets{Keys: FilesOrContents{key}})
+	defer first.Close()
+
+	second := newTicketServer(t, SessionTickets{Keys: FilesOrContents{key}})
+	defer second.Close()
+
+	other := newTicketServer(t, SessionTickets{})
+	defer other.Close()
+
+	clientConfig := newTicketClientConfig()
+
+	assert.False(t, dialTicketServer(t, first.Addr().String(), clientConfig))
+	assert.True(t, dialTicketServer(t, second.Addr().String(), clientConfig))
+	assert.False(t, dialTicketServer(t, other.Addr().String(), clientConfig))
+}
+
+func TestSessionTicketKeys_Rotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "session-tickets")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	keyFile := filepath.Join(dir, "ticket.key")
+	writeTicketKey(t, keyFile, "a")
+
+	tlsConfig := newTicketTLSConfig(t)
+	keys, err := NewSessionTicketKeys(SessionTickets{Keys: FilesOrContents{FileOrContent(keyFile)}}, tlsConfig)
+	require.NoError(t, err)
+
+	listener := serveTicketServer(t, tlsConfig)
+	defer listener.Close()
+
+	firstClient := newTicketClientConfig()
+	assert.False(t, dialTicketServer(t, listener.Addr().String(), firstClient))
+
+	secondClient := newTicketClientConfig()
+	assert.False(t, dialTicketServer(t, listener.Addr().String(), secondClient))
+
+	// The replaced key still decrypts the tickets during the grace period.
+	writeTicketKey(t, keyFile, "b")
+	require.NoError(t, keys.Rotate())
+	assert.Len(t, keys.retired, 1)
+	assert.True(t, dialTicketServer(t, listener.Addr().String(), firstClient))
+
+	// Once the grace period is over, the replaced key is dropped.
+	keys.lock.Lock()
+	keys.retired[0].expires = time.Now().Add(-time.Second)
+	keys.lock.Unlock()
+
+	require.NoError(t, keys.Rotate())
+	assert.Empty(t, keys.retired)
+	assert.False(t, dialTicketServer(t, listener.Addr().String(), secondClient))
+}
+
+func TestNewSessionTicketKeys_InvalidKey(t *testing.T) {
+	testCases := []struct {
+		desc string
+		key  FileOrContent
+	}{
+		{
+			desc: "not base64",
+			key:  "not base64!",
+		},
+		{
+			desc: "too short",
+			key:  FileOrContent(base64.StdEncoding.EncodeToString([]byte("short"))),
+		},
+		{
+			desc: "missing file",
+			key:  "/does/not/exist.key",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewSessionTicketKeys(SessionTickets{Keys: FilesOrContents{test.key}}, &tls.Config{})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func writeTicketKey(t *testing.T, path, char string) {
+	t.Helper()
+
+	content := base64.StdEncoding.EncodeToString([]byte(strings.Repeat(char, 32)))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content+"\n"), 0600))
+}
+
+func newTicketTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+
+	cert, err := generate.DefaultCertificate()
+	require.NoError(t, err)
+
+	return &tls.Config{Certificates: []tls.Certificate{*cert}}
+}
+
+func newTicketServer(t *testing.T, conf SessionTickets) net.Listener {
+	t.Helper()
+
+	tlsConfig := newTicketTLSConfig(t)
+	_, err := NewSessionTicketKeys(conf, tlsConfig)
+	require.NoError(t, err)
+
+	return serveTicketServer(t, tlsConfig)
+}
+
+// serveTicketServer accepts TLS connections, and writes a single byte on each of them once the handshake is done.
+func serveTicketServer(t *testing.T, tlsConfig *tls.Config) net.Listener {
+	t.Helper()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+				_, _ = conn.Write([]byte("x"))
+			}(conn)
+		}
+	}()
+
+	return listener
+}
+
+func newTicketClientConfig() *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: true,
+		// The same server name shares the session cache entry between the servers.
+		ServerName:         "traefik.test",
+		MaxVersion:         tls.VersionTLS12,
+		ClientSessionCache: tls.NewLRUClientSessionCache(1),
+	}
+}
+
+// dialTicketServer connects to the server, and tells whether the TLS session was resumed.
+func dialTicketServer(t *testing.T, addr string, clientConfig *tls.Config) bool {
+	t.Helper()
+
+	conn, err := tls.Dial("tcp", addr, clientConfig)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = io.ReadFull(conn, make([]byte, 1))
+	require.NoError(t, err)
+
+	return conn.ConnectionState().DidResume
+}
@@ -24,9 +24,23 @@ type ClientCA struct {
 type TLS struct {
 	MinVersion         string `export:"true"`
 	CipherSuites       []string
+	ALPNProtocols      []string
 	ClientCA           ClientCA
 	DefaultCertificate *Certificate
 	SniStrict          bool `export:"true"`
+	SessionTickets     *SessionTickets
+	// DefaultOptions is the name of the TLS options of the entry point, when no TLS options target it.
+	DefaultOptions string
+}
+
+// Options holds the TLS options of entry points, which override the ones of their static configuration.
+// They are reloaded with the dynamic configuration, and apply to the new connections.
+// Named options can also be referenced by the routers, for their domains, and by the entry points, as their default.
+type Options struct {
+	Name         string
+	EntryPoints  []string
+	MinVersion   string
+	CipherSuites []string
 }
 
 // FilesOrContents hold the CA we want to have in root
@@ -0,0 +1,195 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	zipkin "github.com/openzipkin/zipkin-go-opentracing"
+	"github.com/openzipkin/zipkin-go-opentracing/types"
+	"github.com/uber/jaeger-client-go"
+)
+
+// W3C Trace Context headers.
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+const (
+	traceContextVersion = "00"
+	traceFlagSampled    = 0x01
+)
+
+// TraceContext is the W3C Trace Context of a request.
+type TraceContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+	State   string
+}
+
+// ParseTraceContext parses the traceparent and tracestate headers of the request.
+func ParseTraceContext(header http.Header) (TraceContext, error) {
+	traceParent := strings.TrimSpace(header.Get(TraceParentHeader))
+	if traceParent == "" {
+		return TraceContext{}, fmt.Errorf("no %s header", TraceParentHeader)
+	}
+
+	parts := strings.Split(traceParent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == traceContextVersion && len(parts) != 4) {
+		return TraceContext{}, fmt.Errorf("invalid %s header: %q", TraceParentHeader, traceParent)
+	}
+
+	var tc TraceContext
+	if err := decodeHex(tc.TraceID[:], parts[1]); err != nil || tc.TraceID == [16]byte{} {
+		return TraceContext{}, fmt.Errorf("invalid trace ID in the %s header: %q", TraceParentHeader, traceParent)
+	}
+	if err := decodeHex(tc.SpanID[:], parts[2]); err != nil || tc.SpanID == [8]byte{} {
+		return TraceContext{}, fmt.Errorf("invalid parent ID in the %s header: %q", TraceParentHeader, traceParent)
+	}
+
+	var flags [1]byte
+	if err := decodeHex(flags[:], parts[3]); err != nil {
+		return TraceContext{}, fmt.Errorf("invalid flags in the %s header: %q", TraceParentHeader, traceParent)
+	}
+	tc.Sampled = flags[0]&traceFlagSampled != 0
+
+	tc.State = strings.Join(header[http.CanonicalHeaderKey(TraceStateHeader)], ",")
+
+	return tc, nil
+}
+
+// NewTraceContext creates the Trace Context of a new trace.
+func NewTraceContext(sampled bool) (TraceContext, error) {
+	tc := TraceContext{Sampled: sampled}
+	if _, err := rand.Read(tc.TraceID[:]); err != nil {
+		return TraceContext{}, err
+	}
+	if _, err := rand.Read(tc.SpanID[:]); err != nil {
+		return TraceContext{}, err
+	}
+	return tc, nil
+}
+
+// Child creates the Trace Context of a child span, in the same trace.
+func (tc TraceContext) Child() (TraceContext, error) {
+	child := tc
+	if _, err := rand.Read(child.SpanID[:]); err != nil {
+		return TraceContext{}, err
+	}
+	return child, nil
+}
+
+// TraceParent returns the value of the traceparent header.
+func (tc TraceContext) TraceParent() string {
+	var flags byte
+	if tc.Sampled {
+		flags |= traceFlagSampled
+	}
+	return fmt.Sprintf("%s-%s-%s-%02x", traceContextVersion, hex.EncodeToString(tc.TraceID[:]), hex.EncodeToString(tc.SpanID[:]), flags)
+}
+
+// Inject sets the traceparent and tracestate headers.
+func (tc TraceContext) Inject(header http.Header) {
+	header.Set(TraceParentHeader, tc.TraceParent())
+	if tc.State != "" {
+		header.Set(TraceStateHeader, tc.State)
+	} else {
+		header.Del(TraceStateHeader)
+	}
+}
+
+// SpanContextFromTraceContext returns the span context of the tracing backend continuing the W3C Trace Context,
+// so that the spans of the backend belong to the same trace, or nil if the backend cannot continue it.
+func (t *Tracing) SpanContextFromTraceContext(tc TraceContext) opentracing.SpanContext {
+	high := binary.BigEndian.Uint64(tc.TraceID[:8])
+	low := binary.BigEndian.Uint64(tc.TraceID[8:])
+	spanID := binary.BigEndian.Uint64(tc.SpanID[:])
+
+	switch t.tracer.(type) {
+	case *jaeger.Tracer:
+		return jaeger.NewSpanContext(jaeger.TraceID{High: high, Low: low}, jaeger.SpanID(spanID), 0, tc.Sampled, nil)
+	case zipkin.Tracer:
+		return zipkin.SpanContext{TraceID: types.TraceID{High: high, Low: low}, SpanID: spanID, Sampled: tc.Sampled}
+	default:
+		// Datadog has no exported span context: it extracts its own headers, which hold the lower 64 bits of the trace ID.
+		priority := "0"
+		if tc.Sampled {
+			priority = "1"
+		}
+
+		header := make(http.Header)
+		header.Set("X-Datadog-Trace-Id", strconv.FormatUint(low, 10))
+		header.Set("X-Datadog-Parent-Id", strconv.FormatUint(spanID, 10))
+		header.Set("X-Datadog-Sampling-Priority", priority)
+
+		sc, err := t.tracer.Extract(opentracing.HTTPHeaders, HTTPHeadersCarrier(header))
+		if err != nil {
+			return nil
+		}
+		return sc
+	}
+}
+
+// TraceContextFromSpan returns the W3C Trace Context of the span, if its tracing backend is known.
+// The state, and the sampling decision if the backend does not expose it, are the ones of the parent Trace Context.
+// The trace ID of the parent is kept when the backend only knows its lower 64 bits.
+func TraceContextFromSpan(span opentracing.Span, parent TraceContext) (TraceContext, bool) {
+	tc := TraceContext{Sampled: parent.Sampled, State: parent.State}
+
+	var high, low, spanID uint64
+	switch sc := span.Context().(type) {
+	case jaeger.SpanContext:
+		high, low, spanID = sc.TraceID().High, sc.TraceID().Low, uint64(sc.SpanID())
+		tc.Sampled = sc.IsSampled()
+	case zipkin.SpanContext:
+		high, low, spanID = sc.TraceID.High, sc.TraceID.Low, sc.SpanID
+		tc.Sampled = sc.Sampled
+	case interface {
+		TraceID() uint64
+		SpanID() uint64
+	}:
+		// Datadog
+		low, spanID = sc.TraceID(), sc.SpanID()
+		if binary.BigEndian.Uint64(parent.TraceID[8:]) == low {
+			high = binary.BigEndian.Uint64(parent.TraceID[:8])
+		}
+	default:
+		return TraceContext{}, false
+	}
+
+	if (high == 0 && low == 0) || spanID == 0 {
+		return TraceContext{}, false
+	}
+
+	binary.BigEndian.PutUint64(tc.TraceID[:8], high)
+	binary.BigEndian.PutUint64(tc.TraceID[8:], low)
+	binary.BigEndian.PutUint64(tc.SpanID[:], spanID)
+	return tc, true
+}
+
+// WithTraceContext adds the Trace Context into the context.
+func WithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey, tc)
+}
+
+// TraceContextFromContext gets the Trace Context from the context.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey).(TraceContext)
+	return tc, ok
+}
+
+func decodeHex(dst []byte, value string) error {
+	if len(value) != 2*len(dst) || strings.ToLower(value) != value {
+		return fmt.Errorf("invalid hexadecimal value %q", value)
+	}
+	_, err := hex.Decode(dst, []byte(value))
+	return err
+}
@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceContext(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		header      http.Header
+		expected    TraceContext
+		expectedErr bool
+	}{
+		{
+			desc: "sampled",
+			header: http.Header{
+				"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+				"Tracestate":  {"congo=t61rcWkgMzE", "rojo=00f067aa0ba902b7"},
+			},
+			expected: TraceContext{
+				TraceID: [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+				SpanID:  [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+				Sampled: true,
+				State:   "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7",
+			},
+		},
+		{
+			desc:   "not sampled",
+			header: http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"}},
+			expected: TraceContext{
+				TraceID: [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+				SpanID:  [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			},
+		},
+		{
+			desc:   "future version with more fields",
+			header: http.Header{"Traceparent": {"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-foo"}},
+			expected: TraceContext{
+				TraceID: [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+				SpanID:  [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+				Sampled: true,
+			},
+		},
+		{
+			desc:        "no header",
+			header:      http.Header{},
+			expectedErr: true,
+		},
+		{
+			desc:        "invalid version",
+			header:      http.Header{"Traceparent": {"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+			expectedErr: true,
+		},
+		{
+			desc:        "extra fields in version 00",
+			header:      http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-foo"}},
+			expectedErr: true,
+		},
+		{
+			desc:        "zero trace ID",
+			header:      http.Header{"Traceparent": {"00-00000000000000000000000000000000-00f067aa0ba902b7-01"}},
+			expectedErr: true,
+		},
+		{
+			desc:        "zero parent ID",
+			header:      http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"}},
+			expectedErr: true,
+		},
+		{
+			desc:        "upper case trace ID",
+			header:      http.Header{"Traceparent": {"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"}},
+			expectedErr: true,
+		},
+		{
+			desc:        "short parent ID",
+			header:      http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902-01"}},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tc, err := ParseTraceContext(test.header)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, tc)
+		})
+	}
+}
+
+func TestTraceContext_Child(t *testing.T) {
+	tc, err := ParseTraceContext(http.Header{
+		"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		"Tracestate":  {"congo=t61rcWkgMzE"},
+	})
+	require.NoError(t, err)
+
+	child, err := tc.Child()
+	require.NoError(t, err)
+
+	assert.Equal(t, tc.TraceID, child.TraceID)
+	assert.NotEqual(t, tc.SpanID, child.SpanID)
+
+	header := http.Header{}
+	child.Inject(header)
+
+	assert.Regexp(t, `^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-00$`, header.Get(TraceParentHeader))
+	assert.Equal(t, "congo=t61rcWkgMzE", header.Get(TraceStateHeader))
+
+	parsed, err := ParseTraceContext(header)
+	require.NoError(t, err)
+	assert.Equal(t, child, parsed)
+}
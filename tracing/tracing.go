@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/containous/traefik/log"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	zipkin "github.com/openzipkin/zipkin-go-opentracing"
+	"github.com/uber/jaeger-client-go"
 )
 
 type contextKey int
@@ -17,6 +20,14 @@ const (
 	// SpanKindNoneEnum Span kind enum none.
 	SpanKindNoneEnum ext.SpanKindEnum = "none"
 	tracingKey       contextKey       = iota
+	traceContextKey
+)
+
+// Propagation formats.
+const (
+	PropagationVendor = "vendor"
+	PropagationW3C    = "w3c"
+	PropagationAll    = "all"
 )
 
 // WithTracing Adds Tracing into the context.
@@ -46,16 +57,26 @@ type TrackingBackend interface {
 type Tracing struct {
 	ServiceName   string `description:"Set the name for this service" export:"true"`
 	SpanNameLimit int    `description:"Set the maximum character limit for Span names (default 0 = no limit)" export:"true"`
+	Propagation   string `description:"Selects the propagated headers: 'vendor', 'w3c' or 'all' (default 'vendor')" export:"true"`
 
 	tracer opentracing.Tracer
 	closer io.Closer
 }
 
 // NewTracing Creates a Tracing.
-func NewTracing(serviceName string, spanNameLimit int, trackingBackend TrackingBackend) (*Tracing, error) {
+func NewTracing(serviceName string, spanNameLimit int, propagation string, trackingBackend TrackingBackend) (*Tracing, error) {
+	switch propagation {
+	case "":
+		propagation = PropagationVendor
+	case PropagationVendor, PropagationW3C, PropagationAll:
+	default:
+		return nil, fmt.Errorf("unsupported propagation %q: it must be %q, %q or %q", propagation, PropagationVendor, PropagationW3C, PropagationAll)
+	}
+
 	tracing := &Tracing{
 		ServiceName:   serviceName,
 		SpanNameLimit: spanNameLimit,
+		Propagation:   propagation,
 	}
 
 	var err error
@@ -88,6 +109,16 @@ func (t *Tracing) Extract(format interface{}, carrier interface{}) (opentracing.
 	return t.tracer.Extract(format, carrier)
 }
 
+// PropagatesVendor tells whether the headers of the tracing backend are propagated.
+func (t *Tracing) PropagatesVendor() bool {
+	return t.Propagation != PropagationW3C
+}
+
+// PropagatesW3C tells whether the W3C Trace Context headers are propagated.
+func (t *Tracing) PropagatesW3C() bool {
+	return t.Propagation == PropagationW3C || t.Propagation == PropagationAll
+}
+
 // IsEnabled determines if tracing was successfully activated.
 func (t *Tracing) IsEnabled() bool {
 	if t == nil || t.tracer == nil {
@@ -130,6 +161,26 @@ func GetSpan(r *http.Request) opentracing.Span {
 	return opentracing.SpanFromContext(r.Context())
 }
 
+// GetSpanIDs returns the trace ID and the span ID of the span, formatted as by its tracing backend,
+// or empty strings for an unknown backend.
+// The IDs exist even if the trace is not sampled.
+func GetSpanIDs(span opentracing.Span) (string, string) {
+	switch sc := span.Context().(type) {
+	case jaeger.SpanContext:
+		return sc.TraceID().String(), sc.SpanID().String()
+	case zipkin.SpanContext:
+		return sc.TraceID.ToHex(), strconv.FormatUint(sc.SpanID, 16)
+	case interface {
+		TraceID() uint64
+		SpanID() uint64
+	}:
+		// Datadog
+		return strconv.FormatUint(sc.TraceID(), 10), strconv.FormatUint(sc.SpanID(), 10)
+	default:
+		return "", ""
+	}
+}
+
 // InjectRequestHeaders used to inject OpenTracing headers into the request.
 func InjectRequestHeaders(r *http.Request) {
 	if span := GetSpan(r); span != nil {
@@ -5,4 +5,5 @@ type HostResolverConfig struct {
 	CnameFlattening bool   `description:"A flag to enable/disable CNAME flattening" export:"true"`
 	ResolvConfig    string `description:"resolv.conf used for DNS resolving" export:"true"`
 	ResolvDepth     int    `description:"The maximal depth of DNS recursive resolving" export:"true"`
+	CaseSensitive   bool   `description:"Match the Host() rules with the case of the request host instead of lowercasing it" export:"true"`
 }
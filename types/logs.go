@@ -30,6 +30,22 @@ type AccessLog struct {
 	Filters       *AccessLogFilters `json:"filters,omitempty" description:"Access log filters, used to keep only specific access logs" export:"true"`
 	Fields        *AccessLogFields  `json:"fields,omitempty" description:"AccessLogFields" export:"true"`
 	BufferingSize int64             `json:"bufferingSize,omitempty" description:"Number of access log lines to process in a buffered way. Default 0." export:"true"`
+	TimeFormat    string            `json:"timeFormat,omitempty" description:"Access log timestamp format: common | rfc3339. Defaults to the one of the log format" export:"true"`
+	TimeZone      string            `json:"timeZone,omitempty" description:"Access log timestamp time zone: utc | local. Defaults to the one of the log format" export:"true"`
+	// Outputs are additional outputs, which receive the same access logs with their own format, filters and fields.
+	Outputs []AccessLogOutput `json:"outputs,omitempty" export:"true"`
+}
+
+// AccessLogOutput holds the configuration of an additional access log output.
+// The output is written asynchronously: when it cannot keep up, its buffer fills up and the next access logs are dropped.
+type AccessLogOutput struct {
+	FilePath      string            `json:"file,omitempty" export:"true"`
+	Format        string            `json:"format,omitempty" export:"true"`
+	Filters       *AccessLogFilters `json:"filters,omitempty" export:"true"`
+	Fields        *AccessLogFields  `json:"fields,omitempty" export:"true"`
+	BufferingSize int64             `json:"bufferingSize,omitempty" export:"true"`
+	TimeFormat    string            `json:"timeFormat,omitempty" export:"true"`
+	TimeZone      string            `json:"timeZone,omitempty" export:"true"`
 }
 
 // AccessLogFilters holds filters configuration
@@ -12,6 +12,9 @@ type Metrics struct {
 	Datadog    *Datadog    `description:"DataDog metrics exporter type" export:"true"`
 	StatsD     *Statsd     `description:"StatsD metrics exporter type" export:"true"`
 	InfluxDB   *InfluxDB   `description:"InfluxDB metrics exporter type"`
+	// PayloadSizes enables the histograms of the body sizes of the requests and responses of the routers,
+	// which count the bytes of all the bodies.
+	PayloadSizes bool `description:"Enable the histograms of the request and response body sizes of the routers" export:"true"`
 }
 
 // Prometheus can contain specific configuration used by the Prometheus Metrics exporter